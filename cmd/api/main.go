@@ -1,11 +1,15 @@
 package main
 
 import (
+	"context"
 	"log"
+	"math/rand"
+	"time"
 
 	"github.com/NahuelDT/portfolio-api/internal/api"
 	"github.com/NahuelDT/portfolio-api/internal/api/handlers"
 	"github.com/NahuelDT/portfolio-api/internal/config"
+	"github.com/NahuelDT/portfolio-api/internal/marketdata"
 	"github.com/NahuelDT/portfolio-api/internal/repository"
 	"github.com/NahuelDT/portfolio-api/internal/service"
 	"github.com/gin-gonic/gin"
@@ -17,21 +21,119 @@ func main() {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
 
+	settlementCycle, err := config.LoadSettlementCycle()
+	if err != nil {
+		log.Fatalf("Failed to load settlement cycle: %v", err)
+	}
+
+	withdrawalThresholds, err := config.LoadWithdrawalThresholds()
+	if err != nil {
+		log.Fatalf("Failed to load withdrawal thresholds: %v", err)
+	}
+
+	simulatedMarketData, err := config.LoadSimulatedMarketData()
+	if err != nil {
+		log.Fatalf("Failed to load simulated market data: %v", err)
+	}
+
+	marketDataCacheTTL, err := config.LoadMarketDataCacheTTL()
+	if err != nil {
+		log.Fatalf("Failed to load market data cache TTL: %v", err)
+	}
+
 	userRepo := repository.NewUserRepository(db)
 	orderRepo := repository.NewOrderRepository(db)
 	instrumentRepo := repository.NewInstrumentRepository(db)
-	marketDataRepo := repository.NewMarketDataRepository(db)
+	// Orders and valuations read the latest prices from memory; prices
+	// stored by other processes show up within the TTL
+	marketDataRepo := marketdata.NewCache(repository.NewMarketDataRepository(db), marketDataCacheTTL)
+	fxRateRepo := repository.NewFXRateRepository(db)
+	statementRepo := repository.NewStatementRepository(db)
+	distributionRepo := repository.NewDistributionRepository(db)
+	corporateActionRepo := repository.NewCorporateActionRepository(db)
+	positionRepo := repository.NewPositionRepository(db)
+	watchlistRepo := repository.NewWatchlistRepository(db)
+	alertRepo := repository.NewAlertRepository(db)
+	accountRepo := repository.NewAccountRepository(db)
+	transferRepo := repository.NewTransferRepository(db)
+	ledgerRepo := repository.NewLedgerRepository(db)
+	withdrawalRepo := repository.NewWithdrawalRepository(db)
 
-	portfolioService := service.NewPortfolioService(userRepo, orderRepo, instrumentRepo, marketDataRepo)
+	portfolioService := service.NewPortfolioService(userRepo, orderRepo, instrumentRepo, marketDataRepo, fxRateRepo, corporateActionRepo, positionRepo, accountRepo)
 	searchService := service.NewSearchService(instrumentRepo)
-	orderService := service.NewOrderService(orderRepo, userRepo, instrumentRepo, marketDataRepo)
+	// Large withdrawals and the ones that empty an account wait for an
+	// admin's approval
+	orderService := service.NewOrderService(orderRepo, userRepo, instrumentRepo, marketDataRepo, positionRepo, accountRepo, settlementCycle, withdrawalRepo,
+		service.WithdrawalThresholdRule(withdrawalThresholds), service.EmptyAccountWithdrawalRule)
+	performanceService := service.NewPerformanceService(userRepo, orderRepo, instrumentRepo, marketDataRepo, fxRateRepo, corporateActionRepo)
+	riskService := service.NewRiskService(userRepo, orderRepo, instrumentRepo, marketDataRepo, fxRateRepo, corporateActionRepo)
+	taxService := service.NewTaxService(userRepo, orderRepo, instrumentRepo, corporateActionRepo)
+	statementService := service.NewStatementService(userRepo, orderRepo, instrumentRepo, statementRepo, portfolioService, performanceService, ledgerRepo)
+	distributionService := service.NewDistributionService(distributionRepo, orderRepo, instrumentRepo, corporateActionRepo)
+	positionService := service.NewPositionService(orderRepo, positionRepo, corporateActionRepo)
+	corporateActionService := service.NewCorporateActionService(corporateActionRepo, instrumentRepo, marketDataRepo, positionService)
+	watchlistService := service.NewWatchlistService(watchlistRepo, userRepo, instrumentRepo, marketDataRepo)
+	accountService := service.NewAccountService(accountRepo, userRepo)
+	transferService := service.NewTransferService(transferRepo, userRepo, accountRepo, instrumentRepo)
+	ledgerService := service.NewLedgerService(ledgerRepo, orderRepo, corporateActionRepo)
+	withdrawalService := service.NewWithdrawalService(withdrawalRepo)
+	fxRateService := service.NewFXRateService(fxRateRepo)
+	alertService := service.NewAlertService(alertRepo, userRepo, instrumentRepo)
+	quoteHub := service.NewQuoteHub(instrumentRepo)
+	// New prices are stored through the market data service so they reach
+	// the price alerts and the quote stream
+	marketDataService := service.NewMarketDataService(marketDataRepo, alertService, quoteHub)
+
+	// Simulated prices are stored through the market data service as well
+	if len(simulatedMarketData) > 0 {
+		instruments, err := marketdata.SimulateTickers(instrumentRepo, marketDataRepo, simulatedMarketData)
+		if err != nil {
+			log.Fatalf("Failed to set up simulated market data: %v", err)
+		}
+		provider := marketdata.NewSimulatedProvider(instruments, time.Now(), rand.NewSource(time.Now().UnixNano()))
+		go marketdata.NewPoller(provider, marketDataService, marketdata.DefaultPollInterval).Run(context.Background())
+	}
 
 	portfolioHandler := handlers.NewPortfolioHandler(portfolioService)
 	searchHandler := handlers.NewSearchHandler(searchService)
 	orderHandler := handlers.NewOrderHandler(orderService)
+	performanceHandler := handlers.NewPerformanceHandler(performanceService)
+	riskHandler := handlers.NewRiskHandler(riskService)
+	taxHandler := handlers.NewTaxHandler(taxService)
+	statementHandler := handlers.NewStatementHandler(statementService)
+	distributionHandler := handlers.NewDistributionHandler(distributionService)
+	corporateActionHandler := handlers.NewCorporateActionHandler(corporateActionService)
+	watchlistHandler := handlers.NewWatchlistHandler(watchlistService)
+	alertHandler := handlers.NewAlertHandler(alertService)
+	marketDataHandler := handlers.NewMarketDataHandler(marketDataService, marketDataRepo)
+	fxRateHandler := handlers.NewFXRateHandler(fxRateService)
+	accountHandler := handlers.NewAccountHandler(accountService)
+	transferHandler := handlers.NewTransferHandler(transferService)
+	ledgerHandler := handlers.NewLedgerHandler(ledgerService)
+	withdrawalHandler := handlers.NewWithdrawalHandler(withdrawalService)
+	quoteStreamHandler := handlers.NewQuoteStreamHandler(quoteHub)
+
+	// Retry failed price alert deliveries on their own worker, so slow
+	// webhooks do not hold up the hourly jobs
+	go alertService.RunDeliveries(context.Background(), service.AlertDeliveryInterval)
+
+	// Credit distributions and restate positions for corporate actions as
+	// their dates are reached
+	go func() {
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+		for range ticker.C {
+			if err := distributionService.ProcessDistributions(time.Now()); err != nil {
+				log.Printf("Failed to process distributions: %v", err)
+			}
+			if err := positionService.ApplyCorporateActions(time.Now()); err != nil {
+				log.Printf("Failed to apply corporate actions: %v", err)
+			}
+		}
+	}()
 
 	r := gin.Default()
-	api.SetupRoutes(r, portfolioHandler, searchHandler, orderHandler)
+	api.SetupRoutes(r, portfolioHandler, searchHandler, orderHandler, performanceHandler, riskHandler, taxHandler, statementHandler, distributionHandler, corporateActionHandler, watchlistHandler, alertHandler, marketDataHandler, fxRateHandler, accountHandler, transferHandler, ledgerHandler, withdrawalHandler, quoteStreamHandler)
 
 	if err := r.Run(":8080"); err != nil {
 		log.Fatalf("Failed to start server: %v", err)
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/NahuelDT/portfolio-api/internal/config"
+	"github.com/NahuelDT/portfolio-api/internal/repository"
+	"github.com/NahuelDT/portfolio-api/internal/service"
+)
+
+// importer loads market data from CSV files with a header line naming the
+// ticker, date, open, high, low, close and, optionally, previousclose
+// columns. Rows replace the prices their instrument already has on the same
+// date, and the latest imported price of each instrument is checked against
+// its alerts. Rejected lines are printed and make the command exit with
+// status 1.
+//
+//	go run cmd/importer/main.go [-dry-run] file.csv...
+func main() {
+	dryRun := flag.Bool("dry-run", false, "validate the files without storing anything")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [-dry-run] file.csv...\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	db, err := config.SetupDatabase()
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+
+	// Imported prices go through a MarketDataService so they trigger price
+	// alerts. Deliveries still pending when the importer exits are retried
+	// by the API's delivery worker.
+	instrumentRepo := repository.NewInstrumentRepository(db)
+	alertService := service.NewAlertService(repository.NewAlertRepository(db), repository.NewUserRepository(db), instrumentRepo)
+	marketDataService := service.NewMarketDataService(repository.NewMarketDataRepository(db), alertService)
+	importService := service.NewPriceImportService(instrumentRepo, marketDataService)
+
+	rejected := 0
+	for _, path := range flag.Args() {
+		file, err := os.Open(path)
+		if err != nil {
+			log.Fatalf("Failed to open %s: %v", path, err)
+		}
+		report, err := importService.Import(file, *dryRun)
+		file.Close()
+		if err != nil {
+			log.Fatalf("Failed to import %s: %v", path, err)
+		}
+
+		for _, line := range report.Rejected {
+			fmt.Printf("%s:%d: %s\n", path, line.Line, line.Reason)
+		}
+		rejected += len(report.Rejected)
+		if report.DryRun {
+			log.Printf("%s: %d of %d rows would be imported", path, report.Imported, report.Lines)
+		} else {
+			log.Printf("%s: imported %d of %d rows", path, report.Imported, report.Lines)
+		}
+	}
+	if rejected > 0 {
+		log.Fatalf("Rejected %d lines", rejected)
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/NahuelDT/portfolio-api/internal/config"
+	"github.com/NahuelDT/portfolio-api/internal/models"
+	"github.com/NahuelDT/portfolio-api/internal/repository"
+	"github.com/NahuelDT/portfolio-api/internal/service"
+)
+
+// positions rebuilds the materialized positions, cash balances and ledger
+// journal from the order history, or checks the positions and the ledger's
+// cash against it and the cash balances projected from the ledger against
+// their postings.
+//
+//	go run cmd/positions/main.go [-user ID] rebuild|check
+func main() {
+	userID := flag.Uint("user", 0, "only rebuild or check this user")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [-user ID] rebuild|check\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	db, err := config.SetupDatabase()
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+
+	orderRepo := repository.NewOrderRepository(db)
+	corporateActionRepo := repository.NewCorporateActionRepository(db)
+	positionService := service.NewPositionService(orderRepo, repository.NewPositionRepository(db), corporateActionRepo)
+	ledgerService := service.NewLedgerService(repository.NewLedgerRepository(db), orderRepo, corporateActionRepo)
+
+	switch flag.Arg(0) {
+	case "rebuild":
+		if *userID != 0 {
+			if err := positionService.RebuildUser(*userID); err != nil {
+				log.Fatalf("Failed to rebuild user %d: %v", *userID, err)
+			}
+			if err := ledgerService.RebuildUser(*userID); err != nil {
+				log.Fatalf("Failed to rebuild the journal of user %d: %v", *userID, err)
+			}
+			log.Printf("Rebuilt user %d", *userID)
+			return
+		}
+		rebuilt, err := positionService.RebuildAll()
+		if err != nil {
+			log.Fatalf("Failed to rebuild after %d users: %v", rebuilt, err)
+		}
+		if rebuilt, err = ledgerService.RebuildAll(); err != nil {
+			log.Fatalf("Failed to rebuild the journal after %d users: %v", rebuilt, err)
+		}
+		log.Printf("Rebuilt %d users", rebuilt)
+
+	case "check":
+		var mismatches []models.BalanceMismatch
+		if *userID != 0 {
+			mismatches, err = positionService.CheckUser(*userID)
+		} else {
+			mismatches, err = positionService.CheckAll()
+		}
+		if err != nil {
+			log.Fatalf("Failed to check balances: %v", err)
+		}
+
+		// The journal is checked as a whole: every entry must balance and
+		// every projected cash balance must match its postings
+		trialBalance, err := ledgerService.GetTrialBalance()
+		if err != nil {
+			log.Fatalf("Failed to check the ledger: %v", err)
+		}
+		for _, mismatch := range trialBalance.CashMismatches {
+			if *userID == 0 || mismatch.UserID == *userID {
+				mismatches = append(mismatches, mismatch)
+			}
+		}
+
+		for _, mismatch := range mismatches {
+			fmt.Printf("user %d %s %s %s: stored %v, expected %v\n",
+				mismatch.UserID, mismatch.Kind, mismatch.Key, mismatch.Field, mismatch.Stored, mismatch.Expected)
+		}
+		for _, entryID := range trialBalance.UnbalancedEntries {
+			fmt.Printf("journal entry %d does not balance\n", entryID)
+		}
+		if !trialBalance.Balanced && len(trialBalance.UnbalancedEntries) == 0 && len(trialBalance.CashMismatches) == 0 {
+			fmt.Println("ledger debits and credits do not add up")
+		}
+		if len(mismatches) > 0 || !trialBalance.Balanced {
+			log.Fatalf("Found %d mismatches and %d unbalanced journal entries", len(mismatches), len(trialBalance.UnbalancedEntries))
+		}
+		log.Printf("Positions, cash balances and the ledger are consistent")
+
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+}
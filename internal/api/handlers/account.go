@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/NahuelDT/portfolio-api/internal/models"
+	"github.com/NahuelDT/portfolio-api/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type AccountHandler struct {
+	accountService *service.AccountService
+}
+
+func NewAccountHandler(accountService *service.AccountService) *AccountHandler {
+	return &AccountHandler{accountService: accountService}
+}
+
+type accountRequest struct {
+	Name string `json:"name"`
+}
+
+func (h *AccountHandler) CreateAccount(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("userID"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var request accountRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	account := models.Account{UserID: uint(userID), Name: request.Name}
+	if err := h.accountService.CreateAccount(&account); err != nil {
+		respondAccountError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, account)
+}
+
+func (h *AccountHandler) GetAccounts(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("userID"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	accounts, err := h.accountService.GetAccounts(uint(userID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, accounts)
+}
+
+func respondAccountError(c *gin.Context, err error) {
+	if errors.Is(err, service.ErrInvalidAccount) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
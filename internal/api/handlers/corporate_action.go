@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/NahuelDT/portfolio-api/internal/models"
+	"github.com/NahuelDT/portfolio-api/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type CorporateActionHandler struct {
+	corporateActionService *service.CorporateActionService
+}
+
+func NewCorporateActionHandler(corporateActionService *service.CorporateActionService) *CorporateActionHandler {
+	return &CorporateActionHandler{corporateActionService: corporateActionService}
+}
+
+func (h *CorporateActionHandler) RegisterCorporateAction(c *gin.Context) {
+	var action models.CorporateAction
+	if err := c.ShouldBindJSON(&action); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.corporateActionService.RegisterCorporateAction(&action); err != nil {
+		if errors.Is(err, service.ErrInvalidCorporateAction) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, action)
+}
+
+func (h *CorporateActionHandler) GetPriceHistory(c *gin.Context) {
+	instrumentID, err := strconv.ParseUint(c.Param("instrumentID"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid instrument ID"})
+		return
+	}
+
+	var from time.Time
+	if value := c.Query("from"); value != "" {
+		if from, err = time.Parse("2006-01-02", value); err != nil {
+			if from, err = time.Parse(time.RFC3339, value); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from date"})
+				return
+			}
+		}
+	}
+
+	to := time.Now()
+	if value := c.Query("to"); value != "" {
+		if to, err = parseAsOf(value); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to date"})
+			return
+		}
+	}
+
+	adjusted, err := strconv.ParseBool(c.DefaultQuery("adjusted", "true"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid adjusted flag"})
+		return
+	}
+
+	prices, err := h.corporateActionService.GetPriceHistory(uint(instrumentID), from, to, adjusted)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, prices)
+}
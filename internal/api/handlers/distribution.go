@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/NahuelDT/portfolio-api/internal/models"
+	"github.com/NahuelDT/portfolio-api/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type DistributionHandler struct {
+	distributionService *service.DistributionService
+}
+
+func NewDistributionHandler(distributionService *service.DistributionService) *DistributionHandler {
+	return &DistributionHandler{distributionService: distributionService}
+}
+
+func (h *DistributionHandler) RegisterDistribution(c *gin.Context) {
+	var distribution models.Distribution
+	if err := c.ShouldBindJSON(&distribution); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.distributionService.RegisterDistribution(&distribution); err != nil {
+		if errors.Is(err, service.ErrInvalidDistribution) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, distribution)
+}
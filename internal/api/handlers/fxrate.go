@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/NahuelDT/portfolio-api/internal/models"
+	"github.com/NahuelDT/portfolio-api/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type FXRateHandler struct {
+	fxRateService *service.FXRateService
+}
+
+func NewFXRateHandler(fxRateService *service.FXRateService) *FXRateHandler {
+	return &FXRateHandler{fxRateService: fxRateService}
+}
+
+func (h *FXRateHandler) CreateRate(c *gin.Context) {
+	var rate models.FXRate
+	if err := c.ShouldBindJSON(&rate); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.fxRateService.CreateRate(&rate); err != nil {
+		if errors.Is(err, service.ErrInvalidFXRate) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, rate)
+}
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/NahuelDT/portfolio-api/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type LedgerHandler struct {
+	ledgerService *service.LedgerService
+}
+
+func NewLedgerHandler(ledgerService *service.LedgerService) *LedgerHandler {
+	return &LedgerHandler{ledgerService: ledgerService}
+}
+
+func (h *LedgerHandler) GetJournal(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("userID"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var from time.Time
+	if value := c.Query("from"); value != "" {
+		if from, err = time.Parse("2006-01-02", value); err != nil {
+			if from, err = time.Parse(time.RFC3339, value); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from date"})
+				return
+			}
+		}
+	}
+
+	to := time.Now()
+	if value := c.Query("to"); value != "" {
+		if to, err = parseAsOf(value); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to date"})
+			return
+		}
+	}
+
+	entries, err := h.ledgerService.GetJournal(uint(userID), from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
+
+func (h *LedgerHandler) GetTrialBalance(c *gin.Context) {
+	trialBalance, err := h.ledgerService.GetTrialBalance()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, trialBalance)
+}
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/NahuelDT/portfolio-api/internal/marketdata"
+	"github.com/NahuelDT/portfolio-api/internal/models"
+	"github.com/NahuelDT/portfolio-api/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type MarketDataHandler struct {
+	marketDataService *service.MarketDataService
+	marketDataCache   *marketdata.Cache
+}
+
+func NewMarketDataHandler(marketDataService *service.MarketDataService, marketDataCache *marketdata.Cache) *MarketDataHandler {
+	return &MarketDataHandler{marketDataService: marketDataService, marketDataCache: marketDataCache}
+}
+
+func (h *MarketDataHandler) CreateMarketData(c *gin.Context) {
+	var data models.MarketData
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if data.InstrumentID == 0 || data.DateTime.IsZero() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Instrument ID and date are required"})
+		return
+	}
+
+	if err := h.marketDataService.Create(&data); err != nil {
+		if errors.Is(err, service.ErrDuplicateMarketData) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, data)
+}
+
+func (h *MarketDataHandler) GetCandles(c *gin.Context) {
+	instrumentID, err := strconv.ParseUint(c.Param("instrumentID"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid instrument ID"})
+		return
+	}
+
+	var from time.Time
+	if value := c.Query("from"); value != "" {
+		if from, err = time.Parse("2006-01-02", value); err != nil {
+			if from, err = time.Parse(time.RFC3339, value); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from date"})
+				return
+			}
+		}
+	}
+
+	to := time.Now()
+	if value := c.Query("to"); value != "" {
+		if to, err = parseAsOf(value); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to date"})
+			return
+		}
+	}
+
+	limit := 0
+	if value := c.Query("limit"); value != "" {
+		if limit, err = strconv.Atoi(value); err != nil || limit <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+			return
+		}
+	}
+
+	page, err := h.marketDataService.GetCandlePage(uint(instrumentID), c.DefaultQuery("interval", models.CandleDaily), from, to, limit)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidCandles) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+func (h *MarketDataHandler) GetCacheStats(c *gin.Context) {
+	c.JSON(http.StatusOK, h.marketDataCache.Stats())
+}
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/NahuelDT/portfolio-api/internal/models"
+	"github.com/NahuelDT/portfolio-api/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type PerformanceHandler struct {
+	performanceService *service.PerformanceService
+}
+
+func NewPerformanceHandler(performanceService *service.PerformanceService) *PerformanceHandler {
+	return &PerformanceHandler{performanceService: performanceService}
+}
+
+func (h *PerformanceHandler) GetPerformance(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("userID"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	currency := c.DefaultQuery("currency", models.DefaultCurrency)
+
+	// A from or to date asks for a custom period instead of a period code
+	var performance *models.Performance
+	if c.Query("from") != "" || c.Query("to") != "" {
+		var from time.Time
+		if value := c.Query("from"); value != "" {
+			if from, err = time.Parse("2006-01-02", value); err != nil {
+				if from, err = time.Parse(time.RFC3339, value); err != nil {
+					c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from date"})
+					return
+				}
+			}
+		}
+		to := time.Now()
+		if value := c.Query("to"); value != "" {
+			if to, err = parseAsOf(value); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to date"})
+				return
+			}
+		}
+		performance, err = h.performanceService.GetPerformanceBetween(uint(userID), from, to, currency)
+	} else {
+		performance, err = h.performanceService.GetPerformance(uint(userID), c.DefaultQuery("period", "ALL"), currency)
+	}
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidPeriod) || errors.Is(err, service.ErrNoFXRate) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, performance)
+}
+
+func (h *PerformanceHandler) CompareToBenchmark(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("userID"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	benchmarkID, err := strconv.ParseUint(c.Query("benchmark"), 10, 64)
+	if err != nil || benchmarkID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid benchmark"})
+		return
+	}
+
+	comparison, err := h.performanceService.CompareToBenchmark(uint(userID), uint(benchmarkID), c.DefaultQuery("period", "YTD"), c.DefaultQuery("currency", models.DefaultCurrency))
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidPeriod) || errors.Is(err, service.ErrInvalidBenchmark) || errors.Is(err, service.ErrNoFXRate) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, comparison)
+}
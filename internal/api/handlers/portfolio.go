@@ -1,10 +1,13 @@
 package handlers
 
 import (
+    "errors"
     "net/http"
     "strconv"
+    "time"
 
     "github.com/gin-gonic/gin"
+    "github.com/NahuelDT/portfolio-api/internal/models"
     "github.com/NahuelDT/portfolio-api/internal/service"
 )
 
@@ -23,8 +26,42 @@ func (h *PortfolioHandler) GetPortfolio(c *gin.Context) {
         return
     }
 
-    portfolio, err := h.portfolioService.GetPortfolio(uint(userID))
+    currency := c.DefaultQuery("currency", models.DefaultCurrency)
+
+    // Without an account the portfolio consolidates all the user's accounts
+    var accountID *uint
+    if accountParam := c.Query("account"); accountParam != "" {
+        id, parseErr := strconv.ParseUint(accountParam, 10, 64)
+        if parseErr != nil {
+            c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid account ID"})
+            return
+        }
+        account := uint(id)
+        accountID = &account
+    }
+
+    var portfolio *models.Portfolio
+    if asOfParam := c.Query("asOf"); asOfParam != "" {
+        asOf, parseErr := parseAsOf(asOfParam)
+        if parseErr != nil {
+            c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid asOf, expected RFC3339 or YYYY-MM-DD"})
+            return
+        }
+        if accountID != nil {
+            portfolio, err = h.portfolioService.GetAccountPortfolioAsOf(uint(userID), *accountID, asOf, currency)
+        } else {
+            portfolio, err = h.portfolioService.GetPortfolioAsOf(uint(userID), asOf, currency)
+        }
+    } else if accountID != nil {
+        portfolio, err = h.portfolioService.GetAccountPortfolio(uint(userID), *accountID, currency)
+    } else {
+        portfolio, err = h.portfolioService.GetPortfolio(uint(userID), currency)
+    }
     if err != nil {
+        if errors.Is(err, service.ErrInvalidAccount) || errors.Is(err, service.ErrNoFXRate) {
+            c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+            return
+        }
         c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
         return
     }
@@ -32,3 +69,36 @@ func (h *PortfolioHandler) GetPortfolio(c *gin.Context) {
     c.JSON(http.StatusOK, portfolio)
 }
 
+func (h *PortfolioHandler) GetAllocation(c *gin.Context) {
+    userID, err := strconv.ParseUint(c.Param("userID"), 10, 64)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+        return
+    }
+
+    allocation, err := h.portfolioService.GetAllocation(uint(userID), c.DefaultQuery("groupBy", "type"), c.DefaultQuery("currency", models.DefaultCurrency))
+    if err != nil {
+        if errors.Is(err, service.ErrInvalidAllocationGroup) || errors.Is(err, service.ErrNoFXRate) {
+            c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+            return
+        }
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, allocation)
+}
+
+// parseAsOf accepts an RFC3339 timestamp or a plain date, which is taken as
+// the end of that day in UTC
+func parseAsOf(value string) (time.Time, error) {
+    if asOf, err := time.Parse(time.RFC3339, value); err == nil {
+        return asOf, nil
+    }
+    day, err := time.Parse("2006-01-02", value)
+    if err != nil {
+        return time.Time{}, err
+    }
+    return day.Add(24*time.Hour - time.Nanosecond), nil
+}
+
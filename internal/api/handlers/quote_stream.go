@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/NahuelDT/portfolio-api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+// Heartbeats and limits of the quote stream. The server pings every
+// pingPeriod and drops clients that do not answer within pongWait.
+const (
+	writeWait      = 10 * time.Second
+	pongWait       = 60 * time.Second
+	pingPeriod     = pongWait * 9 / 10
+	maxMessageSize = 4096
+)
+
+// upgrader accepts clients without an Origin header, such as servers, and
+// browsers on the API's own host
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+}
+
+type QuoteStreamHandler struct {
+	quoteHub *service.QuoteHub
+}
+
+func NewQuoteStreamHandler(quoteHub *service.QuoteHub) *QuoteStreamHandler {
+	return &QuoteStreamHandler{quoteHub: quoteHub}
+}
+
+// quoteStreamRequest is a message from the client: subscribe or unsubscribe
+// to some tickers
+type quoteStreamRequest struct {
+	Action  string   `json:"action"`
+	Tickers []string `json:"tickers"`
+}
+
+// quoteStreamMessage is a message to the client: a quote, or the reply to
+// one of its requests
+type quoteStreamMessage struct {
+	Type    string               `json:"type"`
+	Tickers []string             `json:"tickers,omitempty"`
+	Unknown []string             `json:"unknown,omitempty"`
+	Quote   *service.QuoteUpdate `json:"quote,omitempty"`
+	Error   string               `json:"error,omitempty"`
+}
+
+func (h *QuoteStreamHandler) Stream(c *gin.Context) {
+	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// The upgrader already replied with the error
+		return
+	}
+
+	subscriber := h.quoteHub.Connect()
+	replies := make(chan quoteStreamMessage, 16)
+	done := make(chan struct{})
+	go h.write(conn, subscriber, replies, done)
+
+	defer func() {
+		h.quoteHub.Disconnect(subscriber)
+		close(done)
+	}()
+
+	conn.SetReadLimit(maxMessageSize)
+	conn.SetReadDeadline(time.Now().Add(pongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseNormalClosure) {
+				log.Printf("Quote stream closed: %v", err)
+			}
+			return
+		}
+
+		reply := h.handleRequest(subscriber, data)
+		select {
+		case replies <- reply:
+		default:
+			// A client that does not read its replies is as slow as one that
+			// does not read its quotes
+			return
+		}
+	}
+}
+
+// handleRequest applies a client's message and returns the reply to it
+func (h *QuoteStreamHandler) handleRequest(subscriber *service.QuoteSubscriber, data []byte) quoteStreamMessage {
+	var request quoteStreamRequest
+	if err := json.Unmarshal(data, &request); err != nil {
+		return quoteStreamMessage{Type: "error", Error: "Invalid request"}
+	}
+
+	switch request.Action {
+	case "subscribe":
+		subscribed, unknown, err := h.quoteHub.Subscribe(subscriber, request.Tickers)
+		if err != nil {
+			return quoteStreamMessage{Type: "error", Error: err.Error()}
+		}
+		return quoteStreamMessage{Type: "subscribed", Tickers: subscribed, Unknown: unknown}
+	case "unsubscribe":
+		return quoteStreamMessage{Type: "unsubscribed", Tickers: h.quoteHub.Unsubscribe(subscriber, request.Tickers)}
+	}
+	return quoteStreamMessage{Type: "error", Error: "Invalid action"}
+}
+
+// write is the only writer of the connection: it sends the quotes, the
+// replies and the heartbeat pings until the client leaves or falls behind
+func (h *QuoteStreamHandler) write(conn *websocket.Conn, subscriber *service.QuoteSubscriber, replies <-chan quoteStreamMessage, done <-chan struct{}) {
+	ticker := time.NewTicker(pingPeriod)
+	defer func() {
+		ticker.Stop()
+		conn.Close()
+	}()
+
+	for {
+		var message quoteStreamMessage
+		select {
+		case quote, ok := <-subscriber.Quotes():
+			if !ok {
+				closeCode, reason := websocket.CloseNormalClosure, ""
+				if h.quoteHub.Slow(subscriber) {
+					closeCode, reason = websocket.ClosePolicyViolation, "client too slow"
+				}
+				conn.SetWriteDeadline(time.Now().Add(writeWait))
+				conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(closeCode, reason))
+				return
+			}
+			message = quoteStreamMessage{Type: "quote", Quote: &quote}
+		case message = <-replies:
+		case <-ticker.C:
+			conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+			continue
+		case <-done:
+			return
+		}
+
+		conn.SetWriteDeadline(time.Now().Add(writeWait))
+		if err := conn.WriteJSON(message); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/NahuelDT/portfolio-api/internal/models"
+	"github.com/NahuelDT/portfolio-api/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type RiskHandler struct {
+	riskService *service.RiskService
+}
+
+func NewRiskHandler(riskService *service.RiskService) *RiskHandler {
+	return &RiskHandler{riskService: riskService}
+}
+
+func (h *RiskHandler) GetRisk(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("userID"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	benchmarkID, err := strconv.ParseUint(c.DefaultQuery("benchmark", "0"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid benchmark"})
+		return
+	}
+
+	riskFreeRate, err := strconv.ParseFloat(c.DefaultQuery("riskFreeRate", "0"), 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid risk free rate"})
+		return
+	}
+
+	risk, err := h.riskService.GetRisk(uint(userID), c.DefaultQuery("period", "1Y"), c.DefaultQuery("currency", models.DefaultCurrency), uint(benchmarkID), riskFreeRate)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidPeriod) || errors.Is(err, service.ErrInvalidBenchmark) || errors.Is(err, service.ErrNoFXRate) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, risk)
+}
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/NahuelDT/portfolio-api/internal/models"
+	"github.com/NahuelDT/portfolio-api/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type StatementHandler struct {
+	statementService *service.StatementService
+}
+
+func NewStatementHandler(statementService *service.StatementService) *StatementHandler {
+	return &StatementHandler{statementService: statementService}
+}
+
+func (h *StatementHandler) GenerateStatement(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("userID"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	year, err := strconv.Atoi(c.Query("year"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid year"})
+		return
+	}
+
+	period, err := strconv.Atoi(c.Query("period"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid period"})
+		return
+	}
+
+	statement, err := h.statementService.GenerateStatement(
+		uint(userID),
+		c.DefaultQuery("type", models.MonthlyStatement),
+		year,
+		period,
+		c.DefaultQuery("currency", models.DefaultCurrency),
+	)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidStatementPeriod) || errors.Is(err, service.ErrNoFXRate) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, statement)
+}
+
+func (h *StatementHandler) GetStatements(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("userID"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	statements, err := h.statementService.GetStatements(uint(userID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, statements)
+}
+
+func (h *StatementHandler) GetStatement(c *gin.Context) {
+	statementID, err := strconv.ParseUint(c.Param("statementID"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid statement ID"})
+		return
+	}
+
+	statement, err := h.statementService.GetStatement(uint(statementID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	switch c.DefaultQuery("format", "json") {
+	case "json":
+		c.JSON(http.StatusOK, statement)
+	case "html":
+		var buf bytes.Buffer
+		if err := service.RenderStatementHTML(&buf, statement); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.Data(http.StatusOK, "text/html; charset=utf-8", buf.Bytes())
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid format, expected json or html"})
+	}
+}
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/NahuelDT/portfolio-api/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type TaxHandler struct {
+	taxService *service.TaxService
+}
+
+func NewTaxHandler(taxService *service.TaxService) *TaxHandler {
+	return &TaxHandler{taxService: taxService}
+}
+
+func (h *TaxHandler) GetCapitalGains(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("userID"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	year, err := strconv.Atoi(c.DefaultQuery("year", strconv.Itoa(time.Now().Year())))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid year"})
+		return
+	}
+
+	report, err := h.taxService.GetCapitalGains(uint(userID), year)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	switch c.DefaultQuery("format", "json") {
+	case "json":
+		c.JSON(http.StatusOK, report)
+	case "csv":
+		var buf bytes.Buffer
+		if err := service.WriteCapitalGainsCSV(&buf, report); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=capital-gains-%d-%d.csv", userID, year))
+		c.Data(http.StatusOK, "text/csv", buf.Bytes())
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid format, expected json or csv"})
+	}
+}
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/NahuelDT/portfolio-api/internal/models"
+	"github.com/NahuelDT/portfolio-api/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type TransferHandler struct {
+	transferService *service.TransferService
+}
+
+func NewTransferHandler(transferService *service.TransferService) *TransferHandler {
+	return &TransferHandler{transferService: transferService}
+}
+
+type transferRequest struct {
+	FromAccountID uint    `json:"fromAccountId"`
+	ToUserID      uint    `json:"toUserId"`
+	ToAccountID   uint    `json:"toAccountId"`
+	Currency      string  `json:"currency"`
+	Amount        float64 `json:"amount"`
+	InstrumentID  uint    `json:"instrumentId"`
+	Quantity      float64 `json:"quantity"`
+	Note          string  `json:"note"`
+}
+
+func (h *TransferHandler) CreateTransfer(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("userID"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var request transferRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Without a recipient the transfer moves between the user's own accounts
+	transfer := models.Transfer{
+		FromUserID:    uint(userID),
+		FromAccountID: request.FromAccountID,
+		ToUserID:      request.ToUserID,
+		ToAccountID:   request.ToAccountID,
+		Currency:      request.Currency,
+		Amount:        request.Amount,
+		InstrumentID:  request.InstrumentID,
+		Quantity:      request.Quantity,
+		Note:          request.Note,
+	}
+	if transfer.ToUserID == 0 {
+		transfer.ToUserID = transfer.FromUserID
+	}
+	if err := h.transferService.Transfer(&transfer); err != nil {
+		if errors.Is(err, service.ErrInvalidTransfer) || errors.Is(err, service.ErrInvalidAccount) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, transfer)
+}
+
+func (h *TransferHandler) GetTransfers(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("userID"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	transfers, err := h.transferService.GetTransfers(uint(userID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, transfers)
+}
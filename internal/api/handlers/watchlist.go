@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/NahuelDT/portfolio-api/internal/models"
+	"github.com/NahuelDT/portfolio-api/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type WatchlistHandler struct {
+	watchlistService *service.WatchlistService
+}
+
+func NewWatchlistHandler(watchlistService *service.WatchlistService) *WatchlistHandler {
+	return &WatchlistHandler{watchlistService: watchlistService}
+}
+
+type watchlistRequest struct {
+	Name          string `json:"name"`
+	InstrumentIDs []uint `json:"instrumentIds"`
+}
+
+func (h *WatchlistHandler) CreateWatchlist(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("userID"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var request watchlistRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	watchlist := models.Watchlist{
+		UserID:        uint(userID),
+		Name:          request.Name,
+		InstrumentIDs: request.InstrumentIDs,
+	}
+	if err := h.watchlistService.CreateWatchlist(&watchlist); err != nil {
+		respondWatchlistError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, watchlist)
+}
+
+func (h *WatchlistHandler) GetWatchlists(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("userID"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	watchlists, err := h.watchlistService.GetWatchlists(uint(userID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, watchlists)
+}
+
+func (h *WatchlistHandler) GetWatchlist(c *gin.Context) {
+	watchlistID, ok := parseWatchlistID(c)
+	if !ok {
+		return
+	}
+
+	watchlist, err := h.watchlistService.GetWatchlist(watchlistID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, watchlist)
+}
+
+func (h *WatchlistHandler) UpdateWatchlist(c *gin.Context) {
+	watchlistID, ok := parseWatchlistID(c)
+	if !ok {
+		return
+	}
+
+	var request watchlistRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	watchlist, err := h.watchlistService.UpdateWatchlist(watchlistID, request.Name, request.InstrumentIDs)
+	if err != nil {
+		respondWatchlistError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, watchlist)
+}
+
+func (h *WatchlistHandler) DeleteWatchlist(c *gin.Context) {
+	watchlistID, ok := parseWatchlistID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.watchlistService.DeleteWatchlist(watchlistID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Watchlist deleted successfully"})
+}
+
+func (h *WatchlistHandler) AddInstrument(c *gin.Context) {
+	watchlistID, ok := parseWatchlistID(c)
+	if !ok {
+		return
+	}
+
+	var request struct {
+		InstrumentID uint `json:"instrumentId"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	watchlist, err := h.watchlistService.AddInstrument(watchlistID, request.InstrumentID)
+	if err != nil {
+		respondWatchlistError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, watchlist)
+}
+
+func (h *WatchlistHandler) RemoveInstrument(c *gin.Context) {
+	watchlistID, ok := parseWatchlistID(c)
+	if !ok {
+		return
+	}
+
+	instrumentID, err := strconv.ParseUint(c.Param("instrumentID"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid instrument ID"})
+		return
+	}
+
+	watchlist, err := h.watchlistService.RemoveInstrument(watchlistID, uint(instrumentID))
+	if err != nil {
+		respondWatchlistError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, watchlist)
+}
+
+func (h *WatchlistHandler) GetQuotes(c *gin.Context) {
+	watchlistID, ok := parseWatchlistID(c)
+	if !ok {
+		return
+	}
+
+	snapshot, err := h.watchlistService.GetQuotes(watchlistID, c.DefaultQuery("sortBy", "position"), c.DefaultQuery("order", "asc"))
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidWatchlistSort) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, snapshot)
+}
+
+func parseWatchlistID(c *gin.Context) (uint, bool) {
+	watchlistID, err := strconv.ParseUint(c.Param("watchlistID"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid watchlist ID"})
+		return 0, false
+	}
+	return uint(watchlistID), true
+}
+
+func respondWatchlistError(c *gin.Context, err error) {
+	if errors.Is(err, service.ErrInvalidWatchlist) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
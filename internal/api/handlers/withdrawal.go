@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/NahuelDT/portfolio-api/internal/models"
+	"github.com/NahuelDT/portfolio-api/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type WithdrawalHandler struct {
+	withdrawalService *service.WithdrawalService
+}
+
+func NewWithdrawalHandler(withdrawalService *service.WithdrawalService) *WithdrawalHandler {
+	return &WithdrawalHandler{withdrawalService: withdrawalService}
+}
+
+type withdrawalDecisionRequest struct {
+	Reviewer string `json:"reviewer"`
+	Note     string `json:"note"`
+}
+
+func (h *WithdrawalHandler) GetPending(c *gin.Context) {
+	orders, err := h.withdrawalService.GetPending()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, orders)
+}
+
+func (h *WithdrawalHandler) Approve(c *gin.Context) {
+	h.decide(c, h.withdrawalService.Approve)
+}
+
+func (h *WithdrawalHandler) Reject(c *gin.Context) {
+	h.decide(c, h.withdrawalService.Reject)
+}
+
+func (h *WithdrawalHandler) GetAuditTrail(c *gin.Context) {
+	orderID, err := strconv.ParseUint(c.Param("orderID"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order ID"})
+		return
+	}
+
+	audits, err := h.withdrawalService.GetAuditTrail(uint(orderID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, audits)
+}
+
+func (h *WithdrawalHandler) decide(c *gin.Context, decision func(orderID uint, reviewer, note string) (*models.Order, error)) {
+	orderID, err := strconv.ParseUint(c.Param("orderID"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order ID"})
+		return
+	}
+
+	var request withdrawalDecisionRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	order, err := decision(uint(orderID), request.Reviewer, request.Note)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidWithdrawal) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, order)
+}
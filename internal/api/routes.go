@@ -11,11 +11,65 @@ func SetupRoutes(
 	portfolioHandler *handlers.PortfolioHandler,
 	searchHandler *handlers.SearchHandler,
 	orderHandler *handlers.OrderHandler,
+	performanceHandler *handlers.PerformanceHandler,
+	riskHandler *handlers.RiskHandler,
+	taxHandler *handlers.TaxHandler,
+	statementHandler *handlers.StatementHandler,
+	distributionHandler *handlers.DistributionHandler,
+	corporateActionHandler *handlers.CorporateActionHandler,
+	watchlistHandler *handlers.WatchlistHandler,
+	alertHandler *handlers.AlertHandler,
+	marketDataHandler *handlers.MarketDataHandler,
+	fxRateHandler *handlers.FXRateHandler,
+	accountHandler *handlers.AccountHandler,
+	transferHandler *handlers.TransferHandler,
+	ledgerHandler *handlers.LedgerHandler,
+	withdrawalHandler *handlers.WithdrawalHandler,
+	quoteStreamHandler *handlers.QuoteStreamHandler,
 ) {
 	api := r.Group("/api")
 	api.Use(middleware.ErrorHandler())
 
 	api.GET("/portfolio/:userID", portfolioHandler.GetPortfolio)
+	api.POST("/portfolio/:userID/accounts", accountHandler.CreateAccount)
+	api.GET("/portfolio/:userID/accounts", accountHandler.GetAccounts)
+	api.POST("/portfolio/:userID/transfers", transferHandler.CreateTransfer)
+	api.GET("/portfolio/:userID/transfers", transferHandler.GetTransfers)
+	api.GET("/portfolio/:userID/ledger", ledgerHandler.GetJournal)
+	api.GET("/portfolio/:userID/allocation", portfolioHandler.GetAllocation)
+	api.GET("/portfolio/:userID/performance", performanceHandler.GetPerformance)
+	api.GET("/portfolio/:userID/benchmark", performanceHandler.CompareToBenchmark)
+	api.GET("/portfolio/:userID/risk", riskHandler.GetRisk)
+	api.GET("/portfolio/:userID/capital-gains", taxHandler.GetCapitalGains)
+	api.POST("/portfolio/:userID/statements", statementHandler.GenerateStatement)
+	api.GET("/portfolio/:userID/statements", statementHandler.GetStatements)
+	api.GET("/statements/:statementID", statementHandler.GetStatement)
+	api.POST("/portfolio/:userID/watchlists", watchlistHandler.CreateWatchlist)
+	api.GET("/portfolio/:userID/watchlists", watchlistHandler.GetWatchlists)
+	api.GET("/watchlists/:watchlistID", watchlistHandler.GetWatchlist)
+	api.PUT("/watchlists/:watchlistID", watchlistHandler.UpdateWatchlist)
+	api.DELETE("/watchlists/:watchlistID", watchlistHandler.DeleteWatchlist)
+	api.POST("/watchlists/:watchlistID/instruments", watchlistHandler.AddInstrument)
+	api.DELETE("/watchlists/:watchlistID/instruments/:instrumentID", watchlistHandler.RemoveInstrument)
+	api.GET("/watchlists/:watchlistID/quotes", watchlistHandler.GetQuotes)
+	api.POST("/portfolio/:userID/alerts", alertHandler.CreateAlert)
+	api.GET("/portfolio/:userID/alerts", alertHandler.GetAlerts)
+	api.GET("/portfolio/:userID/alerts/triggers", alertHandler.GetTriggers)
+	api.PUT("/portfolio/:userID/alerts/webhook", alertHandler.SetWebhook)
+	api.DELETE("/portfolio/:userID/alerts/:alertID", alertHandler.DeleteAlert)
+	api.POST("/admin/marketdata", marketDataHandler.CreateMarketData)
+	api.GET("/admin/marketdata/cache", marketDataHandler.GetCacheStats)
+	api.POST("/admin/fx-rates", fxRateHandler.CreateRate)
+	api.POST("/admin/distributions", distributionHandler.RegisterDistribution)
+	api.POST("/admin/corporate-actions", corporateActionHandler.RegisterCorporateAction)
+	api.GET("/admin/ledger/trial-balance", ledgerHandler.GetTrialBalance)
+	api.GET("/admin/withdrawals", withdrawalHandler.GetPending)
+	api.POST("/admin/withdrawals/:orderID/approve", withdrawalHandler.Approve)
+	api.POST("/admin/withdrawals/:orderID/reject", withdrawalHandler.Reject)
+	api.GET("/admin/withdrawals/:orderID/audit", withdrawalHandler.GetAuditTrail)
+	api.GET("/instruments/:instrumentID/prices", corporateActionHandler.GetPriceHistory)
+	api.GET("/instruments/:instrumentID/candles", marketDataHandler.GetCandles)
+	api.GET("/stream/quotes", quoteStreamHandler.Stream)
 	api.GET("/search", searchHandler.SearchAssets)
 	api.POST("/order", orderHandler.PlaceOrder)
 	api.POST("/orders/:orderID/cancel", orderHandler.CancelOrder)
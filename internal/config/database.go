@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/NahuelDT/portfolio-api/internal/migrations"
 	"github.com/joho/godotenv"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
@@ -28,5 +29,9 @@ func SetupDatabase() (*gorm.DB, error) {
 		return nil, fmt.Errorf("failed to connect database: %w", err)
 	}
 
+	if err := migrations.Migrate(db); err != nil {
+		return nil, fmt.Errorf("failed to migrate database: %w", err)
+	}
+
 	return db, nil
 }
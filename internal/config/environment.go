@@ -0,0 +1,29 @@
+package config
+
+import (
+	"os"
+	"strings"
+)
+
+// environmentByPrefix returns the value of the variable called name, keyed
+// by "", and of every name_<SUFFIX> variable, keyed by the upper-cased
+// suffix
+func environmentByPrefix(name string) map[string]string {
+	values := make(map[string]string)
+	for _, variable := range os.Environ() {
+		key, value, _ := strings.Cut(variable, "=")
+		if key == name {
+			values[""] = value
+		} else if suffix, ok := strings.CutPrefix(key, name+"_"); ok && suffix != "" {
+			values[strings.ToUpper(suffix)] = value
+		}
+	}
+	return values
+}
+
+func joinVariable(name, suffix string) string {
+	if suffix == "" {
+		return name
+	}
+	return name + "_" + suffix
+}
@@ -0,0 +1,99 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/NahuelDT/portfolio-api/internal/marketdata"
+)
+
+const (
+	simulatedMarketDataVariable = "SIMULATED_MARKETDATA"
+	marketDataCacheTTLVariable  = "MARKETDATA_CACHE_TTL"
+)
+
+// LoadMarketDataCacheTTL reads how long the latest prices are cached from
+// MARKETDATA_CACHE_TTL, e.g. MARKETDATA_CACHE_TTL=30s. Prices are cached for
+// marketdata.DefaultCacheTTL when it is not set.
+func LoadMarketDataCacheTTL() (time.Duration, error) {
+	value := strings.TrimSpace(os.Getenv(marketDataCacheTTLVariable))
+	if value == "" {
+		return marketdata.DefaultCacheTTL, nil
+	}
+	ttl, err := time.ParseDuration(value)
+	if err != nil || ttl <= 0 {
+		return 0, fmt.Errorf("invalid %s: %q", marketDataCacheTTLVariable, value)
+	}
+	return ttl, nil
+}
+
+// LoadSimulatedMarketData reads the instruments whose prices are simulated,
+// keyed by ticker. Each SIMULATED_MARKETDATA_<TICKER> variable simulates a
+// ticker with the parameters it lists, e.g.
+// SIMULATED_MARKETDATA_GGAL=drift=0.1,volatility=0.4,tick=2s,price=1500,
+// taking the missing ones from SIMULATED_MARKETDATA and then from
+// marketdata.DefaultSimulationParams. Nothing is simulated when no ticker is
+// set.
+func LoadSimulatedMarketData() (map[string]marketdata.SimulationParams, error) {
+	values := environmentByPrefix(simulatedMarketDataVariable)
+
+	defaults, err := parseSimulationParams(values[""], marketdata.DefaultSimulationParams)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", simulatedMarketDataVariable, err)
+	}
+
+	params := make(map[string]marketdata.SimulationParams)
+	for ticker, value := range values {
+		if ticker == "" {
+			continue
+		}
+		if params[ticker], err = parseSimulationParams(value, defaults); err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", joinVariable(simulatedMarketDataVariable, ticker), err)
+		}
+	}
+	return params, nil
+}
+
+// parseSimulationParams reads a comma-separated list of key=value parameters
+// over the given defaults
+func parseSimulationParams(value string, params marketdata.SimulationParams) (marketdata.SimulationParams, error) {
+	for _, field := range strings.Split(value, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		key, raw, _ := strings.Cut(field, "=")
+		key = strings.ToLower(strings.TrimSpace(key))
+		raw = strings.TrimSpace(raw)
+
+		var err error
+		switch key {
+		case "drift":
+			params.Drift, err = strconv.ParseFloat(raw, 64)
+		case "volatility":
+			params.Volatility, err = strconv.ParseFloat(raw, 64)
+			if err == nil && params.Volatility < 0 {
+				err = fmt.Errorf("volatility cannot be negative")
+			}
+		case "tick":
+			params.TickInterval, err = time.ParseDuration(raw)
+			if err == nil && params.TickInterval <= 0 {
+				err = fmt.Errorf("tick must be positive")
+			}
+		case "price":
+			params.StartPrice, err = strconv.ParseFloat(raw, 64)
+			if err == nil && params.StartPrice <= 0 {
+				err = fmt.Errorf("price must be positive")
+			}
+		default:
+			err = fmt.Errorf("unknown parameter %q", key)
+		}
+		if err != nil {
+			return params, fmt.Errorf("%s: %w", field, err)
+		}
+	}
+	return params, nil
+}
@@ -0,0 +1,32 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/NahuelDT/portfolio-api/internal/models"
+)
+
+const settlementDaysVariable = "SETTLEMENT_DAYS"
+
+// LoadSettlementCycle reads the settlement cycle of trades from the
+// environment: SETTLEMENT_DAYS for every instrument type and
+// SETTLEMENT_DAYS_<TYPE> for a single one, e.g. SETTLEMENT_DAYS_BONOS=2.
+// Trades settle on T+1 when nothing is set.
+func LoadSettlementCycle() (models.SettlementCycle, error) {
+	cycle := models.SettlementCycle{Default: models.DefaultSettlementCycle.Default, ByType: make(map[string]int)}
+
+	for suffix, value := range environmentByPrefix(settlementDaysVariable) {
+		days, err := strconv.Atoi(value)
+		if err != nil || days < 0 {
+			return cycle, fmt.Errorf("invalid %s: %q", joinVariable(settlementDaysVariable, suffix), value)
+		}
+		if suffix == "" {
+			cycle.Default = days
+		} else {
+			cycle.ByType[suffix] = days
+		}
+	}
+
+	return cycle, nil
+}
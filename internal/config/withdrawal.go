@@ -0,0 +1,25 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+)
+
+const withdrawalThresholdVariable = "WITHDRAWAL_APPROVAL_THRESHOLD"
+
+// LoadWithdrawalThresholds reads the amounts above which CASH_OUT orders
+// need an admin's approval: WITHDRAWAL_APPROVAL_THRESHOLD for every
+// currency, keyed by "", and WITHDRAWAL_APPROVAL_THRESHOLD_<CURRENCY> for a
+// single one, e.g. WITHDRAWAL_APPROVAL_THRESHOLD_USD=10000. Currencies
+// without a threshold are not held for their amount.
+func LoadWithdrawalThresholds() (map[string]float64, error) {
+	thresholds := make(map[string]float64)
+	for suffix, value := range environmentByPrefix(withdrawalThresholdVariable) {
+		amount, err := strconv.ParseFloat(value, 64)
+		if err != nil || amount <= 0 {
+			return nil, fmt.Errorf("invalid %s: %q", joinVariable(withdrawalThresholdVariable, suffix), value)
+		}
+		thresholds[suffix] = amount
+	}
+	return thresholds, nil
+}
@@ -0,0 +1,195 @@
+package marketdata
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/NahuelDT/portfolio-api/internal/models"
+	"github.com/NahuelDT/portfolio-api/internal/repository"
+)
+
+// DefaultCacheTTL is how long the cache trusts the latest bar it holds
+const DefaultCacheTTL = time.Minute
+
+// CacheStats are the counters of a Cache since it was created. A hit is an
+// instrument served from memory, a miss one loaded from the repository
+// because it was not cached, and a refresh one reloaded because its bar was
+// older than the TTL. Updates count the bars stored through the cache that
+// replaced the cached one.
+type CacheStats struct {
+	Entries   int     `json:"entries"`
+	Hits      int64   `json:"hits"`
+	Misses    int64   `json:"misses"`
+	Refreshes int64   `json:"refreshes"`
+	Updates   int64   `json:"updates"`
+	HitRatio  float64 `json:"hitRatio"`
+}
+
+type cacheEntry struct {
+	data     models.MarketData
+	loadedAt time.Time
+}
+
+// Cache keeps the latest bar of each instrument in memory in front of a
+// MarketDataRepositorer. Bars stored through it update the cache right
+// away; the ones stored elsewhere, such as by another process, show up once
+// the cached bar is older than the TTL and is reloaded. Queries other than
+// the latest bar go straight to the repository. It is safe for concurrent
+// use.
+type Cache struct {
+	repository.MarketDataRepositorer
+	ttl time.Duration
+	now func() time.Time
+
+	mu      sync.RWMutex
+	entries map[uint]*cacheEntry
+
+	hits      atomic.Int64
+	misses    atomic.Int64
+	refreshes atomic.Int64
+	updates   atomic.Int64
+}
+
+func NewCache(marketDataRepo repository.MarketDataRepositorer, ttl time.Duration) *Cache {
+	return &Cache{
+		MarketDataRepositorer: marketDataRepo,
+		ttl:                   ttl,
+		now:                   time.Now,
+		entries:               make(map[uint]*cacheEntry),
+	}
+}
+
+// GetLatestMarketData returns the cached bar of the instrument, loading it
+// from the repository when it is missing or expired
+func (c *Cache) GetLatestMarketData(instrumentID uint) (*models.MarketData, error) {
+	if data, ok := c.lookup(instrumentID); ok {
+		return data, nil
+	}
+
+	data, err := c.MarketDataRepositorer.GetLatestMarketData(instrumentID)
+	if err != nil {
+		return nil, err
+	}
+	return c.store(data, false), nil
+}
+
+// GetLatestMarketDataForInstruments returns the cached bars of the
+// instruments, loading the missing and expired ones in a single query
+func (c *Cache) GetLatestMarketDataForInstruments(instrumentIDs []uint) (map[uint]*models.MarketData, error) {
+	marketData := make(map[uint]*models.MarketData, len(instrumentIDs))
+	missing := make([]uint, 0)
+	for _, instrumentID := range instrumentIDs {
+		if data, ok := c.lookup(instrumentID); ok {
+			marketData[instrumentID] = data
+		} else {
+			missing = append(missing, instrumentID)
+		}
+	}
+	if len(missing) == 0 {
+		return marketData, nil
+	}
+
+	loaded, err := c.MarketDataRepositorer.GetLatestMarketDataForInstruments(missing)
+	if err != nil {
+		return nil, err
+	}
+	for instrumentID, data := range loaded {
+		marketData[instrumentID] = c.store(data, false)
+	}
+	return marketData, nil
+}
+
+// Create stores the bar and makes it the cached one of its instrument if it
+// is not older than the cached bar
+func (c *Cache) Create(marketData *models.MarketData) error {
+	if err := c.MarketDataRepositorer.Create(marketData); err != nil {
+		return err
+	}
+	c.store(marketData, true)
+	return nil
+}
+
+// Upsert stores the bars and updates the cached ones they replace or follow
+func (c *Cache) Upsert(marketData []models.MarketData) error {
+	if err := c.MarketDataRepositorer.Upsert(marketData); err != nil {
+		return err
+	}
+	for i := range marketData {
+		c.store(&marketData[i], true)
+	}
+	return nil
+}
+
+// Stats returns the cache's counters
+func (c *Cache) Stats() CacheStats {
+	c.mu.RLock()
+	entries := len(c.entries)
+	c.mu.RUnlock()
+
+	stats := CacheStats{
+		Entries:   entries,
+		Hits:      c.hits.Load(),
+		Misses:    c.misses.Load(),
+		Refreshes: c.refreshes.Load(),
+		Updates:   c.updates.Load(),
+	}
+	if lookups := stats.Hits + stats.Misses + stats.Refreshes; lookups > 0 {
+		stats.HitRatio = float64(stats.Hits) / float64(lookups)
+	}
+	return stats
+}
+
+// lookup returns a copy of the instrument's cached bar if it has not
+// expired, counting the hit, miss or refresh
+func (c *Cache) lookup(instrumentID uint) (*models.MarketData, bool) {
+	c.mu.RLock()
+	entry, ok := c.entries[instrumentID]
+	var data models.MarketData
+	fresh := false
+	if ok {
+		data = entry.data
+		fresh = c.now().Sub(entry.loadedAt) < c.ttl
+	}
+	c.mu.RUnlock()
+
+	switch {
+	case !ok:
+		c.misses.Add(1)
+	case !fresh:
+		c.refreshes.Add(1)
+	default:
+		c.hits.Add(1)
+		return &data, true
+	}
+	return nil, false
+}
+
+// store caches the bar unless the cached one is newer, and returns a copy of
+// the bar the instrument ends up with. Bars written through the cache only
+// update instruments already cached, since an uncached one may have newer
+// bars in the repository.
+func (c *Cache) store(data *models.MarketData, written bool) *models.MarketData {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[data.InstrumentID]
+	switch {
+	case !ok && written:
+		stored := *data
+		return &stored
+	case !ok:
+		entry = &cacheEntry{data: *data}
+		c.entries[data.InstrumentID] = entry
+	case !data.DateTime.Before(entry.data.DateTime):
+		entry.data = *data
+		if written {
+			c.updates.Add(1)
+		}
+	}
+	if !written {
+		entry.loadedAt = c.now()
+	}
+	result := entry.data
+	return &result
+}
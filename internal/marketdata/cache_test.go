@@ -0,0 +1,169 @@
+package marketdata
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	mocks "github.com/NahuelDT/portfolio-api/internal/mocks/repository"
+	"github.com/NahuelDT/portfolio-api/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCache(t *testing.T) {
+	now := time.Date(2024, time.March, 4, 15, 0, 0, 0, time.UTC)
+
+	setUp := func() (*Cache, *mocks.MarketDataRepositorer, *time.Time) {
+		mockMarketDataRepo := new(mocks.MarketDataRepositorer)
+		cache := NewCache(mockMarketDataRepo, time.Minute)
+		clock := now
+		cache.now = func() time.Time { return clock }
+		return cache, mockMarketDataRepo, &clock
+	}
+
+	t.Run("The latest bar is loaded once until it expires", func(t *testing.T) {
+		cache, mockMarketDataRepo, clock := setUp()
+		mockMarketDataRepo.On("GetLatestMarketData", uint(1)).Return(&models.MarketData{InstrumentID: 1, Close: 100, DateTime: now}, nil).Once()
+
+		for i := 0; i < 3; i++ {
+			data, err := cache.GetLatestMarketData(1)
+			assert.NoError(t, err)
+			assert.Equal(t, float64(100), data.Close)
+		}
+		mockMarketDataRepo.AssertNumberOfCalls(t, "GetLatestMarketData", 1)
+
+		*clock = now.Add(time.Minute)
+		mockMarketDataRepo.On("GetLatestMarketData", uint(1)).Return(&models.MarketData{InstrumentID: 1, Close: 101, DateTime: now.Add(30 * time.Second)}, nil).Once()
+		data, err := cache.GetLatestMarketData(1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, float64(101), data.Close)
+		assert.Equal(t, CacheStats{Entries: 1, Hits: 2, Misses: 1, Refreshes: 1, HitRatio: 0.5}, cache.Stats())
+	})
+
+	t.Run("Callers get their own copy of the bar", func(t *testing.T) {
+		cache, mockMarketDataRepo, _ := setUp()
+		mockMarketDataRepo.On("GetLatestMarketData", uint(1)).Return(&models.MarketData{InstrumentID: 1, Close: 100, DateTime: now}, nil).Once()
+
+		data, err := cache.GetLatestMarketData(1)
+		assert.NoError(t, err)
+		data.Close = 0
+
+		data, err = cache.GetLatestMarketData(1)
+		assert.NoError(t, err)
+		assert.Equal(t, float64(100), data.Close)
+	})
+
+	t.Run("Errors are not cached", func(t *testing.T) {
+		cache, mockMarketDataRepo, _ := setUp()
+		mockMarketDataRepo.On("GetLatestMarketData", uint(1)).Return(nil, errors.New("record not found")).Once()
+		mockMarketDataRepo.On("GetLatestMarketData", uint(1)).Return(&models.MarketData{InstrumentID: 1, Close: 100, DateTime: now}, nil).Once()
+
+		_, err := cache.GetLatestMarketData(1)
+		assert.Error(t, err)
+		data, err := cache.GetLatestMarketData(1)
+		assert.NoError(t, err)
+		assert.Equal(t, float64(100), data.Close)
+	})
+
+	t.Run("Only missing and expired instruments are loaded in batch", func(t *testing.T) {
+		cache, mockMarketDataRepo, clock := setUp()
+		mockMarketDataRepo.On("GetLatestMarketDataForInstruments", []uint{1, 2}).Return(map[uint]*models.MarketData{
+			1: {InstrumentID: 1, Close: 100, DateTime: now},
+			2: {InstrumentID: 2, Close: 50, DateTime: now},
+		}, nil).Once()
+		_, err := cache.GetLatestMarketDataForInstruments([]uint{1, 2})
+		assert.NoError(t, err)
+
+		*clock = now.Add(30 * time.Second)
+		mockMarketDataRepo.On("GetLatestMarketData", uint(3)).Return(&models.MarketData{InstrumentID: 3, Close: 10, DateTime: now}, nil).Once()
+		_, err = cache.GetLatestMarketData(3)
+		assert.NoError(t, err)
+
+		*clock = now.Add(time.Minute)
+		mockMarketDataRepo.On("GetLatestMarketDataForInstruments", []uint{1, 2, 4}).Return(map[uint]*models.MarketData{
+			1: {InstrumentID: 1, Close: 105, DateTime: now.Add(time.Minute)},
+			2: {InstrumentID: 2, Close: 50, DateTime: now},
+		}, nil).Once()
+		marketData, err := cache.GetLatestMarketDataForInstruments([]uint{1, 2, 3, 4})
+
+		assert.NoError(t, err)
+		assert.Len(t, marketData, 3)
+		assert.Equal(t, float64(105), marketData[1].Close)
+		assert.Equal(t, float64(50), marketData[2].Close)
+		assert.Equal(t, float64(10), marketData[3].Close)
+		mockMarketDataRepo.AssertExpectations(t)
+	})
+
+	t.Run("Stored bars update cached instruments", func(t *testing.T) {
+		cache, mockMarketDataRepo, _ := setUp()
+		mockMarketDataRepo.On("GetLatestMarketData", uint(1)).Return(&models.MarketData{InstrumentID: 1, Close: 100, DateTime: now}, nil).Once()
+		mockMarketDataRepo.On("Create", mock.AnythingOfType("*models.MarketData")).Return(nil)
+		mockMarketDataRepo.On("Upsert", mock.Anything).Return(nil)
+		_, err := cache.GetLatestMarketData(1)
+		assert.NoError(t, err)
+
+		assert.NoError(t, cache.Create(&models.MarketData{InstrumentID: 1, Close: 102, DateTime: now.Add(time.Second)}))
+		// Backfilled bars do not replace a newer one
+		assert.NoError(t, cache.Upsert([]models.MarketData{{InstrumentID: 1, Close: 90, DateTime: now.Add(-24 * time.Hour)}}))
+		data, err := cache.GetLatestMarketData(1)
+		assert.NoError(t, err)
+		assert.Equal(t, float64(102), data.Close)
+
+		// Uncached instruments may have newer bars, so they are loaded
+		assert.NoError(t, cache.Create(&models.MarketData{InstrumentID: 2, Close: 50, DateTime: now}))
+		mockMarketDataRepo.On("GetLatestMarketData", uint(2)).Return(&models.MarketData{InstrumentID: 2, Close: 55, DateTime: now.Add(time.Hour)}, nil).Once()
+		data, err = cache.GetLatestMarketData(2)
+		assert.NoError(t, err)
+		assert.Equal(t, float64(55), data.Close)
+		assert.Equal(t, int64(1), cache.Stats().Updates)
+	})
+
+	t.Run("Failed writes leave the cache untouched", func(t *testing.T) {
+		cache, mockMarketDataRepo, _ := setUp()
+		mockMarketDataRepo.On("GetLatestMarketData", uint(1)).Return(&models.MarketData{InstrumentID: 1, Close: 100, DateTime: now}, nil).Once()
+		mockMarketDataRepo.On("Create", mock.AnythingOfType("*models.MarketData")).Return(errors.New("connection refused"))
+		_, err := cache.GetLatestMarketData(1)
+		assert.NoError(t, err)
+
+		assert.Error(t, cache.Create(&models.MarketData{InstrumentID: 1, Close: 102, DateTime: now.Add(time.Second)}))
+		data, err := cache.GetLatestMarketData(1)
+		assert.NoError(t, err)
+		assert.Equal(t, float64(100), data.Close)
+	})
+
+	t.Run("Concurrent reads and writes", func(t *testing.T) {
+		mockMarketDataRepo := new(mocks.MarketDataRepositorer)
+		cache := NewCache(mockMarketDataRepo, time.Minute)
+		mockMarketDataRepo.On("GetLatestMarketData", mock.AnythingOfType("uint")).Return(func(instrumentID uint) *models.MarketData {
+			return &models.MarketData{InstrumentID: instrumentID, DateTime: now}
+		}, nil)
+		mockMarketDataRepo.On("Create", mock.AnythingOfType("*models.MarketData")).Return(nil)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				instrumentID := uint(i % 2)
+				for j := 1; j <= 100; j++ {
+					_, err := cache.GetLatestMarketData(instrumentID)
+					assert.NoError(t, err)
+					assert.NoError(t, cache.Create(&models.MarketData{InstrumentID: instrumentID, Close: float64(j), DateTime: now.Add(time.Duration(j) * time.Second)}))
+				}
+			}(i)
+		}
+		wg.Wait()
+
+		for instrumentID := uint(0); instrumentID < 2; instrumentID++ {
+			data, err := cache.GetLatestMarketData(instrumentID)
+			assert.NoError(t, err)
+			assert.Equal(t, float64(100), data.Close)
+		}
+		stats := cache.Stats()
+		assert.Equal(t, 2, stats.Entries)
+		assert.Equal(t, int64(8*100+2), stats.Hits+stats.Misses)
+	})
+}
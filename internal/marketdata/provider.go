@@ -0,0 +1,71 @@
+// Package marketdata feeds prices into the API: providers supply new market
+// data bars and a poller stores them through a MarketDataRepositorer, so
+// that, stored through the MarketDataService, they reach its listeners.
+package marketdata
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/NahuelDT/portfolio-api/internal/models"
+	"github.com/NahuelDT/portfolio-api/internal/repository"
+)
+
+// DefaultPollInterval is how often the poller asks its provider for new bars
+const DefaultPollInterval = time.Second
+
+// MarketDataProvider supplies market data bars
+type MarketDataProvider interface {
+	// Poll returns the bars produced since the previous call up to now,
+	// oldest first. Instruments without new prices are left out.
+	Poll(ctx context.Context, now time.Time) ([]models.MarketData, error)
+}
+
+// Poller periodically stores the bars of a provider
+type Poller struct {
+	provider       MarketDataProvider
+	marketDataRepo repository.MarketDataRepositorer
+	interval       time.Duration
+}
+
+func NewPoller(provider MarketDataProvider, marketDataRepo repository.MarketDataRepositorer, interval time.Duration) *Poller {
+	return &Poller{
+		provider:       provider,
+		marketDataRepo: marketDataRepo,
+		interval:       interval,
+	}
+}
+
+// Run polls the provider every interval until the context is cancelled.
+// Failures are logged and retried on the next poll.
+func (p *Poller) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if _, err := p.PollOnce(ctx, now); err != nil {
+				log.Printf("Failed to poll market data: %v", err)
+			}
+		}
+	}
+}
+
+// PollOnce asks the provider for its new bars and stores them in order,
+// returning how many were stored. It stops at the first bar that cannot be
+// stored.
+func (p *Poller) PollOnce(ctx context.Context, now time.Time) (int, error) {
+	bars, err := p.provider.Poll(ctx, now)
+	if err != nil {
+		return 0, err
+	}
+	for i := range bars {
+		if err := p.marketDataRepo.Create(&bars[i]); err != nil {
+			return i, err
+		}
+	}
+	return len(bars), nil
+}
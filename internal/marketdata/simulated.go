@@ -0,0 +1,186 @@
+package marketdata
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/NahuelDT/portfolio-api/internal/models"
+	"github.com/NahuelDT/portfolio-api/internal/repository"
+)
+
+// Defaults of the simulation parameters
+const (
+	DefaultSimulatedPrice      = 100
+	DefaultSimulatedVolatility = 0.2
+	DefaultSimulatedTick       = 5 * time.Second
+)
+
+// maxTicksPerPoll bounds the bars an instrument produces in a single poll,
+// so a poller that falls behind skips ahead instead of flooding the store
+const maxTicksPerPoll = 100
+
+// year is the time unit of the annualized drift and volatility
+const year = 365 * 24 * time.Hour
+
+// SimulationParams describe the price of a simulated instrument. Drift and
+// Volatility are annualized, as a fraction; StartPrice is where instruments
+// without market data start.
+type SimulationParams struct {
+	Drift        float64
+	Volatility   float64
+	TickInterval time.Duration
+	StartPrice   float64
+}
+
+// DefaultSimulationParams leave the price flat on average
+var DefaultSimulationParams = SimulationParams{
+	Volatility:   DefaultSimulatedVolatility,
+	TickInterval: DefaultSimulatedTick,
+	StartPrice:   DefaultSimulatedPrice,
+}
+
+// SimulatedInstrument is an instrument whose price the SimulatedProvider
+// moves, starting from Price with PreviousClose as the close of the day
+// before. StartPrice is not used.
+type SimulatedInstrument struct {
+	InstrumentID  uint
+	Price         float64
+	PreviousClose float64
+	SimulationParams
+}
+
+type simulatedPrice struct {
+	SimulatedInstrument
+	nextTick time.Time
+	day      time.Time
+}
+
+// SimulatedProvider moves prices with a geometric Brownian motion: every
+// tick multiplies an instrument's price by
+// exp((drift - volatility²/2)·dt + volatility·√dt·Z), with dt the tick
+// interval in years and Z a standard normal draw. Each tick is a bar that
+// opens at the price before it and closes at the new one.
+type SimulatedProvider struct {
+	mu     sync.Mutex
+	rand   *rand.Rand
+	prices []*simulatedPrice
+}
+
+// NewSimulatedProvider simulates the instruments from start on, drawing from
+// the given random source so runs can be repeated
+func NewSimulatedProvider(instruments []SimulatedInstrument, start time.Time, source rand.Source) *SimulatedProvider {
+	provider := &SimulatedProvider{rand: rand.New(source)}
+	for _, instrument := range instruments {
+		if instrument.TickInterval <= 0 {
+			instrument.TickInterval = DefaultSimulatedTick
+		}
+		if instrument.Price <= 0 {
+			instrument.Price = DefaultSimulatedPrice
+		}
+		if instrument.PreviousClose == 0 {
+			instrument.PreviousClose = instrument.Price
+		}
+		provider.prices = append(provider.prices, &simulatedPrice{
+			SimulatedInstrument: instrument,
+			nextTick:            start.Add(instrument.TickInterval),
+			day:                 startOfDay(start),
+		})
+	}
+	return provider
+}
+
+// Poll returns a bar for every tick of every instrument up to now, oldest
+// first
+func (p *SimulatedProvider) Poll(ctx context.Context, now time.Time) ([]models.MarketData, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	bars := make([]models.MarketData, 0)
+	for _, price := range p.prices {
+		if skipped := int64(now.Sub(price.nextTick) / price.TickInterval); skipped >= maxTicksPerPoll {
+			price.nextTick = price.nextTick.Add(time.Duration(skipped-maxTicksPerPoll+1) * price.TickInterval)
+		}
+		for !price.nextTick.After(now) {
+			bars = append(bars, p.tick(price))
+			price.nextTick = price.nextTick.Add(price.TickInterval)
+		}
+	}
+	sort.SliceStable(bars, func(i, j int) bool {
+		return bars[i].DateTime.Before(bars[j].DateTime)
+	})
+	return bars, nil
+}
+
+// tick moves the instrument's price one step and returns its bar
+func (p *SimulatedProvider) tick(price *simulatedPrice) models.MarketData {
+	// The last price of a day is the previous close of the next one
+	if day := startOfDay(price.nextTick); day.After(price.day) {
+		price.PreviousClose = price.Price
+		price.day = day
+	}
+
+	dt := float64(price.TickInterval) / float64(year)
+	open := price.Price
+	price.Price *= math.Exp((price.Drift-price.Volatility*price.Volatility/2)*dt +
+		price.Volatility*math.Sqrt(dt)*p.rand.NormFloat64())
+
+	return models.MarketData{
+		InstrumentID:  price.InstrumentID,
+		Open:          open,
+		High:          math.Max(open, price.Price),
+		Low:           math.Min(open, price.Price),
+		Close:         price.Price,
+		PreviousClose: price.PreviousClose,
+		DateTime:      price.nextTick,
+	}
+}
+
+// SimulateTickers resolves the tickers to simulate, keyed by upper-cased
+// ticker, to their instruments. Instruments continue from their latest
+// stored bar, or start at the StartPrice of their parameters if they have
+// none.
+func SimulateTickers(instrumentRepo repository.InstrumentRepositorer, marketDataRepo repository.MarketDataRepositorer, params map[string]SimulationParams) ([]SimulatedInstrument, error) {
+	tickers := make([]string, 0, len(params))
+	for ticker := range params {
+		tickers = append(tickers, ticker)
+	}
+	sort.Strings(tickers)
+
+	instrumentsByTicker, err := instrumentRepo.GetByTickers(tickers)
+	if err != nil {
+		return nil, err
+	}
+	instrumentIDs := make([]uint, 0, len(instrumentsByTicker))
+	for _, ticker := range tickers {
+		instrument, ok := instrumentsByTicker[ticker]
+		if !ok {
+			return nil, fmt.Errorf("unknown ticker %q", ticker)
+		}
+		instrumentIDs = append(instrumentIDs, instrument.ID)
+	}
+	latest, err := marketDataRepo.GetLatestMarketDataForInstruments(instrumentIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	instruments := make([]SimulatedInstrument, 0, len(tickers))
+	for i, ticker := range tickers {
+		instrument := SimulatedInstrument{InstrumentID: instrumentIDs[i], Price: params[ticker].StartPrice, SimulationParams: params[ticker]}
+		if data, ok := latest[instrument.InstrumentID]; ok && data.Close > 0 {
+			instrument.Price = data.Close
+			instrument.PreviousClose = data.PreviousClose
+		}
+		instruments = append(instruments, instrument)
+	}
+	return instruments, nil
+}
+
+func startOfDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
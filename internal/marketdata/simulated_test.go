@@ -0,0 +1,204 @@
+package marketdata
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	providermocks "github.com/NahuelDT/portfolio-api/internal/mocks/marketdata"
+	mocks "github.com/NahuelDT/portfolio-api/internal/mocks/repository"
+	"github.com/NahuelDT/portfolio-api/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSimulatedProvider(t *testing.T) {
+	start := time.Date(2024, time.March, 4, 23, 59, 50, 0, time.UTC)
+
+	t.Run("Each instrument ticks at its own rate", func(t *testing.T) {
+		provider := NewSimulatedProvider([]SimulatedInstrument{
+			{InstrumentID: 1, Price: 100, SimulationParams: SimulationParams{Volatility: 0.3, TickInterval: time.Second}},
+			{InstrumentID: 2, Price: 50, PreviousClose: 49, SimulationParams: SimulationParams{Volatility: 0.3, TickInterval: 4 * time.Second}},
+		}, start, rand.NewSource(1))
+
+		bars, err := provider.Poll(context.Background(), start.Add(8*time.Second))
+
+		assert.NoError(t, err)
+		assert.Len(t, bars, 8+2)
+		counts := map[uint]int{}
+		closes := map[uint]float64{1: 100, 2: 50}
+		for i, bar := range bars {
+			counts[bar.InstrumentID]++
+			if i > 0 {
+				assert.False(t, bar.DateTime.Before(bars[i-1].DateTime))
+			}
+			// Bars chain from one close to the next open
+			assert.Equal(t, closes[bar.InstrumentID], bar.Open)
+			assert.Equal(t, math.Max(bar.Open, bar.Close), bar.High)
+			assert.Equal(t, math.Min(bar.Open, bar.Close), bar.Low)
+			closes[bar.InstrumentID] = bar.Close
+		}
+		assert.Equal(t, map[uint]int{1: 8, 2: 2}, counts)
+
+		// Nothing is produced until the next tick is due
+		bars, err = provider.Poll(context.Background(), start.Add(8*time.Second+500*time.Millisecond))
+		assert.NoError(t, err)
+		assert.Empty(t, bars)
+	})
+
+	t.Run("The last price of a day becomes the previous close", func(t *testing.T) {
+		provider := NewSimulatedProvider([]SimulatedInstrument{
+			{InstrumentID: 1, Price: 100, PreviousClose: 98, SimulationParams: SimulationParams{Volatility: 0.3, TickInterval: 5 * time.Second}},
+		}, start, rand.NewSource(1))
+
+		bars, err := provider.Poll(context.Background(), start.Add(15*time.Second))
+
+		assert.NoError(t, err)
+		assert.Len(t, bars, 3)
+		assert.Equal(t, float64(98), bars[0].PreviousClose)
+		assert.Equal(t, bars[0].Close, bars[1].PreviousClose)
+		assert.Equal(t, bars[0].Close, bars[2].PreviousClose)
+	})
+
+	t.Run("Drift and volatility follow a geometric Brownian motion", func(t *testing.T) {
+		drift, volatility := 0.5, 0.2
+		provider := NewSimulatedProvider([]SimulatedInstrument{
+			{InstrumentID: 1, Price: 100, SimulationParams: SimulationParams{Drift: drift, Volatility: volatility, TickInterval: 24 * time.Hour}},
+		}, start, rand.NewSource(7))
+
+		// Log returns of daily ticks have mean (drift - volatility²/2)/365 and
+		// standard deviation volatility/√365
+		returns := make([]float64, 0)
+		now := start
+		for len(returns) < 20000 {
+			now = now.Add(50 * 24 * time.Hour)
+			bars, err := provider.Poll(context.Background(), now)
+			assert.NoError(t, err)
+			for _, bar := range bars {
+				returns = append(returns, math.Log(bar.Close/bar.Open))
+			}
+		}
+		mean, variance := 0.0, 0.0
+		for _, r := range returns {
+			mean += r
+		}
+		mean /= float64(len(returns))
+		for _, r := range returns {
+			variance += (r - mean) * (r - mean)
+		}
+		variance /= float64(len(returns) - 1)
+
+		dt := 1.0 / 365
+		assert.InDelta(t, (drift-volatility*volatility/2)*dt, mean, 3*volatility*math.Sqrt(dt)/math.Sqrt(float64(len(returns))))
+		assert.InDelta(t, volatility*math.Sqrt(dt), math.Sqrt(variance), 0.02*volatility*math.Sqrt(dt))
+	})
+
+	t.Run("A poller that falls behind skips ahead", func(t *testing.T) {
+		provider := NewSimulatedProvider([]SimulatedInstrument{
+			{InstrumentID: 1, Price: 100, SimulationParams: SimulationParams{Volatility: 0.3, TickInterval: time.Second}},
+		}, start, rand.NewSource(1))
+
+		bars, err := provider.Poll(context.Background(), start.Add(time.Hour))
+
+		assert.NoError(t, err)
+		assert.Len(t, bars, maxTicksPerPoll)
+		assert.Equal(t, start.Add(time.Hour), bars[len(bars)-1].DateTime)
+	})
+
+	t.Run("Simulated tickers continue from their latest bar", func(t *testing.T) {
+		mockInstrumentRepo := new(mocks.InstrumentRepositorer)
+		mockMarketDataRepo := new(mocks.MarketDataRepositorer)
+
+		params := SimulationParams{Drift: 0.1, Volatility: 0.4, TickInterval: 2 * time.Second, StartPrice: 10}
+		mockInstrumentRepo.On("GetByTickers", []string{"GGAL", "YPFD"}).Return(map[string]*models.Instrument{
+			"GGAL": {ID: 1, Ticker: "GGAL"},
+			"YPFD": {ID: 2, Ticker: "YPFD"},
+		}, nil)
+		mockMarketDataRepo.On("GetLatestMarketDataForInstruments", []uint{1, 2}).Return(map[uint]*models.MarketData{
+			1: {InstrumentID: 1, Close: 1500, PreviousClose: 1450},
+		}, nil)
+
+		instruments, err := SimulateTickers(mockInstrumentRepo, mockMarketDataRepo, map[string]SimulationParams{"YPFD": params, "GGAL": params})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []SimulatedInstrument{
+			{InstrumentID: 1, Price: 1500, PreviousClose: 1450, SimulationParams: params},
+			{InstrumentID: 2, Price: 10, SimulationParams: params},
+		}, instruments)
+
+		mockInstrumentRepo.On("GetByTickers", []string{"GGAL", "PAMP"}).Return(map[string]*models.Instrument{
+			"GGAL": {ID: 1, Ticker: "GGAL"},
+		}, nil)
+		_, err = SimulateTickers(mockInstrumentRepo, mockMarketDataRepo, map[string]SimulationParams{"GGAL": params, "PAMP": params})
+		assert.EqualError(t, err, `unknown ticker "PAMP"`)
+	})
+}
+
+func TestPoller(t *testing.T) {
+	now := time.Date(2024, time.March, 4, 15, 0, 0, 0, time.UTC)
+
+	t.Run("Store the bars of the provider in order", func(t *testing.T) {
+		mockProvider := new(providermocks.MarketDataProvider)
+		mockMarketDataRepo := new(mocks.MarketDataRepositorer)
+		poller := NewPoller(mockProvider, mockMarketDataRepo, DefaultPollInterval)
+
+		bars := []models.MarketData{
+			{InstrumentID: 1, Close: 100, DateTime: now.Add(-time.Second)},
+			{InstrumentID: 2, Close: 50, DateTime: now},
+		}
+		mockProvider.On("Poll", mock.Anything, now).Return(bars, nil)
+		var stored []uint
+		mockMarketDataRepo.On("Create", mock.AnythingOfType("*models.MarketData")).Run(func(args mock.Arguments) {
+			stored = append(stored, args.Get(0).(*models.MarketData).InstrumentID)
+		}).Return(nil)
+
+		count, err := poller.PollOnce(context.Background(), now)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, count)
+		assert.Equal(t, []uint{1, 2}, stored)
+	})
+
+	t.Run("Stop at the first bar that cannot be stored", func(t *testing.T) {
+		mockProvider := new(providermocks.MarketDataProvider)
+		mockMarketDataRepo := new(mocks.MarketDataRepositorer)
+		poller := NewPoller(mockProvider, mockMarketDataRepo, DefaultPollInterval)
+
+		mockProvider.On("Poll", mock.Anything, now).Return([]models.MarketData{{InstrumentID: 1}, {InstrumentID: 2}}, nil)
+		mockMarketDataRepo.On("Create", mock.AnythingOfType("*models.MarketData")).Return(errors.New("connection refused")).Once()
+
+		count, err := poller.PollOnce(context.Background(), now)
+
+		assert.Error(t, err)
+		assert.Equal(t, 0, count)
+		mockMarketDataRepo.AssertNumberOfCalls(t, "Create", 1)
+	})
+
+	t.Run("Run polls until cancelled", func(t *testing.T) {
+		mockProvider := new(providermocks.MarketDataProvider)
+		mockMarketDataRepo := new(mocks.MarketDataRepositorer)
+		poller := NewPoller(mockProvider, mockMarketDataRepo, time.Millisecond)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		polled := make(chan struct{}, 10)
+		mockProvider.On("Poll", mock.Anything, mock.AnythingOfType("time.Time")).Run(func(args mock.Arguments) {
+			select {
+			case polled <- struct{}{}:
+			default:
+			}
+		}).Return([]models.MarketData{}, nil)
+
+		done := make(chan struct{})
+		go func() {
+			poller.Run(ctx)
+			close(done)
+		}()
+		<-polled
+		<-polled
+		cancel()
+		<-done
+	})
+}
@@ -0,0 +1,14 @@
+-- Instruments and orders have a currency; the ones stored before are in ARS
+ALTER TABLE instruments ADD COLUMN IF NOT EXISTS currency TEXT NOT NULL DEFAULT 'ARS';
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS currency TEXT NOT NULL DEFAULT 'ARS';
+
+-- Price of one unit of basecurrency in quotecurrency
+CREATE TABLE IF NOT EXISTS fxrates (
+    id SERIAL PRIMARY KEY,
+    basecurrency TEXT NOT NULL,
+    quotecurrency TEXT NOT NULL,
+    rate DOUBLE PRECISION NOT NULL,
+    date TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS fxrates_pair_date_idx ON fxrates (basecurrency, quotecurrency, date);
@@ -0,0 +1,12 @@
+-- Generated account statements; content is the statement as JSON
+CREATE TABLE IF NOT EXISTS statements (
+    id SERIAL PRIMARY KEY,
+    userid INTEGER NOT NULL,
+    periodtype TEXT NOT NULL,
+    periodstart TIMESTAMPTZ NOT NULL,
+    periodend TIMESTAMPTZ NOT NULL,
+    generatedat TIMESTAMPTZ NOT NULL,
+    content TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS statements_userid_idx ON statements (userid, periodstart);
@@ -0,0 +1,13 @@
+-- Dividends and cash distributions per share, credited to the holders
+-- before exdate as DIVIDEND orders on paydate
+CREATE TABLE IF NOT EXISTS distributions (
+    id SERIAL PRIMARY KEY,
+    instrumentid INTEGER NOT NULL,
+    exdate TIMESTAMPTZ NOT NULL,
+    paydate TIMESTAMPTZ NOT NULL,
+    amountpershare DOUBLE PRECISION NOT NULL,
+    currency TEXT NOT NULL DEFAULT '',
+    creditedat TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS distributions_uncredited_idx ON distributions (exdate) WHERE creditedat IS NULL;
@@ -0,0 +1,13 @@
+-- Splits, reverse splits, mergers and ticker changes from effectivedate on
+CREATE TABLE IF NOT EXISTS corporateactions (
+    id SERIAL PRIMARY KEY,
+    instrumentid INTEGER NOT NULL,
+    type TEXT NOT NULL,
+    effectivedate TIMESTAMPTZ NOT NULL,
+    ratio DOUBLE PRECISION NOT NULL DEFAULT 0,
+    targetinstrumentid INTEGER,
+    oldticker TEXT NOT NULL DEFAULT '',
+    newticker TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS corporateactions_effectivedate_idx ON corporateactions (effectivedate);
@@ -0,0 +1,25 @@
+-- Materialized positions and cash balances, updated in the same transaction
+-- as every fill. Databases with orders from before need a one-off
+-- `go run cmd/positions/main.go rebuild` to fill them.
+CREATE TABLE IF NOT EXISTS positions (
+    userid INTEGER NOT NULL,
+    instrumentid INTEGER NOT NULL,
+    quantity DOUBLE PRECISION NOT NULL DEFAULT 0,
+    boughtquantity DOUBLE PRECISION NOT NULL DEFAULT 0,
+    boughtcost DOUBLE PRECISION NOT NULL DEFAULT 0,
+    updatedat TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (userid, instrumentid)
+);
+
+CREATE TABLE IF NOT EXISTS cashbalances (
+    userid INTEGER NOT NULL,
+    currency TEXT NOT NULL,
+    balance DOUBLE PRECISION NOT NULL DEFAULT 0,
+    updatedat TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (userid, currency)
+);
+
+CREATE INDEX IF NOT EXISTS positions_instrumentid_idx ON positions (instrumentid);
+
+-- Set once the positions have been restated for the action
+ALTER TABLE corporateactions ADD COLUMN IF NOT EXISTS appliedat TIMESTAMPTZ;
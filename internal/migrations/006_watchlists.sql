@@ -0,0 +1,16 @@
+-- Watchlists and the instruments they follow, in the user's order
+CREATE TABLE IF NOT EXISTS watchlists (
+    id SERIAL PRIMARY KEY,
+    userid INTEGER NOT NULL,
+    name TEXT NOT NULL,
+    createdat TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS watchlists_userid_idx ON watchlists (userid);
+
+CREATE TABLE IF NOT EXISTS watchlistitems (
+    watchlistid INTEGER NOT NULL REFERENCES watchlists (id) ON DELETE CASCADE,
+    instrumentid INTEGER NOT NULL,
+    position INTEGER NOT NULL,
+    PRIMARY KEY (watchlistid, instrumentid)
+);
@@ -0,0 +1,42 @@
+-- Price alerts, the triggers they produce and the webhooks they are
+-- delivered to
+CREATE TABLE IF NOT EXISTS pricealerts (
+    id SERIAL PRIMARY KEY,
+    userid INTEGER NOT NULL,
+    instrumentid INTEGER NOT NULL,
+    type TEXT NOT NULL,
+    threshold DOUBLE PRECISION NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    createdat TIMESTAMPTZ NOT NULL DEFAULT now(),
+    triggeredat TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS pricealerts_userid_idx ON pricealerts (userid);
+CREATE INDEX IF NOT EXISTS pricealerts_active_idx ON pricealerts (instrumentid) WHERE active;
+
+CREATE TABLE IF NOT EXISTS alerttriggers (
+    id SERIAL PRIMARY KEY,
+    alertid INTEGER NOT NULL,
+    userid INTEGER NOT NULL,
+    instrumentid INTEGER NOT NULL,
+    ticker TEXT NOT NULL DEFAULT '',
+    type TEXT NOT NULL,
+    threshold DOUBLE PRECISION NOT NULL,
+    price DOUBLE PRECISION NOT NULL,
+    changepct DOUBLE PRECISION NOT NULL DEFAULT 0,
+    pricetime TIMESTAMPTZ NOT NULL,
+    triggeredat TIMESTAMPTZ NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    deliveredat TIMESTAMPTZ,
+    lasterror TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS alerttriggers_userid_idx ON alerttriggers (userid);
+CREATE INDEX IF NOT EXISTS alerttriggers_undelivered_idx ON alerttriggers (id) WHERE deliveredat IS NULL;
+
+CREATE TABLE IF NOT EXISTS alertwebhooks (
+    userid INTEGER PRIMARY KEY,
+    url TEXT NOT NULL,
+    secret TEXT NOT NULL,
+    updatedat TIMESTAMPTZ NOT NULL DEFAULT now()
+);
@@ -0,0 +1,20 @@
+-- Named sub-accounts. Account 0 is every user's main account and has no
+-- row; everything stored before belongs to it.
+CREATE TABLE IF NOT EXISTS accounts (
+    id SERIAL PRIMARY KEY,
+    userid INTEGER NOT NULL,
+    name TEXT NOT NULL,
+    createdat TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS accounts_userid_idx ON accounts (userid);
+
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS accountid INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE positions ADD COLUMN IF NOT EXISTS accountid INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE positions DROP CONSTRAINT IF EXISTS positions_pkey;
+ALTER TABLE positions ADD PRIMARY KEY (userid, accountid, instrumentid);
+
+ALTER TABLE cashbalances ADD COLUMN IF NOT EXISTS accountid INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE cashbalances DROP CONSTRAINT IF EXISTS cashbalances_pkey;
+ALTER TABLE cashbalances ADD PRIMARY KEY (userid, accountid, currency);
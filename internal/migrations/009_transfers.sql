@@ -0,0 +1,23 @@
+-- Cash and in-kind transfers, applied as a debit and a credit order
+CREATE TABLE IF NOT EXISTS transfers (
+    id SERIAL PRIMARY KEY,
+    kind TEXT NOT NULL,
+    fromuserid INTEGER NOT NULL,
+    fromaccountid INTEGER NOT NULL DEFAULT 0,
+    touserid INTEGER NOT NULL,
+    toaccountid INTEGER NOT NULL DEFAULT 0,
+    currency TEXT NOT NULL DEFAULT '',
+    amount DOUBLE PRECISION NOT NULL DEFAULT 0,
+    instrumentid INTEGER NOT NULL DEFAULT 0,
+    quantity DOUBLE PRECISION NOT NULL DEFAULT 0,
+    price DOUBLE PRECISION NOT NULL DEFAULT 0,
+    note TEXT NOT NULL DEFAULT '',
+    debitorderid INTEGER NOT NULL DEFAULT 0,
+    creditorderid INTEGER NOT NULL DEFAULT 0,
+    createdat TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS transfers_fromuserid_idx ON transfers (fromuserid);
+CREATE INDEX IF NOT EXISTS transfers_touserid_idx ON transfers (touserid);
+
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS transferid INTEGER NOT NULL DEFAULT 0;
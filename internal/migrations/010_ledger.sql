@@ -0,0 +1,28 @@
+-- Double-entry journal of filled orders. Postings are positive for debits
+-- and negative for credits, and add up to zero per entry and currency.
+CREATE TABLE IF NOT EXISTS journalentries (
+    id SERIAL PRIMARY KEY,
+    orderid INTEGER NOT NULL,
+    userid INTEGER NOT NULL,
+    accountid INTEGER NOT NULL DEFAULT 0,
+    description TEXT NOT NULL DEFAULT '',
+    postedat TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS journalentries_userid_idx ON journalentries (userid);
+CREATE INDEX IF NOT EXISTS journalentries_orderid_idx ON journalentries (orderid);
+
+CREATE TABLE IF NOT EXISTS ledgerpostings (
+    id SERIAL PRIMARY KEY,
+    entryid INTEGER NOT NULL REFERENCES journalentries (id) ON DELETE CASCADE,
+    userid INTEGER NOT NULL,
+    accountid INTEGER NOT NULL DEFAULT 0,
+    ledgeraccount TEXT NOT NULL,
+    instrumentid INTEGER NOT NULL DEFAULT 0,
+    currency TEXT NOT NULL,
+    amount DOUBLE PRECISION NOT NULL,
+    postedat TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS ledgerpostings_entryid_idx ON ledgerpostings (entryid);
+CREATE INDEX IF NOT EXISTS ledgerpostings_balance_idx ON ledgerpostings (userid, accountid, ledgeraccount, currency);
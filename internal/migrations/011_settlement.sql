@@ -0,0 +1,5 @@
+-- Date the proceeds of a SELL settle on. Orders stored before get the zero
+-- time, like the ones that never settle, and count as settled.
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS settlementdate TIMESTAMPTZ NOT NULL DEFAULT '0001-01-01 00:00:00+00';
+
+CREATE INDEX IF NOT EXISTS orders_unsettled_idx ON orders (userid, settlementdate) WHERE side = 'SELL';
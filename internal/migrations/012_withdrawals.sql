@@ -0,0 +1,15 @@
+-- Audit trail of the withdrawals held for an admin's approval
+CREATE TABLE IF NOT EXISTS withdrawalaudits (
+    id SERIAL PRIMARY KEY,
+    orderid INTEGER NOT NULL,
+    userid INTEGER NOT NULL,
+    action TEXT NOT NULL,
+    amount DOUBLE PRECISION NOT NULL,
+    currency TEXT NOT NULL,
+    reviewer TEXT NOT NULL DEFAULT '',
+    note TEXT NOT NULL DEFAULT '',
+    createdat TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS withdrawalaudits_orderid_idx ON withdrawalaudits (orderid);
+CREATE INDEX IF NOT EXISTS orders_pending_approval_idx ON orders (userid) WHERE status = 'PENDING_APPROVAL';
@@ -0,0 +1,22 @@
+-- Imports upsert prices on (instrumentid, date). Duplicated bars keep the
+-- last one stored; the others are copied to marketdataduplicates before
+-- they are removed, so they can be reviewed or restored.
+CREATE TABLE IF NOT EXISTS marketdataduplicates (LIKE marketdata);
+
+INSERT INTO marketdataduplicates
+SELECT m.*
+FROM marketdata m
+WHERE EXISTS (
+    SELECT 1 FROM marketdata newer
+    WHERE newer.instrumentid = m.instrumentid
+        AND newer.date = m.date
+        AND newer.id > m.id
+);
+
+DELETE FROM marketdata m
+USING marketdata newer
+WHERE newer.instrumentid = m.instrumentid
+    AND newer.date = m.date
+    AND newer.id > m.id;
+
+CREATE UNIQUE INDEX IF NOT EXISTS marketdata_instrumentid_date_idx ON marketdata (instrumentid, date);
@@ -0,0 +1,8 @@
+-- Failed alert deliveries are retried by a worker once their backoff
+-- elapses. Triggers without nextattemptat are not retried again.
+ALTER TABLE alerttriggers ADD COLUMN IF NOT EXISTS nextattemptat TIMESTAMPTZ;
+
+UPDATE alerttriggers SET nextattemptat = triggeredat WHERE deliveredat IS NULL;
+
+DROP INDEX IF EXISTS alerttriggers_undelivered_idx;
+CREATE INDEX IF NOT EXISTS alerttriggers_due_idx ON alerttriggers (nextattemptat) WHERE deliveredat IS NULL;
@@ -0,0 +1,68 @@
+// Package migrations holds the schema changes the API needs on top of the
+// users, instruments, orders and marketdata tables, as numbered SQL files
+// applied in name order.
+package migrations
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"sort"
+
+	"gorm.io/gorm"
+)
+
+//go:embed *.sql
+var files embed.FS
+
+// advisoryLock keeps processes that start at the same time from applying
+// the migrations twice
+const advisoryLock = 7318004
+
+// Migrate applies the migrations not recorded in schemamigrations yet, in a
+// single transaction, so a failing migration leaves the schema untouched
+func Migrate(db *gorm.DB) error {
+	names, err := fs.Glob(files, "*.sql")
+	if err != nil {
+		return err
+	}
+	sort.Strings(names)
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", advisoryLock).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec(`CREATE TABLE IF NOT EXISTS schemamigrations (
+			version TEXT PRIMARY KEY,
+			appliedat TIMESTAMPTZ NOT NULL DEFAULT now()
+		)`).Error; err != nil {
+			return err
+		}
+
+		var versions []string
+		if err := tx.Table("schemamigrations").Pluck("version", &versions).Error; err != nil {
+			return err
+		}
+		applied := make(map[string]bool, len(versions))
+		for _, version := range versions {
+			applied[version] = true
+		}
+
+		for _, name := range names {
+			if applied[name] {
+				continue
+			}
+			content, err := files.ReadFile(name)
+			if err != nil {
+				return err
+			}
+			if err := tx.Exec(string(content)).Error; err != nil {
+				return fmt.Errorf("migration %s: %w", name, err)
+			}
+			if err := tx.Exec("INSERT INTO schemamigrations (version) VALUES (?)", name).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
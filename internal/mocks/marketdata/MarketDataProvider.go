@@ -0,0 +1,61 @@
+// Code generated by mockery v2.43.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	time "time"
+
+	models "github.com/NahuelDT/portfolio-api/internal/models"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// MarketDataProvider is an autogenerated mock type for the MarketDataProvider type
+type MarketDataProvider struct {
+	mock.Mock
+}
+
+// Poll provides a mock function with given fields: ctx, now
+func (_m *MarketDataProvider) Poll(ctx context.Context, now time.Time) ([]models.MarketData, error) {
+	ret := _m.Called(ctx, now)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Poll")
+	}
+
+	var r0 []models.MarketData
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) ([]models.MarketData, error)); ok {
+		return rf(ctx, now)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) []models.MarketData); ok {
+		r0 = rf(ctx, now)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.MarketData)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, now)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewMarketDataProvider creates a new instance of MarketDataProvider. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMarketDataProvider(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MarketDataProvider {
+	mock := &MarketDataProvider{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,105 @@
+// Code generated by mockery v2.43.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	models "github.com/NahuelDT/portfolio-api/internal/models"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// AccountRepositorer is an autogenerated mock type for the AccountRepositorer type
+type AccountRepositorer struct {
+	mock.Mock
+}
+
+// Create provides a mock function with given fields: account
+func (_m *AccountRepositorer) Create(account *models.Account) error {
+	ret := _m.Called(account)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*models.Account) error); ok {
+		r0 = rf(account)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetByID provides a mock function with given fields: id
+func (_m *AccountRepositorer) GetByID(id uint) (*models.Account, error) {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByID")
+	}
+
+	var r0 *models.Account
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uint) (*models.Account, error)); ok {
+		return rf(id)
+	}
+	if rf, ok := ret.Get(0).(func(uint) *models.Account); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Account)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uint) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetByUser provides a mock function with given fields: userID
+func (_m *AccountRepositorer) GetByUser(userID uint) ([]models.Account, error) {
+	ret := _m.Called(userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByUser")
+	}
+
+	var r0 []models.Account
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uint) ([]models.Account, error)); ok {
+		return rf(userID)
+	}
+	if rf, ok := ret.Get(0).(func(uint) []models.Account); ok {
+		r0 = rf(userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Account)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uint) error); ok {
+		r1 = rf(userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewAccountRepositorer creates a new instance of AccountRepositorer. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewAccountRepositorer(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *AccountRepositorer {
+	mock := &AccountRepositorer{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
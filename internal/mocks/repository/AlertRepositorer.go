@@ -0,0 +1,309 @@
+// Code generated by mockery v2.43.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	models "github.com/NahuelDT/portfolio-api/internal/models"
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// AlertRepositorer is an autogenerated mock type for the AlertRepositorer type
+type AlertRepositorer struct {
+	mock.Mock
+}
+
+// ClaimDueTriggers provides a mock function with given fields: maxAttempts, now, lease
+func (_m *AlertRepositorer) ClaimDueTriggers(maxAttempts int, now time.Time, lease time.Duration) ([]models.AlertTrigger, error) {
+	ret := _m.Called(maxAttempts, now, lease)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ClaimDueTriggers")
+	}
+
+	var r0 []models.AlertTrigger
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int, time.Time, time.Duration) ([]models.AlertTrigger, error)); ok {
+		return rf(maxAttempts, now, lease)
+	}
+	if rf, ok := ret.Get(0).(func(int, time.Time, time.Duration) []models.AlertTrigger); ok {
+		r0 = rf(maxAttempts, now, lease)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.AlertTrigger)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(int, time.Time, time.Duration) error); ok {
+		r1 = rf(maxAttempts, now, lease)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Create provides a mock function with given fields: alert
+func (_m *AlertRepositorer) Create(alert *models.PriceAlert) error {
+	ret := _m.Called(alert)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*models.PriceAlert) error); ok {
+		r0 = rf(alert)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Delete provides a mock function with given fields: userID, id
+func (_m *AlertRepositorer) Delete(userID uint, id uint) error {
+	ret := _m.Called(userID, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Delete")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uint, uint) error); ok {
+		r0 = rf(userID, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetActiveByInstrument provides a mock function with given fields: instrumentID
+func (_m *AlertRepositorer) GetActiveByInstrument(instrumentID uint) ([]models.PriceAlert, error) {
+	ret := _m.Called(instrumentID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetActiveByInstrument")
+	}
+
+	var r0 []models.PriceAlert
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uint) ([]models.PriceAlert, error)); ok {
+		return rf(instrumentID)
+	}
+	if rf, ok := ret.Get(0).(func(uint) []models.PriceAlert); ok {
+		r0 = rf(instrumentID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.PriceAlert)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uint) error); ok {
+		r1 = rf(instrumentID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetByID provides a mock function with given fields: id
+func (_m *AlertRepositorer) GetByID(id uint) (*models.PriceAlert, error) {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByID")
+	}
+
+	var r0 *models.PriceAlert
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uint) (*models.PriceAlert, error)); ok {
+		return rf(id)
+	}
+	if rf, ok := ret.Get(0).(func(uint) *models.PriceAlert); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.PriceAlert)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uint) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetByUser provides a mock function with given fields: userID
+func (_m *AlertRepositorer) GetByUser(userID uint) ([]models.PriceAlert, error) {
+	ret := _m.Called(userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByUser")
+	}
+
+	var r0 []models.PriceAlert
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uint) ([]models.PriceAlert, error)); ok {
+		return rf(userID)
+	}
+	if rf, ok := ret.Get(0).(func(uint) []models.PriceAlert); ok {
+		r0 = rf(userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.PriceAlert)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uint) error); ok {
+		r1 = rf(userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetTriggersByUser provides a mock function with given fields: userID
+func (_m *AlertRepositorer) GetTriggersByUser(userID uint) ([]models.AlertTrigger, error) {
+	ret := _m.Called(userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTriggersByUser")
+	}
+
+	var r0 []models.AlertTrigger
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uint) ([]models.AlertTrigger, error)); ok {
+		return rf(userID)
+	}
+	if rf, ok := ret.Get(0).(func(uint) []models.AlertTrigger); ok {
+		r0 = rf(userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.AlertTrigger)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uint) error); ok {
+		r1 = rf(userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetWebhook provides a mock function with given fields: userID
+func (_m *AlertRepositorer) GetWebhook(userID uint) (*models.AlertWebhook, error) {
+	ret := _m.Called(userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetWebhook")
+	}
+
+	var r0 *models.AlertWebhook
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uint) (*models.AlertWebhook, error)); ok {
+		return rf(userID)
+	}
+	if rf, ok := ret.Get(0).(func(uint) *models.AlertWebhook); ok {
+		r0 = rf(userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.AlertWebhook)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uint) error); ok {
+		r1 = rf(userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RecordTrigger provides a mock function with given fields: alert, trigger
+func (_m *AlertRepositorer) RecordTrigger(alert *models.PriceAlert, trigger *models.AlertTrigger) (bool, error) {
+	ret := _m.Called(alert, trigger)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RecordTrigger")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(*models.PriceAlert, *models.AlertTrigger) (bool, error)); ok {
+		return rf(alert, trigger)
+	}
+	if rf, ok := ret.Get(0).(func(*models.PriceAlert, *models.AlertTrigger) bool); ok {
+		r0 = rf(alert, trigger)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(*models.PriceAlert, *models.AlertTrigger) error); ok {
+		r1 = rf(alert, trigger)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SaveWebhook provides a mock function with given fields: webhook
+func (_m *AlertRepositorer) SaveWebhook(webhook *models.AlertWebhook) error {
+	ret := _m.Called(webhook)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveWebhook")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*models.AlertWebhook) error); ok {
+		r0 = rf(webhook)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateDelivery provides a mock function with given fields: trigger
+func (_m *AlertRepositorer) UpdateDelivery(trigger *models.AlertTrigger) error {
+	ret := _m.Called(trigger)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateDelivery")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*models.AlertTrigger) error); ok {
+		r0 = rf(trigger)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewAlertRepositorer creates a new instance of AlertRepositorer. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewAlertRepositorer(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *AlertRepositorer {
+	mock := &AlertRepositorer{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
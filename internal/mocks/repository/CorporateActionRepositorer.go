@@ -0,0 +1,143 @@
+// Code generated by mockery v2.43.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	models "github.com/NahuelDT/portfolio-api/internal/models"
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// CorporateActionRepositorer is an autogenerated mock type for the CorporateActionRepositorer type
+type CorporateActionRepositorer struct {
+	mock.Mock
+}
+
+// Create provides a mock function with given fields: action
+func (_m *CorporateActionRepositorer) Create(action *models.CorporateAction) error {
+	ret := _m.Called(action)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*models.CorporateAction) error); ok {
+		r0 = rf(action)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CreateTickerChange provides a mock function with given fields: action
+func (_m *CorporateActionRepositorer) CreateTickerChange(action *models.CorporateAction) error {
+	ret := _m.Called(action)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateTickerChange")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*models.CorporateAction) error); ok {
+		r0 = rf(action)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetEffectiveAsOf provides a mock function with given fields: asOf
+func (_m *CorporateActionRepositorer) GetEffectiveAsOf(asOf time.Time) ([]models.CorporateAction, error) {
+	ret := _m.Called(asOf)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetEffectiveAsOf")
+	}
+
+	var r0 []models.CorporateAction
+	var r1 error
+	if rf, ok := ret.Get(0).(func(time.Time) ([]models.CorporateAction, error)); ok {
+		return rf(asOf)
+	}
+	if rf, ok := ret.Get(0).(func(time.Time) []models.CorporateAction); ok {
+		r0 = rf(asOf)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.CorporateAction)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(time.Time) error); ok {
+		r1 = rf(asOf)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetUnapplied provides a mock function with given fields: asOf
+func (_m *CorporateActionRepositorer) GetUnapplied(asOf time.Time) ([]models.CorporateAction, error) {
+	ret := _m.Called(asOf)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUnapplied")
+	}
+
+	var r0 []models.CorporateAction
+	var r1 error
+	if rf, ok := ret.Get(0).(func(time.Time) ([]models.CorporateAction, error)); ok {
+		return rf(asOf)
+	}
+	if rf, ok := ret.Get(0).(func(time.Time) []models.CorporateAction); ok {
+		r0 = rf(asOf)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.CorporateAction)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(time.Time) error); ok {
+		r1 = rf(asOf)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MarkApplied provides a mock function with given fields: action
+func (_m *CorporateActionRepositorer) MarkApplied(action *models.CorporateAction) error {
+	ret := _m.Called(action)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MarkApplied")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*models.CorporateAction) error); ok {
+		r0 = rf(action)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewCorporateActionRepositorer creates a new instance of CorporateActionRepositorer. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewCorporateActionRepositorer(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *CorporateActionRepositorer {
+	mock := &CorporateActionRepositorer{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,113 @@
+// Code generated by mockery v2.43.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	models "github.com/NahuelDT/portfolio-api/internal/models"
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// DistributionRepositorer is an autogenerated mock type for the DistributionRepositorer type
+type DistributionRepositorer struct {
+	mock.Mock
+}
+
+// Create provides a mock function with given fields: distribution
+func (_m *DistributionRepositorer) Create(distribution *models.Distribution) error {
+	ret := _m.Called(distribution)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*models.Distribution) error); ok {
+		r0 = rf(distribution)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CreateCredited provides a mock function with given fields: distribution, credits
+func (_m *DistributionRepositorer) CreateCredited(distribution *models.Distribution, credits []models.Order) error {
+	ret := _m.Called(distribution, credits)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateCredited")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*models.Distribution, []models.Order) error); ok {
+		r0 = rf(distribution, credits)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CreditHolders provides a mock function with given fields: distribution, credits
+func (_m *DistributionRepositorer) CreditHolders(distribution *models.Distribution, credits []models.Order) error {
+	ret := _m.Called(distribution, credits)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreditHolders")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*models.Distribution, []models.Order) error); ok {
+		r0 = rf(distribution, credits)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetUncredited provides a mock function with given fields: asOf
+func (_m *DistributionRepositorer) GetUncredited(asOf time.Time) ([]models.Distribution, error) {
+	ret := _m.Called(asOf)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUncredited")
+	}
+
+	var r0 []models.Distribution
+	var r1 error
+	if rf, ok := ret.Get(0).(func(time.Time) ([]models.Distribution, error)); ok {
+		return rf(asOf)
+	}
+	if rf, ok := ret.Get(0).(func(time.Time) []models.Distribution); ok {
+		r0 = rf(asOf)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Distribution)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(time.Time) error); ok {
+		r1 = rf(asOf)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewDistributionRepositorer creates a new instance of DistributionRepositorer. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewDistributionRepositorer(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *DistributionRepositorer {
+	mock := &DistributionRepositorer{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
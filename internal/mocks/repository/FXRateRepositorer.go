@@ -0,0 +1,137 @@
+// Code generated by mockery v2.43.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	models "github.com/NahuelDT/portfolio-api/internal/models"
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// FXRateRepositorer is an autogenerated mock type for the FXRateRepositorer type
+type FXRateRepositorer struct {
+	mock.Mock
+}
+
+// Create provides a mock function with given fields: rate
+func (_m *FXRateRepositorer) Create(rate *models.FXRate) error {
+	ret := _m.Called(rate)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*models.FXRate) error); ok {
+		r0 = rf(rate)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetLatestRate provides a mock function with given fields: baseCurrency, quoteCurrency
+func (_m *FXRateRepositorer) GetLatestRate(baseCurrency string, quoteCurrency string) (*models.FXRate, error) {
+	ret := _m.Called(baseCurrency, quoteCurrency)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetLatestRate")
+	}
+
+	var r0 *models.FXRate
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string) (*models.FXRate, error)); ok {
+		return rf(baseCurrency, quoteCurrency)
+	}
+	if rf, ok := ret.Get(0).(func(string, string) *models.FXRate); ok {
+		r0 = rf(baseCurrency, quoteCurrency)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.FXRate)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(baseCurrency, quoteCurrency)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetRateAsOf provides a mock function with given fields: baseCurrency, quoteCurrency, asOf
+func (_m *FXRateRepositorer) GetRateAsOf(baseCurrency string, quoteCurrency string, asOf time.Time) (*models.FXRate, error) {
+	ret := _m.Called(baseCurrency, quoteCurrency, asOf)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetRateAsOf")
+	}
+
+	var r0 *models.FXRate
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, time.Time) (*models.FXRate, error)); ok {
+		return rf(baseCurrency, quoteCurrency, asOf)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, time.Time) *models.FXRate); ok {
+		r0 = rf(baseCurrency, quoteCurrency, asOf)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.FXRate)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, time.Time) error); ok {
+		r1 = rf(baseCurrency, quoteCurrency, asOf)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetRateHistory provides a mock function with given fields: baseCurrency, quoteCurrency, to
+func (_m *FXRateRepositorer) GetRateHistory(baseCurrency string, quoteCurrency string, to time.Time) ([]models.FXRate, error) {
+	ret := _m.Called(baseCurrency, quoteCurrency, to)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetRateHistory")
+	}
+
+	var r0 []models.FXRate
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, time.Time) ([]models.FXRate, error)); ok {
+		return rf(baseCurrency, quoteCurrency, to)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, time.Time) []models.FXRate); ok {
+		r0 = rf(baseCurrency, quoteCurrency, to)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.FXRate)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, time.Time) error); ok {
+		r1 = rf(baseCurrency, quoteCurrency, to)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewFXRateRepositorer creates a new instance of FXRateRepositorer. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewFXRateRepositorer(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *FXRateRepositorer {
+	mock := &FXRateRepositorer{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
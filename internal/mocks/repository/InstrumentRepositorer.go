@@ -60,6 +60,66 @@ func (_m *InstrumentRepositorer) GetByID(id uint) (*models.Instrument, error) {
 	return r0, r1
 }
 
+// GetByIDs provides a mock function with given fields: ids
+func (_m *InstrumentRepositorer) GetByIDs(ids []uint) (map[uint]*models.Instrument, error) {
+	ret := _m.Called(ids)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByIDs")
+	}
+
+	var r0 map[uint]*models.Instrument
+	var r1 error
+	if rf, ok := ret.Get(0).(func([]uint) (map[uint]*models.Instrument, error)); ok {
+		return rf(ids)
+	}
+	if rf, ok := ret.Get(0).(func([]uint) map[uint]*models.Instrument); ok {
+		r0 = rf(ids)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[uint]*models.Instrument)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func([]uint) error); ok {
+		r1 = rf(ids)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetByTickers provides a mock function with given fields: tickers
+func (_m *InstrumentRepositorer) GetByTickers(tickers []string) (map[string]*models.Instrument, error) {
+	ret := _m.Called(tickers)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByTickers")
+	}
+
+	var r0 map[string]*models.Instrument
+	var r1 error
+	if rf, ok := ret.Get(0).(func([]string) (map[string]*models.Instrument, error)); ok {
+		return rf(tickers)
+	}
+	if rf, ok := ret.Get(0).(func([]string) map[string]*models.Instrument); ok {
+		r0 = rf(tickers)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[string]*models.Instrument)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func([]string) error); ok {
+		r1 = rf(tickers)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Search provides a mock function with given fields: query
 func (_m *InstrumentRepositorer) Search(query string) ([]models.Instrument, error) {
 	ret := _m.Called(query)
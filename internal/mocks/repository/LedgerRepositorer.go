@@ -0,0 +1,225 @@
+// Code generated by mockery v2.43.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	models "github.com/NahuelDT/portfolio-api/internal/models"
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// LedgerRepositorer is an autogenerated mock type for the LedgerRepositorer type
+type LedgerRepositorer struct {
+	mock.Mock
+}
+
+// GetCashBalance provides a mock function with given fields: userID, currency
+func (_m *LedgerRepositorer) GetCashBalance(userID uint, currency string) (float64, error) {
+	ret := _m.Called(userID, currency)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetCashBalance")
+	}
+
+	var r0 float64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uint, string) (float64, error)); ok {
+		return rf(userID, currency)
+	}
+	if rf, ok := ret.Get(0).(func(uint, string) float64); ok {
+		r0 = rf(userID, currency)
+	} else {
+		r0 = ret.Get(0).(float64)
+	}
+
+	if rf, ok := ret.Get(1).(func(uint, string) error); ok {
+		r1 = rf(userID, currency)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetCashBalancesAsOf provides a mock function with given fields: userID, asOf
+func (_m *LedgerRepositorer) GetCashBalancesAsOf(userID uint, asOf time.Time) (map[string]float64, error) {
+	ret := _m.Called(userID, asOf)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetCashBalancesAsOf")
+	}
+
+	var r0 map[string]float64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uint, time.Time) (map[string]float64, error)); ok {
+		return rf(userID, asOf)
+	}
+	if rf, ok := ret.Get(0).(func(uint, time.Time) map[string]float64); ok {
+		r0 = rf(userID, asOf)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[string]float64)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uint, time.Time) error); ok {
+		r1 = rf(userID, asOf)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetCashMismatches provides a mock function with given fields: tolerance
+func (_m *LedgerRepositorer) GetCashMismatches(tolerance float64) ([]models.BalanceMismatch, error) {
+	ret := _m.Called(tolerance)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetCashMismatches")
+	}
+
+	var r0 []models.BalanceMismatch
+	var r1 error
+	if rf, ok := ret.Get(0).(func(float64) ([]models.BalanceMismatch, error)); ok {
+		return rf(tolerance)
+	}
+	if rf, ok := ret.Get(0).(func(float64) []models.BalanceMismatch); ok {
+		r0 = rf(tolerance)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.BalanceMismatch)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(float64) error); ok {
+		r1 = rf(tolerance)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetTrialBalance provides a mock function with no fields
+func (_m *LedgerRepositorer) GetTrialBalance() ([]models.TrialBalanceLine, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTrialBalance")
+	}
+
+	var r0 []models.TrialBalanceLine
+	var r1 error
+	if rf, ok := ret.Get(0).(func() ([]models.TrialBalanceLine, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() []models.TrialBalanceLine); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.TrialBalanceLine)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetUnbalancedEntries provides a mock function with given fields: tolerance
+func (_m *LedgerRepositorer) GetUnbalancedEntries(tolerance float64) ([]uint, error) {
+	ret := _m.Called(tolerance)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUnbalancedEntries")
+	}
+
+	var r0 []uint
+	var r1 error
+	if rf, ok := ret.Get(0).(func(float64) ([]uint, error)); ok {
+		return rf(tolerance)
+	}
+	if rf, ok := ret.Get(0).(func(float64) []uint); ok {
+		r0 = rf(tolerance)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]uint)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(float64) error); ok {
+		r1 = rf(tolerance)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetUserEntries provides a mock function with given fields: userID, from, to
+func (_m *LedgerRepositorer) GetUserEntries(userID uint, from time.Time, to time.Time) ([]models.JournalEntry, error) {
+	ret := _m.Called(userID, from, to)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUserEntries")
+	}
+
+	var r0 []models.JournalEntry
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uint, time.Time, time.Time) ([]models.JournalEntry, error)); ok {
+		return rf(userID, from, to)
+	}
+	if rf, ok := ret.Get(0).(func(uint, time.Time, time.Time) []models.JournalEntry); ok {
+		r0 = rf(userID, from, to)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.JournalEntry)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uint, time.Time, time.Time) error); ok {
+		r1 = rf(userID, from, to)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RebuildUserEntries provides a mock function with given fields: userID, replay
+func (_m *LedgerRepositorer) RebuildUserEntries(userID uint, replay func([]models.Order) []models.JournalEntry) error {
+	ret := _m.Called(userID, replay)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RebuildUserEntries")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uint, func([]models.Order) []models.JournalEntry) error); ok {
+		r0 = rf(userID, replay)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewLedgerRepositorer creates a new instance of LedgerRepositorer. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewLedgerRepositorer(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *LedgerRepositorer {
+	mock := &LedgerRepositorer{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
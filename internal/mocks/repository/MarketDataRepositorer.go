@@ -5,6 +5,8 @@ package mocks
 import (
 	models "github.com/NahuelDT/portfolio-api/internal/models"
 	mock "github.com/stretchr/testify/mock"
+
+	time "time"
 )
 
 // MarketDataRepositorer is an autogenerated mock type for the MarketDataRepositorer type
@@ -30,6 +32,36 @@ func (_m *MarketDataRepositorer) Create(marketData *models.MarketData) error {
 	return r0
 }
 
+// GetCandles provides a mock function with given fields: instrumentID, unit, from, to, limit
+func (_m *MarketDataRepositorer) GetCandles(instrumentID uint, unit string, from time.Time, to time.Time, limit int) ([]models.Candle, error) {
+	ret := _m.Called(instrumentID, unit, from, to, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetCandles")
+	}
+
+	var r0 []models.Candle
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uint, string, time.Time, time.Time, int) ([]models.Candle, error)); ok {
+		return rf(instrumentID, unit, from, to, limit)
+	}
+	if rf, ok := ret.Get(0).(func(uint, string, time.Time, time.Time, int) []models.Candle); ok {
+		r0 = rf(instrumentID, unit, from, to, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Candle)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uint, string, time.Time, time.Time, int) error); ok {
+		r1 = rf(instrumentID, unit, from, to, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetLatestMarketData provides a mock function with given fields: instrumentID
 func (_m *MarketDataRepositorer) GetLatestMarketData(instrumentID uint) (*models.MarketData, error) {
 	ret := _m.Called(instrumentID)
@@ -60,6 +92,114 @@ func (_m *MarketDataRepositorer) GetLatestMarketData(instrumentID uint) (*models
 	return r0, r1
 }
 
+// GetLatestMarketDataAsOf provides a mock function with given fields: instrumentID, asOf
+func (_m *MarketDataRepositorer) GetLatestMarketDataAsOf(instrumentID uint, asOf time.Time) (*models.MarketData, error) {
+	ret := _m.Called(instrumentID, asOf)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetLatestMarketDataAsOf")
+	}
+
+	var r0 *models.MarketData
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uint, time.Time) (*models.MarketData, error)); ok {
+		return rf(instrumentID, asOf)
+	}
+	if rf, ok := ret.Get(0).(func(uint, time.Time) *models.MarketData); ok {
+		r0 = rf(instrumentID, asOf)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.MarketData)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uint, time.Time) error); ok {
+		r1 = rf(instrumentID, asOf)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetLatestMarketDataForInstruments provides a mock function with given fields: instrumentIDs
+func (_m *MarketDataRepositorer) GetLatestMarketDataForInstruments(instrumentIDs []uint) (map[uint]*models.MarketData, error) {
+	ret := _m.Called(instrumentIDs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetLatestMarketDataForInstruments")
+	}
+
+	var r0 map[uint]*models.MarketData
+	var r1 error
+	if rf, ok := ret.Get(0).(func([]uint) (map[uint]*models.MarketData, error)); ok {
+		return rf(instrumentIDs)
+	}
+	if rf, ok := ret.Get(0).(func([]uint) map[uint]*models.MarketData); ok {
+		r0 = rf(instrumentIDs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[uint]*models.MarketData)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func([]uint) error); ok {
+		r1 = rf(instrumentIDs)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetMarketDataRange provides a mock function with given fields: instrumentID, from, to
+func (_m *MarketDataRepositorer) GetMarketDataRange(instrumentID uint, from time.Time, to time.Time) ([]models.MarketData, error) {
+	ret := _m.Called(instrumentID, from, to)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetMarketDataRange")
+	}
+
+	var r0 []models.MarketData
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uint, time.Time, time.Time) ([]models.MarketData, error)); ok {
+		return rf(instrumentID, from, to)
+	}
+	if rf, ok := ret.Get(0).(func(uint, time.Time, time.Time) []models.MarketData); ok {
+		r0 = rf(instrumentID, from, to)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.MarketData)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uint, time.Time, time.Time) error); ok {
+		r1 = rf(instrumentID, from, to)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Upsert provides a mock function with given fields: marketData
+func (_m *MarketDataRepositorer) Upsert(marketData []models.MarketData) error {
+	ret := _m.Called(marketData)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Upsert")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func([]models.MarketData) error); ok {
+		r0 = rf(marketData)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // NewMarketDataRepositorer creates a new instance of MarketDataRepositorer. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewMarketDataRepositorer(t interface {
@@ -5,6 +5,8 @@ package mocks
 import (
 	models "github.com/NahuelDT/portfolio-api/internal/models"
 	mock "github.com/stretchr/testify/mock"
+
+	time "time"
 )
 
 // OrderRepositorer is an autogenerated mock type for the OrderRepositorer type
@@ -30,6 +32,34 @@ func (_m *OrderRepositorer) Create(order *models.Order) error {
 	return r0
 }
 
+// FillPendingOrders provides a mock function with given fields: side, until
+func (_m *OrderRepositorer) FillPendingOrders(side string, until time.Time) (int64, error) {
+	ret := _m.Called(side, until)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FillPendingOrders")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, time.Time) (int64, error)); ok {
+		return rf(side, until)
+	}
+	if rf, ok := ret.Get(0).(func(string, time.Time) int64); ok {
+		r0 = rf(side, until)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, time.Time) error); ok {
+		r1 = rf(side, until)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetByID provides a mock function with given fields: id
 func (_m *OrderRepositorer) GetByID(id uint) (*models.Order, error) {
 	ret := _m.Called(id)
@@ -60,27 +90,59 @@ func (_m *OrderRepositorer) GetByID(id uint) (*models.Order, error) {
 	return r0, r1
 }
 
-// GetUserCashBalance provides a mock function with given fields: userID
-func (_m *OrderRepositorer) GetUserCashBalance(userID uint) (float64, error) {
-	ret := _m.Called(userID)
+// GetInstrumentOrdersBefore provides a mock function with given fields: instrumentIDs, before
+func (_m *OrderRepositorer) GetInstrumentOrdersBefore(instrumentIDs []uint, before time.Time) ([]models.Order, error) {
+	ret := _m.Called(instrumentIDs, before)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetUserCashBalance")
+		panic("no return value specified for GetInstrumentOrdersBefore")
 	}
 
-	var r0 float64
+	var r0 []models.Order
 	var r1 error
-	if rf, ok := ret.Get(0).(func(uint) (float64, error)); ok {
-		return rf(userID)
+	if rf, ok := ret.Get(0).(func([]uint, time.Time) ([]models.Order, error)); ok {
+		return rf(instrumentIDs, before)
 	}
-	if rf, ok := ret.Get(0).(func(uint) float64); ok {
-		r0 = rf(userID)
+	if rf, ok := ret.Get(0).(func([]uint, time.Time) []models.Order); ok {
+		r0 = rf(instrumentIDs, before)
 	} else {
-		r0 = ret.Get(0).(float64)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Order)
+		}
 	}
 
-	if rf, ok := ret.Get(1).(func(uint) error); ok {
-		r1 = rf(userID)
+	if rf, ok := ret.Get(1).(func([]uint, time.Time) error); ok {
+		r1 = rf(instrumentIDs, before)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetUnsettledCash provides a mock function with given fields: userID, asOf
+func (_m *OrderRepositorer) GetUnsettledCash(userID uint, asOf time.Time) ([]models.CashBalance, error) {
+	ret := _m.Called(userID, asOf)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUnsettledCash")
+	}
+
+	var r0 []models.CashBalance
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uint, time.Time) ([]models.CashBalance, error)); ok {
+		return rf(userID, asOf)
+	}
+	if rf, ok := ret.Get(0).(func(uint, time.Time) []models.CashBalance); ok {
+		r0 = rf(userID, asOf)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.CashBalance)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uint, time.Time) error); ok {
+		r1 = rf(userID, asOf)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -118,6 +180,96 @@ func (_m *OrderRepositorer) GetUserFilledOrders(userID uint) ([]models.Order, er
 	return r0, r1
 }
 
+// GetUserFilledOrdersAsOf provides a mock function with given fields: userID, asOf
+func (_m *OrderRepositorer) GetUserFilledOrdersAsOf(userID uint, asOf time.Time) ([]models.Order, error) {
+	ret := _m.Called(userID, asOf)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUserFilledOrdersAsOf")
+	}
+
+	var r0 []models.Order
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uint, time.Time) ([]models.Order, error)); ok {
+		return rf(userID, asOf)
+	}
+	if rf, ok := ret.Get(0).(func(uint, time.Time) []models.Order); ok {
+		r0 = rf(userID, asOf)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Order)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uint, time.Time) error); ok {
+		r1 = rf(userID, asOf)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetUserIDs provides a mock function with no fields
+func (_m *OrderRepositorer) GetUserIDs() ([]uint, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUserIDs")
+	}
+
+	var r0 []uint
+	var r1 error
+	if rf, ok := ret.Get(0).(func() ([]uint, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() []uint); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]uint)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetUserInstrumentOrders provides a mock function with given fields: userID, instrumentIDs, since
+func (_m *OrderRepositorer) GetUserInstrumentOrders(userID uint, instrumentIDs []uint, since time.Time) ([]models.Order, error) {
+	ret := _m.Called(userID, instrumentIDs, since)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUserInstrumentOrders")
+	}
+
+	var r0 []models.Order
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uint, []uint, time.Time) ([]models.Order, error)); ok {
+		return rf(userID, instrumentIDs, since)
+	}
+	if rf, ok := ret.Get(0).(func(uint, []uint, time.Time) []models.Order); ok {
+		r0 = rf(userID, instrumentIDs, since)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Order)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uint, []uint, time.Time) error); ok {
+		r1 = rf(userID, instrumentIDs, since)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // UpdateStatus provides a mock function with given fields: orderID, status
 func (_m *OrderRepositorer) UpdateStatus(orderID uint, status string) error {
 	ret := _m.Called(orderID, status)
@@ -0,0 +1,193 @@
+// Code generated by mockery v2.43.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	models "github.com/NahuelDT/portfolio-api/internal/models"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// PositionRepositorer is an autogenerated mock type for the PositionRepositorer type
+type PositionRepositorer struct {
+	mock.Mock
+}
+
+// GetAccountCashBalance provides a mock function with given fields: userID, accountID, currency
+func (_m *PositionRepositorer) GetAccountCashBalance(userID uint, accountID uint, currency string) (float64, error) {
+	ret := _m.Called(userID, accountID, currency)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAccountCashBalance")
+	}
+
+	var r0 float64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uint, uint, string) (float64, error)); ok {
+		return rf(userID, accountID, currency)
+	}
+	if rf, ok := ret.Get(0).(func(uint, uint, string) float64); ok {
+		r0 = rf(userID, accountID, currency)
+	} else {
+		r0 = ret.Get(0).(float64)
+	}
+
+	if rf, ok := ret.Get(1).(func(uint, uint, string) error); ok {
+		r1 = rf(userID, accountID, currency)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAccountPosition provides a mock function with given fields: userID, accountID, instrumentID
+func (_m *PositionRepositorer) GetAccountPosition(userID uint, accountID uint, instrumentID uint) (*models.Position, error) {
+	ret := _m.Called(userID, accountID, instrumentID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAccountPosition")
+	}
+
+	var r0 *models.Position
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uint, uint, uint) (*models.Position, error)); ok {
+		return rf(userID, accountID, instrumentID)
+	}
+	if rf, ok := ret.Get(0).(func(uint, uint, uint) *models.Position); ok {
+		r0 = rf(userID, accountID, instrumentID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Position)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uint, uint, uint) error); ok {
+		r1 = rf(userID, accountID, instrumentID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetHolderIDs provides a mock function with given fields: instrumentID
+func (_m *PositionRepositorer) GetHolderIDs(instrumentID uint) ([]uint, error) {
+	ret := _m.Called(instrumentID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetHolderIDs")
+	}
+
+	var r0 []uint
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uint) ([]uint, error)); ok {
+		return rf(instrumentID)
+	}
+	if rf, ok := ret.Get(0).(func(uint) []uint); ok {
+		r0 = rf(instrumentID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]uint)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uint) error); ok {
+		r1 = rf(instrumentID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetUserCashBalances provides a mock function with given fields: userID
+func (_m *PositionRepositorer) GetUserCashBalances(userID uint) ([]models.CashBalance, error) {
+	ret := _m.Called(userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUserCashBalances")
+	}
+
+	var r0 []models.CashBalance
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uint) ([]models.CashBalance, error)); ok {
+		return rf(userID)
+	}
+	if rf, ok := ret.Get(0).(func(uint) []models.CashBalance); ok {
+		r0 = rf(userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.CashBalance)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uint) error); ok {
+		r1 = rf(userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetUserPositions provides a mock function with given fields: userID
+func (_m *PositionRepositorer) GetUserPositions(userID uint) ([]models.Position, error) {
+	ret := _m.Called(userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUserPositions")
+	}
+
+	var r0 []models.Position
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uint) ([]models.Position, error)); ok {
+		return rf(userID)
+	}
+	if rf, ok := ret.Get(0).(func(uint) []models.Position); ok {
+		r0 = rf(userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Position)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uint) error); ok {
+		r1 = rf(userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RebuildUserBalances provides a mock function with given fields: userID, replay
+func (_m *PositionRepositorer) RebuildUserBalances(userID uint, replay func([]models.Order) ([]models.Position, []models.CashBalance)) error {
+	ret := _m.Called(userID, replay)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RebuildUserBalances")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uint, func([]models.Order) ([]models.Position, []models.CashBalance)) error); ok {
+		r0 = rf(userID, replay)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewPositionRepositorer creates a new instance of PositionRepositorer. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewPositionRepositorer(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *PositionRepositorer {
+	mock := &PositionRepositorer{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
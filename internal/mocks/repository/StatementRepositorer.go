@@ -0,0 +1,105 @@
+// Code generated by mockery v2.43.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	models "github.com/NahuelDT/portfolio-api/internal/models"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// StatementRepositorer is an autogenerated mock type for the StatementRepositorer type
+type StatementRepositorer struct {
+	mock.Mock
+}
+
+// Create provides a mock function with given fields: statement
+func (_m *StatementRepositorer) Create(statement *models.Statement) error {
+	ret := _m.Called(statement)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*models.Statement) error); ok {
+		r0 = rf(statement)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetByID provides a mock function with given fields: id
+func (_m *StatementRepositorer) GetByID(id uint) (*models.Statement, error) {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByID")
+	}
+
+	var r0 *models.Statement
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uint) (*models.Statement, error)); ok {
+		return rf(id)
+	}
+	if rf, ok := ret.Get(0).(func(uint) *models.Statement); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Statement)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uint) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetByUser provides a mock function with given fields: userID
+func (_m *StatementRepositorer) GetByUser(userID uint) ([]models.Statement, error) {
+	ret := _m.Called(userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByUser")
+	}
+
+	var r0 []models.Statement
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uint) ([]models.Statement, error)); ok {
+		return rf(userID)
+	}
+	if rf, ok := ret.Get(0).(func(uint) []models.Statement); ok {
+		r0 = rf(userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Statement)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uint) error); ok {
+		r1 = rf(userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewStatementRepositorer creates a new instance of StatementRepositorer. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewStatementRepositorer(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *StatementRepositorer {
+	mock := &StatementRepositorer{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
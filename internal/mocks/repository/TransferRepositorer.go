@@ -0,0 +1,75 @@
+// Code generated by mockery v2.43.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	models "github.com/NahuelDT/portfolio-api/internal/models"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// TransferRepositorer is an autogenerated mock type for the TransferRepositorer type
+type TransferRepositorer struct {
+	mock.Mock
+}
+
+// Create provides a mock function with given fields: transfer, debit, credit
+func (_m *TransferRepositorer) Create(transfer *models.Transfer, debit *models.Order, credit *models.Order) error {
+	ret := _m.Called(transfer, debit, credit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*models.Transfer, *models.Order, *models.Order) error); ok {
+		r0 = rf(transfer, debit, credit)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetByUser provides a mock function with given fields: userID
+func (_m *TransferRepositorer) GetByUser(userID uint) ([]models.Transfer, error) {
+	ret := _m.Called(userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByUser")
+	}
+
+	var r0 []models.Transfer
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uint) ([]models.Transfer, error)); ok {
+		return rf(userID)
+	}
+	if rf, ok := ret.Get(0).(func(uint) []models.Transfer); ok {
+		r0 = rf(userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Transfer)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uint) error); ok {
+		r1 = rf(userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewTransferRepositorer creates a new instance of TransferRepositorer. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewTransferRepositorer(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *TransferRepositorer {
+	mock := &TransferRepositorer{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,141 @@
+// Code generated by mockery v2.43.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	models "github.com/NahuelDT/portfolio-api/internal/models"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// WatchlistRepositorer is an autogenerated mock type for the WatchlistRepositorer type
+type WatchlistRepositorer struct {
+	mock.Mock
+}
+
+// Create provides a mock function with given fields: watchlist
+func (_m *WatchlistRepositorer) Create(watchlist *models.Watchlist) error {
+	ret := _m.Called(watchlist)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*models.Watchlist) error); ok {
+		r0 = rf(watchlist)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Delete provides a mock function with given fields: id
+func (_m *WatchlistRepositorer) Delete(id uint) error {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Delete")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uint) error); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetByID provides a mock function with given fields: id
+func (_m *WatchlistRepositorer) GetByID(id uint) (*models.Watchlist, error) {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByID")
+	}
+
+	var r0 *models.Watchlist
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uint) (*models.Watchlist, error)); ok {
+		return rf(id)
+	}
+	if rf, ok := ret.Get(0).(func(uint) *models.Watchlist); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Watchlist)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uint) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetByUser provides a mock function with given fields: userID
+func (_m *WatchlistRepositorer) GetByUser(userID uint) ([]models.Watchlist, error) {
+	ret := _m.Called(userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByUser")
+	}
+
+	var r0 []models.Watchlist
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uint) ([]models.Watchlist, error)); ok {
+		return rf(userID)
+	}
+	if rf, ok := ret.Get(0).(func(uint) []models.Watchlist); ok {
+		r0 = rf(userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Watchlist)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uint) error); ok {
+		r1 = rf(userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Update provides a mock function with given fields: watchlist
+func (_m *WatchlistRepositorer) Update(watchlist *models.Watchlist) error {
+	ret := _m.Called(watchlist)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Update")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*models.Watchlist) error); ok {
+		r0 = rf(watchlist)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewWatchlistRepositorer creates a new instance of WatchlistRepositorer. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewWatchlistRepositorer(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *WatchlistRepositorer {
+	mock := &WatchlistRepositorer{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
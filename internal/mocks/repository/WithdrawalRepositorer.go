@@ -0,0 +1,197 @@
+// Code generated by mockery v2.43.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	models "github.com/NahuelDT/portfolio-api/internal/models"
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// WithdrawalRepositorer is an autogenerated mock type for the WithdrawalRepositorer type
+type WithdrawalRepositorer struct {
+	mock.Mock
+}
+
+// Approve provides a mock function with given fields: orderID, at, audit
+func (_m *WithdrawalRepositorer) Approve(orderID uint, at time.Time, audit *models.WithdrawalAudit) (*models.Order, error) {
+	ret := _m.Called(orderID, at, audit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Approve")
+	}
+
+	var r0 *models.Order
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uint, time.Time, *models.WithdrawalAudit) (*models.Order, error)); ok {
+		return rf(orderID, at, audit)
+	}
+	if rf, ok := ret.Get(0).(func(uint, time.Time, *models.WithdrawalAudit) *models.Order); ok {
+		r0 = rf(orderID, at, audit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Order)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uint, time.Time, *models.WithdrawalAudit) error); ok {
+		r1 = rf(orderID, at, audit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAuditTrail provides a mock function with given fields: orderID
+func (_m *WithdrawalRepositorer) GetAuditTrail(orderID uint) ([]models.WithdrawalAudit, error) {
+	ret := _m.Called(orderID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAuditTrail")
+	}
+
+	var r0 []models.WithdrawalAudit
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uint) ([]models.WithdrawalAudit, error)); ok {
+		return rf(orderID)
+	}
+	if rf, ok := ret.Get(0).(func(uint) []models.WithdrawalAudit); ok {
+		r0 = rf(orderID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.WithdrawalAudit)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uint) error); ok {
+		r1 = rf(orderID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetHeldCash provides a mock function with given fields: userID
+func (_m *WithdrawalRepositorer) GetHeldCash(userID uint) ([]models.CashBalance, error) {
+	ret := _m.Called(userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetHeldCash")
+	}
+
+	var r0 []models.CashBalance
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uint) ([]models.CashBalance, error)); ok {
+		return rf(userID)
+	}
+	if rf, ok := ret.Get(0).(func(uint) []models.CashBalance); ok {
+		r0 = rf(userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.CashBalance)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uint) error); ok {
+		r1 = rf(userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPending provides a mock function with no fields
+func (_m *WithdrawalRepositorer) GetPending() ([]models.Order, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPending")
+	}
+
+	var r0 []models.Order
+	var r1 error
+	if rf, ok := ret.Get(0).(func() ([]models.Order, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() []models.Order); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Order)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Hold provides a mock function with given fields: order, audit
+func (_m *WithdrawalRepositorer) Hold(order *models.Order, audit *models.WithdrawalAudit) error {
+	ret := _m.Called(order, audit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Hold")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*models.Order, *models.WithdrawalAudit) error); ok {
+		r0 = rf(order, audit)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Reject provides a mock function with given fields: orderID, audit
+func (_m *WithdrawalRepositorer) Reject(orderID uint, audit *models.WithdrawalAudit) (*models.Order, error) {
+	ret := _m.Called(orderID, audit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Reject")
+	}
+
+	var r0 *models.Order
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uint, *models.WithdrawalAudit) (*models.Order, error)); ok {
+		return rf(orderID, audit)
+	}
+	if rf, ok := ret.Get(0).(func(uint, *models.WithdrawalAudit) *models.Order); ok {
+		r0 = rf(orderID, audit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Order)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uint, *models.WithdrawalAudit) error); ok {
+		r1 = rf(orderID, audit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewWithdrawalRepositorer creates a new instance of WithdrawalRepositorer. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewWithdrawalRepositorer(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *WithdrawalRepositorer {
+	mock := &WithdrawalRepositorer{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,75 @@
+// Code generated by mockery v2.43.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	models "github.com/NahuelDT/portfolio-api/internal/models"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// AccountServicer is an autogenerated mock type for the AccountServicer type
+type AccountServicer struct {
+	mock.Mock
+}
+
+// CreateAccount provides a mock function with given fields: account
+func (_m *AccountServicer) CreateAccount(account *models.Account) error {
+	ret := _m.Called(account)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateAccount")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*models.Account) error); ok {
+		r0 = rf(account)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetAccounts provides a mock function with given fields: userID
+func (_m *AccountServicer) GetAccounts(userID uint) ([]models.Account, error) {
+	ret := _m.Called(userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAccounts")
+	}
+
+	var r0 []models.Account
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uint) ([]models.Account, error)); ok {
+		return rf(userID)
+	}
+	if rf, ok := ret.Get(0).(func(uint) []models.Account); ok {
+		r0 = rf(userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Account)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uint) error); ok {
+		r1 = rf(userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewAccountServicer creates a new instance of AccountServicer. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewAccountServicer(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *AccountServicer {
+	mock := &AccountServicer{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,229 @@
+// Code generated by mockery v2.43.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	models "github.com/NahuelDT/portfolio-api/internal/models"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// AlertServicer is an autogenerated mock type for the AlertServicer type
+type AlertServicer struct {
+	mock.Mock
+}
+
+// CreateAlert provides a mock function with given fields: alert
+func (_m *AlertServicer) CreateAlert(alert *models.PriceAlert) error {
+	ret := _m.Called(alert)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateAlert")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*models.PriceAlert) error); ok {
+		r0 = rf(alert)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteAlert provides a mock function with given fields: userID, alertID
+func (_m *AlertServicer) DeleteAlert(userID uint, alertID uint) error {
+	ret := _m.Called(userID, alertID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteAlert")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uint, uint) error); ok {
+		r0 = rf(userID, alertID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Deliver provides a mock function with given fields: trigger
+func (_m *AlertServicer) Deliver(trigger *models.AlertTrigger) error {
+	ret := _m.Called(trigger)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Deliver")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*models.AlertTrigger) error); ok {
+		r0 = rf(trigger)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// EvaluateMarketData provides a mock function with given fields: previous, data
+func (_m *AlertServicer) EvaluateMarketData(previous *models.MarketData, data *models.MarketData) ([]models.AlertTrigger, error) {
+	ret := _m.Called(previous, data)
+
+	if len(ret) == 0 {
+		panic("no return value specified for EvaluateMarketData")
+	}
+
+	var r0 []models.AlertTrigger
+	var r1 error
+	if rf, ok := ret.Get(0).(func(*models.MarketData, *models.MarketData) ([]models.AlertTrigger, error)); ok {
+		return rf(previous, data)
+	}
+	if rf, ok := ret.Get(0).(func(*models.MarketData, *models.MarketData) []models.AlertTrigger); ok {
+		r0 = rf(previous, data)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.AlertTrigger)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(*models.MarketData, *models.MarketData) error); ok {
+		r1 = rf(previous, data)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAlerts provides a mock function with given fields: userID
+func (_m *AlertServicer) GetAlerts(userID uint) ([]models.PriceAlert, error) {
+	ret := _m.Called(userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAlerts")
+	}
+
+	var r0 []models.PriceAlert
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uint) ([]models.PriceAlert, error)); ok {
+		return rf(userID)
+	}
+	if rf, ok := ret.Get(0).(func(uint) []models.PriceAlert); ok {
+		r0 = rf(userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.PriceAlert)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uint) error); ok {
+		r1 = rf(userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetTriggers provides a mock function with given fields: userID
+func (_m *AlertServicer) GetTriggers(userID uint) ([]models.AlertTrigger, error) {
+	ret := _m.Called(userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTriggers")
+	}
+
+	var r0 []models.AlertTrigger
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uint) ([]models.AlertTrigger, error)); ok {
+		return rf(userID)
+	}
+	if rf, ok := ret.Get(0).(func(uint) []models.AlertTrigger); ok {
+		r0 = rf(userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.AlertTrigger)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uint) error); ok {
+		r1 = rf(userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RetryDeliveries provides a mock function with no fields
+func (_m *AlertServicer) RetryDeliveries() (int, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for RetryDeliveries")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func() (int, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() int); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetWebhook provides a mock function with given fields: userID, webhookURL
+func (_m *AlertServicer) SetWebhook(userID uint, webhookURL string) (*models.AlertWebhook, error) {
+	ret := _m.Called(userID, webhookURL)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetWebhook")
+	}
+
+	var r0 *models.AlertWebhook
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uint, string) (*models.AlertWebhook, error)); ok {
+		return rf(userID, webhookURL)
+	}
+	if rf, ok := ret.Get(0).(func(uint, string) *models.AlertWebhook); ok {
+		r0 = rf(userID, webhookURL)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.AlertWebhook)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uint, string) error); ok {
+		r1 = rf(userID, webhookURL)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewAlertServicer creates a new instance of AlertServicer. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewAlertServicer(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *AlertServicer {
+	mock := &AlertServicer{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,77 @@
+// Code generated by mockery v2.43.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	models "github.com/NahuelDT/portfolio-api/internal/models"
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// CorporateActionServicer is an autogenerated mock type for the CorporateActionServicer type
+type CorporateActionServicer struct {
+	mock.Mock
+}
+
+// GetPriceHistory provides a mock function with given fields: instrumentID, from, to, adjusted
+func (_m *CorporateActionServicer) GetPriceHistory(instrumentID uint, from time.Time, to time.Time, adjusted bool) ([]models.MarketData, error) {
+	ret := _m.Called(instrumentID, from, to, adjusted)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPriceHistory")
+	}
+
+	var r0 []models.MarketData
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uint, time.Time, time.Time, bool) ([]models.MarketData, error)); ok {
+		return rf(instrumentID, from, to, adjusted)
+	}
+	if rf, ok := ret.Get(0).(func(uint, time.Time, time.Time, bool) []models.MarketData); ok {
+		r0 = rf(instrumentID, from, to, adjusted)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.MarketData)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uint, time.Time, time.Time, bool) error); ok {
+		r1 = rf(instrumentID, from, to, adjusted)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RegisterCorporateAction provides a mock function with given fields: action
+func (_m *CorporateActionServicer) RegisterCorporateAction(action *models.CorporateAction) error {
+	ret := _m.Called(action)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RegisterCorporateAction")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*models.CorporateAction) error); ok {
+		r0 = rf(action)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewCorporateActionServicer creates a new instance of CorporateActionServicer. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewCorporateActionServicer(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *CorporateActionServicer {
+	mock := &CorporateActionServicer{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
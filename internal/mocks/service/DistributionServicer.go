@@ -0,0 +1,65 @@
+// Code generated by mockery v2.43.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	models "github.com/NahuelDT/portfolio-api/internal/models"
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// DistributionServicer is an autogenerated mock type for the DistributionServicer type
+type DistributionServicer struct {
+	mock.Mock
+}
+
+// ProcessDistributions provides a mock function with given fields: now
+func (_m *DistributionServicer) ProcessDistributions(now time.Time) error {
+	ret := _m.Called(now)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ProcessDistributions")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(time.Time) error); ok {
+		r0 = rf(now)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RegisterDistribution provides a mock function with given fields: distribution
+func (_m *DistributionServicer) RegisterDistribution(distribution *models.Distribution) error {
+	ret := _m.Called(distribution)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RegisterDistribution")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*models.Distribution) error); ok {
+		r0 = rf(distribution)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewDistributionServicer creates a new instance of DistributionServicer. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewDistributionServicer(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *DistributionServicer {
+	mock := &DistributionServicer{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
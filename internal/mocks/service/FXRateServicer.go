@@ -0,0 +1,45 @@
+// Code generated by mockery v2.43.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	models "github.com/NahuelDT/portfolio-api/internal/models"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// FXRateServicer is an autogenerated mock type for the FXRateServicer type
+type FXRateServicer struct {
+	mock.Mock
+}
+
+// CreateRate provides a mock function with given fields: rate
+func (_m *FXRateServicer) CreateRate(rate *models.FXRate) error {
+	ret := _m.Called(rate)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateRate")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*models.FXRate) error); ok {
+		r0 = rf(rate)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewFXRateServicer creates a new instance of FXRateServicer. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewFXRateServicer(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *FXRateServicer {
+	mock := &FXRateServicer{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
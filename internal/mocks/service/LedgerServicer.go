@@ -0,0 +1,135 @@
+// Code generated by mockery v2.43.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	models "github.com/NahuelDT/portfolio-api/internal/models"
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// LedgerServicer is an autogenerated mock type for the LedgerServicer type
+type LedgerServicer struct {
+	mock.Mock
+}
+
+// GetJournal provides a mock function with given fields: userID, from, to
+func (_m *LedgerServicer) GetJournal(userID uint, from time.Time, to time.Time) ([]models.JournalEntry, error) {
+	ret := _m.Called(userID, from, to)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetJournal")
+	}
+
+	var r0 []models.JournalEntry
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uint, time.Time, time.Time) ([]models.JournalEntry, error)); ok {
+		return rf(userID, from, to)
+	}
+	if rf, ok := ret.Get(0).(func(uint, time.Time, time.Time) []models.JournalEntry); ok {
+		r0 = rf(userID, from, to)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.JournalEntry)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uint, time.Time, time.Time) error); ok {
+		r1 = rf(userID, from, to)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetTrialBalance provides a mock function with no fields
+func (_m *LedgerServicer) GetTrialBalance() (*models.TrialBalance, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTrialBalance")
+	}
+
+	var r0 *models.TrialBalance
+	var r1 error
+	if rf, ok := ret.Get(0).(func() (*models.TrialBalance, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() *models.TrialBalance); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.TrialBalance)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RebuildAll provides a mock function with no fields
+func (_m *LedgerServicer) RebuildAll() (int, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for RebuildAll")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func() (int, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() int); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RebuildUser provides a mock function with given fields: userID
+func (_m *LedgerServicer) RebuildUser(userID uint) error {
+	ret := _m.Called(userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RebuildUser")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uint) error); ok {
+		r0 = rf(userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewLedgerServicer creates a new instance of LedgerServicer. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewLedgerServicer(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *LedgerServicer {
+	mock := &LedgerServicer{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,119 @@
+// Code generated by mockery v2.43.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	models "github.com/NahuelDT/portfolio-api/internal/models"
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// PerformanceServicer is an autogenerated mock type for the PerformanceServicer type
+type PerformanceServicer struct {
+	mock.Mock
+}
+
+// CompareToBenchmark provides a mock function with given fields: userID, benchmarkID, period, baseCurrency
+func (_m *PerformanceServicer) CompareToBenchmark(userID uint, benchmarkID uint, period string, baseCurrency string) (*models.BenchmarkComparison, error) {
+	ret := _m.Called(userID, benchmarkID, period, baseCurrency)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CompareToBenchmark")
+	}
+
+	var r0 *models.BenchmarkComparison
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uint, uint, string, string) (*models.BenchmarkComparison, error)); ok {
+		return rf(userID, benchmarkID, period, baseCurrency)
+	}
+	if rf, ok := ret.Get(0).(func(uint, uint, string, string) *models.BenchmarkComparison); ok {
+		r0 = rf(userID, benchmarkID, period, baseCurrency)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.BenchmarkComparison)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uint, uint, string, string) error); ok {
+		r1 = rf(userID, benchmarkID, period, baseCurrency)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPerformance provides a mock function with given fields: userID, period, baseCurrency
+func (_m *PerformanceServicer) GetPerformance(userID uint, period string, baseCurrency string) (*models.Performance, error) {
+	ret := _m.Called(userID, period, baseCurrency)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPerformance")
+	}
+
+	var r0 *models.Performance
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uint, string, string) (*models.Performance, error)); ok {
+		return rf(userID, period, baseCurrency)
+	}
+	if rf, ok := ret.Get(0).(func(uint, string, string) *models.Performance); ok {
+		r0 = rf(userID, period, baseCurrency)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Performance)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uint, string, string) error); ok {
+		r1 = rf(userID, period, baseCurrency)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPerformanceBetween provides a mock function with given fields: userID, from, to, baseCurrency
+func (_m *PerformanceServicer) GetPerformanceBetween(userID uint, from time.Time, to time.Time, baseCurrency string) (*models.Performance, error) {
+	ret := _m.Called(userID, from, to, baseCurrency)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPerformanceBetween")
+	}
+
+	var r0 *models.Performance
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uint, time.Time, time.Time, string) (*models.Performance, error)); ok {
+		return rf(userID, from, to, baseCurrency)
+	}
+	if rf, ok := ret.Get(0).(func(uint, time.Time, time.Time, string) *models.Performance); ok {
+		r0 = rf(userID, from, to, baseCurrency)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Performance)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uint, time.Time, time.Time, string) error); ok {
+		r1 = rf(userID, from, to, baseCurrency)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewPerformanceServicer creates a new instance of PerformanceServicer. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewPerformanceServicer(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *PerformanceServicer {
+	mock := &PerformanceServicer{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -5,6 +5,8 @@ package mocks
 import (
 	models "github.com/NahuelDT/portfolio-api/internal/models"
 	mock "github.com/stretchr/testify/mock"
+
+	time "time"
 )
 
 // PortfolioServicer is an autogenerated mock type for the PortfolioServicer type
@@ -12,9 +14,99 @@ type PortfolioServicer struct {
 	mock.Mock
 }
 
-// GetPortfolio provides a mock function with given fields: userID
-func (_m *PortfolioServicer) GetPortfolio(userID uint) (*models.Portfolio, error) {
-	ret := _m.Called(userID)
+// GetAccountPortfolio provides a mock function with given fields: userID, accountID, baseCurrency
+func (_m *PortfolioServicer) GetAccountPortfolio(userID uint, accountID uint, baseCurrency string) (*models.Portfolio, error) {
+	ret := _m.Called(userID, accountID, baseCurrency)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAccountPortfolio")
+	}
+
+	var r0 *models.Portfolio
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uint, uint, string) (*models.Portfolio, error)); ok {
+		return rf(userID, accountID, baseCurrency)
+	}
+	if rf, ok := ret.Get(0).(func(uint, uint, string) *models.Portfolio); ok {
+		r0 = rf(userID, accountID, baseCurrency)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Portfolio)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uint, uint, string) error); ok {
+		r1 = rf(userID, accountID, baseCurrency)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAccountPortfolioAsOf provides a mock function with given fields: userID, accountID, asOf, baseCurrency
+func (_m *PortfolioServicer) GetAccountPortfolioAsOf(userID uint, accountID uint, asOf time.Time, baseCurrency string) (*models.Portfolio, error) {
+	ret := _m.Called(userID, accountID, asOf, baseCurrency)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAccountPortfolioAsOf")
+	}
+
+	var r0 *models.Portfolio
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uint, uint, time.Time, string) (*models.Portfolio, error)); ok {
+		return rf(userID, accountID, asOf, baseCurrency)
+	}
+	if rf, ok := ret.Get(0).(func(uint, uint, time.Time, string) *models.Portfolio); ok {
+		r0 = rf(userID, accountID, asOf, baseCurrency)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Portfolio)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uint, uint, time.Time, string) error); ok {
+		r1 = rf(userID, accountID, asOf, baseCurrency)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAllocation provides a mock function with given fields: userID, groupBy, baseCurrency
+func (_m *PortfolioServicer) GetAllocation(userID uint, groupBy string, baseCurrency string) (*models.PortfolioAllocation, error) {
+	ret := _m.Called(userID, groupBy, baseCurrency)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAllocation")
+	}
+
+	var r0 *models.PortfolioAllocation
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uint, string, string) (*models.PortfolioAllocation, error)); ok {
+		return rf(userID, groupBy, baseCurrency)
+	}
+	if rf, ok := ret.Get(0).(func(uint, string, string) *models.PortfolioAllocation); ok {
+		r0 = rf(userID, groupBy, baseCurrency)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.PortfolioAllocation)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uint, string, string) error); ok {
+		r1 = rf(userID, groupBy, baseCurrency)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPortfolio provides a mock function with given fields: userID, baseCurrency
+func (_m *PortfolioServicer) GetPortfolio(userID uint, baseCurrency string) (*models.Portfolio, error) {
+	ret := _m.Called(userID, baseCurrency)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetPortfolio")
@@ -22,19 +114,49 @@ func (_m *PortfolioServicer) GetPortfolio(userID uint) (*models.Portfolio, error
 
 	var r0 *models.Portfolio
 	var r1 error
-	if rf, ok := ret.Get(0).(func(uint) (*models.Portfolio, error)); ok {
-		return rf(userID)
+	if rf, ok := ret.Get(0).(func(uint, string) (*models.Portfolio, error)); ok {
+		return rf(userID, baseCurrency)
+	}
+	if rf, ok := ret.Get(0).(func(uint, string) *models.Portfolio); ok {
+		r0 = rf(userID, baseCurrency)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Portfolio)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uint, string) error); ok {
+		r1 = rf(userID, baseCurrency)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPortfolioAsOf provides a mock function with given fields: userID, asOf, baseCurrency
+func (_m *PortfolioServicer) GetPortfolioAsOf(userID uint, asOf time.Time, baseCurrency string) (*models.Portfolio, error) {
+	ret := _m.Called(userID, asOf, baseCurrency)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPortfolioAsOf")
+	}
+
+	var r0 *models.Portfolio
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uint, time.Time, string) (*models.Portfolio, error)); ok {
+		return rf(userID, asOf, baseCurrency)
 	}
-	if rf, ok := ret.Get(0).(func(uint) *models.Portfolio); ok {
-		r0 = rf(userID)
+	if rf, ok := ret.Get(0).(func(uint, time.Time, string) *models.Portfolio); ok {
+		r0 = rf(userID, asOf, baseCurrency)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*models.Portfolio)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(uint) error); ok {
-		r1 = rf(userID)
+	if rf, ok := ret.Get(1).(func(uint, time.Time, string) error); ok {
+		r1 = rf(userID, asOf, baseCurrency)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -0,0 +1,153 @@
+// Code generated by mockery v2.43.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	models "github.com/NahuelDT/portfolio-api/internal/models"
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// PositionServicer is an autogenerated mock type for the PositionServicer type
+type PositionServicer struct {
+	mock.Mock
+}
+
+// ApplyCorporateActions provides a mock function with given fields: now
+func (_m *PositionServicer) ApplyCorporateActions(now time.Time) error {
+	ret := _m.Called(now)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ApplyCorporateActions")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(time.Time) error); ok {
+		r0 = rf(now)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CheckAll provides a mock function with no fields
+func (_m *PositionServicer) CheckAll() ([]models.BalanceMismatch, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for CheckAll")
+	}
+
+	var r0 []models.BalanceMismatch
+	var r1 error
+	if rf, ok := ret.Get(0).(func() ([]models.BalanceMismatch, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() []models.BalanceMismatch); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.BalanceMismatch)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CheckUser provides a mock function with given fields: userID
+func (_m *PositionServicer) CheckUser(userID uint) ([]models.BalanceMismatch, error) {
+	ret := _m.Called(userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CheckUser")
+	}
+
+	var r0 []models.BalanceMismatch
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uint) ([]models.BalanceMismatch, error)); ok {
+		return rf(userID)
+	}
+	if rf, ok := ret.Get(0).(func(uint) []models.BalanceMismatch); ok {
+		r0 = rf(userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.BalanceMismatch)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uint) error); ok {
+		r1 = rf(userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RebuildAll provides a mock function with no fields
+func (_m *PositionServicer) RebuildAll() (int, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for RebuildAll")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func() (int, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() int); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RebuildUser provides a mock function with given fields: userID
+func (_m *PositionServicer) RebuildUser(userID uint) error {
+	ret := _m.Called(userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RebuildUser")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uint) error); ok {
+		r0 = rf(userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewPositionServicer creates a new instance of PositionServicer. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewPositionServicer(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *PositionServicer {
+	mock := &PositionServicer{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
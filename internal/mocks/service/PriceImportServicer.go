@@ -0,0 +1,59 @@
+// Code generated by mockery v2.43.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	io "io"
+
+	models "github.com/NahuelDT/portfolio-api/internal/models"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// PriceImportServicer is an autogenerated mock type for the PriceImportServicer type
+type PriceImportServicer struct {
+	mock.Mock
+}
+
+// Import provides a mock function with given fields: file, dryRun
+func (_m *PriceImportServicer) Import(file io.Reader, dryRun bool) (*models.PriceImportReport, error) {
+	ret := _m.Called(file, dryRun)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Import")
+	}
+
+	var r0 *models.PriceImportReport
+	var r1 error
+	if rf, ok := ret.Get(0).(func(io.Reader, bool) (*models.PriceImportReport, error)); ok {
+		return rf(file, dryRun)
+	}
+	if rf, ok := ret.Get(0).(func(io.Reader, bool) *models.PriceImportReport); ok {
+		r0 = rf(file, dryRun)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.PriceImportReport)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(io.Reader, bool) error); ok {
+		r1 = rf(file, dryRun)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewPriceImportServicer creates a new instance of PriceImportServicer. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewPriceImportServicer(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *PriceImportServicer {
+	mock := &PriceImportServicer{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
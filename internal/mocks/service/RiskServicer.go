@@ -0,0 +1,57 @@
+// Code generated by mockery v2.43.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	models "github.com/NahuelDT/portfolio-api/internal/models"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// RiskServicer is an autogenerated mock type for the RiskServicer type
+type RiskServicer struct {
+	mock.Mock
+}
+
+// GetRisk provides a mock function with given fields: userID, period, baseCurrency, benchmarkID, riskFreeRate
+func (_m *RiskServicer) GetRisk(userID uint, period string, baseCurrency string, benchmarkID uint, riskFreeRate float64) (*models.PortfolioRisk, error) {
+	ret := _m.Called(userID, period, baseCurrency, benchmarkID, riskFreeRate)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetRisk")
+	}
+
+	var r0 *models.PortfolioRisk
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uint, string, string, uint, float64) (*models.PortfolioRisk, error)); ok {
+		return rf(userID, period, baseCurrency, benchmarkID, riskFreeRate)
+	}
+	if rf, ok := ret.Get(0).(func(uint, string, string, uint, float64) *models.PortfolioRisk); ok {
+		r0 = rf(userID, period, baseCurrency, benchmarkID, riskFreeRate)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.PortfolioRisk)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uint, string, string, uint, float64) error); ok {
+		r1 = rf(userID, period, baseCurrency, benchmarkID, riskFreeRate)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewRiskServicer creates a new instance of RiskServicer. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewRiskServicer(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *RiskServicer {
+	mock := &RiskServicer{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,117 @@
+// Code generated by mockery v2.43.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	models "github.com/NahuelDT/portfolio-api/internal/models"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// StatementServicer is an autogenerated mock type for the StatementServicer type
+type StatementServicer struct {
+	mock.Mock
+}
+
+// GenerateStatement provides a mock function with given fields: userID, periodType, year, number, baseCurrency
+func (_m *StatementServicer) GenerateStatement(userID uint, periodType string, year int, number int, baseCurrency string) (*models.AccountStatement, error) {
+	ret := _m.Called(userID, periodType, year, number, baseCurrency)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GenerateStatement")
+	}
+
+	var r0 *models.AccountStatement
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uint, string, int, int, string) (*models.AccountStatement, error)); ok {
+		return rf(userID, periodType, year, number, baseCurrency)
+	}
+	if rf, ok := ret.Get(0).(func(uint, string, int, int, string) *models.AccountStatement); ok {
+		r0 = rf(userID, periodType, year, number, baseCurrency)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.AccountStatement)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uint, string, int, int, string) error); ok {
+		r1 = rf(userID, periodType, year, number, baseCurrency)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetStatement provides a mock function with given fields: statementID
+func (_m *StatementServicer) GetStatement(statementID uint) (*models.AccountStatement, error) {
+	ret := _m.Called(statementID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetStatement")
+	}
+
+	var r0 *models.AccountStatement
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uint) (*models.AccountStatement, error)); ok {
+		return rf(statementID)
+	}
+	if rf, ok := ret.Get(0).(func(uint) *models.AccountStatement); ok {
+		r0 = rf(statementID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.AccountStatement)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uint) error); ok {
+		r1 = rf(statementID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetStatements provides a mock function with given fields: userID
+func (_m *StatementServicer) GetStatements(userID uint) ([]models.Statement, error) {
+	ret := _m.Called(userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetStatements")
+	}
+
+	var r0 []models.Statement
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uint) ([]models.Statement, error)); ok {
+		return rf(userID)
+	}
+	if rf, ok := ret.Get(0).(func(uint) []models.Statement); ok {
+		r0 = rf(userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Statement)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uint) error); ok {
+		r1 = rf(userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewStatementServicer creates a new instance of StatementServicer. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewStatementServicer(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *StatementServicer {
+	mock := &StatementServicer{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
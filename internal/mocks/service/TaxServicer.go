@@ -0,0 +1,57 @@
+// Code generated by mockery v2.43.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	models "github.com/NahuelDT/portfolio-api/internal/models"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// TaxServicer is an autogenerated mock type for the TaxServicer type
+type TaxServicer struct {
+	mock.Mock
+}
+
+// GetCapitalGains provides a mock function with given fields: userID, year
+func (_m *TaxServicer) GetCapitalGains(userID uint, year int) (*models.CapitalGainsReport, error) {
+	ret := _m.Called(userID, year)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetCapitalGains")
+	}
+
+	var r0 *models.CapitalGainsReport
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uint, int) (*models.CapitalGainsReport, error)); ok {
+		return rf(userID, year)
+	}
+	if rf, ok := ret.Get(0).(func(uint, int) *models.CapitalGainsReport); ok {
+		r0 = rf(userID, year)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.CapitalGainsReport)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uint, int) error); ok {
+		r1 = rf(userID, year)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewTaxServicer creates a new instance of TaxServicer. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewTaxServicer(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *TaxServicer {
+	mock := &TaxServicer{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,75 @@
+// Code generated by mockery v2.43.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	models "github.com/NahuelDT/portfolio-api/internal/models"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// TransferServicer is an autogenerated mock type for the TransferServicer type
+type TransferServicer struct {
+	mock.Mock
+}
+
+// GetTransfers provides a mock function with given fields: userID
+func (_m *TransferServicer) GetTransfers(userID uint) ([]models.Transfer, error) {
+	ret := _m.Called(userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTransfers")
+	}
+
+	var r0 []models.Transfer
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uint) ([]models.Transfer, error)); ok {
+		return rf(userID)
+	}
+	if rf, ok := ret.Get(0).(func(uint) []models.Transfer); ok {
+		r0 = rf(userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Transfer)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uint) error); ok {
+		r1 = rf(userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Transfer provides a mock function with given fields: transfer
+func (_m *TransferServicer) Transfer(transfer *models.Transfer) error {
+	ret := _m.Called(transfer)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Transfer")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*models.Transfer) error); ok {
+		r0 = rf(transfer)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewTransferServicer creates a new instance of TransferServicer. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewTransferServicer(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *TransferServicer {
+	mock := &TransferServicer{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
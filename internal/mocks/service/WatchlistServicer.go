@@ -0,0 +1,243 @@
+// Code generated by mockery v2.43.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	models "github.com/NahuelDT/portfolio-api/internal/models"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// WatchlistServicer is an autogenerated mock type for the WatchlistServicer type
+type WatchlistServicer struct {
+	mock.Mock
+}
+
+// AddInstrument provides a mock function with given fields: watchlistID, instrumentID
+func (_m *WatchlistServicer) AddInstrument(watchlistID uint, instrumentID uint) (*models.Watchlist, error) {
+	ret := _m.Called(watchlistID, instrumentID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddInstrument")
+	}
+
+	var r0 *models.Watchlist
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uint, uint) (*models.Watchlist, error)); ok {
+		return rf(watchlistID, instrumentID)
+	}
+	if rf, ok := ret.Get(0).(func(uint, uint) *models.Watchlist); ok {
+		r0 = rf(watchlistID, instrumentID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Watchlist)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uint, uint) error); ok {
+		r1 = rf(watchlistID, instrumentID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateWatchlist provides a mock function with given fields: watchlist
+func (_m *WatchlistServicer) CreateWatchlist(watchlist *models.Watchlist) error {
+	ret := _m.Called(watchlist)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateWatchlist")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*models.Watchlist) error); ok {
+		r0 = rf(watchlist)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteWatchlist provides a mock function with given fields: watchlistID
+func (_m *WatchlistServicer) DeleteWatchlist(watchlistID uint) error {
+	ret := _m.Called(watchlistID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteWatchlist")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uint) error); ok {
+		r0 = rf(watchlistID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetQuotes provides a mock function with given fields: watchlistID, sortBy, order
+func (_m *WatchlistServicer) GetQuotes(watchlistID uint, sortBy string, order string) (*models.WatchlistSnapshot, error) {
+	ret := _m.Called(watchlistID, sortBy, order)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetQuotes")
+	}
+
+	var r0 *models.WatchlistSnapshot
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uint, string, string) (*models.WatchlistSnapshot, error)); ok {
+		return rf(watchlistID, sortBy, order)
+	}
+	if rf, ok := ret.Get(0).(func(uint, string, string) *models.WatchlistSnapshot); ok {
+		r0 = rf(watchlistID, sortBy, order)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.WatchlistSnapshot)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uint, string, string) error); ok {
+		r1 = rf(watchlistID, sortBy, order)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetWatchlist provides a mock function with given fields: watchlistID
+func (_m *WatchlistServicer) GetWatchlist(watchlistID uint) (*models.Watchlist, error) {
+	ret := _m.Called(watchlistID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetWatchlist")
+	}
+
+	var r0 *models.Watchlist
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uint) (*models.Watchlist, error)); ok {
+		return rf(watchlistID)
+	}
+	if rf, ok := ret.Get(0).(func(uint) *models.Watchlist); ok {
+		r0 = rf(watchlistID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Watchlist)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uint) error); ok {
+		r1 = rf(watchlistID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetWatchlists provides a mock function with given fields: userID
+func (_m *WatchlistServicer) GetWatchlists(userID uint) ([]models.Watchlist, error) {
+	ret := _m.Called(userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetWatchlists")
+	}
+
+	var r0 []models.Watchlist
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uint) ([]models.Watchlist, error)); ok {
+		return rf(userID)
+	}
+	if rf, ok := ret.Get(0).(func(uint) []models.Watchlist); ok {
+		r0 = rf(userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Watchlist)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uint) error); ok {
+		r1 = rf(userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RemoveInstrument provides a mock function with given fields: watchlistID, instrumentID
+func (_m *WatchlistServicer) RemoveInstrument(watchlistID uint, instrumentID uint) (*models.Watchlist, error) {
+	ret := _m.Called(watchlistID, instrumentID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RemoveInstrument")
+	}
+
+	var r0 *models.Watchlist
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uint, uint) (*models.Watchlist, error)); ok {
+		return rf(watchlistID, instrumentID)
+	}
+	if rf, ok := ret.Get(0).(func(uint, uint) *models.Watchlist); ok {
+		r0 = rf(watchlistID, instrumentID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Watchlist)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uint, uint) error); ok {
+		r1 = rf(watchlistID, instrumentID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateWatchlist provides a mock function with given fields: watchlistID, name, instrumentIDs
+func (_m *WatchlistServicer) UpdateWatchlist(watchlistID uint, name string, instrumentIDs []uint) (*models.Watchlist, error) {
+	ret := _m.Called(watchlistID, name, instrumentIDs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateWatchlist")
+	}
+
+	var r0 *models.Watchlist
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uint, string, []uint) (*models.Watchlist, error)); ok {
+		return rf(watchlistID, name, instrumentIDs)
+	}
+	if rf, ok := ret.Get(0).(func(uint, string, []uint) *models.Watchlist); ok {
+		r0 = rf(watchlistID, name, instrumentIDs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Watchlist)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uint, string, []uint) error); ok {
+		r1 = rf(watchlistID, name, instrumentIDs)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewWatchlistServicer creates a new instance of WatchlistServicer. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewWatchlistServicer(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *WatchlistServicer {
+	mock := &WatchlistServicer{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
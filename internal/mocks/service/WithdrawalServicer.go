@@ -0,0 +1,147 @@
+// Code generated by mockery v2.43.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	models "github.com/NahuelDT/portfolio-api/internal/models"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// WithdrawalServicer is an autogenerated mock type for the WithdrawalServicer type
+type WithdrawalServicer struct {
+	mock.Mock
+}
+
+// Approve provides a mock function with given fields: orderID, reviewer, note
+func (_m *WithdrawalServicer) Approve(orderID uint, reviewer string, note string) (*models.Order, error) {
+	ret := _m.Called(orderID, reviewer, note)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Approve")
+	}
+
+	var r0 *models.Order
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uint, string, string) (*models.Order, error)); ok {
+		return rf(orderID, reviewer, note)
+	}
+	if rf, ok := ret.Get(0).(func(uint, string, string) *models.Order); ok {
+		r0 = rf(orderID, reviewer, note)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Order)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uint, string, string) error); ok {
+		r1 = rf(orderID, reviewer, note)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAuditTrail provides a mock function with given fields: orderID
+func (_m *WithdrawalServicer) GetAuditTrail(orderID uint) ([]models.WithdrawalAudit, error) {
+	ret := _m.Called(orderID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAuditTrail")
+	}
+
+	var r0 []models.WithdrawalAudit
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uint) ([]models.WithdrawalAudit, error)); ok {
+		return rf(orderID)
+	}
+	if rf, ok := ret.Get(0).(func(uint) []models.WithdrawalAudit); ok {
+		r0 = rf(orderID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.WithdrawalAudit)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uint) error); ok {
+		r1 = rf(orderID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPending provides a mock function with no fields
+func (_m *WithdrawalServicer) GetPending() ([]models.Order, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPending")
+	}
+
+	var r0 []models.Order
+	var r1 error
+	if rf, ok := ret.Get(0).(func() ([]models.Order, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() []models.Order); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Order)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Reject provides a mock function with given fields: orderID, reviewer, note
+func (_m *WithdrawalServicer) Reject(orderID uint, reviewer string, note string) (*models.Order, error) {
+	ret := _m.Called(orderID, reviewer, note)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Reject")
+	}
+
+	var r0 *models.Order
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uint, string, string) (*models.Order, error)); ok {
+		return rf(orderID, reviewer, note)
+	}
+	if rf, ok := ret.Get(0).(func(uint, string, string) *models.Order); ok {
+		r0 = rf(orderID, reviewer, note)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Order)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uint, string, string) error); ok {
+		r1 = rf(orderID, reviewer, note)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewWithdrawalServicer creates a new instance of WithdrawalServicer. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewWithdrawalServicer(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *WithdrawalServicer {
+	mock := &WithdrawalServicer{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
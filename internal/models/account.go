@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+)
+
+// MainAccountID is the account of orders placed without one. Every user has
+// it without creating it, and it holds everything traded before sub-accounts
+// existed.
+const MainAccountID uint = 0
+
+// MainAccountName is the name the main account is listed with
+const MainAccountName = "Principal"
+
+// Account is a named sub-account of a user, such as retirement savings or
+// a trading account, with its own cash and positions
+type Account struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"column:userid" json:"userId"`
+	Name      string    `gorm:"column:name" json:"name"`
+	CreatedAt time.Time `gorm:"column:createdat" json:"createdAt"`
+}
+
+// TableName especifica el nombre de la tabla para GORM
+func (Account) TableName() string {
+	return "accounts"
+}
@@ -0,0 +1,87 @@
+package models
+
+import (
+	"time"
+)
+
+const (
+	AlertCrossesAbove = "CROSSES_ABOVE"
+	AlertCrossesBelow = "CROSSES_BELOW"
+	AlertDailyMove    = "DAILY_MOVE"
+)
+
+// PriceAlert watches the price of an instrument. CROSSES_ABOVE and
+// CROSSES_BELOW trigger when the price crosses Threshold, DAILY_MOVE when
+// the price moves more than Threshold percent from the previous close in
+// either direction. An alert triggers once and is then deactivated.
+type PriceAlert struct {
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	UserID       uint       `gorm:"column:userid" json:"userId"`
+	InstrumentID uint       `gorm:"column:instrumentid" json:"instrumentId"`
+	Type         string     `gorm:"column:type" json:"type"`
+	Threshold    float64    `gorm:"column:threshold" json:"threshold"`
+	Active       bool       `gorm:"column:active" json:"active"`
+	CreatedAt    time.Time  `gorm:"column:createdat" json:"createdAt"`
+	TriggeredAt  *time.Time `gorm:"column:triggeredat" json:"triggeredAt"`
+}
+
+// TableName especifica el nombre de la tabla para GORM
+func (PriceAlert) TableName() string {
+	return "pricealerts"
+}
+
+// AlertTrigger records an alert that triggered and the delivery of its
+// notification to the user's webhook. NextAttemptAt is when an undelivered
+// trigger is attempted again, nil once delivered or given up.
+type AlertTrigger struct {
+	ID            uint       `gorm:"primaryKey" json:"id"`
+	AlertID       uint       `gorm:"column:alertid" json:"alertId"`
+	UserID        uint       `gorm:"column:userid" json:"userId"`
+	InstrumentID  uint       `gorm:"column:instrumentid" json:"instrumentId"`
+	Ticker        string     `gorm:"column:ticker" json:"ticker"`
+	Type          string     `gorm:"column:type" json:"type"`
+	Threshold     float64    `gorm:"column:threshold" json:"threshold"`
+	Price         float64    `gorm:"column:price" json:"price"`
+	ChangePct     float64    `gorm:"column:changepct" json:"changePercent"`
+	PriceTime     time.Time  `gorm:"column:pricetime" json:"priceTime"`
+	TriggeredAt   time.Time  `gorm:"column:triggeredat" json:"triggeredAt"`
+	Attempts      int        `gorm:"column:attempts" json:"attempts"`
+	DeliveredAt   *time.Time `gorm:"column:deliveredat" json:"deliveredAt"`
+	NextAttemptAt *time.Time `gorm:"column:nextattemptat" json:"nextAttemptAt,omitempty"`
+	LastError     string     `gorm:"column:lasterror" json:"lastError,omitempty"`
+}
+
+// TableName especifica el nombre de la tabla para GORM
+func (AlertTrigger) TableName() string {
+	return "alerttriggers"
+}
+
+// AlertWebhook is the URL where a user's triggered alerts are delivered.
+// Every delivery is signed with an HMAC-SHA256 of the body keyed by Secret.
+type AlertWebhook struct {
+	UserID    uint      `gorm:"primaryKey;autoIncrement:false;column:userid" json:"userId"`
+	URL       string    `gorm:"column:url" json:"url"`
+	Secret    string    `gorm:"column:secret" json:"secret"`
+	UpdatedAt time.Time `gorm:"column:updatedat" json:"updatedAt"`
+}
+
+// TableName especifica el nombre de la tabla para GORM
+func (AlertWebhook) TableName() string {
+	return "alertwebhooks"
+}
+
+// AlertNotification is the body posted to the webhook when an alert triggers
+type AlertNotification struct {
+	Event         string    `json:"event"`
+	TriggerID     uint      `json:"triggerId"`
+	AlertID       uint      `json:"alertId"`
+	UserID        uint      `json:"userId"`
+	InstrumentID  uint      `json:"instrumentId"`
+	Ticker        string    `json:"ticker"`
+	Type          string    `json:"type"`
+	Threshold     float64   `json:"threshold"`
+	Price         float64   `json:"price"`
+	ChangePercent float64   `json:"changePercent"`
+	PriceTime     time.Time `json:"priceTime"`
+	TriggeredAt   time.Time `json:"triggeredAt"`
+}
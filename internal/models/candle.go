@@ -0,0 +1,60 @@
+package models
+
+import (
+	"time"
+)
+
+// Candle intervals and the PostgreSQL date_trunc unit that groups the
+// market data rows of each
+const (
+	CandleDaily   = "1d"
+	CandleWeekly  = "1w"
+	CandleMonthly = "1M"
+)
+
+var candleUnits = map[string]string{
+	CandleDaily:   "day",
+	CandleWeekly:  "week",
+	CandleMonthly: "month",
+}
+
+// CandleUnit returns the date_trunc unit of a candle interval, or "" if the
+// interval is not supported
+func CandleUnit(interval string) string {
+	return candleUnits[interval]
+}
+
+// CandleStart returns the start of the interval's bucket that contains t, in
+// UTC. Weeks start on Monday, as they do for date_trunc.
+func CandleStart(interval string, t time.Time) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	switch interval {
+	case CandleWeekly:
+		return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	case CandleMonthly:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+	return day
+}
+
+// Candle is the OHLC bar of an instrument over one interval: the open of its
+// first market data row, the highest high, the lowest low and the close of
+// its last row. Rows counts the market data rows it aggregates.
+type Candle struct {
+	Time  time.Time `gorm:"column:time" json:"time"`
+	Open  float64   `gorm:"column:open" json:"open"`
+	High  float64   `gorm:"column:high" json:"high"`
+	Low   float64   `gorm:"column:low" json:"low"`
+	Close float64   `gorm:"column:close" json:"close"`
+	Rows  int       `gorm:"column:rows" json:"rows"`
+}
+
+// CandlePage is a page of candles, oldest first. NextFrom is the from of the
+// next page, or nil on the last one.
+type CandlePage struct {
+	InstrumentID uint       `json:"instrumentId"`
+	Interval     string     `json:"interval"`
+	Candles      []Candle   `json:"candles"`
+	NextFrom     *time.Time `json:"nextFrom,omitempty"`
+}
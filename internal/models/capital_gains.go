@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+)
+
+const (
+	ShortTermGain = "SHORT"
+	LongTermGain  = "LONG"
+)
+
+type RealizedGain struct {
+	Ticker          string    `json:"ticker"`
+	Name            string    `json:"name"`
+	Currency        string    `json:"currency"`
+	Quantity        float64   `json:"quantity"`
+	AcquisitionDate time.Time `json:"acquisitionDate"`
+	DisposalDate    time.Time `json:"disposalDate"`
+	Proceeds        float64   `json:"proceeds"`
+	CostBasis       float64   `json:"costBasis"`
+	Gain            float64   `json:"gain"`
+	Term            string    `json:"term"`
+}
+
+type CapitalGainsTotal struct {
+	Currency      string  `json:"currency"`
+	Proceeds      float64 `json:"proceeds"`
+	CostBasis     float64 `json:"costBasis"`
+	ShortTermGain float64 `json:"shortTermGain"`
+	LongTermGain  float64 `json:"longTermGain"`
+	TotalGain     float64 `json:"totalGain"`
+}
+
+type CapitalGainsReport struct {
+	UserID uint                `json:"userId"`
+	Year   int                 `json:"year"`
+	Gains  []RealizedGain      `json:"gains"`
+	Totals []CapitalGainsTotal `json:"totals"`
+}
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+)
+
+// Corporate action types
+const (
+	SplitAction        = "SPLIT"
+	ReverseSplitAction = "REVERSE_SPLIT"
+	TickerChangeAction = "TICKER_CHANGE"
+	MergerAction       = "MERGER"
+)
+
+// CorporateAction changes the shares of an instrument from EffectiveDate on.
+// Ratio is the number of new shares received for each old share: 2 for a
+// 2-for-1 split, 0.1 for a 1-for-10 reverse split. A merger converts the
+// shares into TargetInstrumentID at Ratio; a ticker change renames the
+// instrument to NewTicker and keeps the old one in OldTicker. AppliedAt is
+// set once the materialized positions have been restated.
+type CorporateAction struct {
+	ID                 uint       `gorm:"primaryKey" json:"id"`
+	InstrumentID       uint       `gorm:"column:instrumentid" json:"instrumentId"`
+	Type               string     `gorm:"column:type" json:"type"`
+	EffectiveDate      time.Time  `gorm:"column:effectivedate" json:"effectiveDate"`
+	Ratio              float64    `gorm:"column:ratio" json:"ratio,omitempty"`
+	TargetInstrumentID *uint      `gorm:"column:targetinstrumentid" json:"targetInstrumentId,omitempty"`
+	OldTicker          string     `gorm:"column:oldticker" json:"oldTicker,omitempty"`
+	NewTicker          string     `gorm:"column:newticker" json:"newTicker,omitempty"`
+	AppliedAt          *time.Time `gorm:"column:appliedat" json:"appliedAt,omitempty"`
+}
+
+// TableName especifica el nombre de la tabla para GORM
+func (CorporateAction) TableName() string {
+	return "corporateactions"
+}
@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+)
+
+// Distribution is a dividend or other cash distribution paid per share of
+// an instrument to whoever holds it before the ex-date
+type Distribution struct {
+	ID             uint       `gorm:"primaryKey" json:"id"`
+	InstrumentID   uint       `gorm:"column:instrumentid" json:"instrumentId"`
+	ExDate         time.Time  `gorm:"column:exdate" json:"exDate"`
+	PayDate        time.Time  `gorm:"column:paydate" json:"payDate"`
+	AmountPerShare float64    `gorm:"column:amountpershare" json:"amountPerShare"`
+	Currency       string     `gorm:"column:currency" json:"currency"`
+	CreditedAt     *time.Time `gorm:"column:creditedat" json:"creditedAt"`
+}
+
+// TableName especifica el nombre de la tabla para GORM
+func (Distribution) TableName() string {
+	return "distributions"
+}
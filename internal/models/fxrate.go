@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+)
+
+// FXRate is the price of one unit of BaseCurrency in QuoteCurrency
+type FXRate struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	BaseCurrency  string    `gorm:"column:basecurrency" json:"baseCurrency"`
+	QuoteCurrency string    `gorm:"column:quotecurrency" json:"quoteCurrency"`
+	Rate          float64   `gorm:"column:rate" json:"rate"`
+	DateTime      time.Time `gorm:"column:date" json:"date"`
+}
+
+// TableName especifica el nombre de la tabla para GORM
+func (FXRate) TableName() string {
+	return "fxrates"
+}
@@ -1,8 +1,25 @@
 package models
 
+import (
+	"strings"
+)
+
+// DefaultCurrency is used for instruments and orders stored without a currency
+const DefaultCurrency = "ARS"
+
 type Instrument struct {
-	ID     uint   `gorm:"primaryKey;column:id"`
-	Ticker string `gorm:"unique;not null:column:ticker"`
-	Name   string `gorm:"not null;column:name"`
-	Type   string `gorm:"not null;column:type"`
+	ID       uint   `gorm:"primaryKey;column:id"`
+	Ticker   string `gorm:"unique;not null:column:ticker"`
+	Name     string `gorm:"not null;column:name"`
+	Type     string `gorm:"not null;column:type"`
+	Currency string `gorm:"column:currency"`
+}
+
+// NormalizeCurrency upper-cases a currency code, falling back to DefaultCurrency
+func NormalizeCurrency(currency string) string {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if currency == "" {
+		return DefaultCurrency
+	}
+	return currency
 }
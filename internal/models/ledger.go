@@ -0,0 +1,152 @@
+package models
+
+import (
+	"time"
+)
+
+// Ledger accounts. Cash balances are the sum of the CASH postings, which
+// orders, transfers and withdrawals are checked against; the cashbalances
+// table is a projection of them written in the same transaction. CASH and
+// SECURITIES are what a user's account holds, so debits
+// increase them; the others record where those holdings came from.
+// SECURITIES is kept at cost per currency, while quantities stay in the
+// positions, so splits and mergers need no entries.
+const (
+	LedgerCash          = "CASH"
+	LedgerSecurities    = "SECURITIES"
+	LedgerContributions = "CONTRIBUTIONS"
+	LedgerTransfers     = "TRANSFERS"
+	LedgerIncome        = "INCOME"
+	LedgerFees          = "FEES"
+	LedgerRealizedGains = "REALIZED_GAINS"
+)
+
+// JournalEntry is the double-entry record of a filled order. Its postings
+// add up to zero in the order's currency.
+type JournalEntry struct {
+	ID          uint            `gorm:"primaryKey" json:"id"`
+	OrderID     uint            `gorm:"column:orderid" json:"orderId"`
+	UserID      uint            `gorm:"column:userid" json:"userId"`
+	AccountID   uint            `gorm:"column:accountid" json:"accountId"`
+	Description string          `gorm:"column:description" json:"description"`
+	PostedAt    time.Time       `gorm:"column:postedat" json:"postedAt"`
+	Postings    []LedgerPosting `gorm:"foreignKey:EntryID" json:"postings"`
+}
+
+// TableName especifica el nombre de la tabla para GORM
+func (JournalEntry) TableName() string {
+	return "journalentries"
+}
+
+// LedgerPosting is one line of a journal entry. Amount is positive for
+// debits and negative for credits.
+type LedgerPosting struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	EntryID       uint      `gorm:"column:entryid" json:"entryId"`
+	UserID        uint      `gorm:"column:userid" json:"userId"`
+	AccountID     uint      `gorm:"column:accountid" json:"accountId"`
+	LedgerAccount string    `gorm:"column:ledgeraccount" json:"ledgerAccount"`
+	InstrumentID  uint      `gorm:"column:instrumentid" json:"instrumentId,omitempty"`
+	Currency      string    `gorm:"column:currency" json:"currency"`
+	Amount        float64   `gorm:"column:amount" json:"amount"`
+	PostedAt      time.Time `gorm:"column:postedat" json:"postedAt"`
+}
+
+// TableName especifica el nombre de la tabla para GORM
+func (LedgerPosting) TableName() string {
+	return "ledgerpostings"
+}
+
+// NewJournalEntry builds the entry of a filled order, or nil if the order
+// moves nothing. averageCost is the account's average purchase price of the
+// instrument before the fill and gives the cost of the securities a SELL
+// takes out of the ledger. No fees are charged on orders yet, so nothing is
+// posted to FEES.
+func NewJournalEntry(order *Order, averageCost float64) *JournalEntry {
+	var lines []LedgerPosting
+	line := func(account string, instrumentID uint, amount float64) LedgerPosting {
+		return LedgerPosting{LedgerAccount: account, InstrumentID: instrumentID, Amount: amount}
+	}
+
+	switch order.Side {
+	case "CASH_IN":
+		lines = []LedgerPosting{line(LedgerCash, 0, order.Size), line(LedgerContributions, 0, -order.Size)}
+	case "CASH_OUT":
+		lines = []LedgerPosting{line(LedgerContributions, 0, order.Size), line(LedgerCash, 0, -order.Size)}
+	case "TRANSFER_IN":
+		lines = []LedgerPosting{line(LedgerCash, 0, order.Size), line(LedgerTransfers, 0, -order.Size)}
+	case "TRANSFER_OUT":
+		lines = []LedgerPosting{line(LedgerTransfers, 0, order.Size), line(LedgerCash, 0, -order.Size)}
+	case "DIVIDEND":
+		amount := order.Size * order.Price
+		lines = []LedgerPosting{line(LedgerCash, 0, amount), line(LedgerIncome, order.InstrumentID, -amount)}
+	case "BUY":
+		amount := order.Size * order.Price
+		lines = []LedgerPosting{line(LedgerSecurities, order.InstrumentID, amount), line(LedgerCash, 0, -amount)}
+	case "SELL":
+		proceeds := order.Size * order.Price
+		cost := order.Size * averageCost
+		lines = []LedgerPosting{
+			line(LedgerCash, 0, proceeds),
+			line(LedgerSecurities, order.InstrumentID, -cost),
+			line(LedgerRealizedGains, order.InstrumentID, cost-proceeds),
+		}
+	case "SECURITIES_IN":
+		amount := order.Size * order.Price
+		lines = []LedgerPosting{line(LedgerSecurities, order.InstrumentID, amount), line(LedgerTransfers, order.InstrumentID, -amount)}
+	case "SECURITIES_OUT":
+		amount := order.Size * order.Price
+		lines = []LedgerPosting{line(LedgerTransfers, order.InstrumentID, amount), line(LedgerSecurities, order.InstrumentID, -amount)}
+	}
+
+	entry := &JournalEntry{
+		OrderID:     order.ID,
+		UserID:      order.UserID,
+		AccountID:   order.AccountID,
+		Description: order.Side,
+		PostedAt:    order.DateTime,
+	}
+	for _, posting := range lines {
+		if posting.Amount == 0 {
+			continue
+		}
+		posting.UserID = order.UserID
+		posting.AccountID = order.AccountID
+		posting.Currency = NormalizeCurrency(order.Currency)
+		posting.PostedAt = order.DateTime
+		entry.Postings = append(entry.Postings, posting)
+	}
+	if len(entry.Postings) == 0 {
+		return nil
+	}
+	return entry
+}
+
+// TrialBalanceLine adds up the postings of a ledger account in one currency
+type TrialBalanceLine struct {
+	LedgerAccount string  `gorm:"column:ledgeraccount" json:"ledgerAccount"`
+	Currency      string  `gorm:"column:currency" json:"currency"`
+	Debits        float64 `gorm:"column:debits" json:"debits"`
+	Credits       float64 `gorm:"column:credits" json:"credits"`
+	Balance       float64 `gorm:"column:balance" json:"balance"`
+}
+
+// TrialBalanceTotal adds up every posting in one currency. Debits and
+// credits match when the ledger is balanced.
+type TrialBalanceTotal struct {
+	Currency string  `json:"currency"`
+	Debits   float64 `json:"debits"`
+	Credits  float64 `json:"credits"`
+}
+
+// TrialBalance is the integrity check of the ledger: the balance of every
+// ledger account, the entries whose postings do not add up to zero and the
+// materialized cash balances that differ from the ledger's cash
+type TrialBalance struct {
+	GeneratedAt       time.Time           `json:"generatedAt"`
+	Lines             []TrialBalanceLine  `json:"lines"`
+	Totals            []TrialBalanceTotal `json:"totals"`
+	UnbalancedEntries []uint              `json:"unbalancedEntries"`
+	CashMismatches    []BalanceMismatch   `json:"cashMismatches"`
+	Balanced          bool                `json:"balanced"`
+}
@@ -5,13 +5,17 @@ import (
 )
 
 type Order struct {
-	ID           uint      `gorm:"primaryKey"`
-	InstrumentID uint      `gorm:"column:instrumentid"`
-	UserID       uint      `gorm:"column:userid"`
-	Side         string    `gorm:"column:side"`
-	Size         float64   `gorm:"column:size"`
-	Price        float64   `gorm:"column:price"`
-	Type         string    `gorm:"column:type"`
-	Status       string    `gorm:"column:status"`
-	DateTime     time.Time `gorm:"column:datetime"`
+	ID             uint      `gorm:"primaryKey"`
+	InstrumentID   uint      `gorm:"column:instrumentid"`
+	UserID         uint      `gorm:"column:userid"`
+	AccountID      uint      `gorm:"column:accountid"`
+	Side           string    `gorm:"column:side"`
+	Size           float64   `gorm:"column:size"`
+	Price          float64   `gorm:"column:price"`
+	Type           string    `gorm:"column:type"`
+	Status         string    `gorm:"column:status"`
+	Currency       string    `gorm:"column:currency"`
+	DateTime       time.Time `gorm:"column:datetime"`
+	TransferID     uint      `gorm:"column:transferid"`
+	SettlementDate time.Time `gorm:"column:settlementdate"`
 }
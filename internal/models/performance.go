@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+)
+
+type Performance struct {
+	Period              string    `json:"period"`
+	From                time.Time `json:"from"`
+	To                  time.Time `json:"to"`
+	BaseCurrency        string    `json:"baseCurrency"`
+	StartValue          float64   `json:"startValue"`
+	EndValue            float64   `json:"endValue"`
+	NetCashFlow         float64   `json:"netCashFlow"`
+	TimeWeightedReturn  float64   `json:"timeWeightedReturn"`
+	MoneyWeightedReturn float64   `json:"moneyWeightedReturn"`
+}
+
+type BenchmarkPoint struct {
+	Date      time.Time `json:"date"`
+	Portfolio float64   `json:"portfolio"`
+	Benchmark float64   `json:"benchmark"`
+}
+
+type BenchmarkComparison struct {
+	Period          string           `json:"period"`
+	From            time.Time        `json:"from"`
+	To              time.Time        `json:"to"`
+	BaseCurrency    string           `json:"baseCurrency"`
+	Benchmark       string           `json:"benchmark"`
+	PortfolioReturn float64          `json:"portfolioReturn"`
+	BenchmarkReturn float64          `json:"benchmarkReturn"`
+	ExcessReturn    float64          `json:"excessReturn"`
+	TrackingError   float64          `json:"trackingError"`
+	Series          []BenchmarkPoint `json:"series"`
+}
@@ -1,15 +1,49 @@
 package models
 
+import (
+	"time"
+)
+
 type PortfolioAsset struct {
-	Ticker     string  `json:"ticker"`
-	Name       string  `json:"name"`
-	Quantity   float64 `json:"quantity"`
-	TotalValue float64 `json:"totalValue"`
-	Return     float64 `json:"return"`
+	Ticker             string    `json:"ticker"`
+	Name               string    `json:"name"`
+	Type               string    `json:"type"`
+	Currency           string    `json:"currency"`
+	Quantity           float64   `json:"quantity"`
+	TotalValue         float64   `json:"totalValue"`
+	Return             float64   `json:"return"`
+	LastPrice          float64   `json:"lastPrice"`
+	PriceTime          time.Time `json:"priceTime"`
+	DailyChange        float64   `json:"dailyChange"`
+	DailyChangePercent float64   `json:"dailyChangePercent"`
 }
 
 type Portfolio struct {
-	TotalValue    float64          `json:"totalValue"`
-	AvailableCash float64          `json:"availableCash"`
-	Assets        []PortfolioAsset `json:"assets"`
+	AccountID     *uint              `json:"accountId,omitempty"`
+	AsOf          *time.Time         `json:"asOf,omitempty"`
+	BaseCurrency  string             `json:"baseCurrency"`
+	TotalValue    float64            `json:"totalValue"`
+	AvailableCash float64            `json:"availableCash"`
+	CashBalances  map[string]float64 `json:"cashBalances"`
+	// SettledCash and UnsettledCash split CashBalances into the cash that
+	// can be withdrawn and the sale proceeds that have not settled yet.
+	// Settled cash is negative when purchases used unsettled proceeds.
+	SettledCash        map[string]float64 `json:"settledCash"`
+	UnsettledCash      map[string]float64 `json:"unsettledCash"`
+	DailyChange        float64            `json:"dailyChange"`
+	DailyChangePercent float64            `json:"dailyChangePercent"`
+	Assets             []PortfolioAsset   `json:"assets"`
+}
+
+type AllocationBucket struct {
+	Key    string   `json:"key"`
+	Value  float64  `json:"value"`
+	Weight float64  `json:"weight"`
+	Assets []string `json:"assets"`
+}
+
+type PortfolioAllocation struct {
+	GroupBy    string             `json:"groupBy"`
+	TotalValue float64            `json:"totalValue"`
+	Buckets    []AllocationBucket `json:"buckets"`
 }
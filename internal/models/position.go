@@ -0,0 +1,63 @@
+package models
+
+import (
+	"time"
+)
+
+// Position is the materialized holding of a user's account in an
+// instrument, updated in the same transaction as every fill. BoughtQuantity
+// and BoughtCost add up all BUY fills and give the average purchase price.
+type Position struct {
+	UserID         uint      `gorm:"primaryKey;autoIncrement:false;column:userid" json:"userId"`
+	AccountID      uint      `gorm:"primaryKey;autoIncrement:false;column:accountid" json:"accountId"`
+	InstrumentID   uint      `gorm:"primaryKey;autoIncrement:false;column:instrumentid" json:"instrumentId"`
+	Quantity       float64   `gorm:"column:quantity" json:"quantity"`
+	BoughtQuantity float64   `gorm:"column:boughtquantity" json:"boughtQuantity"`
+	BoughtCost     float64   `gorm:"column:boughtcost" json:"boughtCost"`
+	UpdatedAt      time.Time `gorm:"column:updatedat" json:"updatedAt"`
+}
+
+// TableName especifica el nombre de la tabla para GORM
+func (Position) TableName() string {
+	return "positions"
+}
+
+// CashBalance is the materialized cash of a user's account in one currency
+type CashBalance struct {
+	UserID    uint      `gorm:"primaryKey;autoIncrement:false;column:userid" json:"userId"`
+	AccountID uint      `gorm:"primaryKey;autoIncrement:false;column:accountid" json:"accountId"`
+	Currency  string    `gorm:"primaryKey;column:currency" json:"currency"`
+	Balance   float64   `gorm:"column:balance" json:"balance"`
+	UpdatedAt time.Time `gorm:"column:updatedat" json:"updatedAt"`
+}
+
+// TableName especifica el nombre de la tabla para GORM
+func (CashBalance) TableName() string {
+	return "cashbalances"
+}
+
+// Balance mismatch kinds
+const (
+	PositionMismatch = "POSITION"
+	CashMismatch     = "CASH"
+)
+
+// BalanceMismatch is a materialized position or cash balance that differs
+// from the one rebuilt from the order history. Key is the instrument ID for
+// positions and the currency for cash.
+type BalanceMismatch struct {
+	UserID    uint    `json:"userId"`
+	AccountID uint    `json:"accountId"`
+	Kind      string  `json:"kind"`
+	Key       string  `json:"key"`
+	Field     string  `json:"field"`
+	Stored    float64 `json:"stored"`
+	Expected  float64 `json:"expected"`
+}
+
+// Holding is the quantity of an instrument held in a user's account
+type Holding struct {
+	UserID    uint    `gorm:"column:userid"`
+	AccountID uint    `gorm:"column:accountid"`
+	Quantity  float64 `gorm:"column:quantity"`
+}
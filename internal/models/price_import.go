@@ -0,0 +1,17 @@
+package models
+
+// RejectedLine is a line of an imported file that was not stored, with the
+// reason why
+type RejectedLine struct {
+	Line   int    `json:"line"`
+	Reason string `json:"reason"`
+}
+
+// PriceImportReport sums up the import of a market data file. On a dry run
+// Imported counts the rows that would have been stored.
+type PriceImportReport struct {
+	Lines    int            `json:"lines"`
+	Imported int            `json:"imported"`
+	Rejected []RejectedLine `json:"rejected"`
+	DryRun   bool           `json:"dryRun"`
+}
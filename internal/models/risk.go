@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+)
+
+type RiskMetrics struct {
+	Volatility   float64  `json:"volatility"`
+	SharpeRatio  float64  `json:"sharpeRatio"`
+	SortinoRatio float64  `json:"sortinoRatio"`
+	MaxDrawdown  float64  `json:"maxDrawdown"`
+	Beta         *float64 `json:"beta,omitempty"`
+}
+
+type AssetRisk struct {
+	Ticker string `json:"ticker"`
+	Name   string `json:"name"`
+	RiskMetrics
+}
+
+type PortfolioRisk struct {
+	Period       string      `json:"period"`
+	From         time.Time   `json:"from"`
+	To           time.Time   `json:"to"`
+	BaseCurrency string      `json:"baseCurrency"`
+	Benchmark    string      `json:"benchmark,omitempty"`
+	Portfolio    RiskMetrics `json:"portfolio"`
+	Assets       []AssetRisk `json:"assets"`
+}
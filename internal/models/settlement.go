@@ -0,0 +1,39 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// SettlementCycle is the number of business days after the trade date on
+// which trades settle, by instrument type. Types without their own cycle
+// use Default. ByType is keyed by the upper-cased type. Cash movements
+// always settle on the trade date.
+type SettlementCycle struct {
+	Default int
+	ByType  map[string]int
+}
+
+// DefaultSettlementCycle settles every trade on T+1
+var DefaultSettlementCycle = SettlementCycle{Default: 1}
+
+// Days returns the settlement cycle of the instrument type
+func (c SettlementCycle) Days(instrumentType string) int {
+	if days, ok := c.ByType[strings.ToUpper(instrumentType)]; ok {
+		return days
+	}
+	return c.Default
+}
+
+// SettlementDate returns when a trade of the instrument type made at
+// tradeDate settles, counting only weekdays
+func (c SettlementCycle) SettlementDate(instrumentType string, tradeDate time.Time) time.Time {
+	date := tradeDate
+	for days := c.Days(instrumentType); days > 0; {
+		date = date.AddDate(0, 0, 1)
+		if date.Weekday() != time.Saturday && date.Weekday() != time.Sunday {
+			days--
+		}
+	}
+	return date
+}
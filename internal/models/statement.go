@@ -0,0 +1,60 @@
+package models
+
+import (
+	"time"
+)
+
+const (
+	MonthlyStatement   = "MONTHLY"
+	QuarterlyStatement = "QUARTERLY"
+)
+
+// Statement is a generated account statement as stored in the history.
+// Content holds the AccountStatement serialized as JSON.
+type Statement struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	UserID      uint      `gorm:"column:userid" json:"userId"`
+	PeriodType  string    `gorm:"column:periodtype" json:"periodType"`
+	PeriodStart time.Time `gorm:"column:periodstart" json:"periodStart"`
+	PeriodEnd   time.Time `gorm:"column:periodend" json:"periodEnd"`
+	GeneratedAt time.Time `gorm:"column:generatedat" json:"generatedAt"`
+	Content     string    `gorm:"column:content" json:"-"`
+}
+
+// TableName especifica el nombre de la tabla para GORM
+func (Statement) TableName() string {
+	return "statements"
+}
+
+type StatementTrade struct {
+	Date     time.Time `json:"date"`
+	Ticker   string    `json:"ticker"`
+	Side     string    `json:"side"`
+	Quantity float64   `json:"quantity"`
+	Price    float64   `json:"price"`
+	Amount   float64   `json:"amount"`
+	Currency string    `json:"currency"`
+}
+
+type AccountStatement struct {
+	ID            uint               `json:"id"`
+	UserID        uint               `json:"userId"`
+	AccountNumber string             `json:"accountNumber"`
+	PeriodType    string             `json:"periodType"`
+	PeriodStart   time.Time          `json:"periodStart"`
+	PeriodEnd     time.Time          `json:"periodEnd"`
+	GeneratedAt   time.Time          `json:"generatedAt"`
+	BaseCurrency  string             `json:"baseCurrency"`
+	OpeningCash   map[string]float64 `json:"openingCash"`
+	ClosingCash   map[string]float64 `json:"closingCash"`
+	Deposits      map[string]float64 `json:"deposits"`
+	Withdrawals   map[string]float64 `json:"withdrawals"`
+	TransfersIn   map[string]float64 `json:"transfersIn"`
+	TransfersOut  map[string]float64 `json:"transfersOut"`
+	Income        map[string]float64 `json:"income"`
+	Fees          map[string]float64 `json:"fees"`
+	Trades        []StatementTrade   `json:"trades"`
+	Holdings      []PortfolioAsset   `json:"holdings"`
+	TotalValue    float64            `json:"totalValue"`
+	Performance   *Performance       `json:"performance"`
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+)
+
+const (
+	CashTransfer       = "CASH"
+	SecuritiesTransfer = "SECURITIES"
+)
+
+// Transfer moves cash, or securities in kind, from an account of one user
+// to an account of the same or another user. It is applied as a debit order
+// (TRANSFER_OUT or SECURITIES_OUT) on the source and a credit order
+// (TRANSFER_IN or SECURITIES_IN) on the destination, both linked to it.
+// Securities carry the source account's average cost in Price.
+type Transfer struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	Kind          string    `gorm:"column:kind" json:"kind"`
+	FromUserID    uint      `gorm:"column:fromuserid" json:"fromUserId"`
+	FromAccountID uint      `gorm:"column:fromaccountid" json:"fromAccountId"`
+	ToUserID      uint      `gorm:"column:touserid" json:"toUserId"`
+	ToAccountID   uint      `gorm:"column:toaccountid" json:"toAccountId"`
+	Currency      string    `gorm:"column:currency" json:"currency"`
+	Amount        float64   `gorm:"column:amount" json:"amount,omitempty"`
+	InstrumentID  uint      `gorm:"column:instrumentid" json:"instrumentId,omitempty"`
+	Quantity      float64   `gorm:"column:quantity" json:"quantity,omitempty"`
+	Price         float64   `gorm:"column:price" json:"price,omitempty"`
+	Note          string    `gorm:"column:note" json:"note,omitempty"`
+	DebitOrderID  uint      `gorm:"column:debitorderid" json:"debitOrderId"`
+	CreditOrderID uint      `gorm:"column:creditorderid" json:"creditOrderId"`
+	CreatedAt     time.Time `gorm:"column:createdat" json:"createdAt"`
+}
+
+// TableName especifica el nombre de la tabla para GORM
+func (Transfer) TableName() string {
+	return "transfers"
+}
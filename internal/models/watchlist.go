@@ -0,0 +1,58 @@
+package models
+
+import (
+	"time"
+)
+
+// MaxWatchlistSize is the most instruments a single watchlist can follow
+const MaxWatchlistSize = 50
+
+// Watchlist is a named list of instruments a user follows without holding
+// them. InstrumentIDs keeps the order in which the user arranged the list.
+type Watchlist struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	UserID        uint      `gorm:"column:userid" json:"userId"`
+	Name          string    `gorm:"column:name" json:"name"`
+	CreatedAt     time.Time `gorm:"column:createdat" json:"createdAt"`
+	InstrumentIDs []uint    `gorm:"-" json:"instrumentIds"`
+}
+
+// TableName especifica el nombre de la tabla para GORM
+func (Watchlist) TableName() string {
+	return "watchlists"
+}
+
+// WatchlistItem is an instrument in a watchlist, at the given position
+type WatchlistItem struct {
+	WatchlistID  uint `gorm:"primaryKey;autoIncrement:false;column:watchlistid"`
+	InstrumentID uint `gorm:"primaryKey;autoIncrement:false;column:instrumentid"`
+	Position     int  `gorm:"column:position"`
+}
+
+// TableName especifica el nombre de la tabla para GORM
+func (WatchlistItem) TableName() string {
+	return "watchlistitems"
+}
+
+// WatchlistQuote is the latest price of a watched instrument. PriceTime is
+// nil when the instrument has no market data yet.
+type WatchlistQuote struct {
+	InstrumentID     uint       `json:"instrumentId"`
+	Ticker           string     `json:"ticker"`
+	Name             string     `json:"name"`
+	Currency         string     `json:"currency"`
+	Close            float64    `json:"close"`
+	PreviousClose    float64    `json:"previousClose"`
+	DayChange        float64    `json:"dayChange"`
+	DayChangePercent float64    `json:"dayChangePercent"`
+	PriceTime        *time.Time `json:"priceTime"`
+}
+
+type WatchlistSnapshot struct {
+	ID     uint             `json:"id"`
+	UserID uint             `json:"userId"`
+	Name   string           `json:"name"`
+	SortBy string           `json:"sortBy"`
+	Order  string           `json:"order"`
+	Quotes []WatchlistQuote `json:"quotes"`
+}
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+)
+
+// PendingApprovalStatus is the status of a CASH_OUT order held until an
+// admin approves or rejects it. Its amount stays held in the account and
+// cannot be spent or withdrawn meanwhile.
+const PendingApprovalStatus = "PENDING_APPROVAL"
+
+// Withdrawal audit actions
+const (
+	WithdrawalRequested = "REQUESTED"
+	WithdrawalApproved  = "APPROVED"
+	WithdrawalRejected  = "REJECTED"
+)
+
+// WithdrawalAudit is an entry of the audit trail of a withdrawal held for
+// approval: the rules that flagged it when it was requested, and the
+// reviewer and note of the decision on it
+type WithdrawalAudit struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	OrderID   uint      `gorm:"column:orderid" json:"orderId"`
+	UserID    uint      `gorm:"column:userid" json:"userId"`
+	Action    string    `gorm:"column:action" json:"action"`
+	Amount    float64   `gorm:"column:amount" json:"amount"`
+	Currency  string    `gorm:"column:currency" json:"currency"`
+	Reviewer  string    `gorm:"column:reviewer" json:"reviewer,omitempty"`
+	Note      string    `gorm:"column:note" json:"note,omitempty"`
+	CreatedAt time.Time `gorm:"column:createdat" json:"createdAt"`
+}
+
+// TableName especifica el nombre de la tabla para GORM
+func (WithdrawalAudit) TableName() string {
+	return "withdrawalaudits"
+}
@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"github.com/NahuelDT/portfolio-api/internal/models"
+	"gorm.io/gorm"
+)
+
+type AccountRepository struct {
+	db *gorm.DB
+}
+
+func NewAccountRepository(db *gorm.DB) *AccountRepository {
+	return &AccountRepository{db: db}
+}
+
+func (r *AccountRepository) Create(account *models.Account) error {
+	return r.db.Create(account).Error
+}
+
+// GetByID retrieves an account by its ID
+func (r *AccountRepository) GetByID(id uint) (*models.Account, error) {
+	var account models.Account
+	result := r.db.First(&account, id)
+	return &account, result.Error
+}
+
+// GetByUser retrieves the user's sub-accounts, oldest first
+func (r *AccountRepository) GetByUser(userID uint) ([]models.Account, error) {
+	var accounts []models.Account
+	result := r.db.Where("userid = ?", userID).Order("createdat ASC, id ASC").Find(&accounts)
+	return accounts, result.Error
+}
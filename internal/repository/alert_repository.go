@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/NahuelDT/portfolio-api/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type AlertRepository struct {
+	db *gorm.DB
+}
+
+func NewAlertRepository(db *gorm.DB) *AlertRepository {
+	return &AlertRepository{db: db}
+}
+
+func (r *AlertRepository) Create(alert *models.PriceAlert) error {
+	return r.db.Create(alert).Error
+}
+
+// GetByID retrieves an alert by its ID
+func (r *AlertRepository) GetByID(id uint) (*models.PriceAlert, error) {
+	var alert models.PriceAlert
+	result := r.db.First(&alert, id)
+	return &alert, result.Error
+}
+
+// GetByUser retrieves the user's alerts, newest first
+func (r *AlertRepository) GetByUser(userID uint) ([]models.PriceAlert, error) {
+	var alerts []models.PriceAlert
+	result := r.db.Where("userid = ?", userID).Order("createdat DESC, id DESC").Find(&alerts)
+	return alerts, result.Error
+}
+
+// GetActiveByInstrument retrieves the alerts of an instrument that have not
+// triggered yet
+func (r *AlertRepository) GetActiveByInstrument(instrumentID uint) ([]models.PriceAlert, error) {
+	var alerts []models.PriceAlert
+	result := r.db.Where("instrumentid = ? AND active", instrumentID).Order("id ASC").Find(&alerts)
+	return alerts, result.Error
+}
+
+// Delete removes one of the user's alerts. It returns
+// gorm.ErrRecordNotFound if the user has no alert with that ID.
+func (r *AlertRepository) Delete(userID, id uint) error {
+	result := r.db.Where("id = ? AND userid = ?", id, userID).Delete(&models.PriceAlert{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// RecordTrigger deactivates the alert and stores its trigger in a single
+// transaction. It reports false without storing anything if the alert was
+// already triggered, so concurrent prices cannot trigger it twice.
+func (r *AlertRepository) RecordTrigger(alert *models.PriceAlert, trigger *models.AlertTrigger) (bool, error) {
+	recorded := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.PriceAlert{}).
+			Where("id = ? AND active", alert.ID).
+			Updates(map[string]interface{}{"active": false, "triggeredat": trigger.TriggeredAt})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		if err := tx.Create(trigger).Error; err != nil {
+			return err
+		}
+		recorded = true
+		return nil
+	})
+	if recorded {
+		alert.Active = false
+		alert.TriggeredAt = &trigger.TriggeredAt
+	}
+	return recorded, err
+}
+
+// GetTriggersByUser retrieves the user's triggered alerts, newest first
+func (r *AlertRepository) GetTriggersByUser(userID uint) ([]models.AlertTrigger, error) {
+	var triggers []models.AlertTrigger
+	result := r.db.Where("userid = ?", userID).Order("triggeredat DESC, id DESC").Find(&triggers)
+	return triggers, result.Error
+}
+
+// ClaimDueTriggers retrieves the undelivered triggers of users with a
+// webhook whose next attempt is due at now and that have been attempted
+// fewer than maxAttempts times, oldest first. Their next attempt is pushed
+// back by lease in the same transaction, so other workers skip them while
+// they are being delivered.
+func (r *AlertRepository) ClaimDueTriggers(maxAttempts int, now time.Time, lease time.Duration) ([]models.AlertTrigger, error) {
+	var triggers []models.AlertTrigger
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("deliveredat IS NULL AND nextattemptat <= ? AND attempts < ?", now, maxAttempts).
+			Where("userid IN (?)", tx.Model(&models.AlertWebhook{}).Select("userid")).
+			Order("triggeredat ASC, id ASC").
+			Find(&triggers)
+		if result.Error != nil || len(triggers) == 0 {
+			return result.Error
+		}
+
+		ids := make([]uint, len(triggers))
+		for i := range triggers {
+			ids[i] = triggers[i].ID
+		}
+		return tx.Model(&models.AlertTrigger{}).
+			Where("id IN ?", ids).
+			Update("nextattemptat", now.Add(lease)).Error
+	})
+	return triggers, err
+}
+
+// UpdateDelivery stores the outcome of delivering a trigger
+func (r *AlertRepository) UpdateDelivery(trigger *models.AlertTrigger) error {
+	return r.db.Model(trigger).Updates(map[string]interface{}{
+		"attempts":      trigger.Attempts,
+		"deliveredat":   trigger.DeliveredAt,
+		"nextattemptat": trigger.NextAttemptAt,
+		"lasterror":     trigger.LastError,
+	}).Error
+}
+
+// GetWebhook retrieves the user's webhook, or one without URL if the user
+// has not configured it
+func (r *AlertRepository) GetWebhook(userID uint) (*models.AlertWebhook, error) {
+	webhook := models.AlertWebhook{UserID: userID}
+	result := r.db.Where("userid = ?", userID).Limit(1).Find(&webhook)
+	return &webhook, result.Error
+}
+
+// SaveWebhook creates or replaces the user's webhook
+func (r *AlertRepository) SaveWebhook(webhook *models.AlertWebhook) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "userid"}},
+		DoUpdates: clause.AssignmentColumns([]string{"url", "secret", "updatedat"}),
+	}).Create(webhook).Error
+}
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/NahuelDT/portfolio-api/internal/models"
+	"gorm.io/gorm"
+)
+
+type CorporateActionRepository struct {
+	db *gorm.DB
+}
+
+func NewCorporateActionRepository(db *gorm.DB) *CorporateActionRepository {
+	return &CorporateActionRepository{db: db}
+}
+
+func (r *CorporateActionRepository) Create(action *models.CorporateAction) error {
+	return r.db.Create(action).Error
+}
+
+// CreateTickerChange stores a ticker change and renames the instrument in a
+// single transaction
+func (r *CorporateActionRepository) CreateTickerChange(action *models.CorporateAction) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(action).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.Instrument{}).
+			Where("id = ?", action.InstrumentID).
+			Update("ticker", action.NewTicker).Error
+	})
+}
+
+// GetUnapplied retrieves the corporate actions effective at or before the
+// given time whose positions have not been restated yet, oldest first
+func (r *CorporateActionRepository) GetUnapplied(asOf time.Time) ([]models.CorporateAction, error) {
+	var actions []models.CorporateAction
+	result := r.db.Where("appliedat IS NULL AND effectivedate <= ?", asOf).
+		Order("effectivedate ASC, id ASC").
+		Find(&actions)
+	return actions, result.Error
+}
+
+// MarkApplied records that the positions affected by the action have been
+// restated
+func (r *CorporateActionRepository) MarkApplied(action *models.CorporateAction) error {
+	now := time.Now()
+	if err := r.db.Model(action).Update("appliedat", now).Error; err != nil {
+		return err
+	}
+	action.AppliedAt = &now
+	return nil
+}
+
+// GetEffectiveAsOf retrieves the corporate actions effective at or before
+// the given time, oldest first
+func (r *CorporateActionRepository) GetEffectiveAsOf(asOf time.Time) ([]models.CorporateAction, error) {
+	var actions []models.CorporateAction
+	result := r.db.Where("effectivedate <= ?", asOf).
+		Order("effectivedate ASC, id ASC").
+		Find(&actions)
+	return actions, result.Error
+}
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/NahuelDT/portfolio-api/internal/models"
+	"gorm.io/gorm"
+)
+
+type DistributionRepository struct {
+	db *gorm.DB
+}
+
+func NewDistributionRepository(db *gorm.DB) *DistributionRepository {
+	return &DistributionRepository{db: db}
+}
+
+func (r *DistributionRepository) Create(distribution *models.Distribution) error {
+	return r.db.Create(distribution).Error
+}
+
+// GetUncredited retrieves the distributions whose ex-date has been reached
+// but whose holders have not been credited yet
+func (r *DistributionRepository) GetUncredited(asOf time.Time) ([]models.Distribution, error) {
+	var distributions []models.Distribution
+	result := r.db.Where("creditedat IS NULL AND exdate <= ?", asOf).
+		Order("exdate ASC").
+		Find(&distributions)
+	return distributions, result.Error
+}
+
+// CreateCredited stores a distribution whose ex-date has been reached
+// together with its credit orders in a single transaction, applying the
+// FILLED ones to the cash balances and marking it as credited
+func (r *DistributionRepository) CreateCredited(distribution *models.Distribution, credits []models.Order) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(distribution).Error; err != nil {
+			return err
+		}
+		return creditHolders(tx, distribution, credits)
+	})
+}
+
+// CreditHolders stores the credit orders of a distribution, applies the
+// FILLED ones to the cash balances and marks it as credited in a single
+// transaction
+func (r *DistributionRepository) CreditHolders(distribution *models.Distribution, credits []models.Order) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return creditHolders(tx, distribution, credits)
+	})
+}
+
+func creditHolders(tx *gorm.DB, distribution *models.Distribution, credits []models.Order) error {
+	if len(credits) > 0 {
+		if err := tx.Create(&credits).Error; err != nil {
+			return err
+		}
+	}
+	for i := range credits {
+		if credits[i].Status == "FILLED" {
+			if err := applyFill(tx, &credits[i]); err != nil {
+				return err
+			}
+		}
+	}
+	now := time.Now()
+	result := tx.Model(&models.Distribution{}).
+		Where("id = ? AND creditedat IS NULL", distribution.ID).
+		Update("creditedat", now)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	distribution.CreditedAt = &now
+	return nil
+}
@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/NahuelDT/portfolio-api/internal/models"
+	"gorm.io/gorm"
+)
+
+type FXRateRepository struct {
+	db *gorm.DB
+}
+
+func NewFXRateRepository(db *gorm.DB) *FXRateRepository {
+	return &FXRateRepository{db: db}
+}
+
+// GetLatestRate retrieves the latest rate for the given currency pair
+func (r *FXRateRepository) GetLatestRate(baseCurrency, quoteCurrency string) (*models.FXRate, error) {
+	var rate models.FXRate
+	result := r.db.Where("basecurrency = ? AND quotecurrency = ?", baseCurrency, quoteCurrency).
+		Order("date DESC").
+		First(&rate)
+	return &rate, result.Error
+}
+
+// GetRateAsOf retrieves the latest rate for the given currency pair at or
+// before the given time
+func (r *FXRateRepository) GetRateAsOf(baseCurrency, quoteCurrency string, asOf time.Time) (*models.FXRate, error) {
+	var rate models.FXRate
+	result := r.db.Where("basecurrency = ? AND quotecurrency = ? AND date <= ?", baseCurrency, quoteCurrency, asOf).
+		Order("date DESC").
+		First(&rate)
+	return &rate, result.Error
+}
+
+// GetRateHistory retrieves every rate for the given currency pair up to the
+// given time, oldest first
+func (r *FXRateRepository) GetRateHistory(baseCurrency, quoteCurrency string, to time.Time) ([]models.FXRate, error) {
+	var rates []models.FXRate
+	result := r.db.Where("basecurrency = ? AND quotecurrency = ? AND date <= ?", baseCurrency, quoteCurrency, to).
+		Order("date ASC").
+		Find(&rates)
+	return rates, result.Error
+}
+
+// Create stores a rate for a currency pair
+func (r *FXRateRepository) Create(rate *models.FXRate) error {
+	return r.db.Create(rate).Error
+}
@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"strings"
+
 	"github.com/NahuelDT/portfolio-api/internal/models"
 	"gorm.io/gorm"
 )
@@ -20,6 +22,46 @@ func (r *InstrumentRepository) GetByID(id uint) (*models.Instrument, error) {
 	return &instrument, result.Error
 }
 
+// GetByIDs retrieves the instruments with the given IDs in a single query,
+// keyed by ID. IDs that do not exist are left out.
+func (r *InstrumentRepository) GetByIDs(ids []uint) (map[uint]*models.Instrument, error) {
+	instruments := make(map[uint]*models.Instrument, len(ids))
+	if len(ids) == 0 {
+		return instruments, nil
+	}
+
+	var rows []models.Instrument
+	if err := r.db.Where("id IN ?", ids).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	for i := range rows {
+		instruments[rows[i].ID] = &rows[i]
+	}
+	return instruments, nil
+}
+
+// GetByTickers retrieves the instruments with the given tickers in a single
+// query, keyed by upper-cased ticker. Tickers that do not exist are left out.
+func (r *InstrumentRepository) GetByTickers(tickers []string) (map[string]*models.Instrument, error) {
+	instruments := make(map[string]*models.Instrument, len(tickers))
+	if len(tickers) == 0 {
+		return instruments, nil
+	}
+
+	upper := make([]string, len(tickers))
+	for i, ticker := range tickers {
+		upper[i] = strings.ToUpper(ticker)
+	}
+	var rows []models.Instrument
+	if err := r.db.Where("UPPER(ticker) IN ?", upper).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	for i := range rows {
+		instruments[strings.ToUpper(rows[i].Ticker)] = &rows[i]
+	}
+	return instruments, nil
+}
+
 // Search performs a general search on instruments based on ticker or name
 func (r *InstrumentRepository) Search(query string) ([]models.Instrument, error) {
 	var instruments []models.Instrument
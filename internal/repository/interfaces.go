@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"time"
+
 	"github.com/NahuelDT/portfolio-api/internal/models"
 )
 
@@ -9,21 +11,122 @@ type UserRepositorer interface {
 	Create(user *models.User) error
 }
 
+type AccountRepositorer interface {
+	Create(account *models.Account) error
+	GetByID(id uint) (*models.Account, error)
+	GetByUser(userID uint) ([]models.Account, error)
+}
+
 type OrderRepositorer interface {
 	Create(order *models.Order) error
 	GetByID(id uint) (*models.Order, error)
 	UpdateStatus(orderID uint, status string) error
 	GetUserFilledOrders(userID uint) ([]models.Order, error)
-	GetUserCashBalance(userID uint) (float64, error)
+	GetUserFilledOrdersAsOf(userID uint, asOf time.Time) ([]models.Order, error)
+	GetUserInstrumentOrders(userID uint, instrumentIDs []uint, since time.Time) ([]models.Order, error)
+	GetUserIDs() ([]uint, error)
+	GetInstrumentOrdersBefore(instrumentIDs []uint, before time.Time) ([]models.Order, error)
+	GetUnsettledCash(userID uint, asOf time.Time) ([]models.CashBalance, error)
+	FillPendingOrders(side string, until time.Time) (int64, error)
+}
+
+type TransferRepositorer interface {
+	Create(transfer *models.Transfer, debit, credit *models.Order) error
+	GetByUser(userID uint) ([]models.Transfer, error)
+}
+
+type LedgerRepositorer interface {
+	GetCashBalance(userID uint, currency string) (float64, error)
+	GetCashBalancesAsOf(userID uint, asOf time.Time) (map[string]float64, error)
+	GetUserEntries(userID uint, from, to time.Time) ([]models.JournalEntry, error)
+	GetTrialBalance() ([]models.TrialBalanceLine, error)
+	GetUnbalancedEntries(tolerance float64) ([]uint, error)
+	GetCashMismatches(tolerance float64) ([]models.BalanceMismatch, error)
+	RebuildUserEntries(userID uint, replay func(orders []models.Order) []models.JournalEntry) error
+}
+
+type WithdrawalRepositorer interface {
+	Hold(order *models.Order, audit *models.WithdrawalAudit) error
+	Approve(orderID uint, at time.Time, audit *models.WithdrawalAudit) (*models.Order, error)
+	Reject(orderID uint, audit *models.WithdrawalAudit) (*models.Order, error)
+	GetPending() ([]models.Order, error)
+	GetAuditTrail(orderID uint) ([]models.WithdrawalAudit, error)
+	GetHeldCash(userID uint) ([]models.CashBalance, error)
 }
 
 type InstrumentRepositorer interface {
 	GetByID(id uint) (*models.Instrument, error)
+	GetByIDs(ids []uint) (map[uint]*models.Instrument, error)
+	GetByTickers(tickers []string) (map[string]*models.Instrument, error)
 	Search(query string) ([]models.Instrument, error)
 	Create(instrument *models.Instrument) error
 }
 
+type FXRateRepositorer interface {
+	GetLatestRate(baseCurrency, quoteCurrency string) (*models.FXRate, error)
+	GetRateAsOf(baseCurrency, quoteCurrency string, asOf time.Time) (*models.FXRate, error)
+	GetRateHistory(baseCurrency, quoteCurrency string, to time.Time) ([]models.FXRate, error)
+	Create(rate *models.FXRate) error
+}
+
 type MarketDataRepositorer interface {
 	GetLatestMarketData(instrumentID uint) (*models.MarketData, error)
+	GetLatestMarketDataForInstruments(instrumentIDs []uint) (map[uint]*models.MarketData, error)
+	GetLatestMarketDataAsOf(instrumentID uint, asOf time.Time) (*models.MarketData, error)
+	GetMarketDataRange(instrumentID uint, from, to time.Time) ([]models.MarketData, error)
+	GetCandles(instrumentID uint, unit string, from, to time.Time, limit int) ([]models.Candle, error)
 	Create(marketData *models.MarketData) error
+	Upsert(marketData []models.MarketData) error
+}
+
+type StatementRepositorer interface {
+	Create(statement *models.Statement) error
+	GetByID(id uint) (*models.Statement, error)
+	GetByUser(userID uint) ([]models.Statement, error)
+}
+
+type DistributionRepositorer interface {
+	Create(distribution *models.Distribution) error
+	GetUncredited(asOf time.Time) ([]models.Distribution, error)
+	CreateCredited(distribution *models.Distribution, credits []models.Order) error
+	CreditHolders(distribution *models.Distribution, credits []models.Order) error
+}
+
+type CorporateActionRepositorer interface {
+	Create(action *models.CorporateAction) error
+	CreateTickerChange(action *models.CorporateAction) error
+	GetEffectiveAsOf(asOf time.Time) ([]models.CorporateAction, error)
+	GetUnapplied(asOf time.Time) ([]models.CorporateAction, error)
+	MarkApplied(action *models.CorporateAction) error
+}
+
+type PositionRepositorer interface {
+	GetUserPositions(userID uint) ([]models.Position, error)
+	GetAccountPosition(userID, accountID, instrumentID uint) (*models.Position, error)
+	GetAccountCashBalance(userID, accountID uint, currency string) (float64, error)
+	GetUserCashBalances(userID uint) ([]models.CashBalance, error)
+	GetHolderIDs(instrumentID uint) ([]uint, error)
+	RebuildUserBalances(userID uint, replay func(orders []models.Order) ([]models.Position, []models.CashBalance)) error
+}
+
+type WatchlistRepositorer interface {
+	Create(watchlist *models.Watchlist) error
+	GetByID(id uint) (*models.Watchlist, error)
+	GetByUser(userID uint) ([]models.Watchlist, error)
+	Update(watchlist *models.Watchlist) error
+	Delete(id uint) error
+}
+
+type AlertRepositorer interface {
+	Create(alert *models.PriceAlert) error
+	GetByID(id uint) (*models.PriceAlert, error)
+	GetByUser(userID uint) ([]models.PriceAlert, error)
+	GetActiveByInstrument(instrumentID uint) ([]models.PriceAlert, error)
+	Delete(userID, id uint) error
+	RecordTrigger(alert *models.PriceAlert, trigger *models.AlertTrigger) (bool, error)
+	GetTriggersByUser(userID uint) ([]models.AlertTrigger, error)
+	ClaimDueTriggers(maxAttempts int, now time.Time, lease time.Duration) ([]models.AlertTrigger, error)
+	UpdateDelivery(trigger *models.AlertTrigger) error
+	GetWebhook(userID uint) (*models.AlertWebhook, error)
+	SaveWebhook(webhook *models.AlertWebhook) error
 }
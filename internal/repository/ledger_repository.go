@@ -0,0 +1,212 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/NahuelDT/portfolio-api/internal/models"
+	"gorm.io/gorm"
+)
+
+type LedgerRepository struct {
+	db *gorm.DB
+}
+
+func NewLedgerRepository(db *gorm.DB) *LedgerRepository {
+	return &LedgerRepository{db: db}
+}
+
+// GetCashBalance sums the user's cash postings in the given currency across
+// all their accounts
+func (r *LedgerRepository) GetCashBalance(userID uint, currency string) (float64, error) {
+	var result struct {
+		Balance float64
+	}
+
+	err := r.db.Model(&models.LedgerPosting{}).
+		Select("COALESCE(SUM(amount), 0) as balance").
+		Where("userid = ? AND ledgeraccount = ? AND currency = ?", userID, models.LedgerCash, models.NormalizeCurrency(currency)).
+		Scan(&result).Error
+
+	return result.Balance, err
+}
+
+// GetCashBalancesAsOf sums the user's cash postings in each currency up to
+// the given time
+func (r *LedgerRepository) GetCashBalancesAsOf(userID uint, asOf time.Time) (map[string]float64, error) {
+	var rows []struct {
+		Currency string
+		Balance  float64
+	}
+
+	err := r.db.Model(&models.LedgerPosting{}).
+		Select("currency, SUM(amount) as balance").
+		Where("userid = ? AND ledgeraccount = ? AND postedat <= ?", userID, models.LedgerCash, asOf).
+		Group("currency").
+		Scan(&rows).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	balances := make(map[string]float64, len(rows))
+	for _, row := range rows {
+		balances[row.Currency] = row.Balance
+	}
+	return balances, nil
+}
+
+// GetUserEntries retrieves the user's journal entries posted between from
+// and to with their postings, oldest first
+func (r *LedgerRepository) GetUserEntries(userID uint, from, to time.Time) ([]models.JournalEntry, error) {
+	var entries []models.JournalEntry
+	result := r.db.Preload("Postings", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id ASC")
+	}).
+		Where("userid = ? AND postedat BETWEEN ? AND ?", userID, from, to).
+		Order("postedat ASC, id ASC").
+		Find(&entries)
+	return entries, result.Error
+}
+
+// GetTrialBalance adds up the debits and credits of every ledger account in
+// each currency
+func (r *LedgerRepository) GetTrialBalance() ([]models.TrialBalanceLine, error) {
+	var lines []models.TrialBalanceLine
+	result := r.db.Model(&models.LedgerPosting{}).
+		Select("ledgeraccount, currency, " +
+			"SUM(CASE WHEN amount > 0 THEN amount ELSE 0 END) as debits, " +
+			"SUM(CASE WHEN amount < 0 THEN -amount ELSE 0 END) as credits, " +
+			"SUM(amount) as balance").
+		Group("ledgeraccount, currency").
+		Order("currency ASC, ledgeraccount ASC").
+		Scan(&lines)
+	return lines, result.Error
+}
+
+// GetUnbalancedEntries retrieves the IDs of the journal entries whose
+// postings in some currency do not add up to zero within the tolerance
+func (r *LedgerRepository) GetUnbalancedEntries(tolerance float64) ([]uint, error) {
+	var entryIDs []uint
+	result := r.db.Model(&models.LedgerPosting{}).
+		Group("entryid, currency").
+		Having("ABS(SUM(amount)) > ?", tolerance).
+		Distinct("entryid").
+		Order("entryid ASC").
+		Pluck("entryid", &entryIDs)
+	return entryIDs, result.Error
+}
+
+// GetCashMismatches compares the materialized cash balance of every user's
+// account with the sum of its cash postings, reporting the ones that differ
+// by more than the tolerance
+func (r *LedgerRepository) GetCashMismatches(tolerance float64) ([]models.BalanceMismatch, error) {
+	var rows []struct {
+		UserID    uint    `gorm:"column:userid"`
+		AccountID uint    `gorm:"column:accountid"`
+		Currency  string  `gorm:"column:currency"`
+		Stored    float64 `gorm:"column:stored"`
+		Expected  float64 `gorm:"column:expected"`
+	}
+
+	err := r.db.Raw(`SELECT COALESCE(c.userid, l.userid) AS userid,
+			COALESCE(c.accountid, l.accountid) AS accountid,
+			COALESCE(c.currency, l.currency) AS currency,
+			COALESCE(c.balance, 0) AS stored,
+			COALESCE(l.balance, 0) AS expected
+		FROM cashbalances c
+		FULL OUTER JOIN (
+			SELECT userid, accountid, currency, SUM(amount) AS balance
+			FROM ledgerpostings
+			WHERE ledgeraccount = ?
+			GROUP BY userid, accountid, currency
+		) l ON l.userid = c.userid AND l.accountid = c.accountid AND l.currency = c.currency
+		WHERE ABS(COALESCE(c.balance, 0) - COALESCE(l.balance, 0)) > ?
+		ORDER BY 1, 2, 3`, models.LedgerCash, tolerance).
+		Scan(&rows).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	mismatches := make([]models.BalanceMismatch, 0, len(rows))
+	for _, row := range rows {
+		mismatches = append(mismatches, models.BalanceMismatch{
+			UserID:    row.UserID,
+			AccountID: row.AccountID,
+			Kind:      models.CashMismatch,
+			Key:       row.Currency,
+			Field:     "balance",
+			Stored:    row.Stored,
+			Expected:  row.Expected,
+		})
+	}
+	return mismatches, nil
+}
+
+// RebuildUserEntries swaps all the user's journal entries for the ones
+// replay builds from the user's FILLED orders, in a single transaction and
+// under the user's lock, so fills made meanwhile are not lost
+func (r *LedgerRepository) RebuildUserEntries(userID uint, replay func(orders []models.Order) []models.JournalEntry) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockUsers(tx, userID); err != nil {
+			return err
+		}
+		var orders []models.Order
+		if err := tx.Where("userid = ? AND status = ?", userID, "FILLED").Find(&orders).Error; err != nil {
+			return err
+		}
+		entries := replay(orders)
+
+		if err := tx.Where("userid = ?", userID).Delete(&models.LedgerPosting{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("userid = ?", userID).Delete(&models.JournalEntry{}).Error; err != nil {
+			return err
+		}
+		if len(entries) > 0 {
+			return tx.Create(&entries).Error
+		}
+		return nil
+	})
+}
+
+// ledgerCash sums the cash postings of a user's account in the given
+// currency, inside the caller's transaction. Under the user's lock no fill
+// can post to the account until the transaction ends, so the sum is the
+// balance the caller's checks can rely on.
+func ledgerCash(tx *gorm.DB, userID, accountID uint, currency string) (float64, error) {
+	var result struct {
+		Balance float64
+	}
+	err := tx.Model(&models.LedgerPosting{}).
+		Select("COALESCE(SUM(amount), 0) as balance").
+		Where("userid = ? AND accountid = ? AND ledgeraccount = ? AND currency = ?",
+			userID, accountID, models.LedgerCash, models.NormalizeCurrency(currency)).
+		Scan(&result).Error
+	return result.Balance, err
+}
+
+// postJournalEntry records the journal entry of a filled order inside the
+// caller's transaction. It runs before the fill touches the position, so a
+// SELL takes out the securities at the average cost they had until then.
+func postJournalEntry(tx *gorm.DB, order *models.Order) error {
+	averageCost := 0.0
+	if order.Side == "SELL" {
+		var position models.Position
+		err := tx.Where("userid = ? AND accountid = ? AND instrumentid = ?", order.UserID, order.AccountID, order.InstrumentID).
+			Limit(1).
+			Find(&position).Error
+		if err != nil {
+			return err
+		}
+		if position.BoughtQuantity > 0 {
+			averageCost = position.BoughtCost / position.BoughtQuantity
+		}
+	}
+
+	entry := models.NewJournalEntry(order, averageCost)
+	if entry == nil {
+		return nil
+	}
+	return tx.Create(entry).Error
+}
@@ -1,10 +1,22 @@
 package repository
 
 import (
+	"errors"
+	"time"
+
 	"github.com/NahuelDT/portfolio-api/internal/models"
+	"github.com/jackc/pgx/v5/pgconn"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// ErrDuplicateMarketData is returned when creating a price for an instrument
+// that already has one on the same date
+var ErrDuplicateMarketData = errors.New("instrument already has market data on that date")
+
+// uniqueViolation is the Postgres error code of a unique index violation
+const uniqueViolation = "23505"
+
 type MarketDataRepository struct {
 	db *gorm.DB
 }
@@ -22,6 +34,90 @@ func (r *MarketDataRepository) GetLatestMarketData(instrumentID uint) (*models.M
 	return &marketData, result.Error
 }
 
+// GetLatestMarketDataForInstruments retrieves the latest market data of each
+// of the given instruments in a single DISTINCT ON query, keyed by
+// instrument ID. Instruments without market data are left out.
+func (r *MarketDataRepository) GetLatestMarketDataForInstruments(instrumentIDs []uint) (map[uint]*models.MarketData, error) {
+	marketData := make(map[uint]*models.MarketData, len(instrumentIDs))
+	if len(instrumentIDs) == 0 {
+		return marketData, nil
+	}
+
+	var rows []models.MarketData
+	err := r.db.Select("DISTINCT ON (instrumentid) *").
+		Where("instrumentid IN ?", instrumentIDs).
+		Order("instrumentid, date DESC").
+		Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for i := range rows {
+		marketData[rows[i].InstrumentID] = &rows[i]
+	}
+	return marketData, nil
+}
+
+// GetLatestMarketDataAsOf retrieves the latest market data for a given
+// instrument at or before the given time
+func (r *MarketDataRepository) GetLatestMarketDataAsOf(instrumentID uint, asOf time.Time) (*models.MarketData, error) {
+	var marketData models.MarketData
+	result := r.db.Where("instrumentid = ? AND date <= ?", instrumentID, asOf).
+		Order("date DESC").
+		First(&marketData)
+	return &marketData, result.Error
+}
+
+// GetMarketDataRange retrieves the market data for a given instrument between
+// from and to (both inclusive), oldest first. A zero from has no lower bound.
+func (r *MarketDataRepository) GetMarketDataRange(instrumentID uint, from, to time.Time) ([]models.MarketData, error) {
+	var marketData []models.MarketData
+	query := r.db.Where("instrumentid = ? AND date <= ?", instrumentID, to)
+	if !from.IsZero() {
+		query = query.Where("date >= ?", from)
+	}
+	result := query.Order("date ASC").Find(&marketData)
+	return marketData, result.Error
+}
+
+// GetCandles aggregates the market data of an instrument between from and to
+// (both inclusive) into at most limit OHLC bars of the given date_trunc unit,
+// oldest first
+func (r *MarketDataRepository) GetCandles(instrumentID uint, unit string, from, to time.Time, limit int) ([]models.Candle, error) {
+	var candles []models.Candle
+	err := r.db.Raw(`SELECT date_trunc(?, date) AS time,
+			(array_agg(open ORDER BY date ASC, id ASC))[1] AS open,
+			MAX(high) AS high,
+			MIN(low) AS low,
+			(array_agg(close ORDER BY date DESC, id DESC))[1] AS close,
+			COUNT(*) AS rows
+		FROM marketdata
+		WHERE instrumentid = ? AND date >= ? AND date <= ?
+		GROUP BY 1
+		ORDER BY 1 ASC
+		LIMIT ?`, unit, instrumentID, from, to, limit).
+		Scan(&candles).Error
+	return candles, err
+}
+
+// Create stores a new price, or returns ErrDuplicateMarketData if the
+// instrument already has one on the same date
 func (r *MarketDataRepository) Create(marketData *models.MarketData) error {
-	return r.db.Create(marketData).Error
+	err := r.db.Create(marketData).Error
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+		return ErrDuplicateMarketData
+	}
+	return err
+}
+
+// Upsert stores the rows in a single INSERT, replacing the prices of the ones
+// whose instrument already has a row on the same date
+func (r *MarketDataRepository) Upsert(marketData []models.MarketData) error {
+	if len(marketData) == 0 {
+		return nil
+	}
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "instrumentid"}, {Name: "date"}},
+		DoUpdates: clause.AssignmentColumns([]string{"open", "high", "low", "close", "previousclose"}),
+	}).Create(&marketData).Error
 }
@@ -1,8 +1,11 @@
 package repository
 
 import (
+	"time"
+
 	"github.com/NahuelDT/portfolio-api/internal/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type OrderRepository struct {
@@ -13,9 +16,30 @@ func NewOrderRepository(db *gorm.DB) *OrderRepository {
 	return &OrderRepository{db: db}
 }
 
-// Create inserts a new order into the database
+// Create inserts a new order into the database and, if it is already
+// FILLED, applies it to the materialized positions and cash balances. A
+// FILLED order its account cannot cover when it is applied is stored as
+// REJECTED instead.
 func (r *OrderRepository) Create(order *models.Order) error {
-	return r.db.Create(order).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if order.Status == "FILLED" {
+			covered, err := coversFill(tx, order)
+			if err != nil {
+				return err
+			}
+			if !covered {
+				order.Status = "REJECTED"
+				order.SettlementDate = time.Time{}
+			}
+		}
+		if err := tx.Create(order).Error; err != nil {
+			return err
+		}
+		if order.Status == "FILLED" {
+			return applyFill(tx, order)
+		}
+		return nil
+	})
 }
 
 // GetByID retrieves an order by its ID
@@ -25,9 +49,26 @@ func (r *OrderRepository) GetByID(id uint) (*models.Order, error) {
 	return &order, result.Error
 }
 
-// UpdateStatus updates the status of an order
+// UpdateStatus updates the status of an order. Moving an order to FILLED
+// applies it to the materialized positions and cash balances.
 func (r *OrderRepository) UpdateStatus(orderID uint, status string) error {
-	return r.db.Model(&models.Order{}).Where("id = ?", orderID).Update("status", status).Error
+	if status != "FILLED" {
+		return r.db.Model(&models.Order{}).Where("id = ?", orderID).Update("status", status).Error
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var order models.Order
+		if err := tx.First(&order, orderID).Error; err != nil {
+			return err
+		}
+		if order.Status == "FILLED" {
+			return nil
+		}
+		if err := tx.Model(&order).Update("status", status).Error; err != nil {
+			return err
+		}
+		return applyFill(tx, &order)
+	})
 }
 
 // GetUserCashBalance gets the user's FILLED orders
@@ -37,25 +78,137 @@ func (r *OrderRepository) GetUserFilledOrders(userID uint) ([]models.Order, erro
 	return orders, result.Error
 }
 
-// GetUserCashBalance calculates the user's cash balance based on their orders
-func (r *OrderRepository) GetUserCashBalance(userID uint) (float64, error) {
-	var result struct {
-		Balance float64
+// GetUserInstrumentOrders gets the user's FILLED orders of the given
+// instruments from the given time on. A zero since has no lower bound.
+func (r *OrderRepository) GetUserInstrumentOrders(userID uint, instrumentIDs []uint, since time.Time) ([]models.Order, error) {
+	var orders []models.Order
+	if len(instrumentIDs) == 0 {
+		return orders, nil
+	}
+	query := r.db.Where("userid = ? AND instrumentid IN ? AND status = ?", userID, instrumentIDs, "FILLED")
+	if !since.IsZero() {
+		query = query.Where("datetime >= ?", since)
+	}
+	result := query.Order("datetime ASC").Find(&orders)
+	return orders, result.Error
+}
+
+// GetUserIDs gets the IDs of every user with at least one order
+func (r *OrderRepository) GetUserIDs() ([]uint, error) {
+	var userIDs []uint
+	result := r.db.Model(&models.Order{}).Distinct("userid").Order("userid ASC").Pluck("userid", &userIDs)
+	return userIDs, result.Error
+}
+
+// GetUserFilledOrdersAsOf gets the user's orders FILLED up to the given time
+func (r *OrderRepository) GetUserFilledOrdersAsOf(userID uint, asOf time.Time) ([]models.Order, error) {
+	var orders []models.Order
+	result := r.db.Where("userid = ? AND status = ? AND datetime <= ?", userID, "FILLED", asOf).Find(&orders)
+	return orders, result.Error
+}
+
+// GetInstrumentOrdersBefore gets every user's FILLED orders that move a
+// position in the given instruments before the given time, oldest first
+func (r *OrderRepository) GetInstrumentOrdersBefore(instrumentIDs []uint, before time.Time) ([]models.Order, error) {
+	var orders []models.Order
+	if len(instrumentIDs) == 0 {
+		return orders, nil
 	}
+	result := r.db.Where("instrumentid IN ? AND status = ? AND datetime < ?", instrumentIDs, "FILLED", before).
+		Where("side IN ?", []string{"BUY", "SELL", "SECURITIES_IN", "SECURITIES_OUT"}).
+		Order("datetime ASC, id ASC").
+		Find(&orders)
+	return orders, result.Error
+}
 
+// GetUnsettledCash sums, by account and currency, the proceeds of the
+// user's sales filled up to asOf that settle after it
+func (r *OrderRepository) GetUnsettledCash(userID uint, asOf time.Time) ([]models.CashBalance, error) {
+	var rows []models.CashBalance
 	err := r.db.Model(&models.Order{}).
-		Select("COALESCE(SUM(CASE "+
-			"WHEN side = 'CASH_IN' THEN size "+
-			"WHEN side = 'CASH_OUT' THEN -size "+
-			"WHEN side = 'BUY' THEN -size * price "+
-			"WHEN side = 'SELL' THEN size * price "+
-			"ELSE 0 END), 0) as balance").
-		Where("userid = ? AND status = ?", userID, "FILLED").
-		Scan(&result).Error
+		Select("userid, accountid, currency, SUM(size * price) as balance").
+		Where("userid = ? AND side = ? AND status = ?", userID, "SELL", "FILLED").
+		Where("datetime <= ? AND settlementdate > ?", asOf, asOf).
+		Group("userid, accountid, currency").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// Orders stored without a currency are in DefaultCurrency
+	type cashKey struct {
+		accountID uint
+		currency  string
+	}
+	merged := make(map[cashKey]int)
+	unsettled := make([]models.CashBalance, 0, len(rows))
+	for _, row := range rows {
+		row.Currency = models.NormalizeCurrency(row.Currency)
+		key := cashKey{row.AccountID, row.Currency}
+		if i, ok := merged[key]; ok {
+			unsettled[i].Balance += row.Balance
+			continue
+		}
+		merged[key] = len(unsettled)
+		unsettled = append(unsettled, row)
+	}
+	return unsettled, nil
+}
 
+// unsettledCash sums the proceeds of the sales of a user's account in the
+// given currency filled up to at that settle after it, inside the caller's
+// transaction
+func unsettledCash(tx *gorm.DB, userID, accountID uint, currency string, at time.Time) (float64, error) {
+	var rows []models.CashBalance
+	err := tx.Model(&models.Order{}).
+		Select("currency, SUM(size * price) as balance").
+		Where("userid = ? AND accountid = ? AND side = ? AND status = ?", userID, accountID, "SELL", "FILLED").
+		Where("datetime <= ? AND settlementdate > ?", at, at).
+		Group("currency").
+		Scan(&rows).Error
 	if err != nil {
 		return 0, err
 	}
 
-	return result.Balance, nil
+	// Orders stored without a currency are in DefaultCurrency
+	total := 0.0
+	for _, row := range rows {
+		if models.NormalizeCurrency(row.Currency) == models.NormalizeCurrency(currency) {
+			total += row.Balance
+		}
+	}
+	return total, nil
+}
+
+// FillPendingOrders marks the PENDING orders of the given side dated up to
+// the given time as FILLED and applies them to the materialized positions
+// and cash balances
+func (r *OrderRepository) FillPendingOrders(side string, until time.Time) (int64, error) {
+	var filled int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var orders []models.Order
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("side = ? AND status = ? AND datetime <= ?", side, "PENDING", until).
+			Order("userid, id").
+			Find(&orders).Error
+		if err != nil || len(orders) == 0 {
+			return err
+		}
+
+		ids := make([]uint, len(orders))
+		for i, order := range orders {
+			ids[i] = order.ID
+		}
+		if err := tx.Model(&models.Order{}).Where("id IN ?", ids).Update("status", "FILLED").Error; err != nil {
+			return err
+		}
+		for i := range orders {
+			if err := applyFill(tx, &orders[i]); err != nil {
+				return err
+			}
+		}
+		filled = int64(len(orders))
+		return nil
+	})
+	return filled, err
 }
@@ -0,0 +1,260 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/NahuelDT/portfolio-api/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PositionRepository struct {
+	db *gorm.DB
+}
+
+func NewPositionRepository(db *gorm.DB) *PositionRepository {
+	return &PositionRepository{db: db}
+}
+
+// GetUserPositions retrieves every materialized position of the user in
+// each of their accounts, including closed ones
+func (r *PositionRepository) GetUserPositions(userID uint) ([]models.Position, error) {
+	var positions []models.Position
+	result := r.db.Where("userid = ?", userID).Order("accountid ASC, instrumentid ASC").Find(&positions)
+	return positions, result.Error
+}
+
+// GetAccountPosition retrieves the position of a user's account in an
+// instrument, or an empty one if the account never traded it
+func (r *PositionRepository) GetAccountPosition(userID, accountID, instrumentID uint) (*models.Position, error) {
+	position := models.Position{UserID: userID, AccountID: accountID, InstrumentID: instrumentID}
+	result := r.db.Where("userid = ? AND accountid = ? AND instrumentid = ?", userID, accountID, instrumentID).
+		Limit(1).
+		Find(&position)
+	return &position, result.Error
+}
+
+// GetAccountCashBalance sums the ledger's cash postings of a user's account
+// in the given currency
+func (r *PositionRepository) GetAccountCashBalance(userID, accountID uint, currency string) (float64, error) {
+	return ledgerCash(r.db, userID, accountID, currency)
+}
+
+// GetUserCashBalances sums the ledger's cash postings of the user in each
+// currency of each of their accounts
+func (r *PositionRepository) GetUserCashBalances(userID uint) ([]models.CashBalance, error) {
+	var balances []models.CashBalance
+	result := r.db.Model(&models.LedgerPosting{}).
+		Select("userid, accountid, currency, SUM(amount) as balance").
+		Where("userid = ? AND ledgeraccount = ?", userID, models.LedgerCash).
+		Group("userid, accountid, currency").
+		Order("accountid ASC, currency ASC").
+		Scan(&balances)
+	return balances, result.Error
+}
+
+// GetHolderIDs retrieves the users with an open position in the instrument
+// in any of their accounts
+func (r *PositionRepository) GetHolderIDs(instrumentID uint) ([]uint, error) {
+	var userIDs []uint
+	result := r.db.Model(&models.Position{}).
+		Where("instrumentid = ? AND quantity <> 0", instrumentID).
+		Distinct("userid").
+		Order("userid ASC").
+		Pluck("userid", &userIDs)
+	return userIDs, result.Error
+}
+
+// RebuildUserBalances swaps all the user's materialized positions and cash
+// balances for the ones replay builds from the user's FILLED orders, in a
+// single transaction. The user is locked like a fill locks it, so a fill
+// either commits before the orders are read or waits and is applied on top
+// of the rebuilt balances.
+func (r *PositionRepository) RebuildUserBalances(userID uint, replay func(orders []models.Order) ([]models.Position, []models.CashBalance)) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockUsers(tx, userID); err != nil {
+			return err
+		}
+		var orders []models.Order
+		if err := tx.Where("userid = ? AND status = ?", userID, "FILLED").Find(&orders).Error; err != nil {
+			return err
+		}
+		positions, cash := replay(orders)
+
+		if err := tx.Where("userid = ?", userID).Delete(&models.Position{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("userid = ?", userID).Delete(&models.CashBalance{}).Error; err != nil {
+			return err
+		}
+		if len(positions) > 0 {
+			if err := tx.Create(&positions).Error; err != nil {
+				return err
+			}
+		}
+		if len(cash) > 0 {
+			if err := tx.Create(&cash).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// lockUsers locks the users' rows until the end of the transaction, in ID
+// order so that transactions locking several users cannot deadlock. Every
+// change to a user's balances takes this lock first.
+func lockUsers(tx *gorm.DB, userIDs ...uint) error {
+	var locked []uint
+	return tx.Model(&models.User{}).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id IN ?", userIDs).
+		Order("id").
+		Pluck("id", &locked).Error
+}
+
+// applyFill posts the journal entry of a filled order and adds its effect
+// to the materialized position and to the cash balance projected from the
+// ledger, inside the caller's transaction and under the user's lock. It must
+// stay in line with the replay used to rebuild the tables.
+func applyFill(tx *gorm.DB, order *models.Order) error {
+	if err := lockUsers(tx, order.UserID); err != nil {
+		return err
+	}
+	if err := postJournalEntry(tx, order); err != nil {
+		return err
+	}
+	now := time.Now()
+
+	var cash float64
+	switch order.Side {
+	case "CASH_IN", "TRANSFER_IN":
+		cash = order.Size
+	case "CASH_OUT", "TRANSFER_OUT":
+		cash = -order.Size
+	case "DIVIDEND":
+		cash = order.Size * order.Price
+	case "BUY", "SELL":
+		position := models.Position{
+			UserID:       order.UserID,
+			AccountID:    order.AccountID,
+			InstrumentID: order.InstrumentID,
+			UpdatedAt:    now,
+		}
+		if order.Side == "BUY" {
+			cash = -order.Size * order.Price
+			position.Quantity = order.Size
+			position.BoughtQuantity = order.Size
+			position.BoughtCost = order.Size * order.Price
+		} else {
+			cash = order.Size * order.Price
+			position.Quantity = -order.Size
+		}
+
+		if err := upsertPosition(tx, &position, now); err != nil {
+			return err
+		}
+	case "SECURITIES_IN", "SECURITIES_OUT":
+		// Securities moved in kind change the position but not the cash. The
+		// destination takes them at the carried cost so its average cost
+		// matches the source's.
+		position := models.Position{
+			UserID:       order.UserID,
+			AccountID:    order.AccountID,
+			InstrumentID: order.InstrumentID,
+			UpdatedAt:    now,
+		}
+		if order.Side == "SECURITIES_IN" {
+			position.Quantity = order.Size
+			position.BoughtQuantity = order.Size
+			position.BoughtCost = order.Size * order.Price
+		} else {
+			position.Quantity = -order.Size
+		}
+		return upsertPosition(tx, &position, now)
+	default:
+		return nil
+	}
+
+	balance := models.CashBalance{
+		UserID:    order.UserID,
+		AccountID: order.AccountID,
+		Currency:  models.NormalizeCurrency(order.Currency),
+		Balance:   cash,
+		UpdatedAt: now,
+	}
+	return tx.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "userid"}, {Name: "accountid"}, {Name: "currency"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"balance":   gorm.Expr("cashbalances.balance + ?", cash),
+			"updatedat": now,
+		}),
+	}).Create(&balance).Error
+}
+
+// upsertPosition adds the quantities and cost of position to the stored one
+func upsertPosition(tx *gorm.DB, position *models.Position, now time.Time) error {
+	return tx.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "userid"}, {Name: "accountid"}, {Name: "instrumentid"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"quantity":       gorm.Expr("positions.quantity + ?", position.Quantity),
+			"boughtquantity": gorm.Expr("positions.boughtquantity + ?", position.BoughtQuantity),
+			"boughtcost":     gorm.Expr("positions.boughtcost + ?", position.BoughtCost),
+			"updatedat":      now,
+		}),
+	}).Create(position).Error
+}
+
+// coversFill reports whether the order's account can cover it, inside the
+// caller's transaction and under the user's lock, so no other fill can
+// spend the same cash or shares before this one is applied. A BUY needs its
+// cost and a CASH_OUT its amount in settled cash, net of the withdrawals
+// pending approval, so the proceeds of a sale cannot be spent before they
+// settle, and a SELL needs its size in the position.
+func coversFill(tx *gorm.DB, order *models.Order) (bool, error) {
+	switch order.Side {
+	case "BUY", "SELL", "CASH_OUT":
+	default:
+		return true, nil
+	}
+	if err := lockUsers(tx, order.UserID); err != nil {
+		return false, err
+	}
+
+	if order.Side == "SELL" {
+		var position models.Position
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("userid = ? AND accountid = ? AND instrumentid = ?", order.UserID, order.AccountID, order.InstrumentID).
+			Limit(1).
+			Find(&position).Error
+		return position.Quantity >= order.Size, err
+	}
+
+	available, err := availableCash(tx, order.UserID, order.AccountID, order.Currency)
+	if err != nil {
+		return false, err
+	}
+	unsettled, err := unsettledCash(tx, order.UserID, order.AccountID, order.Currency, order.DateTime)
+	if err != nil {
+		return false, err
+	}
+	if order.Side == "BUY" {
+		return available-unsettled >= order.Size*order.Price, nil
+	}
+	return available-unsettled >= order.Size, nil
+}
+
+// availableCash returns the ledger's cash of a user's account in the given
+// currency net of the withdrawals pending approval, inside the caller's
+// transaction and under the user's lock
+func availableCash(tx *gorm.DB, userID, accountID uint, currency string) (float64, error) {
+	balance, err := ledgerCash(tx, userID, accountID, currency)
+	if err != nil {
+		return 0, err
+	}
+	held, err := heldCash(tx, userID, accountID, currency)
+	if err != nil {
+		return 0, err
+	}
+	return balance - held, nil
+}
@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"github.com/NahuelDT/portfolio-api/internal/models"
+	"gorm.io/gorm"
+)
+
+type StatementRepository struct {
+	db *gorm.DB
+}
+
+func NewStatementRepository(db *gorm.DB) *StatementRepository {
+	return &StatementRepository{db: db}
+}
+
+func (r *StatementRepository) Create(statement *models.Statement) error {
+	return r.db.Create(statement).Error
+}
+
+// GetByID retrieves a statement by its ID
+func (r *StatementRepository) GetByID(id uint) (*models.Statement, error) {
+	var statement models.Statement
+	result := r.db.First(&statement, id)
+	return &statement, result.Error
+}
+
+// GetByUser retrieves the user's statements, newest period first, without
+// their content
+func (r *StatementRepository) GetByUser(userID uint) ([]models.Statement, error) {
+	var statements []models.Statement
+	result := r.db.Omit("content").
+		Where("userid = ?", userID).
+		Order("periodstart DESC, generatedat DESC").
+		Find(&statements)
+	return statements, result.Error
+}
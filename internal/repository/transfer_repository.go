@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/NahuelDT/portfolio-api/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrInsufficientFunds is returned when a transfer exceeds the cash
+	// balance of the account it debits
+	ErrInsufficientFunds = errors.New("insufficient funds")
+	// ErrInsufficientPosition is returned when a transfer of securities
+	// exceeds the position of the account it debits
+	ErrInsufficientPosition = errors.New("insufficient position")
+)
+
+type TransferRepository struct {
+	db *gorm.DB
+}
+
+func NewTransferRepository(db *gorm.DB) *TransferRepository {
+	return &TransferRepository{db: db}
+}
+
+// Create stores the transfer with its FILLED debit and credit orders and
+// applies both in a single transaction. Both users are locked, in a fixed
+// order so that opposite transfers cannot deadlock, and the debit must be
+// covered by the source's settled cash in the ledger, net of the cash it
+// holds for withdrawals pending approval. Securities take the
+// source account's average cost as their price.
+func (r *TransferRepository) Create(transfer *models.Transfer, debit, credit *models.Order) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockUsers(tx, debit.UserID, credit.UserID); err != nil {
+			return err
+		}
+		if transfer.Kind == models.SecuritiesTransfer {
+			var positions []models.Position
+			err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("((userid = ? AND accountid = ?) OR (userid = ? AND accountid = ?))",
+					debit.UserID, debit.AccountID, credit.UserID, credit.AccountID).
+				Where("instrumentid = ?", debit.InstrumentID).
+				Order("userid, accountid").
+				Find(&positions).Error
+			if err != nil {
+				return err
+			}
+			var source models.Position
+			for _, position := range positions {
+				if position.UserID == debit.UserID && position.AccountID == debit.AccountID {
+					source = position
+				}
+			}
+			if source.Quantity < debit.Size {
+				return ErrInsufficientPosition
+			}
+			if source.BoughtQuantity > 0 {
+				transfer.Price = source.BoughtCost / source.BoughtQuantity
+			}
+			debit.Price = transfer.Price
+			credit.Price = transfer.Price
+		} else {
+			available, err := ledgerCash(tx, debit.UserID, debit.AccountID, debit.Currency)
+			if err != nil {
+				return err
+			}
+			// Withdrawals pending approval hold part of the balance, and the
+			// proceeds of sales cannot leave the account until they settle
+			held, err := heldCash(tx, debit.UserID, debit.AccountID, debit.Currency)
+			if err != nil {
+				return err
+			}
+			unsettled, err := unsettledCash(tx, debit.UserID, debit.AccountID, debit.Currency, debit.DateTime)
+			if err != nil {
+				return err
+			}
+			if available-held-unsettled < debit.Size {
+				return ErrInsufficientFunds
+			}
+		}
+
+		if err := tx.Create(transfer).Error; err != nil {
+			return err
+		}
+		for _, order := range []*models.Order{debit, credit} {
+			order.TransferID = transfer.ID
+			if err := tx.Create(order).Error; err != nil {
+				return err
+			}
+			if err := applyFill(tx, order); err != nil {
+				return err
+			}
+		}
+
+		transfer.DebitOrderID = debit.ID
+		transfer.CreditOrderID = credit.ID
+		return tx.Model(transfer).Updates(map[string]interface{}{
+			"debitorderid":  transfer.DebitOrderID,
+			"creditorderid": transfer.CreditOrderID,
+		}).Error
+	})
+}
+
+// GetByUser retrieves the transfers the user sent or received, newest first
+func (r *TransferRepository) GetByUser(userID uint) ([]models.Transfer, error) {
+	var transfers []models.Transfer
+	result := r.db.Where("fromuserid = ? OR touserid = ?", userID, userID).
+		Order("createdat DESC, id DESC").
+		Find(&transfers)
+	return transfers, result.Error
+}
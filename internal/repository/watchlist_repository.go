@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"github.com/NahuelDT/portfolio-api/internal/models"
+	"gorm.io/gorm"
+)
+
+type WatchlistRepository struct {
+	db *gorm.DB
+}
+
+func NewWatchlistRepository(db *gorm.DB) *WatchlistRepository {
+	return &WatchlistRepository{db: db}
+}
+
+// Create stores the watchlist and its instruments in a single transaction
+func (r *WatchlistRepository) Create(watchlist *models.Watchlist) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(watchlist).Error; err != nil {
+			return err
+		}
+		return createWatchlistItems(tx, watchlist)
+	})
+}
+
+// GetByID retrieves a watchlist with its instruments in list order
+func (r *WatchlistRepository) GetByID(id uint) (*models.Watchlist, error) {
+	var watchlist models.Watchlist
+	if err := r.db.First(&watchlist, id).Error; err != nil {
+		return nil, err
+	}
+
+	watchlists := []models.Watchlist{watchlist}
+	if err := r.loadItems(watchlists); err != nil {
+		return nil, err
+	}
+	return &watchlists[0], nil
+}
+
+// GetByUser retrieves the user's watchlists, oldest first, with their
+// instruments
+func (r *WatchlistRepository) GetByUser(userID uint) ([]models.Watchlist, error) {
+	var watchlists []models.Watchlist
+	result := r.db.Where("userid = ?", userID).
+		Order("createdat ASC, id ASC").
+		Find(&watchlists)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if err := r.loadItems(watchlists); err != nil {
+		return nil, err
+	}
+	return watchlists, nil
+}
+
+// Update renames the watchlist and replaces its instruments in a single
+// transaction
+func (r *WatchlistRepository) Update(watchlist *models.Watchlist) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(watchlist).Update("name", watchlist.Name).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("watchlistid = ?", watchlist.ID).Delete(&models.WatchlistItem{}).Error; err != nil {
+			return err
+		}
+		return createWatchlistItems(tx, watchlist)
+	})
+}
+
+// Delete removes the watchlist and its instruments
+func (r *WatchlistRepository) Delete(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("watchlistid = ?", id).Delete(&models.WatchlistItem{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Watchlist{}, id).Error
+	})
+}
+
+// loadItems fills the instruments of the watchlists with a single query
+func (r *WatchlistRepository) loadItems(watchlists []models.Watchlist) error {
+	if len(watchlists) == 0 {
+		return nil
+	}
+
+	ids := make([]uint, 0, len(watchlists))
+	byID := make(map[uint]*models.Watchlist, len(watchlists))
+	for i := range watchlists {
+		watchlists[i].InstrumentIDs = make([]uint, 0)
+		ids = append(ids, watchlists[i].ID)
+		byID[watchlists[i].ID] = &watchlists[i]
+	}
+
+	var items []models.WatchlistItem
+	result := r.db.Where("watchlistid IN ?", ids).
+		Order("watchlistid, position").
+		Find(&items)
+	if result.Error != nil {
+		return result.Error
+	}
+	for _, item := range items {
+		watchlist := byID[item.WatchlistID]
+		watchlist.InstrumentIDs = append(watchlist.InstrumentIDs, item.InstrumentID)
+	}
+	return nil
+}
+
+func createWatchlistItems(tx *gorm.DB, watchlist *models.Watchlist) error {
+	if len(watchlist.InstrumentIDs) == 0 {
+		return nil
+	}
+
+	items := make([]models.WatchlistItem, 0, len(watchlist.InstrumentIDs))
+	for i, instrumentID := range watchlist.InstrumentIDs {
+		items = append(items, models.WatchlistItem{
+			WatchlistID:  watchlist.ID,
+			InstrumentID: instrumentID,
+			Position:     i,
+		})
+	}
+	return tx.Create(&items).Error
+}
@@ -0,0 +1,187 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/NahuelDT/portfolio-api/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrWithdrawalNotPending is returned when deciding on an order that is not
+// a withdrawal pending approval
+var ErrWithdrawalNotPending = errors.New("withdrawal is not pending approval")
+
+type WithdrawalRepository struct {
+	db *gorm.DB
+}
+
+func NewWithdrawalRepository(db *gorm.DB) *WithdrawalRepository {
+	return &WithdrawalRepository{db: db}
+}
+
+// Hold stores a CASH_OUT order pending approval together with the audit
+// entry of its request. The user is locked, and the account's cash in the
+// ledger, settled at the order's time, must cover the order on top of every
+// withdrawal already held on it, or ErrInsufficientFunds is returned.
+func (r *WithdrawalRepository) Hold(order *models.Order, audit *models.WithdrawalAudit) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockUsers(tx, order.UserID); err != nil {
+			return err
+		}
+		balance, err := ledgerCash(tx, order.UserID, order.AccountID, order.Currency)
+		if err != nil {
+			return err
+		}
+		held, err := heldCash(tx, order.UserID, order.AccountID, order.Currency)
+		if err != nil {
+			return err
+		}
+		unsettled, err := unsettledCash(tx, order.UserID, order.AccountID, order.Currency, order.DateTime)
+		if err != nil {
+			return err
+		}
+		if balance-unsettled-held < order.Size {
+			return ErrInsufficientFunds
+		}
+
+		if err := tx.Create(order).Error; err != nil {
+			return err
+		}
+		audit.OrderID = order.ID
+		return tx.Create(audit).Error
+	})
+}
+
+// Approve fills a withdrawal pending approval at the given time and records
+// the decision in a single transaction. The user is locked, and the
+// account's cash in the ledger, settled at that time, must still cover every
+// withdrawal held on it.
+func (r *WithdrawalRepository) Approve(orderID uint, at time.Time, audit *models.WithdrawalAudit) (*models.Order, error) {
+	var order models.Order
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockPendingWithdrawal(tx, orderID, &order); err != nil {
+			return err
+		}
+		if err := lockUsers(tx, order.UserID); err != nil {
+			return err
+		}
+
+		balance, err := ledgerCash(tx, order.UserID, order.AccountID, order.Currency)
+		if err != nil {
+			return err
+		}
+		held, err := heldCash(tx, order.UserID, order.AccountID, order.Currency)
+		if err != nil {
+			return err
+		}
+		unsettled, err := unsettledCash(tx, order.UserID, order.AccountID, order.Currency, at)
+		if err != nil {
+			return err
+		}
+		if balance-unsettled < held {
+			return ErrInsufficientFunds
+		}
+
+		// The cash leaves the account when the withdrawal is approved
+		order.Status = "FILLED"
+		order.DateTime = at
+		order.SettlementDate = at
+		err = tx.Model(&order).Updates(map[string]interface{}{
+			"status":         order.Status,
+			"datetime":       order.DateTime,
+			"settlementdate": order.SettlementDate,
+		}).Error
+		if err != nil {
+			return err
+		}
+		if err := applyFill(tx, &order); err != nil {
+			return err
+		}
+		return recordDecision(tx, &order, audit)
+	})
+	return &order, err
+}
+
+// Reject rejects a withdrawal pending approval, releasing its held cash, and
+// records the decision in a single transaction
+func (r *WithdrawalRepository) Reject(orderID uint, audit *models.WithdrawalAudit) (*models.Order, error) {
+	var order models.Order
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockPendingWithdrawal(tx, orderID, &order); err != nil {
+			return err
+		}
+		order.Status = "REJECTED"
+		if err := tx.Model(&order).Update("status", order.Status).Error; err != nil {
+			return err
+		}
+		return recordDecision(tx, &order, audit)
+	})
+	return &order, err
+}
+
+// GetPending retrieves the withdrawals pending approval, oldest first
+func (r *WithdrawalRepository) GetPending() ([]models.Order, error) {
+	var orders []models.Order
+	result := r.db.Where("side = ? AND status = ?", "CASH_OUT", models.PendingApprovalStatus).
+		Order("datetime ASC, id ASC").
+		Find(&orders)
+	return orders, result.Error
+}
+
+// GetAuditTrail retrieves the audit entries of a withdrawal, oldest first
+func (r *WithdrawalRepository) GetAuditTrail(orderID uint) ([]models.WithdrawalAudit, error) {
+	var audits []models.WithdrawalAudit
+	result := r.db.Where("orderid = ?", orderID).Order("createdat ASC, id ASC").Find(&audits)
+	return audits, result.Error
+}
+
+// GetHeldCash sums, by account and currency, the user's withdrawals pending
+// approval
+func (r *WithdrawalRepository) GetHeldCash(userID uint) ([]models.CashBalance, error) {
+	var held []models.CashBalance
+	result := r.db.Model(&models.Order{}).
+		Select("userid, accountid, currency, SUM(size) as balance").
+		Where("userid = ? AND side = ? AND status = ?", userID, "CASH_OUT", models.PendingApprovalStatus).
+		Group("userid, accountid, currency").
+		Order("accountid ASC, currency ASC").
+		Scan(&held)
+	return held, result.Error
+}
+
+// heldCash sums the withdrawals pending approval of a user's account in the
+// given currency, inside the caller's transaction
+func heldCash(tx *gorm.DB, userID, accountID uint, currency string) (float64, error) {
+	var result struct {
+		Held float64
+	}
+	err := tx.Model(&models.Order{}).
+		Select("COALESCE(SUM(size), 0) as held").
+		Where("userid = ? AND accountid = ? AND currency = ?", userID, accountID, models.NormalizeCurrency(currency)).
+		Where("side = ? AND status = ?", "CASH_OUT", models.PendingApprovalStatus).
+		Scan(&result).Error
+	return result.Held, err
+}
+
+func lockPendingWithdrawal(tx *gorm.DB, orderID uint, order *models.Order) error {
+	result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ? AND side = ? AND status = ?", orderID, "CASH_OUT", models.PendingApprovalStatus).
+		Limit(1).
+		Find(order)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrWithdrawalNotPending
+	}
+	return nil
+}
+
+func recordDecision(tx *gorm.DB, order *models.Order, audit *models.WithdrawalAudit) error {
+	audit.OrderID = order.ID
+	audit.UserID = order.UserID
+	audit.Amount = order.Size
+	audit.Currency = order.Currency
+	return tx.Create(audit).Error
+}
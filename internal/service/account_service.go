@@ -0,0 +1,77 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/NahuelDT/portfolio-api/internal/models"
+	"github.com/NahuelDT/portfolio-api/internal/repository"
+)
+
+var ErrInvalidAccount = errors.New("invalid account")
+
+type AccountService struct {
+	accountRepo repository.AccountRepositorer
+	userRepo    repository.UserRepositorer
+}
+
+func NewAccountService(
+	accountRepo repository.AccountRepositorer,
+	userRepo repository.UserRepositorer,
+) *AccountService {
+	return &AccountService{
+		accountRepo: accountRepo,
+		userRepo:    userRepo,
+	}
+}
+
+// CreateAccount opens a new named sub-account for the user
+func (s *AccountService) CreateAccount(account *models.Account) error {
+	account.Name = strings.TrimSpace(account.Name)
+	if account.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidAccount)
+	}
+	if _, err := s.userRepo.GetByID(account.UserID); err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	accounts, err := s.GetAccounts(account.UserID)
+	if err != nil {
+		return err
+	}
+	for _, existing := range accounts {
+		if strings.EqualFold(existing.Name, account.Name) {
+			return fmt.Errorf("%w: the user already has an account named %q", ErrInvalidAccount, existing.Name)
+		}
+	}
+
+	account.ID = 0
+	account.CreatedAt = time.Now()
+	return s.accountRepo.Create(account)
+}
+
+// GetAccounts lists the user's accounts, starting with the main one
+func (s *AccountService) GetAccounts(userID uint) ([]models.Account, error) {
+	accounts, err := s.accountRepo.GetByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	main := models.Account{ID: models.MainAccountID, UserID: userID, Name: models.MainAccountName}
+	return append([]models.Account{main}, accounts...), nil
+}
+
+// checkAccount verifies that the account belongs to the user. The main
+// account belongs to every user.
+func checkAccount(accountRepo repository.AccountRepositorer, userID, accountID uint) error {
+	if accountID == models.MainAccountID {
+		return nil
+	}
+	account, err := accountRepo.GetByID(accountID)
+	if err != nil || account.UserID != userID {
+		return fmt.Errorf("%w: account %d does not belong to user %d", ErrInvalidAccount, accountID, userID)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"testing"
+
+	mocks "github.com/NahuelDT/portfolio-api/internal/mocks/repository"
+	"github.com/NahuelDT/portfolio-api/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAccounts(t *testing.T) {
+	setUp := func() (*mocks.AccountRepositorer, *mocks.UserRepositorer, *AccountService) {
+		mockAccountRepo := new(mocks.AccountRepositorer)
+		mockUserRepo := new(mocks.UserRepositorer)
+		accountService := NewAccountService(mockAccountRepo, mockUserRepo)
+		return mockAccountRepo, mockUserRepo, accountService
+	}
+
+	t.Run("Create an account", func(t *testing.T) {
+		mockAccountRepo, mockUserRepo, accountService := setUp()
+
+		account := &models.Account{UserID: 1, Name: "  Retiro "}
+
+		mockUserRepo.On("GetByID", uint(1)).Return(&models.User{ID: 1}, nil)
+		mockAccountRepo.On("GetByUser", uint(1)).Return([]models.Account{}, nil)
+		mockAccountRepo.On("Create", account).Return(nil)
+
+		err := accountService.CreateAccount(account)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Retiro", account.Name)
+		mockAccountRepo.AssertExpectations(t)
+	})
+
+	t.Run("Reject duplicate account names", func(t *testing.T) {
+		mockAccountRepo, mockUserRepo, accountService := setUp()
+
+		mockUserRepo.On("GetByID", uint(1)).Return(&models.User{ID: 1}, nil)
+		mockAccountRepo.On("GetByUser", uint(1)).Return([]models.Account{{ID: 2, UserID: 1, Name: "Retiro"}}, nil)
+
+		err := accountService.CreateAccount(&models.Account{UserID: 1, Name: "retiro"})
+		assert.ErrorIs(t, err, ErrInvalidAccount)
+
+		err = accountService.CreateAccount(&models.Account{UserID: 1, Name: "principal"})
+		assert.ErrorIs(t, err, ErrInvalidAccount)
+
+		mockAccountRepo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+
+	t.Run("List accounts starting with the main one", func(t *testing.T) {
+		mockAccountRepo, _, accountService := setUp()
+
+		mockAccountRepo.On("GetByUser", uint(1)).Return([]models.Account{{ID: 2, UserID: 1, Name: "Retiro"}}, nil)
+
+		accounts, err := accountService.GetAccounts(1)
+
+		assert.NoError(t, err)
+		assert.Len(t, accounts, 2)
+		assert.Equal(t, models.MainAccountID, accounts[0].ID)
+		assert.Equal(t, models.MainAccountName, accounts[0].Name)
+		assert.Equal(t, uint(2), accounts[1].ID)
+	})
+}
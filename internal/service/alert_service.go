@@ -0,0 +1,359 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/NahuelDT/portfolio-api/internal/models"
+	"github.com/NahuelDT/portfolio-api/internal/repository"
+)
+
+const (
+	// AlertSignatureHeader carries "sha256=" followed by the hex HMAC-SHA256
+	// of the request body keyed by the webhook secret
+	AlertSignatureHeader = "X-Portfolio-Signature"
+	AlertEventHeader     = "X-Portfolio-Event"
+	// AlertDeliveryHeader carries the ID of the trigger, the same on every
+	// attempt, so receivers can drop repeated deliveries
+	AlertDeliveryHeader = "X-Portfolio-Delivery"
+	priceAlertEvent     = "price_alert"
+
+	// AlertDeliveryInterval is how often the delivery worker looks for
+	// triggers due for another attempt
+	AlertDeliveryInterval = 15 * time.Second
+	// deliveryLease is how long a trigger being delivered is kept from
+	// other deliveries
+	deliveryLease = time.Minute
+)
+
+var (
+	ErrInvalidAlert   = errors.New("invalid price alert")
+	ErrInvalidWebhook = errors.New("invalid webhook")
+)
+
+type AlertService struct {
+	alertRepo      repository.AlertRepositorer
+	userRepo       repository.UserRepositorer
+	instrumentRepo repository.InstrumentRepositorer
+	client         *http.Client
+	lookupIP       func(ctx context.Context, host string) ([]net.IPAddr, error)
+	// retryDelays are the waits before each retry of a failed delivery, so
+	// a trigger is attempted at most len(retryDelays)+1 times
+	retryDelays []time.Duration
+}
+
+func NewAlertService(
+	alertRepo repository.AlertRepositorer,
+	userRepo repository.UserRepositorer,
+	instrumentRepo repository.InstrumentRepositorer,
+) *AlertService {
+	return &AlertService{
+		alertRepo:      alertRepo,
+		userRepo:       userRepo,
+		instrumentRepo: instrumentRepo,
+		client:         newWebhookClient(),
+		lookupIP:       net.DefaultResolver.LookupIPAddr,
+		retryDelays:    []time.Duration{time.Minute, 5 * time.Minute, 30 * time.Minute, 2 * time.Hour},
+	}
+}
+
+// CreateAlert validates and stores a new active alert
+func (s *AlertService) CreateAlert(alert *models.PriceAlert) error {
+	alert.Type = strings.ToUpper(alert.Type)
+	switch alert.Type {
+	case models.AlertCrossesAbove, models.AlertCrossesBelow, models.AlertDailyMove:
+	default:
+		return fmt.Errorf("%w: unknown type %q", ErrInvalidAlert, alert.Type)
+	}
+	if alert.Threshold <= 0 {
+		return fmt.Errorf("%w: threshold must be positive", ErrInvalidAlert)
+	}
+	if _, err := s.userRepo.GetByID(alert.UserID); err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if _, err := s.instrumentRepo.GetByID(alert.InstrumentID); err != nil {
+		return fmt.Errorf("%w: instrument %d does not exist", ErrInvalidAlert, alert.InstrumentID)
+	}
+
+	alert.Active = true
+	alert.CreatedAt = time.Now()
+	alert.TriggeredAt = nil
+	return s.alertRepo.Create(alert)
+}
+
+// GetAlerts lists the user's alerts
+func (s *AlertService) GetAlerts(userID uint) ([]models.PriceAlert, error) {
+	return s.alertRepo.GetByUser(userID)
+}
+
+// DeleteAlert removes one of the user's alerts
+func (s *AlertService) DeleteAlert(userID, alertID uint) error {
+	return s.alertRepo.Delete(userID, alertID)
+}
+
+// GetTriggers lists the user's triggered alerts with their delivery status
+func (s *AlertService) GetTriggers(userID uint) ([]models.AlertTrigger, error) {
+	return s.alertRepo.GetTriggersByUser(userID)
+}
+
+// SetWebhook sets the URL where the user's alerts are delivered and issues
+// a new signing secret for it. The URL must resolve to public addresses.
+func (s *AlertService) SetWebhook(userID uint, webhookURL string) (*models.AlertWebhook, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := checkWebhookURL(ctx, s.lookupIP, webhookURL); err != nil {
+		return nil, err
+	}
+	if _, err := s.userRepo.GetByID(userID); err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	webhook := &models.AlertWebhook{
+		UserID:    userID,
+		URL:       webhookURL,
+		Secret:    hex.EncodeToString(secret),
+		UpdatedAt: time.Now(),
+	}
+	if err := s.alertRepo.SaveWebhook(webhook); err != nil {
+		return nil, err
+	}
+	return webhook, nil
+}
+
+// OnMarketData evaluates the instrument's alerts against a new price and
+// attempts to deliver the ones that trigger in the background. Failed
+// deliveries are left to the delivery worker.
+func (s *AlertService) OnMarketData(previous, data *models.MarketData) {
+	triggers, err := s.EvaluateMarketData(previous, data)
+	if err != nil {
+		log.Printf("Failed to evaluate price alerts for instrument %d: %v", data.InstrumentID, err)
+		return
+	}
+	if len(triggers) == 0 {
+		return
+	}
+
+	go func() {
+		for i := range triggers {
+			if err := s.Deliver(&triggers[i]); err != nil {
+				log.Printf("Failed to deliver alert trigger %d: %v", triggers[i].ID, err)
+			}
+		}
+	}()
+}
+
+// EvaluateMarketData records a trigger for every active alert of the
+// instrument that the new price sets off. Crossings are measured from the
+// previous row's close, or from the previous close of the new row if it is
+// the first one. Rows older than the previous one are backfills and do not
+// trigger alerts.
+func (s *AlertService) EvaluateMarketData(previous, data *models.MarketData) ([]models.AlertTrigger, error) {
+	if previous != nil && data.DateTime.Before(previous.DateTime) {
+		return nil, nil
+	}
+
+	alerts, err := s.alertRepo.GetActiveByInstrument(data.InstrumentID)
+	if err != nil || len(alerts) == 0 {
+		return nil, err
+	}
+	instrument, err := s.instrumentRepo.GetByID(data.InstrumentID)
+	if err != nil {
+		return nil, err
+	}
+
+	last := data.PreviousClose
+	if previous != nil {
+		last = previous.Close
+	}
+	changePercent := 0.0
+	if data.PreviousClose != 0 {
+		changePercent = (data.Close - data.PreviousClose) / data.PreviousClose * 100
+	}
+
+	triggers := make([]models.AlertTrigger, 0)
+	for i := range alerts {
+		alert := &alerts[i]
+		if !alertTriggered(alert, last, data.Close, changePercent) {
+			continue
+		}
+
+		// The first attempt is made right away; the next one only if the
+		// process stops before recording its outcome
+		nextAttempt := time.Now().Add(deliveryLease)
+		trigger := models.AlertTrigger{
+			AlertID:       alert.ID,
+			UserID:        alert.UserID,
+			InstrumentID:  alert.InstrumentID,
+			Ticker:        instrument.Ticker,
+			Type:          alert.Type,
+			Threshold:     alert.Threshold,
+			Price:         data.Close,
+			ChangePct:     changePercent,
+			PriceTime:     data.DateTime,
+			TriggeredAt:   time.Now(),
+			NextAttemptAt: &nextAttempt,
+		}
+		recorded, err := s.alertRepo.RecordTrigger(alert, &trigger)
+		if err != nil {
+			return nil, err
+		}
+		if recorded {
+			triggers = append(triggers, trigger)
+		}
+	}
+	return triggers, nil
+}
+
+func alertTriggered(alert *models.PriceAlert, last, price, changePercent float64) bool {
+	switch alert.Type {
+	case models.AlertCrossesAbove:
+		return last < alert.Threshold && price >= alert.Threshold
+	case models.AlertCrossesBelow:
+		return last > alert.Threshold && price <= alert.Threshold
+	case models.AlertDailyMove:
+		return math.Abs(changePercent) > alert.Threshold
+	}
+	return false
+}
+
+// Deliver posts the trigger to the user's webhook once and records the
+// outcome. A failed attempt is scheduled for retry after the next of the
+// retry delays, or given up once they are exhausted. Triggers of users
+// without a webhook are left undelivered.
+func (s *AlertService) Deliver(trigger *models.AlertTrigger) error {
+	webhook, err := s.alertRepo.GetWebhook(trigger.UserID)
+	if err != nil {
+		return err
+	}
+	if webhook.URL == "" {
+		return nil
+	}
+
+	body, err := json.Marshal(models.AlertNotification{
+		Event:         priceAlertEvent,
+		TriggerID:     trigger.ID,
+		AlertID:       trigger.AlertID,
+		UserID:        trigger.UserID,
+		InstrumentID:  trigger.InstrumentID,
+		Ticker:        trigger.Ticker,
+		Type:          trigger.Type,
+		Threshold:     trigger.Threshold,
+		Price:         trigger.Price,
+		ChangePercent: trigger.ChangePct,
+		PriceTime:     trigger.PriceTime,
+		TriggeredAt:   trigger.TriggeredAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	trigger.Attempts++
+	deliveryErr := s.post(webhook, trigger.ID, body)
+	now := time.Now()
+	trigger.NextAttemptAt = nil
+	if deliveryErr == nil {
+		trigger.DeliveredAt = &now
+		trigger.LastError = ""
+	} else {
+		trigger.LastError = deliveryErr.Error()
+		if trigger.Attempts <= len(s.retryDelays) {
+			next := now.Add(s.retryDelays[trigger.Attempts-1])
+			trigger.NextAttemptAt = &next
+		}
+	}
+
+	if err := s.alertRepo.UpdateDelivery(trigger); err != nil {
+		return err
+	}
+	return deliveryErr
+}
+
+// RetryDeliveries attempts the triggers due for another delivery, such as
+// those that failed before or of a process that stopped while delivering
+// them, and returns how many were delivered
+func (s *AlertService) RetryDeliveries() (int, error) {
+	triggers, err := s.alertRepo.ClaimDueTriggers(len(s.retryDelays)+1, time.Now(), deliveryLease)
+	if err != nil {
+		return 0, err
+	}
+
+	delivered := 0
+	var errs []error
+	for i := range triggers {
+		if err := s.Deliver(&triggers[i]); err != nil {
+			errs = append(errs, fmt.Errorf("trigger %d: %w", triggers[i].ID, err))
+			continue
+		}
+		if triggers[i].DeliveredAt != nil {
+			delivered++
+		}
+	}
+	return delivered, errors.Join(errs...)
+}
+
+// RunDeliveries retries the due deliveries every interval until the context
+// is cancelled. Failures are logged and retried on the next run.
+func (s *AlertService) RunDeliveries(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.RetryDeliveries(); err != nil {
+				log.Printf("Failed to deliver price alerts: %v", err)
+			}
+		}
+	}
+}
+
+func (s *AlertService) post(webhook *models.AlertWebhook, triggerID uint, body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(AlertEventHeader, priceAlertEvent)
+	req.Header.Set(AlertDeliveryHeader, strconv.FormatUint(uint64(triggerID), 10))
+	req.Header.Set(AlertSignatureHeader, "sha256="+SignWebhookPayload(webhook.Secret, body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded %s", resp.Status)
+	}
+	return nil
+}
+
+// SignWebhookPayload returns the hex HMAC-SHA256 of body keyed by secret, as
+// sent in AlertSignatureHeader. Receivers recompute it to verify a delivery.
+func SignWebhookPayload(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
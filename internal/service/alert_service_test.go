@@ -0,0 +1,294 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	mocks "github.com/NahuelDT/portfolio-api/internal/mocks/repository"
+	"github.com/NahuelDT/portfolio-api/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func TestPriceAlerts(t *testing.T) {
+	setUp := func() (*mocks.AlertRepositorer, *mocks.UserRepositorer, *mocks.InstrumentRepositorer, *AlertService) {
+		mockAlertRepo := new(mocks.AlertRepositorer)
+		mockUserRepo := new(mocks.UserRepositorer)
+		mockInstrumentRepo := new(mocks.InstrumentRepositorer)
+		alertService := NewAlertService(mockAlertRepo, mockUserRepo, mockInstrumentRepo)
+		alertService.retryDelays = []time.Duration{time.Minute, time.Hour}
+		// httptest servers listen on loopback, which the webhook client
+		// refuses to dial
+		alertService.client = &http.Client{Timeout: time.Second}
+		alertService.lookupIP = func(ctx context.Context, host string) ([]net.IPAddr, error) {
+			switch host {
+			case "example.com":
+				return []net.IPAddr{{IP: net.ParseIP("93.184.216.34")}}, nil
+			case "internal.example.com":
+				return []net.IPAddr{{IP: net.ParseIP("93.184.216.34")}, {IP: net.ParseIP("10.0.0.5")}}, nil
+			case "localhost":
+				return []net.IPAddr{{IP: net.ParseIP("127.0.0.1")}}, nil
+			}
+			return nil, errors.New("no such host")
+		}
+		return mockAlertRepo, mockUserRepo, mockInstrumentRepo, alertService
+	}
+
+	t.Run("Create an alert", func(t *testing.T) {
+		mockAlertRepo, mockUserRepo, mockInstrumentRepo, alertService := setUp()
+
+		alert := &models.PriceAlert{UserID: 1, InstrumentID: 2, Type: "crosses_above", Threshold: 150}
+
+		mockUserRepo.On("GetByID", uint(1)).Return(&models.User{ID: 1}, nil)
+		mockInstrumentRepo.On("GetByID", uint(2)).Return(&models.Instrument{ID: 2, Ticker: "AAPL"}, nil)
+		mockAlertRepo.On("Create", alert).Return(nil)
+
+		err := alertService.CreateAlert(alert)
+
+		assert.NoError(t, err)
+		assert.Equal(t, models.AlertCrossesAbove, alert.Type)
+		assert.True(t, alert.Active)
+		mockAlertRepo.AssertExpectations(t)
+	})
+
+	t.Run("Reject invalid alerts", func(t *testing.T) {
+		_, _, _, alertService := setUp()
+
+		err := alertService.CreateAlert(&models.PriceAlert{UserID: 1, InstrumentID: 2, Type: "VOLUME", Threshold: 1})
+		assert.ErrorIs(t, err, ErrInvalidAlert)
+
+		err = alertService.CreateAlert(&models.PriceAlert{UserID: 1, InstrumentID: 2, Type: models.AlertDailyMove, Threshold: 0})
+		assert.ErrorIs(t, err, ErrInvalidAlert)
+	})
+
+	t.Run("Evaluate alerts against a new price", func(t *testing.T) {
+		mockAlertRepo, _, mockInstrumentRepo, alertService := setUp()
+
+		now := time.Now()
+		previous := &models.MarketData{InstrumentID: 2, Close: 148, PreviousClose: 140, DateTime: now.Add(-time.Minute)}
+		data := &models.MarketData{InstrumentID: 2, Close: 152, PreviousClose: 140, DateTime: now}
+
+		mockAlertRepo.On("GetActiveByInstrument", uint(2)).Return([]models.PriceAlert{
+			{ID: 1, UserID: 1, InstrumentID: 2, Type: models.AlertCrossesAbove, Threshold: 150, Active: true},
+			{ID: 2, UserID: 1, InstrumentID: 2, Type: models.AlertCrossesAbove, Threshold: 145, Active: true},
+			{ID: 3, UserID: 1, InstrumentID: 2, Type: models.AlertCrossesBelow, Threshold: 150, Active: true},
+			{ID: 4, UserID: 3, InstrumentID: 2, Type: models.AlertDailyMove, Threshold: 5, Active: true},
+			{ID: 5, UserID: 3, InstrumentID: 2, Type: models.AlertDailyMove, Threshold: 10, Active: true},
+		}, nil)
+		mockInstrumentRepo.On("GetByID", uint(2)).Return(&models.Instrument{ID: 2, Ticker: "AAPL"}, nil)
+		mockAlertRepo.On("RecordTrigger", mock.AnythingOfType("*models.PriceAlert"), mock.AnythingOfType("*models.AlertTrigger")).Return(true, nil)
+
+		triggers, err := alertService.EvaluateMarketData(previous, data)
+
+		assert.NoError(t, err)
+		// 145 was already crossed by the previous price and the move is
+		// 8.57%, under the 10% alert
+		assert.Len(t, triggers, 2)
+		assert.Equal(t, uint(1), triggers[0].AlertID)
+		assert.Equal(t, "AAPL", triggers[0].Ticker)
+		assert.Equal(t, float64(152), triggers[0].Price)
+		assert.Equal(t, uint(4), triggers[1].AlertID)
+		assert.Equal(t, uint(3), triggers[1].UserID)
+		assert.InDelta(t, 12.0/140*100, triggers[1].ChangePct, 1e-9)
+		mockAlertRepo.AssertNumberOfCalls(t, "RecordTrigger", 2)
+	})
+
+	t.Run("Backfilled prices do not trigger alerts", func(t *testing.T) {
+		mockAlertRepo, _, _, alertService := setUp()
+
+		now := time.Now()
+		previous := &models.MarketData{InstrumentID: 2, Close: 148, DateTime: now}
+		data := &models.MarketData{InstrumentID: 2, Close: 200, DateTime: now.AddDate(0, 0, -1)}
+
+		triggers, err := alertService.EvaluateMarketData(previous, data)
+
+		assert.NoError(t, err)
+		assert.Empty(t, triggers)
+		mockAlertRepo.AssertNotCalled(t, "GetActiveByInstrument", mock.Anything)
+	})
+
+	t.Run("Deliver with an HMAC signature and schedule retries", func(t *testing.T) {
+		mockAlertRepo, _, _, alertService := setUp()
+
+		var mu sync.Mutex
+		requests := 0
+		var body []byte
+		var signature, event, delivery string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			defer mu.Unlock()
+			requests++
+			if requests == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			body, _ = io.ReadAll(r.Body)
+			signature = r.Header.Get(AlertSignatureHeader)
+			event = r.Header.Get(AlertEventHeader)
+			delivery = r.Header.Get(AlertDeliveryHeader)
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer server.Close()
+
+		trigger := &models.AlertTrigger{ID: 9, AlertID: 1, UserID: 1, InstrumentID: 2, Ticker: "AAPL", Type: models.AlertCrossesAbove, Threshold: 150, Price: 152}
+
+		mockAlertRepo.On("GetWebhook", uint(1)).Return(&models.AlertWebhook{UserID: 1, URL: server.URL, Secret: "s3cret"}, nil)
+		mockAlertRepo.On("UpdateDelivery", trigger).Return(nil)
+
+		before := time.Now()
+		err := alertService.Deliver(trigger)
+
+		assert.Error(t, err)
+		assert.Equal(t, 1, requests)
+		assert.Equal(t, 1, trigger.Attempts)
+		assert.Nil(t, trigger.DeliveredAt)
+		assert.Contains(t, trigger.LastError, "503")
+		if assert.NotNil(t, trigger.NextAttemptAt) {
+			assert.False(t, trigger.NextAttemptAt.Before(before.Add(time.Minute)))
+		}
+
+		err = alertService.Deliver(trigger)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, requests)
+		assert.Equal(t, 2, trigger.Attempts)
+		assert.NotNil(t, trigger.DeliveredAt)
+		assert.Nil(t, trigger.NextAttemptAt)
+		assert.Empty(t, trigger.LastError)
+		assert.Equal(t, "price_alert", event)
+		assert.Equal(t, "9", delivery)
+		assert.Equal(t, "sha256="+SignWebhookPayload("s3cret", body), signature)
+
+		var notification models.AlertNotification
+		assert.NoError(t, json.Unmarshal(body, &notification))
+		assert.Equal(t, uint(9), notification.TriggerID)
+		assert.Equal(t, "AAPL", notification.Ticker)
+		assert.Equal(t, float64(152), notification.Price)
+		mockAlertRepo.AssertNumberOfCalls(t, "UpdateDelivery", 2)
+	})
+
+	t.Run("Give up after the last retry", func(t *testing.T) {
+		mockAlertRepo, _, _, alertService := setUp()
+
+		requests := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+
+		trigger := &models.AlertTrigger{ID: 10, UserID: 1, Attempts: 2}
+
+		mockAlertRepo.On("GetWebhook", uint(1)).Return(&models.AlertWebhook{UserID: 1, URL: server.URL, Secret: "s3cret"}, nil)
+		mockAlertRepo.On("UpdateDelivery", trigger).Return(nil)
+
+		err := alertService.Deliver(trigger)
+
+		assert.Error(t, err)
+		assert.Equal(t, 1, requests)
+		assert.Equal(t, 3, trigger.Attempts)
+		assert.Nil(t, trigger.DeliveredAt)
+		assert.Nil(t, trigger.NextAttemptAt)
+		assert.Contains(t, trigger.LastError, "500")
+	})
+
+	t.Run("Retry the due deliveries", func(t *testing.T) {
+		mockAlertRepo, _, _, alertService := setUp()
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer server.Close()
+
+		mockAlertRepo.On("ClaimDueTriggers", 3, mock.AnythingOfType("time.Time"), deliveryLease).Return([]models.AlertTrigger{
+			{ID: 12, UserID: 1, Attempts: 1},
+			{ID: 13, UserID: 1, Attempts: 2},
+		}, nil)
+		mockAlertRepo.On("GetWebhook", uint(1)).Return(&models.AlertWebhook{UserID: 1, URL: server.URL, Secret: "s3cret"}, nil)
+		mockAlertRepo.On("UpdateDelivery", mock.AnythingOfType("*models.AlertTrigger")).Return(nil)
+
+		delivered, err := alertService.RetryDeliveries()
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, delivered)
+		mockAlertRepo.AssertNumberOfCalls(t, "UpdateDelivery", 2)
+	})
+
+	t.Run("Triggers wait until a webhook is configured", func(t *testing.T) {
+		mockAlertRepo, _, _, alertService := setUp()
+
+		trigger := &models.AlertTrigger{ID: 11, UserID: 4}
+		mockAlertRepo.On("GetWebhook", uint(4)).Return(&models.AlertWebhook{UserID: 4}, nil)
+
+		err := alertService.Deliver(trigger)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 0, trigger.Attempts)
+		mockAlertRepo.AssertNotCalled(t, "UpdateDelivery", mock.Anything)
+	})
+
+	t.Run("Set a webhook with a new secret", func(t *testing.T) {
+		mockAlertRepo, mockUserRepo, _, alertService := setUp()
+
+		mockUserRepo.On("GetByID", uint(1)).Return(&models.User{ID: 1}, nil)
+		mockAlertRepo.On("SaveWebhook", mock.AnythingOfType("*models.AlertWebhook")).Return(nil)
+
+		webhook, err := alertService.SetWebhook(1, "https://example.com/hooks/alerts")
+
+		assert.NoError(t, err)
+		assert.Len(t, webhook.Secret, 64)
+
+		_, err = alertService.SetWebhook(1, "ftp://example.com")
+		assert.ErrorIs(t, err, ErrInvalidWebhook)
+	})
+
+	t.Run("Reject webhooks to non-public addresses", func(t *testing.T) {
+		mockAlertRepo, _, _, alertService := setUp()
+
+		for _, webhookURL := range []string{
+			"http://169.254.169.254/latest/meta-data",
+			"http://localhost:8080/hooks",
+			"http://10.0.0.5/hooks",
+			"http://192.168.1.1/hooks",
+			"http://[::1]/hooks",
+			"http://[::ffff:127.0.0.1]/hooks",
+			"http://0.0.0.0/hooks",
+			"https://internal.example.com/hooks",
+			"https://unknown.invalid/hooks",
+		} {
+			_, err := alertService.SetWebhook(1, webhookURL)
+			assert.ErrorIs(t, err, ErrInvalidWebhook, webhookURL)
+		}
+		mockAlertRepo.AssertNotCalled(t, "SaveWebhook", mock.Anything)
+	})
+
+	t.Run("The webhook client does not dial non-public addresses", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer server.Close()
+
+		_, err := newWebhookClient().Post(server.URL, "application/json", nil)
+
+		assert.ErrorContains(t, err, "not public")
+	})
+
+	t.Run("Delete only the user's own alerts", func(t *testing.T) {
+		mockAlertRepo, _, _, alertService := setUp()
+
+		mockAlertRepo.On("Delete", uint(1), uint(5)).Return(nil)
+		mockAlertRepo.On("Delete", uint(2), uint(5)).Return(gorm.ErrRecordNotFound)
+
+		assert.NoError(t, alertService.DeleteAlert(1, 5))
+		assert.ErrorIs(t, alertService.DeleteAlert(2, 5), gorm.ErrRecordNotFound)
+	})
+}
@@ -0,0 +1,232 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/NahuelDT/portfolio-api/internal/models"
+	"github.com/NahuelDT/portfolio-api/internal/repository"
+)
+
+var ErrInvalidCorporateAction = errors.New("invalid corporate action")
+
+type CorporateActionService struct {
+	corporateActionRepo repository.CorporateActionRepositorer
+	instrumentRepo      repository.InstrumentRepositorer
+	marketDataRepo      repository.MarketDataRepositorer
+	positionService     PositionServicer
+}
+
+func NewCorporateActionService(
+	corporateActionRepo repository.CorporateActionRepositorer,
+	instrumentRepo repository.InstrumentRepositorer,
+	marketDataRepo repository.MarketDataRepositorer,
+	positionService PositionServicer,
+) *CorporateActionService {
+	return &CorporateActionService{
+		corporateActionRepo: corporateActionRepo,
+		instrumentRepo:      instrumentRepo,
+		marketDataRepo:      marketDataRepo,
+		positionService:     positionService,
+	}
+}
+
+// RegisterCorporateAction validates and stores a corporate action. Splits
+// and mergers are applied when orders and prices are read, so they are
+// never rewritten, and the materialized positions of the holders are
+// restated once the action is effective. A ticker change renames the
+// instrument right away.
+func (s *CorporateActionService) RegisterCorporateAction(action *models.CorporateAction) error {
+	if action.EffectiveDate.IsZero() {
+		return fmt.Errorf("%w: effective date is required", ErrInvalidCorporateAction)
+	}
+
+	instrument, err := s.instrumentRepo.GetByID(action.InstrumentID)
+	if err != nil {
+		return fmt.Errorf("%w: invalid instrument", ErrInvalidCorporateAction)
+	}
+
+	action.Type = strings.ToUpper(action.Type)
+	switch action.Type {
+	case models.SplitAction:
+		if action.Ratio <= 1 {
+			return fmt.Errorf("%w: a split ratio must be greater than 1", ErrInvalidCorporateAction)
+		}
+	case models.ReverseSplitAction:
+		if action.Ratio <= 0 || action.Ratio >= 1 {
+			return fmt.Errorf("%w: a reverse split ratio must be between 0 and 1", ErrInvalidCorporateAction)
+		}
+	case models.MergerAction:
+		if action.Ratio <= 0 {
+			return fmt.Errorf("%w: a merger ratio must be positive", ErrInvalidCorporateAction)
+		}
+		if action.TargetInstrumentID == nil || *action.TargetInstrumentID == action.InstrumentID {
+			return fmt.Errorf("%w: a merger needs another target instrument", ErrInvalidCorporateAction)
+		}
+		if _, err := s.instrumentRepo.GetByID(*action.TargetInstrumentID); err != nil {
+			return fmt.Errorf("%w: invalid target instrument", ErrInvalidCorporateAction)
+		}
+	case models.TickerChangeAction:
+		action.NewTicker = strings.ToUpper(strings.TrimSpace(action.NewTicker))
+		if action.NewTicker == "" || action.NewTicker == instrument.Ticker {
+			return fmt.Errorf("%w: a ticker change needs a new ticker", ErrInvalidCorporateAction)
+		}
+		now := time.Now()
+		action.OldTicker = instrument.Ticker
+		action.Ratio = 0
+		action.AppliedAt = &now
+		return s.corporateActionRepo.CreateTickerChange(action)
+	default:
+		return fmt.Errorf("%w: unknown type %s", ErrInvalidCorporateAction, action.Type)
+	}
+
+	action.AppliedAt = nil
+	if err := s.corporateActionRepo.Create(action); err != nil {
+		return err
+	}
+
+	return s.positionService.ApplyCorporateActions(time.Now())
+}
+
+// GetPriceHistory returns the instrument's market data between from and to.
+// When adjusted is set, prices before each split are divided by its ratio
+// so the whole series is comparable with today's share count.
+func (s *CorporateActionService) GetPriceHistory(instrumentID uint, from, to time.Time, adjusted bool) ([]models.MarketData, error) {
+	data, err := s.marketDataRepo.GetMarketDataRange(instrumentID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	if !adjusted {
+		return data, nil
+	}
+
+	actions, err := loadCorporateActions(s.corporateActionRepo, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return actions.adjustMarketData(instrumentID, data), nil
+}
+
+// corporateActions holds the effective corporate actions of each
+// instrument, oldest first
+type corporateActions map[uint][]models.CorporateAction
+
+func loadCorporateActions(corporateActionRepo repository.CorporateActionRepositorer, asOf time.Time) (corporateActions, error) {
+	list, err := corporateActionRepo.GetEffectiveAsOf(asOf)
+	if err != nil {
+		return nil, err
+	}
+	actions := make(corporateActions)
+	for _, action := range list {
+		actions[action.InstrumentID] = append(actions[action.InstrumentID], action)
+	}
+	return actions, nil
+}
+
+// adjustOrders restates the orders that move a position (BUY, SELL and
+// securities transfers) placed before a split or merger in post-action
+// shares. Size is multiplied and price divided by the ratio, so cost basis
+// and cash are unchanged. Merged orders move to the target
+// instrument and follow its later actions.
+func (c corporateActions) adjustOrders(orders []models.Order) []models.Order {
+	if len(c) == 0 {
+		return orders
+	}
+	adjusted := make([]models.Order, len(orders))
+	for i, order := range orders {
+		if movesPosition(order.Side) {
+			order = c.adjustOrder(order)
+		}
+		adjusted[i] = order
+	}
+	return adjusted
+}
+
+// withMergedSources returns the instruments followed by the ones merged
+// into them, directly or through earlier mergers, whose orders adjustOrders
+// moves to the instruments
+func (c corporateActions) withMergedSources(instrumentIDs []uint) []uint {
+	result := append([]uint(nil), instrumentIDs...)
+	included := make(map[uint]bool, len(instrumentIDs))
+	for _, instrumentID := range instrumentIDs {
+		included[instrumentID] = true
+	}
+	for added := true; added; {
+		added = false
+		sources := make([]uint, 0)
+		for sourceID, actions := range c {
+			if included[sourceID] {
+				continue
+			}
+			for _, action := range actions {
+				if action.Type == models.MergerAction && included[*action.TargetInstrumentID] {
+					sources = append(sources, sourceID)
+					break
+				}
+			}
+		}
+		sort.Slice(sources, func(i, j int) bool { return sources[i] < sources[j] })
+		for _, sourceID := range sources {
+			included[sourceID] = true
+			result = append(result, sourceID)
+			added = true
+		}
+	}
+	return result
+}
+
+func (c corporateActions) adjustOrder(order models.Order) models.Order {
+	since := order.DateTime
+	for i := 0; i < len(c[order.InstrumentID]); i++ {
+		action := c[order.InstrumentID][i]
+		if !action.EffectiveDate.After(since) {
+			continue
+		}
+		switch action.Type {
+		case models.SplitAction, models.ReverseSplitAction:
+			order.Size *= action.Ratio
+			order.Price /= action.Ratio
+		case models.MergerAction:
+			order.Size *= action.Ratio
+			order.Price /= action.Ratio
+			order.InstrumentID = *action.TargetInstrumentID
+			since = action.EffectiveDate
+			i = -1
+		}
+	}
+	return order
+}
+
+// adjustMarketData divides the prices quoted before each split of the
+// instrument by its ratio. The original rows are left untouched.
+func (c corporateActions) adjustMarketData(instrumentID uint, data []models.MarketData) []models.MarketData {
+	splits := make([]models.CorporateAction, 0)
+	for _, action := range c[instrumentID] {
+		if action.Type == models.SplitAction || action.Type == models.ReverseSplitAction {
+			splits = append(splits, action)
+		}
+	}
+	if len(splits) == 0 {
+		return data
+	}
+
+	adjusted := make([]models.MarketData, len(data))
+	for i, row := range data {
+		factor := 1.0
+		for _, split := range splits {
+			if row.DateTime.Before(split.EffectiveDate) {
+				factor *= split.Ratio
+			}
+		}
+		row.Open /= factor
+		row.High /= factor
+		row.Low /= factor
+		row.Close /= factor
+		row.PreviousClose /= factor
+		adjusted[i] = row
+	}
+	return adjusted
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	mocks "github.com/NahuelDT/portfolio-api/internal/mocks/repository"
+	"github.com/NahuelDT/portfolio-api/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRegisterCorporateAction(t *testing.T) {
+	setUp := func() (*mocks.CorporateActionRepositorer, *mocks.InstrumentRepositorer, *mocks.PositionRepositorer, *CorporateActionService) {
+		mockCorporateActionRepo := new(mocks.CorporateActionRepositorer)
+		mockInstrumentRepo := new(mocks.InstrumentRepositorer)
+		mockPositionRepo := new(mocks.PositionRepositorer)
+		positionService := NewPositionService(nil, mockPositionRepo, mockCorporateActionRepo)
+		corporateActionService := NewCorporateActionService(mockCorporateActionRepo, mockInstrumentRepo, nil, positionService)
+		return mockCorporateActionRepo, mockInstrumentRepo, mockPositionRepo, corporateActionService
+	}
+	effectiveDate := time.Date(2024, time.June, 10, 0, 0, 0, 0, time.UTC)
+
+	t.Run("Register a split", func(t *testing.T) {
+		mockCorporateActionRepo, mockInstrumentRepo, mockPositionRepo, corporateActionService := setUp()
+
+		action := &models.CorporateAction{InstrumentID: 1, Type: "split", Ratio: 4, EffectiveDate: effectiveDate}
+		mockInstrumentRepo.On("GetByID", uint(1)).Return(&models.Instrument{ID: 1, Ticker: "AAPL"}, nil)
+		mockCorporateActionRepo.On("Create", action).Return(nil)
+		mockCorporateActionRepo.On("GetUnapplied", mock.AnythingOfType("time.Time")).Return(func(time.Time) []models.CorporateAction {
+			return []models.CorporateAction{*action}
+		}, nil)
+		mockPositionRepo.On("GetHolderIDs", uint(1)).Return([]uint{}, nil)
+		mockCorporateActionRepo.On("MarkApplied", mock.AnythingOfType("*models.CorporateAction")).Return(nil)
+
+		err := corporateActionService.RegisterCorporateAction(action)
+
+		assert.NoError(t, err)
+		assert.Equal(t, models.SplitAction, action.Type)
+		mockCorporateActionRepo.AssertExpectations(t)
+		mockPositionRepo.AssertExpectations(t)
+	})
+
+	t.Run("Ticker change renames the instrument", func(t *testing.T) {
+		mockCorporateActionRepo, mockInstrumentRepo, _, corporateActionService := setUp()
+
+		action := &models.CorporateAction{InstrumentID: 1, Type: models.TickerChangeAction, NewTicker: " meta ", EffectiveDate: effectiveDate}
+		mockInstrumentRepo.On("GetByID", uint(1)).Return(&models.Instrument{ID: 1, Ticker: "FB"}, nil)
+		mockCorporateActionRepo.On("CreateTickerChange", action).Return(nil)
+
+		err := corporateActionService.RegisterCorporateAction(action)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "FB", action.OldTicker)
+		assert.Equal(t, "META", action.NewTicker)
+		assert.NotNil(t, action.AppliedAt)
+		mockCorporateActionRepo.AssertExpectations(t)
+	})
+
+	t.Run("Invalid actions", func(t *testing.T) {
+		target := uint(1)
+		invalid := []*models.CorporateAction{
+			{InstrumentID: 1, Type: models.SplitAction, Ratio: 0.5, EffectiveDate: effectiveDate},
+			{InstrumentID: 1, Type: models.ReverseSplitAction, Ratio: 10, EffectiveDate: effectiveDate},
+			{InstrumentID: 1, Type: models.MergerAction, Ratio: 1, TargetInstrumentID: &target, EffectiveDate: effectiveDate},
+			{InstrumentID: 1, Type: models.TickerChangeAction, EffectiveDate: effectiveDate},
+			{InstrumentID: 1, Type: "SPINOFF", EffectiveDate: effectiveDate},
+			{InstrumentID: 1, Type: models.SplitAction, Ratio: 2},
+		}
+		for _, action := range invalid {
+			mockCorporateActionRepo, mockInstrumentRepo, _, corporateActionService := setUp()
+			mockInstrumentRepo.On("GetByID", uint(1)).Return(&models.Instrument{ID: 1, Ticker: "AAPL"}, nil)
+
+			err := corporateActionService.RegisterCorporateAction(action)
+
+			assert.True(t, errors.Is(err, ErrInvalidCorporateAction), action.Type)
+			mockCorporateActionRepo.AssertNotCalled(t, "Create", mock.Anything)
+		}
+	})
+}
+
+func TestGetPriceHistory(t *testing.T) {
+	mockCorporateActionRepo := new(mocks.CorporateActionRepositorer)
+	mockMarketDataRepo := new(mocks.MarketDataRepositorer)
+
+	corporateActionService := NewCorporateActionService(mockCorporateActionRepo, nil, mockMarketDataRepo, nil)
+
+	day := func(d int) time.Time {
+		return time.Date(2024, time.June, d, 20, 0, 0, 0, time.UTC)
+	}
+	from, to := day(1), day(30)
+	mockMarketDataRepo.On("GetMarketDataRange", uint(1), from, to).Return([]models.MarketData{
+		{InstrumentID: 1, Open: 396, High: 404, Low: 392, Close: 400, PreviousClose: 390, DateTime: day(7)},
+		{InstrumentID: 1, Open: 102, High: 103, Low: 99, Close: 101, PreviousClose: 100, DateTime: day(10)},
+	}, nil)
+	mockCorporateActionRepo.On("GetEffectiveAsOf", mock.AnythingOfType("time.Time")).Return([]models.CorporateAction{
+		{InstrumentID: 1, Type: models.SplitAction, Ratio: 4, EffectiveDate: time.Date(2024, time.June, 10, 0, 0, 0, 0, time.UTC)},
+		{InstrumentID: 2, Type: models.SplitAction, Ratio: 2, EffectiveDate: time.Date(2024, time.June, 20, 0, 0, 0, 0, time.UTC)},
+	}, nil)
+
+	t.Run("Split-adjusted prices", func(t *testing.T) {
+		prices, err := corporateActionService.GetPriceHistory(1, from, to, true)
+
+		assert.NoError(t, err)
+		assert.Len(t, prices, 2)
+		assert.Equal(t, models.MarketData{InstrumentID: 1, Open: 99, High: 101, Low: 98, Close: 100, PreviousClose: 97.5, DateTime: day(7)}, prices[0])
+		assert.Equal(t, float64(101), prices[1].Close)
+	})
+
+	t.Run("Raw prices are left untouched", func(t *testing.T) {
+		prices, err := corporateActionService.GetPriceHistory(1, from, to, false)
+
+		assert.NoError(t, err)
+		assert.Equal(t, float64(400), prices[0].Close)
+	})
+}
@@ -0,0 +1,173 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/NahuelDT/portfolio-api/internal/models"
+	"github.com/NahuelDT/portfolio-api/internal/repository"
+)
+
+var ErrInvalidDistribution = errors.New("invalid distribution")
+
+type DistributionService struct {
+	distributionRepo    repository.DistributionRepositorer
+	orderRepo           repository.OrderRepositorer
+	instrumentRepo      repository.InstrumentRepositorer
+	corporateActionRepo repository.CorporateActionRepositorer
+}
+
+func NewDistributionService(
+	distributionRepo repository.DistributionRepositorer,
+	orderRepo repository.OrderRepositorer,
+	instrumentRepo repository.InstrumentRepositorer,
+	corporateActionRepo repository.CorporateActionRepositorer,
+) *DistributionService {
+	return &DistributionService{
+		distributionRepo:    distributionRepo,
+		orderRepo:           orderRepo,
+		instrumentRepo:      instrumentRepo,
+		corporateActionRepo: corporateActionRepo,
+	}
+}
+
+// RegisterDistribution stores a new distribution. If its ex-date has
+// already passed, its holders are credited in the same transaction, so the
+// distribution is either stored and credited or not stored at all.
+func (s *DistributionService) RegisterDistribution(distribution *models.Distribution) error {
+	if distribution.AmountPerShare <= 0 {
+		return fmt.Errorf("%w: amount per share must be positive", ErrInvalidDistribution)
+	}
+	if distribution.ExDate.IsZero() || distribution.PayDate.Before(distribution.ExDate) {
+		return fmt.Errorf("%w: pay date must not be before the ex-date", ErrInvalidDistribution)
+	}
+
+	instrument, err := s.instrumentRepo.GetByID(distribution.InstrumentID)
+	if err != nil {
+		return fmt.Errorf("%w: invalid instrument", ErrInvalidDistribution)
+	}
+	if distribution.Currency == "" {
+		distribution.Currency = instrument.Currency
+	}
+	distribution.Currency = models.NormalizeCurrency(distribution.Currency)
+	distribution.CreditedAt = nil
+
+	now := time.Now()
+	if distribution.ExDate.After(now) {
+		return s.distributionRepo.Create(distribution)
+	}
+	credits, err := s.credits(distribution, now)
+	if err != nil {
+		return err
+	}
+	return s.distributionRepo.CreateCredited(distribution, credits)
+}
+
+// ProcessDistributions credits the holders of every distribution whose
+// ex-date has been reached, and fills the credits whose pay date has come.
+// Credits are DIVIDEND orders to each holding account, sized by the
+// quantity held before the ex-date, restated for the splits and mergers
+// effective by then, and priced at the amount per share; they stay PENDING
+// until the pay date.
+func (s *DistributionService) ProcessDistributions(now time.Time) error {
+	distributions, err := s.distributionRepo.GetUncredited(now)
+	if err != nil {
+		return err
+	}
+
+	for i := range distributions {
+		distribution := &distributions[i]
+		credits, err := s.credits(distribution, now)
+		if err != nil {
+			return err
+		}
+		if err := s.distributionRepo.CreditHolders(distribution, credits); err != nil {
+			return err
+		}
+	}
+
+	_, err = s.orderRepo.FillPendingOrders("DIVIDEND", now)
+	return err
+}
+
+// credits builds the DIVIDEND orders of a distribution for its holders,
+// FILLED if the pay date has come by now
+func (s *DistributionService) credits(distribution *models.Distribution, now time.Time) ([]models.Order, error) {
+	holders, err := s.holdersAsOf(distribution.InstrumentID, distribution.ExDate)
+	if err != nil {
+		return nil, err
+	}
+
+	status := "PENDING"
+	if !distribution.PayDate.After(now) {
+		status = "FILLED"
+	}
+	credits := make([]models.Order, 0, len(holders))
+	for _, holder := range holders {
+		credits = append(credits, models.Order{
+			InstrumentID:   distribution.InstrumentID,
+			UserID:         holder.UserID,
+			AccountID:      holder.AccountID,
+			Side:           "DIVIDEND",
+			Size:           holder.Quantity,
+			Price:          distribution.AmountPerShare,
+			Type:           "MARKET",
+			Status:         status,
+			Currency:       distribution.Currency,
+			DateTime:       distribution.PayDate,
+			SettlementDate: distribution.PayDate,
+		})
+	}
+	return credits, nil
+}
+
+// holdersAsOf returns the net quantity of the instrument held in each
+// user's account from the orders filled before the given time, skipping
+// accounts without a position. Orders are restated for the corporate
+// actions effective at that time, so holders of a split instrument are
+// counted in post-split shares and the holders of an instrument merged
+// into this one are included.
+func (s *DistributionService) holdersAsOf(instrumentID uint, asOf time.Time) ([]models.Holding, error) {
+	actions, err := loadCorporateActions(s.corporateActionRepo, asOf)
+	if err != nil {
+		return nil, err
+	}
+	orders, err := s.orderRepo.GetInstrumentOrdersBefore(actions.withMergedSources([]uint{instrumentID}), asOf)
+	if err != nil {
+		return nil, err
+	}
+
+	type holdingKey struct {
+		userID    uint
+		accountID uint
+	}
+	quantities := make(map[holdingKey]float64)
+	for _, order := range actions.adjustOrders(orders) {
+		if order.InstrumentID != instrumentID {
+			continue
+		}
+		key := holdingKey{order.UserID, order.AccountID}
+		switch order.Side {
+		case "BUY", "SECURITIES_IN":
+			quantities[key] += order.Size
+		case "SELL", "SECURITIES_OUT":
+			quantities[key] -= order.Size
+		}
+	}
+
+	holders := make([]models.Holding, 0, len(quantities))
+	for key, quantity := range quantities {
+		if quantity > 0 {
+			holders = append(holders, models.Holding{UserID: key.userID, AccountID: key.accountID, Quantity: quantity})
+		}
+	}
+	sort.Slice(holders, func(i, j int) bool {
+		if holders[i].UserID != holders[j].UserID {
+			return holders[i].UserID < holders[j].UserID
+		}
+		return holders[i].AccountID < holders[j].AccountID
+	})
+	return holders, nil
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	mocks "github.com/NahuelDT/portfolio-api/internal/mocks/repository"
+	"github.com/NahuelDT/portfolio-api/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRegisterDistribution(t *testing.T) {
+	setUp := func() (*mocks.DistributionRepositorer, *mocks.OrderRepositorer, *mocks.InstrumentRepositorer, *DistributionService) {
+		mockDistributionRepo := new(mocks.DistributionRepositorer)
+		mockOrderRepo := new(mocks.OrderRepositorer)
+		mockInstrumentRepo := new(mocks.InstrumentRepositorer)
+		mockCorporateActionRepo := new(mocks.CorporateActionRepositorer)
+		mockCorporateActionRepo.On("GetEffectiveAsOf", mock.Anything).Return([]models.CorporateAction{}, nil)
+		distributionService := NewDistributionService(mockDistributionRepo, mockOrderRepo, mockInstrumentRepo, mockCorporateActionRepo)
+		return mockDistributionRepo, mockOrderRepo, mockInstrumentRepo, distributionService
+	}
+
+	t.Run("Credit holders after the ex-date", func(t *testing.T) {
+		mockDistributionRepo, mockOrderRepo, mockInstrumentRepo, distributionService := setUp()
+
+		exDate := time.Now().AddDate(0, 0, -2)
+		payDate := time.Now().AddDate(0, 0, 5)
+		distribution := &models.Distribution{InstrumentID: 1, ExDate: exDate, PayDate: payDate, AmountPerShare: 0.5}
+
+		mockInstrumentRepo.On("GetByID", uint(1)).Return(&models.Instrument{ID: 1, Currency: "USD"}, nil)
+		mockOrderRepo.On("GetInstrumentOrdersBefore", []uint{1}, exDate).Return([]models.Order{
+			{ID: 1, InstrumentID: 1, UserID: 7, Side: "BUY", Size: 120, Price: 10, Status: "FILLED"},
+			{ID: 2, InstrumentID: 1, UserID: 7, Side: "SELL", Size: 20, Price: 11, Status: "FILLED"},
+			{ID: 3, InstrumentID: 1, UserID: 8, Side: "SECURITIES_IN", Size: 5, Status: "FILLED"},
+			{ID: 4, InstrumentID: 1, UserID: 8, Side: "SECURITIES_OUT", Size: 5, Status: "FILLED"},
+		}, nil)
+		mockDistributionRepo.On("CreateCredited", distribution, []models.Order{
+			{InstrumentID: 1, UserID: 7, Side: "DIVIDEND", Size: 100, Price: 0.5, Type: "MARKET", Status: "PENDING", Currency: "USD", DateTime: payDate, SettlementDate: payDate},
+		}).Return(nil)
+
+		err := distributionService.RegisterDistribution(distribution)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "USD", distribution.Currency)
+		mockDistributionRepo.AssertNotCalled(t, "Create", mock.Anything)
+		mockDistributionRepo.AssertExpectations(t)
+		mockOrderRepo.AssertExpectations(t)
+		mockInstrumentRepo.AssertExpectations(t)
+	})
+
+	t.Run("Nothing is stored when crediting fails", func(t *testing.T) {
+		mockDistributionRepo, mockOrderRepo, mockInstrumentRepo, distributionService := setUp()
+
+		exDate := time.Now().AddDate(0, 0, -2)
+		distribution := &models.Distribution{InstrumentID: 1, ExDate: exDate, PayDate: time.Now().AddDate(0, 0, 5), AmountPerShare: 0.5}
+
+		mockInstrumentRepo.On("GetByID", uint(1)).Return(&models.Instrument{ID: 1, Currency: "USD"}, nil)
+		mockOrderRepo.On("GetInstrumentOrdersBefore", []uint{1}, exDate).Return([]models.Order{
+			{ID: 1, InstrumentID: 1, UserID: 7, Side: "BUY", Size: 10, Price: 10, Status: "FILLED"},
+		}, nil)
+		mockDistributionRepo.On("CreateCredited", distribution, mock.Anything).Return(assert.AnError)
+
+		err := distributionService.RegisterDistribution(distribution)
+
+		assert.ErrorIs(t, err, assert.AnError)
+		mockDistributionRepo.AssertNotCalled(t, "Create", mock.Anything)
+		mockDistributionRepo.AssertExpectations(t)
+	})
+
+	t.Run("Store a distribution whose ex-date is ahead", func(t *testing.T) {
+		mockDistributionRepo, mockOrderRepo, mockInstrumentRepo, distributionService := setUp()
+
+		distribution := &models.Distribution{InstrumentID: 1, ExDate: time.Now().AddDate(0, 0, 3), PayDate: time.Now().AddDate(0, 0, 10), AmountPerShare: 0.5}
+
+		mockInstrumentRepo.On("GetByID", uint(1)).Return(&models.Instrument{ID: 1, Currency: "USD"}, nil)
+		mockDistributionRepo.On("Create", distribution).Return(nil)
+
+		err := distributionService.RegisterDistribution(distribution)
+
+		assert.NoError(t, err)
+		mockDistributionRepo.AssertNotCalled(t, "CreateCredited", mock.Anything, mock.Anything)
+		mockDistributionRepo.AssertExpectations(t)
+		mockOrderRepo.AssertNotCalled(t, "GetInstrumentOrdersBefore", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Credits are filled once the pay date is reached", func(t *testing.T) {
+		mockDistributionRepo, mockOrderRepo, _, distributionService := setUp()
+
+		now := time.Now()
+		distribution := models.Distribution{ID: 3, InstrumentID: 1, ExDate: now.AddDate(0, 0, -10), PayDate: now.AddDate(0, 0, -1), AmountPerShare: 2, Currency: "ARS"}
+
+		mockDistributionRepo.On("GetUncredited", now).Return([]models.Distribution{distribution}, nil)
+		mockOrderRepo.On("GetInstrumentOrdersBefore", []uint{1}, distribution.ExDate).Return([]models.Order{
+			{ID: 1, InstrumentID: 1, UserID: 7, Side: "BUY", Size: 10, Price: 10, Status: "FILLED"},
+		}, nil)
+		mockDistributionRepo.On("CreditHolders", mock.AnythingOfType("*models.Distribution"), []models.Order{
+			{InstrumentID: 1, UserID: 7, Side: "DIVIDEND", Size: 10, Price: 2, Type: "MARKET", Status: "FILLED", Currency: "ARS", DateTime: distribution.PayDate, SettlementDate: distribution.PayDate},
+		}).Return(nil)
+		mockOrderRepo.On("FillPendingOrders", "DIVIDEND", now).Return(int64(3), nil)
+
+		err := distributionService.ProcessDistributions(now)
+
+		assert.NoError(t, err)
+		mockDistributionRepo.AssertExpectations(t)
+		mockOrderRepo.AssertExpectations(t)
+	})
+
+	t.Run("Holders are counted in post-split shares", func(t *testing.T) {
+		mockDistributionRepo := new(mocks.DistributionRepositorer)
+		mockOrderRepo := new(mocks.OrderRepositorer)
+		mockCorporateActionRepo := new(mocks.CorporateActionRepositorer)
+		distributionService := NewDistributionService(mockDistributionRepo, mockOrderRepo, new(mocks.InstrumentRepositorer), mockCorporateActionRepo)
+
+		now := time.Now()
+		targetID := uint(1)
+		distribution := models.Distribution{ID: 4, InstrumentID: 1, ExDate: now.AddDate(0, 0, -5), PayDate: now.AddDate(0, 0, 5), AmountPerShare: 1, Currency: "ARS"}
+
+		mockDistributionRepo.On("GetUncredited", now).Return([]models.Distribution{distribution}, nil)
+		mockCorporateActionRepo.On("GetEffectiveAsOf", distribution.ExDate).Return([]models.CorporateAction{
+			{ID: 1, InstrumentID: 1, Type: models.SplitAction, Ratio: 2, EffectiveDate: now.AddDate(0, 0, -20)},
+			{ID: 2, InstrumentID: 2, Type: models.MergerAction, Ratio: 0.5, TargetInstrumentID: &targetID, EffectiveDate: now.AddDate(0, 0, -10)},
+		}, nil)
+		mockOrderRepo.On("GetInstrumentOrdersBefore", []uint{1, 2}, distribution.ExDate).Return([]models.Order{
+			{ID: 1, InstrumentID: 1, UserID: 7, Side: "BUY", Size: 10, Price: 100, Status: "FILLED", DateTime: now.AddDate(0, 0, -30)},
+			{ID: 2, InstrumentID: 2, UserID: 8, Side: "BUY", Size: 6, Price: 50, Status: "FILLED", DateTime: now.AddDate(0, 0, -30)},
+		}, nil)
+		mockDistributionRepo.On("CreditHolders", mock.AnythingOfType("*models.Distribution"), []models.Order{
+			{InstrumentID: 1, UserID: 7, Side: "DIVIDEND", Size: 20, Price: 1, Type: "MARKET", Status: "PENDING", Currency: "ARS", DateTime: distribution.PayDate, SettlementDate: distribution.PayDate},
+			{InstrumentID: 1, UserID: 8, Side: "DIVIDEND", Size: 3, Price: 1, Type: "MARKET", Status: "PENDING", Currency: "ARS", DateTime: distribution.PayDate, SettlementDate: distribution.PayDate},
+		}).Return(nil)
+		mockOrderRepo.On("FillPendingOrders", "DIVIDEND", now).Return(int64(0), nil)
+
+		err := distributionService.ProcessDistributions(now)
+
+		assert.NoError(t, err)
+		mockDistributionRepo.AssertExpectations(t)
+		mockOrderRepo.AssertExpectations(t)
+	})
+
+	t.Run("Reject non-positive amount", func(t *testing.T) {
+		_, _, _, distributionService := setUp()
+
+		err := distributionService.RegisterDistribution(&models.Distribution{InstrumentID: 1, ExDate: time.Now(), PayDate: time.Now()})
+
+		assert.ErrorIs(t, err, ErrInvalidDistribution)
+	})
+
+	t.Run("Reject pay date before ex-date", func(t *testing.T) {
+		_, _, _, distributionService := setUp()
+
+		now := time.Now()
+		err := distributionService.RegisterDistribution(&models.Distribution{InstrumentID: 1, ExDate: now, PayDate: now.AddDate(0, 0, -1), AmountPerShare: 1})
+
+		assert.ErrorIs(t, err, ErrInvalidDistribution)
+	})
+}
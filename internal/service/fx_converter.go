@@ -0,0 +1,160 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/NahuelDT/portfolio-api/internal/models"
+	"github.com/NahuelDT/portfolio-api/internal/repository"
+)
+
+// ErrNoFXRate is returned when a valuation needs an FX rate that is not
+// loaded in either direction
+var ErrNoFXRate = errors.New("no FX rate")
+
+// fxConverter looks up and caches FX rates for a single valuation. When asOf
+// is set, current rates are the latest ones at or before it.
+type fxConverter struct {
+	fxRateRepo repository.FXRateRepositorer
+	asOf       *time.Time
+	rates      map[string]float64
+}
+
+func newFXConverter(fxRateRepo repository.FXRateRepositorer, asOf *time.Time) *fxConverter {
+	return &fxConverter{
+		fxRateRepo: fxRateRepo,
+		asOf:       asOf,
+		rates:      make(map[string]float64),
+	}
+}
+
+// rate returns the current rate to convert from one currency to another
+func (c *fxConverter) rate(from, to string) (float64, error) {
+	if c.asOf != nil {
+		return c.rateAt(from, to, *c.asOf)
+	}
+	if from == to {
+		return 1, nil
+	}
+
+	key := from + "/" + to
+	if rate, ok := c.rates[key]; ok {
+		return rate, nil
+	}
+	rate, err := lookupRate(c.fxRateRepo.GetLatestRate, from, to)
+	if err != nil {
+		return 0, err
+	}
+	c.rates[key] = rate
+	return rate, nil
+}
+
+// rateAt returns the rate to convert from one currency to another at the
+// given moment
+func (c *fxConverter) rateAt(from, to string, at time.Time) (float64, error) {
+	if from == to {
+		return 1, nil
+	}
+
+	key := from + "/" + to + "@" + at.UTC().Format(time.RFC3339Nano)
+	if rate, ok := c.rates[key]; ok {
+		return rate, nil
+	}
+	rate, err := lookupRate(func(base, quote string) (*models.FXRate, error) {
+		return c.fxRateRepo.GetRateAsOf(base, quote, at)
+	}, from, to)
+	if err != nil {
+		return 0, err
+	}
+	c.rates[key] = rate
+	return rate, nil
+}
+
+// lookupRate tries the direct pair first and falls back to inverting the
+// opposite pair
+func lookupRate(get func(baseCurrency, quoteCurrency string) (*models.FXRate, error), from, to string) (float64, error) {
+	if fxRate, err := get(from, to); err == nil && fxRate.Rate > 0 {
+		return fxRate.Rate, nil
+	}
+	fxRate, err := get(to, from)
+	if err != nil {
+		return 0, fmt.Errorf("%w from %s to %s: %w", ErrNoFXRate, from, to, err)
+	}
+	if fxRate.Rate <= 0 {
+		return 0, fmt.Errorf("invalid FX rate from %s to %s", to, from)
+	}
+	return 1 / fxRate.Rate, nil
+}
+
+// fxHistory holds the rates of several currencies to a base currency over
+// time, to convert amounts at many dates without a query per date. Dates
+// before the first known rate of a currency use that rate.
+type fxHistory struct {
+	baseCurrency string
+	rates        map[string][]pricePoint
+}
+
+// loadFXHistory loads the rates of the currencies to baseCurrency up to the
+// given time, from the direct pair or else by inverting the opposite one
+func loadFXHistory(fxRateRepo repository.FXRateRepositorer, currencies []string, baseCurrency string, to time.Time) (*fxHistory, error) {
+	history := &fxHistory{baseCurrency: baseCurrency, rates: make(map[string][]pricePoint)}
+	for _, currency := range currencies {
+		if currency == baseCurrency {
+			continue
+		}
+		if _, ok := history.rates[currency]; ok {
+			continue
+		}
+
+		rates, err := fxRateRepo.GetRateHistory(currency, baseCurrency, to)
+		if err != nil {
+			return nil, err
+		}
+		inverted := false
+		if len(rates) == 0 {
+			if rates, err = fxRateRepo.GetRateHistory(baseCurrency, currency, to); err != nil {
+				return nil, err
+			}
+			inverted = true
+		}
+
+		points := make([]pricePoint, 0, len(rates))
+		for _, rate := range rates {
+			if rate.Rate <= 0 {
+				continue
+			}
+			if inverted {
+				points = append(points, pricePoint{date: rate.DateTime, price: 1 / rate.Rate})
+			} else {
+				points = append(points, pricePoint{date: rate.DateTime, price: rate.Rate})
+			}
+		}
+		if len(points) == 0 {
+			return nil, fmt.Errorf("%w from %s to %s", ErrNoFXRate, currency, baseCurrency)
+		}
+		history.rates[currency] = points
+	}
+	return history, nil
+}
+
+// rateAt returns the rate to convert from the currency to the base currency
+// at the given moment. Only the base currency and the loaded currencies
+// can be converted.
+func (h *fxHistory) rateAt(currency string, at time.Time) float64 {
+	if currency == h.baseCurrency {
+		return 1
+	}
+	points := h.rates[currency]
+	if len(points) == 0 {
+		return 0
+	}
+	i := sort.Search(len(points), func(i int) bool {
+		return points[i].date.After(at)
+	})
+	if i == 0 {
+		return points[0].price
+	}
+	return points[i-1].price
+}
@@ -0,0 +1,44 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/NahuelDT/portfolio-api/internal/models"
+	"github.com/NahuelDT/portfolio-api/internal/repository"
+)
+
+var ErrInvalidFXRate = errors.New("invalid FX rate")
+
+type FXRateService struct {
+	fxRateRepo repository.FXRateRepositorer
+}
+
+func NewFXRateService(fxRateRepo repository.FXRateRepositorer) *FXRateService {
+	return &FXRateService{fxRateRepo: fxRateRepo}
+}
+
+// CreateRate validates and stores the rate of a currency pair. Rates without
+// a date are taken as of now.
+func (s *FXRateService) CreateRate(rate *models.FXRate) error {
+	if strings.TrimSpace(rate.BaseCurrency) == "" || strings.TrimSpace(rate.QuoteCurrency) == "" {
+		return fmt.Errorf("%w: base and quote currencies are required", ErrInvalidFXRate)
+	}
+	rate.BaseCurrency = models.NormalizeCurrency(rate.BaseCurrency)
+	rate.QuoteCurrency = models.NormalizeCurrency(rate.QuoteCurrency)
+	if rate.BaseCurrency == rate.QuoteCurrency {
+		return fmt.Errorf("%w: base and quote currencies must differ", ErrInvalidFXRate)
+	}
+	if rate.Rate <= 0 || math.IsInf(rate.Rate, 0) || math.IsNaN(rate.Rate) {
+		return fmt.Errorf("%w: rate must be positive", ErrInvalidFXRate)
+	}
+	if rate.DateTime.IsZero() {
+		rate.DateTime = time.Now()
+	}
+
+	rate.ID = 0
+	return s.fxRateRepo.Create(rate)
+}
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	mocks "github.com/NahuelDT/portfolio-api/internal/mocks/repository"
+	"github.com/NahuelDT/portfolio-api/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestFXRates(t *testing.T) {
+	t.Run("Create a rate", func(t *testing.T) {
+		mockFXRateRepo := new(mocks.FXRateRepositorer)
+		fxRateService := NewFXRateService(mockFXRateRepo)
+
+		rate := &models.FXRate{BaseCurrency: " usd", QuoteCurrency: "ars", Rate: 1000}
+		mockFXRateRepo.On("Create", rate).Return(nil)
+
+		err := fxRateService.CreateRate(rate)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "USD", rate.BaseCurrency)
+		assert.Equal(t, "ARS", rate.QuoteCurrency)
+		assert.WithinDuration(t, time.Now(), rate.DateTime, time.Minute)
+		mockFXRateRepo.AssertExpectations(t)
+	})
+
+	t.Run("Reject invalid rates", func(t *testing.T) {
+		mockFXRateRepo := new(mocks.FXRateRepositorer)
+		fxRateService := NewFXRateService(mockFXRateRepo)
+
+		for _, rate := range []models.FXRate{
+			{BaseCurrency: "USD", Rate: 1000},
+			{BaseCurrency: "USD", QuoteCurrency: "usd", Rate: 1},
+			{BaseCurrency: "USD", QuoteCurrency: "ARS", Rate: 0},
+			{BaseCurrency: "USD", QuoteCurrency: "ARS", Rate: -5},
+		} {
+			err := fxRateService.CreateRate(&rate)
+			assert.ErrorIs(t, err, ErrInvalidFXRate)
+		}
+		mockFXRateRepo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+
+	t.Run("Missing rates are reported as such", func(t *testing.T) {
+		mockFXRateRepo := new(mocks.FXRateRepositorer)
+		mockFXRateRepo.On("GetLatestRate", mock.Anything, mock.Anything).Return(nil, errors.New("record not found"))
+		mockFXRateRepo.On("GetRateHistory", mock.Anything, mock.Anything, mock.Anything).Return([]models.FXRate{}, nil)
+
+		_, err := newFXConverter(mockFXRateRepo, nil).rate("USD", "ARS")
+		assert.ErrorIs(t, err, ErrNoFXRate)
+
+		_, err = loadFXHistory(mockFXRateRepo, []string{"USD"}, "ARS", time.Now())
+		assert.ErrorIs(t, err, ErrNoFXRate)
+	})
+}
@@ -1,6 +1,9 @@
 package service
 
 import (
+	"io"
+	"time"
+
 	"github.com/NahuelDT/portfolio-api/internal/models"
 )
 
@@ -10,9 +13,105 @@ type OrderServicer interface {
 }
 
 type PortfolioServicer interface {
-	GetPortfolio(userID uint) (*models.Portfolio, error)
+	GetPortfolio(userID uint, baseCurrency string) (*models.Portfolio, error)
+	GetPortfolioAsOf(userID uint, asOf time.Time, baseCurrency string) (*models.Portfolio, error)
+	GetAccountPortfolio(userID, accountID uint, baseCurrency string) (*models.Portfolio, error)
+	GetAccountPortfolioAsOf(userID, accountID uint, asOf time.Time, baseCurrency string) (*models.Portfolio, error)
+	GetAllocation(userID uint, groupBy string, baseCurrency string) (*models.PortfolioAllocation, error)
+}
+
+type AccountServicer interface {
+	CreateAccount(account *models.Account) error
+	GetAccounts(userID uint) ([]models.Account, error)
+}
+
+type TransferServicer interface {
+	Transfer(transfer *models.Transfer) error
+	GetTransfers(userID uint) ([]models.Transfer, error)
+}
+
+type FXRateServicer interface {
+	CreateRate(rate *models.FXRate) error
+}
+
+type PerformanceServicer interface {
+	GetPerformance(userID uint, period string, baseCurrency string) (*models.Performance, error)
+	GetPerformanceBetween(userID uint, from, to time.Time, baseCurrency string) (*models.Performance, error)
+	CompareToBenchmark(userID uint, benchmarkID uint, period string, baseCurrency string) (*models.BenchmarkComparison, error)
+}
+
+type RiskServicer interface {
+	GetRisk(userID uint, period string, baseCurrency string, benchmarkID uint, riskFreeRate float64) (*models.PortfolioRisk, error)
+}
+
+type TaxServicer interface {
+	GetCapitalGains(userID uint, year int) (*models.CapitalGainsReport, error)
+}
+
+type StatementServicer interface {
+	GenerateStatement(userID uint, periodType string, year, number int, baseCurrency string) (*models.AccountStatement, error)
+	GetStatements(userID uint) ([]models.Statement, error)
+	GetStatement(statementID uint) (*models.AccountStatement, error)
+}
+
+type DistributionServicer interface {
+	RegisterDistribution(distribution *models.Distribution) error
+	ProcessDistributions(now time.Time) error
+}
+
+type CorporateActionServicer interface {
+	RegisterCorporateAction(action *models.CorporateAction) error
+	GetPriceHistory(instrumentID uint, from, to time.Time, adjusted bool) ([]models.MarketData, error)
+}
+
+type PositionServicer interface {
+	RebuildUser(userID uint) error
+	RebuildAll() (int, error)
+	CheckUser(userID uint) ([]models.BalanceMismatch, error)
+	CheckAll() ([]models.BalanceMismatch, error)
+	ApplyCorporateActions(now time.Time) error
+}
+
+type WatchlistServicer interface {
+	CreateWatchlist(watchlist *models.Watchlist) error
+	GetWatchlists(userID uint) ([]models.Watchlist, error)
+	GetWatchlist(watchlistID uint) (*models.Watchlist, error)
+	UpdateWatchlist(watchlistID uint, name string, instrumentIDs []uint) (*models.Watchlist, error)
+	AddInstrument(watchlistID, instrumentID uint) (*models.Watchlist, error)
+	RemoveInstrument(watchlistID, instrumentID uint) (*models.Watchlist, error)
+	DeleteWatchlist(watchlistID uint) error
+	GetQuotes(watchlistID uint, sortBy, order string) (*models.WatchlistSnapshot, error)
+}
+
+type AlertServicer interface {
+	CreateAlert(alert *models.PriceAlert) error
+	GetAlerts(userID uint) ([]models.PriceAlert, error)
+	DeleteAlert(userID, alertID uint) error
+	GetTriggers(userID uint) ([]models.AlertTrigger, error)
+	SetWebhook(userID uint, webhookURL string) (*models.AlertWebhook, error)
+	EvaluateMarketData(previous, data *models.MarketData) ([]models.AlertTrigger, error)
+	Deliver(trigger *models.AlertTrigger) error
+	RetryDeliveries() (int, error)
 }
 
 type SearchServicer interface {
 	SearchAssets(query string) ([]SearchResult, error)
 }
+
+type LedgerServicer interface {
+	GetJournal(userID uint, from, to time.Time) ([]models.JournalEntry, error)
+	GetTrialBalance() (*models.TrialBalance, error)
+	RebuildUser(userID uint) error
+	RebuildAll() (int, error)
+}
+
+type WithdrawalServicer interface {
+	GetPending() ([]models.Order, error)
+	Approve(orderID uint, reviewer, note string) (*models.Order, error)
+	Reject(orderID uint, reviewer, note string) (*models.Order, error)
+	GetAuditTrail(orderID uint) ([]models.WithdrawalAudit, error)
+}
+
+type PriceImportServicer interface {
+	Import(file io.Reader, dryRun bool) (*models.PriceImportReport, error)
+}
@@ -0,0 +1,153 @@
+package service
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/NahuelDT/portfolio-api/internal/models"
+	"github.com/NahuelDT/portfolio-api/internal/repository"
+)
+
+type LedgerService struct {
+	ledgerRepo          repository.LedgerRepositorer
+	orderRepo           repository.OrderRepositorer
+	corporateActionRepo repository.CorporateActionRepositorer
+}
+
+func NewLedgerService(
+	ledgerRepo repository.LedgerRepositorer,
+	orderRepo repository.OrderRepositorer,
+	corporateActionRepo repository.CorporateActionRepositorer,
+) *LedgerService {
+	return &LedgerService{
+		ledgerRepo:          ledgerRepo,
+		orderRepo:           orderRepo,
+		corporateActionRepo: corporateActionRepo,
+	}
+}
+
+// GetJournal lists the user's journal entries posted between from and to
+func (s *LedgerService) GetJournal(userID uint, from, to time.Time) ([]models.JournalEntry, error) {
+	return s.ledgerRepo.GetUserEntries(userID, from, to)
+}
+
+// GetTrialBalance adds up every ledger account and checks that each entry
+// balances and that the materialized cash balances match the ledger
+func (s *LedgerService) GetTrialBalance() (*models.TrialBalance, error) {
+	lines, err := s.ledgerRepo.GetTrialBalance()
+	if err != nil {
+		return nil, err
+	}
+	unbalanced, err := s.ledgerRepo.GetUnbalancedEntries(balanceTolerance)
+	if err != nil {
+		return nil, err
+	}
+	mismatches, err := s.ledgerRepo.GetCashMismatches(balanceTolerance)
+	if err != nil {
+		return nil, err
+	}
+
+	trialBalance := &models.TrialBalance{
+		GeneratedAt:       time.Now(),
+		Lines:             lines,
+		Totals:            make([]models.TrialBalanceTotal, 0),
+		UnbalancedEntries: unbalanced,
+		CashMismatches:    mismatches,
+		Balanced:          len(unbalanced) == 0 && len(mismatches) == 0,
+	}
+	if trialBalance.Lines == nil {
+		trialBalance.Lines = make([]models.TrialBalanceLine, 0)
+	}
+	if trialBalance.UnbalancedEntries == nil {
+		trialBalance.UnbalancedEntries = make([]uint, 0)
+	}
+
+	totals := make(map[string]*models.TrialBalanceTotal)
+	for _, line := range lines {
+		total, ok := totals[line.Currency]
+		if !ok {
+			trialBalance.Totals = append(trialBalance.Totals, models.TrialBalanceTotal{Currency: line.Currency})
+			total = &trialBalance.Totals[len(trialBalance.Totals)-1]
+			totals[line.Currency] = total
+		}
+		total.Debits += line.Debits
+		total.Credits += line.Credits
+	}
+	for _, total := range trialBalance.Totals {
+		if math.Abs(total.Debits-total.Credits) > balanceTolerance*math.Max(1, total.Debits) {
+			trialBalance.Balanced = false
+		}
+	}
+	return trialBalance, nil
+}
+
+// RebuildUser replaces the user's journal with the one replayed from their
+// order history, restated by the effective corporate actions
+func (s *LedgerService) RebuildUser(userID uint) error {
+	actions, err := loadCorporateActions(s.corporateActionRepo, time.Now())
+	if err != nil {
+		return err
+	}
+	return s.ledgerRepo.RebuildUserEntries(userID, func(orders []models.Order) []models.JournalEntry {
+		return replayJournal(actions.adjustOrders(orders))
+	})
+}
+
+// RebuildAll rebuilds the journal of every user with orders and returns how
+// many were rebuilt
+func (s *LedgerService) RebuildAll() (int, error) {
+	userIDs, err := s.orderRepo.GetUserIDs()
+	if err != nil {
+		return 0, err
+	}
+	for i, userID := range userIDs {
+		if err := s.RebuildUser(userID); err != nil {
+			return i, err
+		}
+	}
+	return len(userIDs), nil
+}
+
+// replayJournal builds the journal entries of filled orders in the order
+// they were filled, tracking the average cost of each position the same way
+// fills post them
+func replayJournal(orders []models.Order) []models.JournalEntry {
+	sorted := make([]models.Order, len(orders))
+	copy(sorted, orders)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if !sorted[i].DateTime.Equal(sorted[j].DateTime) {
+			return sorted[i].DateTime.Before(sorted[j].DateTime)
+		}
+		return sorted[i].ID < sorted[j].ID
+	})
+
+	type positionKey struct{ accountID, instrumentID uint }
+	type boughtTotals struct{ quantity, cost float64 }
+	bought := make(map[positionKey]*boughtTotals)
+
+	entries := make([]models.JournalEntry, 0, len(sorted))
+	for i := range sorted {
+		order := &sorted[i]
+		key := positionKey{order.AccountID, order.InstrumentID}
+		totals, ok := bought[key]
+		if !ok {
+			totals = &boughtTotals{}
+			bought[key] = totals
+		}
+
+		averageCost := 0.0
+		if totals.quantity > 0 {
+			averageCost = totals.cost / totals.quantity
+		}
+		if entry := models.NewJournalEntry(order, averageCost); entry != nil {
+			entries = append(entries, *entry)
+		}
+
+		if order.Side == "BUY" || order.Side == "SECURITIES_IN" {
+			totals.quantity += order.Size
+			totals.cost += order.Size * order.Price
+		}
+	}
+	return entries
+}
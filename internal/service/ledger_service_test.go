@@ -0,0 +1,141 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	mocks "github.com/NahuelDT/portfolio-api/internal/mocks/repository"
+	"github.com/NahuelDT/portfolio-api/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestLedger(t *testing.T) {
+	setUp := func() (*mocks.LedgerRepositorer, *mocks.OrderRepositorer, *mocks.CorporateActionRepositorer, *LedgerService) {
+		mockLedgerRepo := new(mocks.LedgerRepositorer)
+		mockOrderRepo := new(mocks.OrderRepositorer)
+		mockCorporateActionRepo := new(mocks.CorporateActionRepositorer)
+		ledgerService := NewLedgerService(mockLedgerRepo, mockOrderRepo, mockCorporateActionRepo)
+		return mockLedgerRepo, mockOrderRepo, mockCorporateActionRepo, ledgerService
+	}
+	date := func(month time.Month, day int) time.Time {
+		return time.Date(2024, month, day, 15, 0, 0, 0, time.UTC)
+	}
+	sum := func(entries []models.JournalEntry, account string) float64 {
+		total := 0.0
+		for _, entry := range entries {
+			for _, posting := range entry.Postings {
+				if posting.LedgerAccount == account {
+					total += posting.Amount
+				}
+			}
+		}
+		return total
+	}
+
+	t.Run("Rebuild the journal from the order history", func(t *testing.T) {
+		mockLedgerRepo, _, mockCorporateActionRepo, ledgerService := setUp()
+
+		userID := uint(1)
+		orders := []models.Order{
+			{ID: 4, UserID: userID, InstrumentID: 1, Side: "SELL", Size: 5, Price: 80, Status: "FILLED", DateTime: date(time.March, 1)},
+			{ID: 1, UserID: userID, Side: "CASH_IN", Size: 2000, Status: "FILLED", DateTime: date(time.January, 2)},
+			{ID: 2, UserID: userID, InstrumentID: 1, Side: "BUY", Size: 10, Price: 50, Status: "FILLED", DateTime: date(time.January, 10)},
+			{ID: 3, UserID: userID, InstrumentID: 1, Side: "BUY", Size: 10, Price: 70, Status: "FILLED", DateTime: date(time.February, 10)},
+			{ID: 5, UserID: userID, InstrumentID: 1, Side: "DIVIDEND", Size: 15, Price: 2, Status: "FILLED", DateTime: date(time.March, 20)},
+			{ID: 6, UserID: userID, Side: "CASH_OUT", Size: 300, Status: "FILLED", DateTime: date(time.April, 1)},
+			{ID: 7, UserID: userID, Side: "CASH_IN", Size: 0, Status: "FILLED", DateTime: date(time.April, 2)},
+		}
+		mockCorporateActionRepo.On("GetEffectiveAsOf", mock.AnythingOfType("time.Time")).Return([]models.CorporateAction{}, nil)
+
+		var entries []models.JournalEntry
+		mockLedgerRepo.On("RebuildUserEntries", userID, mock.Anything).Run(func(args mock.Arguments) {
+			entries = args.Get(1).(func([]models.Order) []models.JournalEntry)(orders)
+		}).Return(nil)
+
+		err := ledgerService.RebuildUser(userID)
+
+		assert.NoError(t, err)
+		// The empty deposit posts nothing
+		assert.Len(t, entries, 6)
+		for _, entry := range entries {
+			total := 0.0
+			for _, posting := range entry.Postings {
+				assert.Equal(t, "ARS", posting.Currency)
+				total += posting.Amount
+			}
+			assert.InDelta(t, 0, total, 1e-9, "entry of order %d", entry.OrderID)
+		}
+
+		// The sale takes out 5 shares at the average cost of 60
+		sale := entries[3]
+		assert.Equal(t, uint(4), sale.OrderID)
+		assert.Equal(t, "SELL", sale.Description)
+		assert.Len(t, sale.Postings, 3)
+		assert.Equal(t, models.LedgerCash, sale.Postings[0].LedgerAccount)
+		assert.Equal(t, float64(400), sale.Postings[0].Amount)
+		assert.Equal(t, models.LedgerSecurities, sale.Postings[1].LedgerAccount)
+		assert.Equal(t, float64(-300), sale.Postings[1].Amount)
+		assert.Equal(t, models.LedgerRealizedGains, sale.Postings[2].LedgerAccount)
+		assert.Equal(t, float64(-100), sale.Postings[2].Amount)
+
+		assert.Equal(t, float64(2000-500-700+400+30-300), sum(entries, models.LedgerCash))
+		assert.Equal(t, float64(900), sum(entries, models.LedgerSecurities))
+		assert.Equal(t, float64(-1700), sum(entries, models.LedgerContributions))
+		assert.Equal(t, float64(-30), sum(entries, models.LedgerIncome))
+	})
+
+	t.Run("Transfers in kind move securities at their carried cost", func(t *testing.T) {
+		entries := replayJournal([]models.Order{
+			{ID: 1, UserID: 1, AccountID: 2, InstrumentID: 1, Side: "SECURITIES_IN", Size: 10, Price: 40, Currency: "usd", DateTime: date(time.January, 2)},
+			{ID: 2, UserID: 1, AccountID: 2, InstrumentID: 1, Side: "SELL", Size: 10, Price: 30, Currency: "USD", DateTime: date(time.January, 3)},
+		})
+
+		assert.Len(t, entries, 2)
+		assert.Equal(t, uint(2), entries[0].AccountID)
+		assert.Equal(t, "USD", entries[0].Postings[0].Currency)
+		assert.Equal(t, float64(-400), sum(entries, models.LedgerTransfers))
+		assert.Equal(t, float64(0), sum(entries, models.LedgerSecurities))
+		assert.Equal(t, float64(100), sum(entries, models.LedgerRealizedGains))
+	})
+
+	t.Run("Balanced trial balance", func(t *testing.T) {
+		mockLedgerRepo, _, _, ledgerService := setUp()
+
+		mockLedgerRepo.On("GetTrialBalance").Return([]models.TrialBalanceLine{
+			{LedgerAccount: models.LedgerCash, Currency: "ARS", Debits: 1500, Credits: 500, Balance: 1000},
+			{LedgerAccount: models.LedgerContributions, Currency: "ARS", Credits: 1500, Balance: -1500},
+			{LedgerAccount: models.LedgerSecurities, Currency: "ARS", Debits: 500, Balance: 500},
+			{LedgerAccount: models.LedgerCash, Currency: "USD", Debits: 10, Balance: 10},
+			{LedgerAccount: models.LedgerTransfers, Currency: "USD", Credits: 10, Balance: -10},
+		}, nil)
+		mockLedgerRepo.On("GetUnbalancedEntries", balanceTolerance).Return([]uint{}, nil)
+		mockLedgerRepo.On("GetCashMismatches", balanceTolerance).Return([]models.BalanceMismatch{}, nil)
+
+		trialBalance, err := ledgerService.GetTrialBalance()
+
+		assert.NoError(t, err)
+		assert.True(t, trialBalance.Balanced)
+		assert.Len(t, trialBalance.Lines, 5)
+		assert.Equal(t, []models.TrialBalanceTotal{
+			{Currency: "ARS", Debits: 2000, Credits: 2000},
+			{Currency: "USD", Debits: 10, Credits: 10},
+		}, trialBalance.Totals)
+	})
+
+	t.Run("Report unbalanced entries and cash mismatches", func(t *testing.T) {
+		mockLedgerRepo, _, _, ledgerService := setUp()
+
+		mismatch := models.BalanceMismatch{UserID: 1, Kind: models.CashMismatch, Key: "ARS", Field: "balance", Stored: 1000, Expected: 900}
+		mockLedgerRepo.On("GetTrialBalance").Return([]models.TrialBalanceLine{}, nil)
+		mockLedgerRepo.On("GetUnbalancedEntries", balanceTolerance).Return([]uint{7}, nil)
+		mockLedgerRepo.On("GetCashMismatches", balanceTolerance).Return([]models.BalanceMismatch{mismatch}, nil)
+
+		trialBalance, err := ledgerService.GetTrialBalance()
+
+		assert.NoError(t, err)
+		assert.False(t, trialBalance.Balanced)
+		assert.Equal(t, []uint{7}, trialBalance.UnbalancedEntries)
+		assert.Equal(t, []models.BalanceMismatch{mismatch}, trialBalance.CashMismatches)
+	})
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/NahuelDT/portfolio-api/internal/models"
+	"github.com/NahuelDT/portfolio-api/internal/repository"
+)
+
+// Number of candles in a page when no limit is given, and the most a page
+// can have
+const (
+	defaultCandleLimit = 500
+	maxCandleLimit     = 5000
+)
+
+var (
+	ErrInvalidCandles = errors.New("invalid candle request")
+	// ErrDuplicateMarketData is returned when creating a price for an
+	// instrument that already has one on the same date
+	ErrDuplicateMarketData = errors.New("duplicate market data")
+)
+
+// MarketDataListener is notified of every market data row created through a
+// MarketDataService, and of the latest row of each instrument in an upsert.
+// previous is the instrument's latest row before data was stored, or nil if
+// it is the first one.
+type MarketDataListener interface {
+	OnMarketData(previous, data *models.MarketData)
+}
+
+// MarketDataService stores market data and notifies the listeners of the new
+// rows. It implements repository.MarketDataRepositorer, so whatever
+// writes prices through it, such as a feed or an import, reaches them.
+type MarketDataService struct {
+	repository.MarketDataRepositorer
+	listeners []MarketDataListener
+}
+
+func NewMarketDataService(marketDataRepo repository.MarketDataRepositorer, listeners ...MarketDataListener) *MarketDataService {
+	return &MarketDataService{
+		MarketDataRepositorer: marketDataRepo,
+		listeners:             listeners,
+	}
+}
+
+// Create stores the row and then notifies the listeners in order
+func (s *MarketDataService) Create(data *models.MarketData) error {
+	var previous *models.MarketData
+	if len(s.listeners) > 0 {
+		if latest, err := s.MarketDataRepositorer.GetLatestMarketData(data.InstrumentID); err == nil {
+			previous = latest
+		}
+	}
+
+	if err := s.MarketDataRepositorer.Create(data); err != nil {
+		if errors.Is(err, repository.ErrDuplicateMarketData) {
+			return fmt.Errorf("%w: %v", ErrDuplicateMarketData, err)
+		}
+		return err
+	}
+	for _, listener := range s.listeners {
+		listener.OnMarketData(previous, data)
+	}
+	return nil
+}
+
+// Upsert stores the rows and then notifies the listeners of the latest row of
+// each instrument, in instrument order. Instruments whose rows are all older
+// than their latest stored one are backfills and are not notified.
+func (s *MarketDataService) Upsert(marketData []models.MarketData) error {
+	if len(s.listeners) == 0 {
+		return s.MarketDataRepositorer.Upsert(marketData)
+	}
+
+	latest := make(map[uint]*models.MarketData)
+	for i := range marketData {
+		data := &marketData[i]
+		if current, ok := latest[data.InstrumentID]; !ok || !data.DateTime.Before(current.DateTime) {
+			latest[data.InstrumentID] = data
+		}
+	}
+	instrumentIDs := make([]uint, 0, len(latest))
+	for instrumentID := range latest {
+		instrumentIDs = append(instrumentIDs, instrumentID)
+	}
+	sort.Slice(instrumentIDs, func(i, j int) bool { return instrumentIDs[i] < instrumentIDs[j] })
+
+	// As in Create, listeners get no previous row if it cannot be loaded
+	previous, err := s.MarketDataRepositorer.GetLatestMarketDataForInstruments(instrumentIDs)
+	if err != nil {
+		previous = nil
+	}
+
+	if err := s.MarketDataRepositorer.Upsert(marketData); err != nil {
+		return err
+	}
+	for _, instrumentID := range instrumentIDs {
+		data, last := latest[instrumentID], previous[instrumentID]
+		if last != nil && data.DateTime.Before(last.DateTime) {
+			continue
+		}
+		for _, listener := range s.listeners {
+			listener.OnMarketData(last, data)
+		}
+	}
+	return nil
+}
+
+// GetCandlePage aggregates the instrument's market data between from and to
+// into OHLC bars of the interval. from is moved back to the start of its
+// bucket so the first bar is complete, and the page holds up to limit bars;
+// when more are left, NextFrom is where the next page starts.
+func (s *MarketDataService) GetCandlePage(instrumentID uint, interval string, from, to time.Time, limit int) (*models.CandlePage, error) {
+	unit := models.CandleUnit(interval)
+	if unit == "" {
+		return nil, fmt.Errorf("%w: unsupported interval %q", ErrInvalidCandles, interval)
+	}
+	if !from.IsZero() {
+		from = models.CandleStart(interval, from)
+	}
+	if from.After(to) {
+		return nil, fmt.Errorf("%w: from is after to", ErrInvalidCandles)
+	}
+	if limit <= 0 {
+		limit = defaultCandleLimit
+	}
+	if limit > maxCandleLimit {
+		limit = maxCandleLimit
+	}
+
+	// One more bar than the page holds tells whether there is a next page
+	candles, err := s.MarketDataRepositorer.GetCandles(instrumentID, unit, from, to, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &models.CandlePage{
+		InstrumentID: instrumentID,
+		Interval:     interval,
+		Candles:      candles,
+	}
+	if len(candles) > limit {
+		next := candles[limit].Time
+		page.Candles = candles[:limit]
+		page.NextFrom = &next
+	}
+	if page.Candles == nil {
+		page.Candles = make([]models.Candle, 0)
+	}
+	return page, nil
+}
@@ -0,0 +1,197 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	mocks "github.com/NahuelDT/portfolio-api/internal/mocks/repository"
+	"github.com/NahuelDT/portfolio-api/internal/models"
+	"github.com/NahuelDT/portfolio-api/internal/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingListener struct {
+	previous []*models.MarketData
+	data     []*models.MarketData
+}
+
+func (l *recordingListener) OnMarketData(previous, data *models.MarketData) {
+	l.previous = append(l.previous, previous)
+	l.data = append(l.data, data)
+}
+
+func TestMarketDataServiceCreate(t *testing.T) {
+	t.Run("Listeners get the new row and the one before it", func(t *testing.T) {
+		mockMarketDataRepo := new(mocks.MarketDataRepositorer)
+		listener := &recordingListener{}
+		marketDataService := NewMarketDataService(mockMarketDataRepo, listener)
+
+		previous := &models.MarketData{ID: 1, InstrumentID: 2, Close: 100, DateTime: time.Now().Add(-time.Minute)}
+		data := &models.MarketData{InstrumentID: 2, Close: 101, DateTime: time.Now()}
+
+		mockMarketDataRepo.On("GetLatestMarketData", uint(2)).Return(previous, nil)
+		mockMarketDataRepo.On("Create", data).Return(nil)
+
+		err := marketDataService.Create(data)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []*models.MarketData{previous}, listener.previous)
+		assert.Equal(t, []*models.MarketData{data}, listener.data)
+		mockMarketDataRepo.AssertExpectations(t)
+	})
+
+	t.Run("The first row of an instrument has no previous one", func(t *testing.T) {
+		mockMarketDataRepo := new(mocks.MarketDataRepositorer)
+		listener := &recordingListener{}
+		marketDataService := NewMarketDataService(mockMarketDataRepo, listener)
+
+		data := &models.MarketData{InstrumentID: 3, Close: 10, DateTime: time.Now()}
+
+		mockMarketDataRepo.On("GetLatestMarketData", uint(3)).Return(nil, errors.New("record not found"))
+		mockMarketDataRepo.On("Create", data).Return(nil)
+
+		err := marketDataService.Create(data)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []*models.MarketData{nil}, listener.previous)
+	})
+
+	t.Run("Listeners are not notified when the row is not stored", func(t *testing.T) {
+		mockMarketDataRepo := new(mocks.MarketDataRepositorer)
+		listener := &recordingListener{}
+		marketDataService := NewMarketDataService(mockMarketDataRepo, listener)
+
+		data := &models.MarketData{InstrumentID: 3, Close: 10, DateTime: time.Now()}
+
+		mockMarketDataRepo.On("GetLatestMarketData", uint(3)).Return(nil, errors.New("record not found"))
+		mockMarketDataRepo.On("Create", data).Return(repository.ErrDuplicateMarketData)
+
+		err := marketDataService.Create(data)
+
+		assert.ErrorIs(t, err, ErrDuplicateMarketData)
+		assert.Empty(t, listener.data)
+	})
+}
+
+func TestMarketDataServiceUpsert(t *testing.T) {
+	t.Run("Listeners get the latest row of each instrument", func(t *testing.T) {
+		mockMarketDataRepo := new(mocks.MarketDataRepositorer)
+		listener := &recordingListener{}
+		marketDataService := NewMarketDataService(mockMarketDataRepo, listener)
+
+		now := time.Now()
+		previous := &models.MarketData{ID: 1, InstrumentID: 2, Close: 100, DateTime: now.AddDate(0, 0, -3)}
+		rows := []models.MarketData{
+			{InstrumentID: 2, Close: 102, DateTime: now.AddDate(0, 0, -1)},
+			{InstrumentID: 2, Close: 101, DateTime: now.AddDate(0, 0, -2)},
+			{InstrumentID: 1, Close: 50, DateTime: now.AddDate(0, 0, -1)},
+		}
+
+		mockMarketDataRepo.On("GetLatestMarketDataForInstruments", []uint{1, 2}).Return(map[uint]*models.MarketData{2: previous}, nil)
+		mockMarketDataRepo.On("Upsert", rows).Return(nil)
+
+		err := marketDataService.Upsert(rows)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []*models.MarketData{nil, previous}, listener.previous)
+		assert.Equal(t, []*models.MarketData{&rows[2], &rows[0]}, listener.data)
+		mockMarketDataRepo.AssertExpectations(t)
+	})
+
+	t.Run("Backfills are not notified", func(t *testing.T) {
+		mockMarketDataRepo := new(mocks.MarketDataRepositorer)
+		listener := &recordingListener{}
+		marketDataService := NewMarketDataService(mockMarketDataRepo, listener)
+
+		now := time.Now()
+		previous := &models.MarketData{ID: 1, InstrumentID: 2, Close: 100, DateTime: now}
+		rows := []models.MarketData{{InstrumentID: 2, Close: 90, DateTime: now.AddDate(0, 0, -5)}}
+
+		mockMarketDataRepo.On("GetLatestMarketDataForInstruments", []uint{2}).Return(map[uint]*models.MarketData{2: previous}, nil)
+		mockMarketDataRepo.On("Upsert", rows).Return(nil)
+
+		err := marketDataService.Upsert(rows)
+
+		assert.NoError(t, err)
+		assert.Empty(t, listener.data)
+	})
+
+	t.Run("Listeners are not notified when the rows are not stored", func(t *testing.T) {
+		mockMarketDataRepo := new(mocks.MarketDataRepositorer)
+		listener := &recordingListener{}
+		marketDataService := NewMarketDataService(mockMarketDataRepo, listener)
+
+		rows := []models.MarketData{{InstrumentID: 3, Close: 10, DateTime: time.Now()}}
+
+		mockMarketDataRepo.On("GetLatestMarketDataForInstruments", []uint{3}).Return(map[uint]*models.MarketData{}, nil)
+		mockMarketDataRepo.On("Upsert", rows).Return(errors.New("connection reset"))
+
+		err := marketDataService.Upsert(rows)
+
+		assert.Error(t, err)
+		assert.Empty(t, listener.data)
+	})
+}
+
+func TestGetCandlePage(t *testing.T) {
+	date := func(month time.Month, day int) time.Time {
+		return time.Date(2024, month, day, 0, 0, 0, 0, time.UTC)
+	}
+
+	t.Run("Weekly candles start on the Monday of from and page by limit", func(t *testing.T) {
+		mockMarketDataRepo := new(mocks.MarketDataRepositorer)
+		marketDataService := NewMarketDataService(mockMarketDataRepo)
+
+		candles := []models.Candle{
+			{Time: date(time.January, 1), Open: 10, High: 12, Low: 9, Close: 11, Rows: 5},
+			{Time: date(time.January, 8), Open: 11, High: 13, Low: 10, Close: 12, Rows: 5},
+			{Time: date(time.January, 15), Open: 12, High: 12, Low: 8, Close: 9, Rows: 5},
+		}
+		// Wednesday the 3rd belongs to the week of Monday the 1st
+		mockMarketDataRepo.On("GetCandles", uint(1), "week", date(time.January, 1), date(time.March, 1), 3).Return(candles, nil)
+
+		page, err := marketDataService.GetCandlePage(1, models.CandleWeekly, date(time.January, 3), date(time.March, 1), 2)
+
+		assert.NoError(t, err)
+		assert.Equal(t, models.CandleWeekly, page.Interval)
+		assert.Equal(t, candles[:2], page.Candles)
+		assert.Equal(t, date(time.January, 15), *page.NextFrom)
+		mockMarketDataRepo.AssertExpectations(t)
+	})
+
+	t.Run("The last page has no next one", func(t *testing.T) {
+		mockMarketDataRepo := new(mocks.MarketDataRepositorer)
+		marketDataService := NewMarketDataService(mockMarketDataRepo)
+
+		mockMarketDataRepo.On("GetCandles", uint(1), "month", date(time.February, 1), date(time.March, 31), defaultCandleLimit+1).Return([]models.Candle{}, nil)
+
+		page, err := marketDataService.GetCandlePage(1, models.CandleMonthly, date(time.February, 20), date(time.March, 31), 0)
+
+		assert.NoError(t, err)
+		assert.Empty(t, page.Candles)
+		assert.NotNil(t, page.Candles)
+		assert.Nil(t, page.NextFrom)
+	})
+
+	t.Run("Limits are capped", func(t *testing.T) {
+		mockMarketDataRepo := new(mocks.MarketDataRepositorer)
+		marketDataService := NewMarketDataService(mockMarketDataRepo)
+
+		mockMarketDataRepo.On("GetCandles", uint(1), "day", time.Time{}, date(time.March, 31), maxCandleLimit+1).Return([]models.Candle{}, nil)
+
+		_, err := marketDataService.GetCandlePage(1, models.CandleDaily, time.Time{}, date(time.March, 31), 100000)
+
+		assert.NoError(t, err)
+		mockMarketDataRepo.AssertExpectations(t)
+	})
+
+	t.Run("Unsupported intervals and empty ranges are invalid", func(t *testing.T) {
+		marketDataService := NewMarketDataService(new(mocks.MarketDataRepositorer))
+
+		_, err := marketDataService.GetCandlePage(1, "1h", date(time.January, 1), date(time.March, 1), 0)
+		assert.ErrorIs(t, err, ErrInvalidCandles)
+		_, err = marketDataService.GetCandlePage(1, models.CandleDaily, date(time.March, 2), date(time.March, 1), 0)
+		assert.ErrorIs(t, err, ErrInvalidCandles)
+	})
+}
@@ -3,6 +3,7 @@ package service
 import (
 	"errors"
 	"math"
+	"strings"
 	"time"
 
 	"github.com/NahuelDT/portfolio-api/internal/models"
@@ -10,10 +11,15 @@ import (
 )
 
 type OrderService struct {
-	orderRepo      repository.OrderRepositorer
-	userRepo       repository.UserRepositorer
-	instrumentRepo repository.InstrumentRepositorer
-	marketDataRepo repository.MarketDataRepositorer
+	orderRepo       repository.OrderRepositorer
+	userRepo        repository.UserRepositorer
+	instrumentRepo  repository.InstrumentRepositorer
+	marketDataRepo  repository.MarketDataRepositorer
+	positionRepo    repository.PositionRepositorer
+	accountRepo     repository.AccountRepositorer
+	settlement      models.SettlementCycle
+	withdrawalRepo  repository.WithdrawalRepositorer
+	withdrawalRules []WithdrawalRule
 }
 
 func NewOrderService(
@@ -21,12 +27,22 @@ func NewOrderService(
 	userRepo repository.UserRepositorer,
 	instrumentRepo repository.InstrumentRepositorer,
 	marketDataRepo repository.MarketDataRepositorer,
+	positionRepo repository.PositionRepositorer,
+	accountRepo repository.AccountRepositorer,
+	settlement models.SettlementCycle,
+	withdrawalRepo repository.WithdrawalRepositorer,
+	withdrawalRules ...WithdrawalRule,
 ) *OrderService {
 	return &OrderService{
-		orderRepo:      orderRepo,
-		userRepo:       userRepo,
-		instrumentRepo: instrumentRepo,
-		marketDataRepo: marketDataRepo,
+		orderRepo:       orderRepo,
+		userRepo:        userRepo,
+		instrumentRepo:  instrumentRepo,
+		marketDataRepo:  marketDataRepo,
+		positionRepo:    positionRepo,
+		accountRepo:     accountRepo,
+		settlement:      settlement,
+		withdrawalRepo:  withdrawalRepo,
+		withdrawalRules: withdrawalRules,
 	}
 }
 
@@ -38,12 +54,20 @@ func (s *OrderService) PlaceOrder(order *models.Order, totalAmount float64) erro
 		return errors.New("invalid user")
 	}
 
+	// Validate account, orders without one go to the main account
+	if err := checkAccount(s.accountRepo, order.UserID, order.AccountID); err != nil {
+		return err
+	}
+
 	switch order.Side {
 	case "BUY", "SELL":
 		// Validate instrument
-		if _, err := s.instrumentRepo.GetByID(order.InstrumentID); err != nil {
+		instrument, err := s.instrumentRepo.GetByID(order.InstrumentID)
+		if err != nil {
 			return errors.New("invalid instrument")
 		}
+		// Trades settle in the instrument's currency
+		order.Currency = models.NormalizeCurrency(instrument.Currency)
 
 		// Get latest market data
 		marketData, err := s.marketDataRepo.GetLatestMarketData(order.InstrumentID)
@@ -74,39 +98,77 @@ func (s *OrderService) PlaceOrder(order *models.Order, totalAmount float64) erro
 
 		}
 
-		// Validate available funds/assets
+		// Validate available funds/assets. Purchases are paid with settled
+		// cash only. The repository checks a FILLED order again under the
+		// account's lock when it applies it, since other fills may spend the
+		// same cash or shares meanwhile.
 		if order.Side == "BUY" {
-			availableCash, err := s.orderRepo.GetUserCashBalance(order.UserID)
+			availableCash, err := s.settledCash(order.UserID, order.AccountID, order.Currency, order.DateTime)
 			if err != nil {
 				return err
 			}
-			if availableCash < order.Size*order.Price {
+			held, err := s.heldCash(order.UserID, order.AccountID, order.Currency)
+			if err != nil {
+				return err
+			}
+			if availableCash-held < order.Size*order.Price {
 				order.Status = "REJECTED"
 			}
 		} else { // SELL
-			userPositions, err := s.calculateUserPositions(order.UserID)
+			position, err := s.positionRepo.GetAccountPosition(order.UserID, order.AccountID, order.InstrumentID)
 			if err != nil {
 				return err
 			}
-			availableAssets := userPositions[order.InstrumentID]
-			if availableAssets < order.Size {
+			if position.Quantity < order.Size {
 				order.Status = "REJECTED"
 			}
 		}
+		if order.Status == "FILLED" {
+			order.SettlementDate = s.settlement.SettlementDate(instrument.Type, order.DateTime)
+		}
 
 	case "CASH_IN":
+		order.Currency = models.NormalizeCurrency(order.Currency)
 		order.Status = "FILLED"
+		order.SettlementDate = order.DateTime
 
 	case "CASH_OUT":
-		availableCash, err := s.orderRepo.GetUserCashBalance(order.UserID)
+		order.Currency = models.NormalizeCurrency(order.Currency)
+		availableCash, err := s.settledCash(order.UserID, order.AccountID, order.Currency, order.DateTime)
+		if err != nil {
+			return err
+		}
+		held, err := s.heldCash(order.UserID, order.AccountID, order.Currency)
 		if err != nil {
 			return err
 		}
+		availableCash -= held
 		if availableCash < order.Size {
 			order.Status = "REJECTED"
-		} else {
-			order.Status = "FILLED"
+			break
+		}
+
+		// Flagged withdrawals hold their cash until an admin decides on them.
+		// The repository checks the cash again under the user's lock, since
+		// other orders may spend it meanwhile.
+		if reasons := flagWithdrawal(s.withdrawalRules, order, availableCash); len(reasons) > 0 {
+			order.Status = models.PendingApprovalStatus
+			err := s.withdrawalRepo.Hold(order, &models.WithdrawalAudit{
+				UserID:    order.UserID,
+				Action:    models.WithdrawalRequested,
+				Amount:    order.Size,
+				Currency:  order.Currency,
+				Note:      strings.Join(reasons, "; "),
+				CreatedAt: order.DateTime,
+			})
+			if !errors.Is(err, repository.ErrInsufficientFunds) {
+				return err
+			}
+			order.Status = "REJECTED"
+			break
 		}
+		order.Status = "FILLED"
+		order.SettlementDate = order.DateTime
 
 	default:
 		return errors.New("invalid order side")
@@ -120,6 +182,41 @@ func (s *OrderService) PlaceOrder(order *models.Order, totalAmount float64) erro
 	return nil
 }
 
+// settledCash is the cash of the account that can be spent or withdrawn:
+// its balance without the proceeds of sales that have not settled yet
+func (s *OrderService) settledCash(userID, accountID uint, currency string, at time.Time) (float64, error) {
+	balance, err := s.positionRepo.GetAccountCashBalance(userID, accountID, currency)
+	if err != nil {
+		return 0, err
+	}
+	unsettled, err := s.orderRepo.GetUnsettledCash(userID, at)
+	if err != nil {
+		return 0, err
+	}
+	for _, proceeds := range unsettled {
+		if proceeds.AccountID == accountID && proceeds.Currency == currency {
+			balance -= proceeds.Balance
+		}
+	}
+	return balance, nil
+}
+
+// heldCash sums the withdrawals of the account pending approval in the given
+// currency
+func (s *OrderService) heldCash(userID, accountID uint, currency string) (float64, error) {
+	held, err := s.withdrawalRepo.GetHeldCash(userID)
+	if err != nil {
+		return 0, err
+	}
+	total := 0.0
+	for _, withdrawal := range held {
+		if withdrawal.AccountID == accountID && withdrawal.Currency == currency {
+			total += withdrawal.Balance
+		}
+	}
+	return total, nil
+}
+
 func (s *OrderService) CancelOrder(orderID uint) error {
 	order, err := s.orderRepo.GetByID(orderID)
 	if err != nil {
@@ -133,21 +230,3 @@ func (s *OrderService) CancelOrder(orderID uint) error {
 	order.Status = "CANCELLED"
 	return s.orderRepo.UpdateStatus(orderID, "CANCELLED")
 }
-
-func (s *OrderService) calculateUserPositions(userID uint) (map[uint]float64, error) {
-	filledOrders, err := s.orderRepo.GetUserFilledOrders(userID)
-	if err != nil {
-		return nil, err // Retorna nil y el error si GetUserFilledOrders falla
-	}
-
-	positions := make(map[uint]float64)
-	for _, order := range filledOrders {
-		if order.Side == "BUY" {
-			positions[order.InstrumentID] += order.Size
-		} else if order.Side == "SELL" {
-			positions[order.InstrumentID] -= order.Size
-		}
-	}
-
-	return positions, nil
-}
@@ -3,25 +3,36 @@ package service
 import (
 	"errors"
 	"testing"
+	"time"
 
 	mocks "github.com/NahuelDT/portfolio-api/internal/mocks/repository"
 	"github.com/NahuelDT/portfolio-api/internal/models"
+	"github.com/NahuelDT/portfolio-api/internal/repository"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
 
+// noHeldCash mocks a withdrawal repository without withdrawals pending
+// approval
+func noHeldCash() *mocks.WithdrawalRepositorer {
+	mockWithdrawalRepo := new(mocks.WithdrawalRepositorer)
+	mockWithdrawalRepo.On("GetHeldCash", mock.Anything).Return([]models.CashBalance{}, nil).Maybe()
+	return mockWithdrawalRepo
+}
+
 func TestPlaceOrder(t *testing.T) {
-	setUp := func() (*mocks.OrderRepositorer, *mocks.UserRepositorer, *mocks.InstrumentRepositorer, *mocks.MarketDataRepositorer, *OrderService) {
+	setUp := func() (*mocks.OrderRepositorer, *mocks.UserRepositorer, *mocks.InstrumentRepositorer, *mocks.MarketDataRepositorer, *mocks.PositionRepositorer, *OrderService) {
 		mockOrderRepo := new(mocks.OrderRepositorer)
 		mockUserRepo := new(mocks.UserRepositorer)
 		mockInstrumentRepo := new(mocks.InstrumentRepositorer)
 		mockMarketDataRepo := new(mocks.MarketDataRepositorer)
-		orderService := NewOrderService(mockOrderRepo, mockUserRepo, mockInstrumentRepo, mockMarketDataRepo)
-		return mockOrderRepo, mockUserRepo, mockInstrumentRepo, mockMarketDataRepo, orderService
+		mockPositionRepo := new(mocks.PositionRepositorer)
+		orderService := NewOrderService(mockOrderRepo, mockUserRepo, mockInstrumentRepo, mockMarketDataRepo, mockPositionRepo, new(mocks.AccountRepositorer), models.DefaultSettlementCycle, noHeldCash())
+		return mockOrderRepo, mockUserRepo, mockInstrumentRepo, mockMarketDataRepo, mockPositionRepo, orderService
 	}
 
 	t.Run("Place valid MARKET BUY order", func(t *testing.T) {
-		mockOrderRepo, mockUserRepo, mockInstrumentRepo, mockMarketDataRepo, orderService := setUp()
+		mockOrderRepo, mockUserRepo, mockInstrumentRepo, mockMarketDataRepo, mockPositionRepo, orderService := setUp()
 
 		order := &models.Order{
 			UserID:       1,
@@ -34,7 +45,8 @@ func TestPlaceOrder(t *testing.T) {
 		mockUserRepo.On("GetByID", uint(1)).Return(&models.User{ID: 1}, nil)
 		mockInstrumentRepo.On("GetByID", uint(1)).Return(&models.Instrument{ID: 1}, nil)
 		mockMarketDataRepo.On("GetLatestMarketData", uint(1)).Return(&models.MarketData{Close: 100}, nil)
-		mockOrderRepo.On("GetUserCashBalance", uint(1)).Return(float64(2000), nil)
+		mockPositionRepo.On("GetAccountCashBalance", uint(1), uint(0), "ARS").Return(float64(2000), nil)
+		mockOrderRepo.On("GetUnsettledCash", uint(1), mock.AnythingOfType("time.Time")).Return([]models.CashBalance{}, nil)
 		mockOrderRepo.On("Create", mock.AnythingOfType("*models.Order")).Return(nil)
 
 		err := orderService.PlaceOrder(order, 0)
@@ -43,13 +55,14 @@ func TestPlaceOrder(t *testing.T) {
 		assert.Equal(t, "FILLED", order.Status)
 		assert.Equal(t, float64(100), order.Price)
 		mockOrderRepo.AssertExpectations(t)
+		mockPositionRepo.AssertExpectations(t)
 		mockUserRepo.AssertExpectations(t)
 		mockInstrumentRepo.AssertExpectations(t)
 		mockMarketDataRepo.AssertExpectations(t)
 	})
 
 	t.Run("Place valid LIMIT BUY order", func(t *testing.T) {
-		mockOrderRepo, mockUserRepo, mockInstrumentRepo, mockMarketDataRepo, orderService := setUp()
+		mockOrderRepo, mockUserRepo, mockInstrumentRepo, mockMarketDataRepo, mockPositionRepo, orderService := setUp()
 
 		order := &models.Order{
 			UserID:       1,
@@ -63,7 +76,8 @@ func TestPlaceOrder(t *testing.T) {
 		mockUserRepo.On("GetByID", uint(1)).Return(&models.User{ID: 1}, nil)
 		mockInstrumentRepo.On("GetByID", uint(1)).Return(&models.Instrument{ID: 1}, nil)
 		mockMarketDataRepo.On("GetLatestMarketData", uint(1)).Return(&models.MarketData{Close: 100}, nil)
-		mockOrderRepo.On("GetUserCashBalance", uint(1)).Return(float64(2000), nil)
+		mockPositionRepo.On("GetAccountCashBalance", uint(1), uint(0), "ARS").Return(float64(2000), nil)
+		mockOrderRepo.On("GetUnsettledCash", uint(1), mock.AnythingOfType("time.Time")).Return([]models.CashBalance{}, nil)
 		mockOrderRepo.On("Create", mock.AnythingOfType("*models.Order")).Return(nil)
 
 		err := orderService.PlaceOrder(order, 0)
@@ -72,13 +86,14 @@ func TestPlaceOrder(t *testing.T) {
 		assert.Equal(t, "NEW", order.Status)
 		assert.Equal(t, float64(90), order.Price)
 		mockOrderRepo.AssertExpectations(t)
+		mockPositionRepo.AssertExpectations(t)
 		mockUserRepo.AssertExpectations(t)
 		mockInstrumentRepo.AssertExpectations(t)
 		mockMarketDataRepo.AssertExpectations(t)
 	})
 
 	t.Run("Place MARKET BUY order with insufficient funds", func(t *testing.T) {
-		mockOrderRepo, mockUserRepo, mockInstrumentRepo, mockMarketDataRepo, orderService := setUp()
+		mockOrderRepo, mockUserRepo, mockInstrumentRepo, mockMarketDataRepo, mockPositionRepo, orderService := setUp()
 
 		order := &models.Order{
 			UserID:       1,
@@ -91,7 +106,8 @@ func TestPlaceOrder(t *testing.T) {
 		mockUserRepo.On("GetByID", uint(1)).Return(&models.User{ID: 1}, nil)
 		mockInstrumentRepo.On("GetByID", uint(1)).Return(&models.Instrument{ID: 1}, nil)
 		mockMarketDataRepo.On("GetLatestMarketData", uint(1)).Return(&models.MarketData{Close: 100}, nil)
-		mockOrderRepo.On("GetUserCashBalance", uint(1)).Return(float64(5), nil)
+		mockPositionRepo.On("GetAccountCashBalance", uint(1), uint(0), "ARS").Return(float64(5), nil)
+		mockOrderRepo.On("GetUnsettledCash", uint(1), mock.AnythingOfType("time.Time")).Return([]models.CashBalance{}, nil)
 		mockOrderRepo.On("Create", mock.AnythingOfType("*models.Order")).Return(nil)
 
 		err := orderService.PlaceOrder(order, 0)
@@ -99,13 +115,73 @@ func TestPlaceOrder(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Equal(t, "REJECTED", order.Status)
 		mockOrderRepo.AssertExpectations(t)
+		mockPositionRepo.AssertExpectations(t)
+		mockUserRepo.AssertExpectations(t)
+		mockInstrumentRepo.AssertExpectations(t)
+		mockMarketDataRepo.AssertExpectations(t)
+	})
+
+	t.Run("Reject MARKET BUY paid with unsettled proceeds", func(t *testing.T) {
+		mockOrderRepo, mockUserRepo, mockInstrumentRepo, mockMarketDataRepo, mockPositionRepo, orderService := setUp()
+
+		order := &models.Order{
+			UserID:       1,
+			InstrumentID: 1,
+			Side:         "BUY",
+			Type:         "MARKET",
+			Size:         10,
+		}
+
+		mockUserRepo.On("GetByID", uint(1)).Return(&models.User{ID: 1}, nil)
+		mockInstrumentRepo.On("GetByID", uint(1)).Return(&models.Instrument{ID: 1}, nil)
+		mockMarketDataRepo.On("GetLatestMarketData", uint(1)).Return(&models.MarketData{Close: 100}, nil)
+		mockPositionRepo.On("GetAccountCashBalance", uint(1), uint(0), "ARS").Return(float64(1500), nil)
+		mockOrderRepo.On("GetUnsettledCash", uint(1), mock.AnythingOfType("time.Time")).Return([]models.CashBalance{
+			{UserID: 1, AccountID: 0, Currency: "ARS", Balance: 1000},
+		}, nil)
+		mockOrderRepo.On("Create", mock.AnythingOfType("*models.Order")).Return(nil)
+
+		err := orderService.PlaceOrder(order, 0)
+
+		// Only 500 of the 1500 have settled
+		assert.NoError(t, err)
+		assert.Equal(t, "REJECTED", order.Status)
+		mockOrderRepo.AssertExpectations(t)
+		mockPositionRepo.AssertExpectations(t)
+	})
+
+	t.Run("Place MARKET BUY order on a USD instrument", func(t *testing.T) {
+		mockOrderRepo, mockUserRepo, mockInstrumentRepo, mockMarketDataRepo, mockPositionRepo, orderService := setUp()
+
+		order := &models.Order{
+			UserID:       1,
+			InstrumentID: 1,
+			Side:         "BUY",
+			Type:         "MARKET",
+			Size:         10,
+		}
+
+		mockUserRepo.On("GetByID", uint(1)).Return(&models.User{ID: 1}, nil)
+		mockInstrumentRepo.On("GetByID", uint(1)).Return(&models.Instrument{ID: 1, Currency: "USD"}, nil)
+		mockMarketDataRepo.On("GetLatestMarketData", uint(1)).Return(&models.MarketData{Close: 100}, nil)
+		mockPositionRepo.On("GetAccountCashBalance", uint(1), uint(0), "USD").Return(float64(500), nil)
+		mockOrderRepo.On("GetUnsettledCash", uint(1), mock.AnythingOfType("time.Time")).Return([]models.CashBalance{}, nil)
+		mockOrderRepo.On("Create", mock.AnythingOfType("*models.Order")).Return(nil)
+
+		err := orderService.PlaceOrder(order, 0)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "USD", order.Currency)
+		assert.Equal(t, "REJECTED", order.Status)
+		mockOrderRepo.AssertExpectations(t)
+		mockPositionRepo.AssertExpectations(t)
 		mockUserRepo.AssertExpectations(t)
 		mockInstrumentRepo.AssertExpectations(t)
 		mockMarketDataRepo.AssertExpectations(t)
 	})
 
 	t.Run("Place valid MARKET SELL order", func(t *testing.T) {
-		mockOrderRepo, mockUserRepo, mockInstrumentRepo, mockMarketDataRepo, orderService := setUp()
+		mockOrderRepo, mockUserRepo, mockInstrumentRepo, mockMarketDataRepo, mockPositionRepo, orderService := setUp()
 
 		order := &models.Order{
 			UserID:       1,
@@ -118,9 +194,7 @@ func TestPlaceOrder(t *testing.T) {
 		mockUserRepo.On("GetByID", uint(1)).Return(&models.User{ID: 1}, nil)
 		mockInstrumentRepo.On("GetByID", uint(1)).Return(&models.Instrument{ID: 1}, nil)
 		mockMarketDataRepo.On("GetLatestMarketData", uint(1)).Return(&models.MarketData{Close: 100}, nil)
-		mockOrderRepo.On("GetUserFilledOrders", uint(1)).Return([]models.Order{
-			{InstrumentID: 1, Side: "BUY", Size: 10, Status: "FILLED"},
-		}, nil)
+		mockPositionRepo.On("GetAccountPosition", uint(1), uint(0), uint(1)).Return(&models.Position{UserID: 1, InstrumentID: 1, Quantity: 10}, nil)
 		mockOrderRepo.On("Create", mock.AnythingOfType("*models.Order")).Return(nil)
 
 		err := orderService.PlaceOrder(order, 0)
@@ -129,13 +203,14 @@ func TestPlaceOrder(t *testing.T) {
 		assert.Equal(t, "FILLED", order.Status)
 		assert.Equal(t, float64(100), order.Price)
 		mockOrderRepo.AssertExpectations(t)
+		mockPositionRepo.AssertExpectations(t)
 		mockUserRepo.AssertExpectations(t)
 		mockInstrumentRepo.AssertExpectations(t)
 		mockMarketDataRepo.AssertExpectations(t)
 	})
 
 	t.Run("Place MARKET SELL order with insufficient assets", func(t *testing.T) {
-		mockOrderRepo, mockUserRepo, mockInstrumentRepo, mockMarketDataRepo, orderService := setUp()
+		mockOrderRepo, mockUserRepo, mockInstrumentRepo, mockMarketDataRepo, mockPositionRepo, orderService := setUp()
 
 		order := &models.Order{
 			UserID:       1,
@@ -148,9 +223,7 @@ func TestPlaceOrder(t *testing.T) {
 		mockUserRepo.On("GetByID", uint(1)).Return(&models.User{ID: 1}, nil)
 		mockInstrumentRepo.On("GetByID", uint(1)).Return(&models.Instrument{ID: 1}, nil)
 		mockMarketDataRepo.On("GetLatestMarketData", uint(1)).Return(&models.MarketData{Close: 100}, nil)
-		mockOrderRepo.On("GetUserFilledOrders", uint(1)).Return([]models.Order{
-			{InstrumentID: 1, Side: "BUY", Size: 10, Status: "FILLED"},
-		}, nil)
+		mockPositionRepo.On("GetAccountPosition", uint(1), uint(0), uint(1)).Return(&models.Position{UserID: 1, InstrumentID: 1, Quantity: 10}, nil)
 		mockOrderRepo.On("Create", mock.AnythingOfType("*models.Order")).Return(nil)
 
 		err := orderService.PlaceOrder(order, 0)
@@ -158,13 +231,14 @@ func TestPlaceOrder(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Equal(t, "REJECTED", order.Status)
 		mockOrderRepo.AssertExpectations(t)
+		mockPositionRepo.AssertExpectations(t)
 		mockUserRepo.AssertExpectations(t)
 		mockInstrumentRepo.AssertExpectations(t)
 		mockMarketDataRepo.AssertExpectations(t)
 	})
 
 	t.Run("Place CASH_IN order", func(t *testing.T) {
-		mockOrderRepo, mockUserRepo, _, _, orderService := setUp()
+		mockOrderRepo, mockUserRepo, _, _, _, orderService := setUp()
 
 		order := &models.Order{
 			UserID: 1,
@@ -184,7 +258,7 @@ func TestPlaceOrder(t *testing.T) {
 	})
 
 	t.Run("Place CASH_OUT order with sufficient funds", func(t *testing.T) {
-		mockOrderRepo, mockUserRepo, _, _, orderService := setUp()
+		mockOrderRepo, mockUserRepo, _, _, mockPositionRepo, orderService := setUp()
 
 		order := &models.Order{
 			UserID: 1,
@@ -193,19 +267,22 @@ func TestPlaceOrder(t *testing.T) {
 		}
 
 		mockUserRepo.On("GetByID", uint(1)).Return(&models.User{ID: 1}, nil)
-		mockOrderRepo.On("GetUserCashBalance", uint(1)).Return(float64(1000), nil)
+		mockPositionRepo.On("GetAccountCashBalance", uint(1), uint(0), "ARS").Return(float64(1000), nil)
+		mockOrderRepo.On("GetUnsettledCash", uint(1), mock.AnythingOfType("time.Time")).Return([]models.CashBalance{}, nil)
 		mockOrderRepo.On("Create", mock.AnythingOfType("*models.Order")).Return(nil)
 
 		err := orderService.PlaceOrder(order, 0)
 
 		assert.NoError(t, err)
 		assert.Equal(t, "FILLED", order.Status)
+		assert.Equal(t, order.DateTime, order.SettlementDate)
 		mockUserRepo.AssertExpectations(t)
 		mockOrderRepo.AssertExpectations(t)
+		mockPositionRepo.AssertExpectations(t)
 	})
 
 	t.Run("Place CASH_OUT order with insufficient funds", func(t *testing.T) {
-		mockOrderRepo, mockUserRepo, _, _, orderService := setUp()
+		mockOrderRepo, mockUserRepo, _, _, mockPositionRepo, orderService := setUp()
 
 		order := &models.Order{
 			UserID: 1,
@@ -214,7 +291,8 @@ func TestPlaceOrder(t *testing.T) {
 		}
 
 		mockUserRepo.On("GetByID", uint(1)).Return(&models.User{ID: 1}, nil)
-		mockOrderRepo.On("GetUserCashBalance", uint(1)).Return(float64(1000), nil)
+		mockPositionRepo.On("GetAccountCashBalance", uint(1), uint(0), "ARS").Return(float64(1000), nil)
+		mockOrderRepo.On("GetUnsettledCash", uint(1), mock.AnythingOfType("time.Time")).Return([]models.CashBalance{}, nil)
 		mockOrderRepo.On("Create", mock.AnythingOfType("*models.Order")).Return(nil)
 
 		err := orderService.PlaceOrder(order, 0)
@@ -223,10 +301,171 @@ func TestPlaceOrder(t *testing.T) {
 		assert.Equal(t, "REJECTED", order.Status)
 		mockUserRepo.AssertExpectations(t)
 		mockOrderRepo.AssertExpectations(t)
+		mockPositionRepo.AssertExpectations(t)
+	})
+
+	t.Run("Reject CASH_OUT of unsettled proceeds", func(t *testing.T) {
+		mockOrderRepo, mockUserRepo, _, _, mockPositionRepo, orderService := setUp()
+
+		order := &models.Order{
+			UserID: 1,
+			Side:   "CASH_OUT",
+			Size:   800,
+		}
+
+		mockUserRepo.On("GetByID", uint(1)).Return(&models.User{ID: 1}, nil)
+		mockPositionRepo.On("GetAccountCashBalance", uint(1), uint(0), "ARS").Return(float64(1000), nil)
+		mockOrderRepo.On("GetUnsettledCash", uint(1), mock.AnythingOfType("time.Time")).Return([]models.CashBalance{
+			{UserID: 1, AccountID: 0, Currency: "ARS", Balance: 600},
+			{UserID: 1, AccountID: 2, Currency: "ARS", Balance: 300},
+			{UserID: 1, AccountID: 0, Currency: "USD", Balance: 50},
+		}, nil)
+		mockOrderRepo.On("Create", mock.AnythingOfType("*models.Order")).Return(nil)
+
+		err := orderService.PlaceOrder(order, 0)
+
+		// Only 400 of the 1000 have settled
+		assert.NoError(t, err)
+		assert.Equal(t, "REJECTED", order.Status)
+		assert.True(t, order.SettlementDate.IsZero())
+		mockOrderRepo.AssertExpectations(t)
+	})
+
+	t.Run("Hold flagged CASH_OUT orders for approval", func(t *testing.T) {
+		mockOrderRepo := new(mocks.OrderRepositorer)
+		mockUserRepo := new(mocks.UserRepositorer)
+		mockPositionRepo := new(mocks.PositionRepositorer)
+		mockWithdrawalRepo := new(mocks.WithdrawalRepositorer)
+		rules := []WithdrawalRule{
+			WithdrawalThresholdRule(map[string]float64{"": 10000, "USD": 500}),
+			EmptyAccountWithdrawalRule,
+		}
+		orderService := NewOrderService(mockOrderRepo, mockUserRepo, nil, nil, mockPositionRepo, new(mocks.AccountRepositorer), models.DefaultSettlementCycle, mockWithdrawalRepo, rules...)
+
+		mockUserRepo.On("GetByID", uint(1)).Return(&models.User{ID: 1}, nil)
+		mockPositionRepo.On("GetAccountCashBalance", uint(1), uint(0), "USD").Return(float64(2000), nil)
+		mockOrderRepo.On("GetUnsettledCash", uint(1), mock.AnythingOfType("time.Time")).Return([]models.CashBalance{}, nil)
+		mockWithdrawalRepo.On("GetHeldCash", uint(1)).Return([]models.CashBalance{
+			{UserID: 1, AccountID: 0, Currency: "USD", Balance: 700},
+			{UserID: 1, AccountID: 0, Currency: "ARS", Balance: 50000},
+		}, nil)
+		var audit *models.WithdrawalAudit
+		mockWithdrawalRepo.On("Hold", mock.AnythingOfType("*models.Order"), mock.AnythingOfType("*models.WithdrawalAudit")).Run(func(args mock.Arguments) {
+			audit = args.Get(1).(*models.WithdrawalAudit)
+		}).Return(nil)
+
+		order := &models.Order{UserID: 1, Side: "CASH_OUT", Size: 600, Currency: "usd"}
+		err := orderService.PlaceOrder(order, 0)
+
+		assert.NoError(t, err)
+		assert.Equal(t, models.PendingApprovalStatus, order.Status)
+		assert.True(t, order.SettlementDate.IsZero())
+		assert.Equal(t, models.WithdrawalRequested, audit.Action)
+		assert.Equal(t, float64(600), audit.Amount)
+		assert.Equal(t, "USD", audit.Currency)
+		assert.Contains(t, audit.Note, "USD 500.00")
+		assert.NotContains(t, audit.Note, "available cash")
+		mockOrderRepo.AssertNotCalled(t, "Create", mock.Anything)
+		mockWithdrawalRepo.AssertExpectations(t)
+
+		// The 700 held leave 1300 to withdraw, so taking them all out is flagged
+		// as well, and more than that is rejected
+		order = &models.Order{UserID: 1, Side: "CASH_OUT", Size: 1300, Currency: "USD"}
+		assert.NoError(t, orderService.PlaceOrder(order, 0))
+		assert.Equal(t, models.PendingApprovalStatus, order.Status)
+		assert.Contains(t, audit.Note, "; withdraws all the available cash")
+
+		mockOrderRepo.On("Create", mock.AnythingOfType("*models.Order")).Return(nil)
+		order = &models.Order{UserID: 1, Side: "CASH_OUT", Size: 1301, Currency: "USD"}
+		assert.NoError(t, orderService.PlaceOrder(order, 0))
+		assert.Equal(t, "REJECTED", order.Status)
+	})
+
+	t.Run("Reject a flagged CASH_OUT the cash no longer covers when held", func(t *testing.T) {
+		mockOrderRepo := new(mocks.OrderRepositorer)
+		mockUserRepo := new(mocks.UserRepositorer)
+		mockPositionRepo := new(mocks.PositionRepositorer)
+		mockWithdrawalRepo := new(mocks.WithdrawalRepositorer)
+		orderService := NewOrderService(mockOrderRepo, mockUserRepo, nil, nil, mockPositionRepo, new(mocks.AccountRepositorer), models.DefaultSettlementCycle, mockWithdrawalRepo, EmptyAccountWithdrawalRule)
+
+		mockUserRepo.On("GetByID", uint(1)).Return(&models.User{ID: 1}, nil)
+		mockPositionRepo.On("GetAccountCashBalance", uint(1), uint(0), "ARS").Return(float64(1000), nil)
+		mockOrderRepo.On("GetUnsettledCash", uint(1), mock.AnythingOfType("time.Time")).Return([]models.CashBalance{}, nil)
+		mockWithdrawalRepo.On("GetHeldCash", uint(1)).Return([]models.CashBalance{}, nil)
+		// Another order spent the cash between the check and the hold
+		mockWithdrawalRepo.On("Hold", mock.AnythingOfType("*models.Order"), mock.AnythingOfType("*models.WithdrawalAudit")).Return(repository.ErrInsufficientFunds)
+		mockOrderRepo.On("Create", mock.AnythingOfType("*models.Order")).Return(nil)
+
+		order := &models.Order{UserID: 1, Side: "CASH_OUT", Size: 1000}
+		err := orderService.PlaceOrder(order, 0)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "REJECTED", order.Status)
+		mockWithdrawalRepo.AssertExpectations(t)
+		mockOrderRepo.AssertExpectations(t)
+	})
+
+	t.Run("Withdrawals pending approval hold their cash from purchases", func(t *testing.T) {
+		mockOrderRepo := new(mocks.OrderRepositorer)
+		mockUserRepo := new(mocks.UserRepositorer)
+		mockInstrumentRepo := new(mocks.InstrumentRepositorer)
+		mockMarketDataRepo := new(mocks.MarketDataRepositorer)
+		mockPositionRepo := new(mocks.PositionRepositorer)
+		mockWithdrawalRepo := new(mocks.WithdrawalRepositorer)
+		orderService := NewOrderService(mockOrderRepo, mockUserRepo, mockInstrumentRepo, mockMarketDataRepo, mockPositionRepo, new(mocks.AccountRepositorer), models.DefaultSettlementCycle, mockWithdrawalRepo)
+
+		mockUserRepo.On("GetByID", uint(1)).Return(&models.User{ID: 1}, nil)
+		mockInstrumentRepo.On("GetByID", uint(1)).Return(&models.Instrument{ID: 1}, nil)
+		mockMarketDataRepo.On("GetLatestMarketData", uint(1)).Return(&models.MarketData{Close: 100}, nil)
+		mockPositionRepo.On("GetAccountCashBalance", uint(1), uint(0), "ARS").Return(float64(2000), nil)
+		mockOrderRepo.On("GetUnsettledCash", uint(1), mock.AnythingOfType("time.Time")).Return([]models.CashBalance{}, nil)
+		mockWithdrawalRepo.On("GetHeldCash", uint(1)).Return([]models.CashBalance{
+			{UserID: 1, AccountID: 0, Currency: "ARS", Balance: 1500},
+		}, nil)
+		mockOrderRepo.On("Create", mock.AnythingOfType("*models.Order")).Return(nil)
+
+		order := &models.Order{UserID: 1, InstrumentID: 1, Side: "BUY", Type: "MARKET", Size: 10}
+		err := orderService.PlaceOrder(order, 0)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "REJECTED", order.Status)
+		mockWithdrawalRepo.AssertExpectations(t)
+	})
+
+	t.Run("Trades settle on the cycle of their instrument type", func(t *testing.T) {
+		mockOrderRepo := new(mocks.OrderRepositorer)
+		mockUserRepo := new(mocks.UserRepositorer)
+		mockInstrumentRepo := new(mocks.InstrumentRepositorer)
+		mockMarketDataRepo := new(mocks.MarketDataRepositorer)
+		mockPositionRepo := new(mocks.PositionRepositorer)
+		cycle := models.SettlementCycle{Default: 1, ByType: map[string]int{"BONOS": 2}}
+		orderService := NewOrderService(mockOrderRepo, mockUserRepo, mockInstrumentRepo, mockMarketDataRepo, mockPositionRepo, new(mocks.AccountRepositorer), cycle, noHeldCash())
+
+		mockUserRepo.On("GetByID", uint(1)).Return(&models.User{ID: 1}, nil)
+		mockInstrumentRepo.On("GetByID", uint(1)).Return(&models.Instrument{ID: 1, Type: "ACCIONES"}, nil)
+		mockInstrumentRepo.On("GetByID", uint(2)).Return(&models.Instrument{ID: 2, Type: "Bonos"}, nil)
+		mockMarketDataRepo.On("GetLatestMarketData", mock.Anything).Return(&models.MarketData{Close: 100}, nil)
+		mockPositionRepo.On("GetAccountPosition", uint(1), uint(0), mock.Anything).Return(&models.Position{Quantity: 10}, nil)
+		mockOrderRepo.On("Create", mock.AnythingOfType("*models.Order")).Return(nil)
+
+		stock := &models.Order{UserID: 1, InstrumentID: 1, Side: "SELL", Type: "MARKET", Size: 5}
+		bond := &models.Order{UserID: 1, InstrumentID: 2, Side: "SELL", Type: "MARKET", Size: 5}
+		assert.NoError(t, orderService.PlaceOrder(stock, 0))
+		assert.NoError(t, orderService.PlaceOrder(bond, 0))
+
+		assert.Equal(t, cycle.SettlementDate("ACCIONES", stock.DateTime), stock.SettlementDate)
+		assert.Equal(t, cycle.SettlementDate("BONOS", bond.DateTime), bond.SettlementDate)
+		assert.True(t, bond.SettlementDate.After(stock.SettlementDate))
+
+		// A Friday trade settles on Monday at T+1 and on Tuesday at T+2
+		friday := time.Date(2024, time.March, 8, 15, 0, 0, 0, time.UTC)
+		assert.Equal(t, time.Date(2024, time.March, 11, 15, 0, 0, 0, time.UTC), cycle.SettlementDate("ACCIONES", friday))
+		assert.Equal(t, time.Date(2024, time.March, 12, 15, 0, 0, 0, time.UTC), cycle.SettlementDate("bonos", friday))
+		assert.Equal(t, friday, models.SettlementCycle{}.SettlementDate("ACCIONES", friday))
 	})
 
 	t.Run("Place order with invalid user", func(t *testing.T) {
-		_, mockUserRepo, _, _, orderService := setUp()
+		_, mockUserRepo, _, _, _, orderService := setUp()
 
 		order := &models.Order{
 			UserID:       999,
@@ -246,7 +485,7 @@ func TestPlaceOrder(t *testing.T) {
 	})
 
 	t.Run("Place order with invalid instrument", func(t *testing.T) {
-		_, mockUserRepo, mockInstrumentRepo, _, orderService := setUp()
+		_, mockUserRepo, mockInstrumentRepo, _, _, orderService := setUp()
 
 		order := &models.Order{
 			UserID:       1,
@@ -268,7 +507,7 @@ func TestPlaceOrder(t *testing.T) {
 	})
 
 	t.Run("Place order with market data fetch error", func(t *testing.T) {
-		_, mockUserRepo, mockInstrumentRepo, mockMarketDataRepo, orderService := setUp()
+		_, mockUserRepo, mockInstrumentRepo, mockMarketDataRepo, _, orderService := setUp()
 
 		order := &models.Order{
 			UserID:       1,
@@ -292,7 +531,7 @@ func TestPlaceOrder(t *testing.T) {
 	})
 
 	t.Run("Place order with invalid order type", func(t *testing.T) {
-		_, mockUserRepo, mockInstrumentRepo, mockMarketDataRepo, orderService := setUp()
+		_, mockUserRepo, mockInstrumentRepo, mockMarketDataRepo, _, orderService := setUp()
 
 		order := &models.Order{
 			UserID:       1,
@@ -316,7 +555,7 @@ func TestPlaceOrder(t *testing.T) {
 	})
 
 	t.Run("Place order with invalid order side", func(t *testing.T) {
-		_, mockUserRepo, _, _, orderService := setUp()
+		_, mockUserRepo, _, _, _, orderService := setUp()
 
 		order := &models.Order{
 			UserID:       1,
@@ -336,7 +575,7 @@ func TestPlaceOrder(t *testing.T) {
 	})
 
 	t.Run("Place order with zero size and no total amount", func(t *testing.T) {
-		_, mockUserRepo, mockInstrumentRepo, mockMarketDataRepo, orderService := setUp()
+		_, mockUserRepo, mockInstrumentRepo, mockMarketDataRepo, _, orderService := setUp()
 
 		order := &models.Order{
 			UserID:       1,
@@ -360,7 +599,7 @@ func TestPlaceOrder(t *testing.T) {
 	})
 
 	t.Run("Place order with create error", func(t *testing.T) {
-		mockOrderRepo, mockUserRepo, mockInstrumentRepo, mockMarketDataRepo, orderService := setUp()
+		mockOrderRepo, mockUserRepo, mockInstrumentRepo, mockMarketDataRepo, mockPositionRepo, orderService := setUp()
 
 		order := &models.Order{
 			UserID:       1,
@@ -373,7 +612,8 @@ func TestPlaceOrder(t *testing.T) {
 		mockUserRepo.On("GetByID", uint(1)).Return(&models.User{ID: 1}, nil)
 		mockInstrumentRepo.On("GetByID", uint(1)).Return(&models.Instrument{ID: 1}, nil)
 		mockMarketDataRepo.On("GetLatestMarketData", uint(1)).Return(&models.MarketData{Close: 100}, nil)
-		mockOrderRepo.On("GetUserCashBalance", uint(1)).Return(float64(2000), nil)
+		mockPositionRepo.On("GetAccountCashBalance", uint(1), uint(0), "ARS").Return(float64(2000), nil)
+		mockOrderRepo.On("GetUnsettledCash", uint(1), mock.AnythingOfType("time.Time")).Return([]models.CashBalance{}, nil)
 		mockOrderRepo.On("Create", mock.AnythingOfType("*models.Order")).Return(errors.New("create error"))
 
 		err := orderService.PlaceOrder(order, 0)
@@ -381,6 +621,7 @@ func TestPlaceOrder(t *testing.T) {
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "create error")
 		mockOrderRepo.AssertExpectations(t)
+		mockPositionRepo.AssertExpectations(t)
 		mockUserRepo.AssertExpectations(t)
 		mockInstrumentRepo.AssertExpectations(t)
 		mockMarketDataRepo.AssertExpectations(t)
@@ -391,7 +632,7 @@ func TestPlaceOrder(t *testing.T) {
 func TestCancelOrder(t *testing.T) {
 	setUp := func() (*mocks.OrderRepositorer, *OrderService) {
 		mockOrderRepo := new(mocks.OrderRepositorer)
-		orderService := NewOrderService(mockOrderRepo, nil, nil, nil)
+		orderService := NewOrderService(mockOrderRepo, nil, nil, nil, nil, nil, models.DefaultSettlementCycle, nil)
 		return mockOrderRepo, orderService
 	}
 
@@ -448,44 +689,3 @@ func TestCancelOrder(t *testing.T) {
 		mockOrderRepo.AssertExpectations(t)
 	})
 }
-
-func TestCalculateUserPositions(t *testing.T) {
-	setUp := func() (*mocks.OrderRepositorer, *OrderService) {
-		mockOrderRepo := new(mocks.OrderRepositorer)
-		orderService := NewOrderService(mockOrderRepo, nil, nil, nil)
-		return mockOrderRepo, orderService
-	}
-
-	t.Run("Calculate user positions", func(t *testing.T) {
-		mockOrderRepo, orderService := setUp()
-
-		userID := uint(1)
-		mockOrderRepo.On("GetUserFilledOrders", userID).Return([]models.Order{
-			{InstrumentID: 1, Side: "BUY", Size: 10, Status: "FILLED"},
-			{InstrumentID: 1, Side: "SELL", Size: 5, Status: "FILLED"},
-			{InstrumentID: 2, Side: "BUY", Size: 20, Status: "FILLED"},
-		}, nil)
-
-		positions, err := orderService.calculateUserPositions(userID)
-
-		assert.NoError(t, err)
-		assert.Equal(t, float64(5), positions[1])
-		assert.Equal(t, float64(20), positions[2])
-		mockOrderRepo.AssertExpectations(t)
-	})
-
-	t.Run("Calculate user positions with error", func(t *testing.T) {
-		mockOrderRepo, orderService := setUp()
-
-		userID := uint(1)
-		expectedError := errors.New("database error")
-		mockOrderRepo.On("GetUserFilledOrders", userID).Return([]models.Order{}, expectedError)
-
-		positions, err := orderService.calculateUserPositions(userID)
-
-		assert.Error(t, err)
-		assert.Nil(t, positions)
-		assert.Equal(t, expectedError, err)
-		mockOrderRepo.AssertExpectations(t)
-	})
-}
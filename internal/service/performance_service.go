@@ -0,0 +1,548 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/NahuelDT/portfolio-api/internal/models"
+	"github.com/NahuelDT/portfolio-api/internal/repository"
+)
+
+var ErrInvalidPeriod = errors.New("invalid period")
+
+type PerformanceService struct {
+	userRepo            repository.UserRepositorer
+	orderRepo           repository.OrderRepositorer
+	instrumentRepo      repository.InstrumentRepositorer
+	marketDataRepo      repository.MarketDataRepositorer
+	fxRateRepo          repository.FXRateRepositorer
+	corporateActionRepo repository.CorporateActionRepositorer
+}
+
+func NewPerformanceService(
+	userRepo repository.UserRepositorer,
+	orderRepo repository.OrderRepositorer,
+	instrumentRepo repository.InstrumentRepositorer,
+	marketDataRepo repository.MarketDataRepositorer,
+	fxRateRepo repository.FXRateRepositorer,
+	corporateActionRepo repository.CorporateActionRepositorer,
+) *PerformanceService {
+	return &PerformanceService{
+		userRepo:            userRepo,
+		orderRepo:           orderRepo,
+		instrumentRepo:      instrumentRepo,
+		marketDataRepo:      marketDataRepo,
+		fxRateRepo:          fxRateRepo,
+		corporateActionRepo: corporateActionRepo,
+	}
+}
+
+// GetPerformance calculates the user's returns over the given period in the
+// given base currency. TimeWeightedReturn is the cumulative return for the
+// period with CASH_IN and CASH_OUT flows neutralized. MoneyWeightedReturn is
+// the annualized internal rate of return (XIRR) of those same flows.
+// Holdings and flows in other currencies are converted at the FX rate of
+// each date they are valued on, so returns include the FX move.
+func (s *PerformanceService) GetPerformance(userID uint, period string, baseCurrency string) (*models.Performance, error) {
+	end := time.Now()
+	start, err := periodStart(period, end)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.calculatePerformance(userID, strings.ToUpper(period), start, end, baseCurrency)
+}
+
+// GetPerformanceBetween calculates the user's returns between two moments,
+// as GetPerformance does for a period code. A zero from starts at the
+// user's first order, and a to in the future ends now.
+func (s *PerformanceService) GetPerformanceBetween(userID uint, from, to time.Time, baseCurrency string) (*models.Performance, error) {
+	if now := time.Now(); to.After(now) {
+		to = now
+	}
+	if !from.Before(to) {
+		return nil, fmt.Errorf("%w: from must be before to", ErrInvalidPeriod)
+	}
+	return s.calculatePerformance(userID, "CUSTOM", from, to, baseCurrency)
+}
+
+func (s *PerformanceService) calculatePerformance(userID uint, period string, start, end time.Time, baseCurrency string) (*models.Performance, error) {
+	if _, err := s.userRepo.GetByID(userID); err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	orders, err := s.orderRepo.GetUserFilledOrders(userID)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(orders, func(i, j int) bool {
+		return orders[i].DateTime.Before(orders[j].DateTime)
+	})
+	for len(orders) > 0 && orders[len(orders)-1].DateTime.After(end) {
+		orders = orders[:len(orders)-1]
+	}
+	actions, err := loadCorporateActions(s.corporateActionRepo, end)
+	if err != nil {
+		return nil, err
+	}
+	orders = actions.adjustOrders(orders)
+
+	if len(orders) > 0 && start.Before(orders[0].DateTime) {
+		start = orders[0].DateTime
+	}
+
+	marketData, err := loadMarketData(s.marketDataRepo, orders, end, actions)
+	if err != nil {
+		return nil, err
+	}
+	valuation, err := newValuation(s.instrumentRepo, s.fxRateRepo, orders, marketData, baseCurrency, end)
+	if err != nil {
+		return nil, err
+	}
+
+	holdings := newHoldings()
+	i := 0
+	for ; i < len(orders) && orders[i].DateTime.Before(start); i++ {
+		holdings.apply(orders[i])
+	}
+
+	performance := &models.Performance{
+		Period:       period,
+		From:         start,
+		To:           end,
+		BaseCurrency: valuation.baseCurrency,
+		StartValue:   valuation.value(holdings, start),
+	}
+
+	flows := make([]cashFlow, 0)
+	if performance.StartValue > 0 {
+		flows = append(flows, cashFlow{date: start, amount: -performance.StartValue})
+	}
+
+	growth := 1.0
+	subPeriodStartValue := performance.StartValue
+	for ; i < len(orders); i++ {
+		order := orders[i]
+		amount, external := valuation.externalFlow(order)
+		if !external {
+			holdings.apply(order)
+			continue
+		}
+
+		// Close the sub-period right before the external flow
+		if subPeriodStartValue > 0 {
+			growth *= valuation.value(holdings, order.DateTime) / subPeriodStartValue
+		}
+		holdings.apply(order)
+		subPeriodStartValue = valuation.value(holdings, order.DateTime)
+
+		performance.NetCashFlow += amount
+		flows = append(flows, cashFlow{date: order.DateTime, amount: -amount})
+	}
+
+	performance.EndValue = valuation.value(holdings, end)
+	if subPeriodStartValue > 0 {
+		growth *= performance.EndValue / subPeriodStartValue
+	}
+	performance.TimeWeightedReturn = (growth - 1) * 100
+
+	flows = append(flows, cashFlow{date: end, amount: performance.EndValue})
+	if rate, ok := xirr(flows); ok {
+		performance.MoneyWeightedReturn = rate * 100
+	}
+
+	return performance, nil
+}
+
+// CompareToBenchmark returns the portfolio's cumulative performance next to
+// the benchmark instrument's over the period, both rebased to 100 on the
+// first day the portfolio has a value. Portfolio performance neutralizes
+// CASH_IN and CASH_OUT flows. Both are valued in the given base currency.
+// TrackingError is the annualized standard deviation of the daily return
+// differences.
+func (s *PerformanceService) CompareToBenchmark(userID uint, benchmarkID uint, period string, baseCurrency string) (*models.BenchmarkComparison, error) {
+	end := time.Now()
+	start, err := periodStart(period, end)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.userRepo.GetByID(userID); err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	benchmark, err := s.instrumentRepo.GetByID(benchmarkID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBenchmark, err)
+	}
+
+	orders, err := s.orderRepo.GetUserFilledOrders(userID)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(orders, func(i, j int) bool {
+		return orders[i].DateTime.Before(orders[j].DateTime)
+	})
+	if len(orders) > 0 && start.Before(orders[0].DateTime) {
+		start = dayOf(orders[0].DateTime)
+	}
+	actions, err := loadCorporateActions(s.corporateActionRepo, end)
+	if err != nil {
+		return nil, err
+	}
+	orders = actions.adjustOrders(orders)
+
+	marketData, err := loadMarketData(s.marketDataRepo, orders, end, actions)
+	if err != nil {
+		return nil, err
+	}
+	benchmarkData, err := s.marketDataRepo.GetMarketDataRange(benchmarkID, time.Time{}, end)
+	if err != nil {
+		return nil, err
+	}
+	benchmarkData = actions.adjustMarketData(benchmarkID, benchmarkData)
+
+	// The benchmark is priced from its closes only, in the base currency
+	benchmarkPrices := make(priceHistory)
+	for _, row := range benchmarkData {
+		benchmarkPrices.add(benchmarkID, row.DateTime, row.Close)
+	}
+	priced := map[uint][]models.MarketData{benchmarkID: benchmarkData}
+	for instrumentID, data := range marketData {
+		priced[instrumentID] = data
+	}
+	valuation, err := newValuation(s.instrumentRepo, s.fxRateRepo, orders, priced, baseCurrency, end)
+	if err != nil {
+		return nil, err
+	}
+
+	comparison := &models.BenchmarkComparison{
+		Period:       strings.ToUpper(period),
+		From:         start,
+		To:           end,
+		BaseCurrency: valuation.baseCurrency,
+		Benchmark:    benchmark.Ticker,
+		Series:       make([]models.BenchmarkPoint, 0),
+	}
+
+	returns := portfolioReturns(orders, marketData, valuation, start)
+	if len(returns) == 0 {
+		return comparison, nil
+	}
+
+	// Rebase on the trading day before the first portfolio return
+	days := tradingDays(marketData, start)
+	baseDay := days[sort.Search(len(days), func(i int) bool {
+		return !days[i].Before(returns[0].day)
+	})-1]
+	closeOn := func(day time.Time) float64 {
+		at := day.Add(24*time.Hour - time.Nanosecond)
+		return benchmarkPrices.at(benchmarkID, at) * valuation.fx.rateAt(valuation.currencies[benchmarkID], at)
+	}
+	benchmarkBase := closeOn(baseDay)
+	if benchmarkBase <= 0 {
+		return nil, fmt.Errorf("%w: no market data for %s on %s", ErrInvalidBenchmark, benchmark.Ticker, baseDay.Format("2006-01-02"))
+	}
+
+	portfolioIndex, benchmarkIndex := 100.0, 100.0
+	comparison.Series = append(comparison.Series, models.BenchmarkPoint{Date: baseDay, Portfolio: portfolioIndex, Benchmark: benchmarkIndex})
+	differences := make([]dailyValue, 0, len(returns))
+	for _, r := range returns {
+		previousBenchmarkIndex := benchmarkIndex
+		portfolioIndex *= 1 + r.value
+		benchmarkIndex = closeOn(r.day) / benchmarkBase * 100
+		differences = append(differences, dailyValue{day: r.day, value: r.value - (benchmarkIndex/previousBenchmarkIndex - 1)})
+		comparison.Series = append(comparison.Series, models.BenchmarkPoint{Date: r.day, Portfolio: portfolioIndex, Benchmark: benchmarkIndex})
+	}
+
+	comparison.PortfolioReturn = portfolioIndex - 100
+	comparison.BenchmarkReturn = benchmarkIndex - 100
+	comparison.ExcessReturn = comparison.PortfolioReturn - comparison.BenchmarkReturn
+	comparison.TrackingError = calculateRiskMetrics(differences, nil, 0).Volatility
+
+	return comparison, nil
+}
+
+// loadMarketData fetches the market data up to the given date of every
+// instrument the orders traded or transferred, adjusted for splits.
+func loadMarketData(marketDataRepo repository.MarketDataRepositorer, orders []models.Order, to time.Time, actions corporateActions) (map[uint][]models.MarketData, error) {
+	marketData := make(map[uint][]models.MarketData)
+	for _, order := range orders {
+		if !movesPosition(order.Side) {
+			continue
+		}
+		if _, ok := marketData[order.InstrumentID]; ok {
+			continue
+		}
+		data, err := marketDataRepo.GetMarketDataRange(order.InstrumentID, time.Time{}, to)
+		if err != nil {
+			return nil, err
+		}
+		marketData[order.InstrumentID] = actions.adjustMarketData(order.InstrumentID, data)
+	}
+	return marketData, nil
+}
+
+// newPriceHistory merges market data closes with fill prices, so dates
+// without market data are still priced.
+func newPriceHistory(marketData map[uint][]models.MarketData, orders []models.Order) priceHistory {
+	prices := make(priceHistory)
+	for instrumentID, data := range marketData {
+		for _, row := range data {
+			prices.add(instrumentID, row.DateTime, row.Close)
+		}
+	}
+	for _, order := range orders {
+		if order.Side == "BUY" || order.Side == "SELL" {
+			prices.add(order.InstrumentID, order.DateTime, order.Price)
+		}
+	}
+	for instrumentID := range prices {
+		points := prices[instrumentID]
+		sort.SliceStable(points, func(i, j int) bool {
+			return points[i].date.Before(points[j].date)
+		})
+	}
+	return prices
+}
+
+// periodStart resolves a period code to the moment it starts. A zero time
+// means since inception.
+func periodStart(period string, now time.Time) (time.Time, error) {
+	switch strings.ToUpper(period) {
+	case "", "ALL", "ITD":
+		return time.Time{}, nil
+	case "MTD":
+		return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location()), nil
+	case "YTD":
+		return time.Date(now.Year(), time.January, 1, 0, 0, 0, 0, now.Location()), nil
+	case "1Y":
+		return now.AddDate(-1, 0, 0), nil
+	default:
+		return time.Time{}, fmt.Errorf("%w: %s", ErrInvalidPeriod, period)
+	}
+}
+
+type pricePoint struct {
+	date  time.Time
+	price float64
+}
+
+// priceHistory holds the known prices of each instrument, oldest first
+type priceHistory map[uint][]pricePoint
+
+func (h priceHistory) add(instrumentID uint, date time.Time, price float64) {
+	h[instrumentID] = append(h[instrumentID], pricePoint{date: date, price: price})
+}
+
+// at returns the latest known price of the instrument at or before date
+func (h priceHistory) at(instrumentID uint, date time.Time) float64 {
+	points := h[instrumentID]
+	i := sort.Search(len(points), func(i int) bool {
+		return points[i].date.After(date)
+	})
+	if i == 0 {
+		return 0
+	}
+	return points[i-1].price
+}
+
+// holdings replays filled orders into cash by currency and position
+// quantities
+type holdings struct {
+	cash      map[string]float64
+	positions map[uint]float64
+}
+
+func newHoldings() *holdings {
+	return &holdings{cash: make(map[string]float64), positions: make(map[uint]float64)}
+}
+
+func (h *holdings) apply(order models.Order) {
+	currency := models.NormalizeCurrency(order.Currency)
+	switch order.Side {
+	case "CASH_IN", "TRANSFER_IN":
+		h.cash[currency] += order.Size
+	case "CASH_OUT", "TRANSFER_OUT":
+		h.cash[currency] -= order.Size
+	case "SECURITIES_IN":
+		h.positions[order.InstrumentID] += order.Size
+	case "SECURITIES_OUT":
+		h.positions[order.InstrumentID] -= order.Size
+	case "BUY":
+		h.cash[currency] -= order.Size * order.Price
+		h.positions[order.InstrumentID] += order.Size
+	case "SELL":
+		h.cash[currency] += order.Size * order.Price
+		h.positions[order.InstrumentID] -= order.Size
+	case "DIVIDEND":
+		h.cash[currency] += order.Size * order.Price
+	}
+}
+
+// movesPosition reports whether orders of the side change a position
+func movesPosition(side string) bool {
+	switch side {
+	case "BUY", "SELL", "SECURITIES_IN", "SECURITIES_OUT":
+		return true
+	}
+	return false
+}
+
+// valuation values holdings and external flows in a base currency. Each
+// instrument is priced in its own currency and every amount is converted
+// at the FX rate of the date it is valued on.
+type valuation struct {
+	prices       priceHistory
+	instruments  map[uint]*models.Instrument
+	currencies   map[uint]string
+	fx           *fxHistory
+	baseCurrency string
+}
+
+// newValuation prices the instruments the orders moved with their market
+// data, fetching them all in one query, and loads the FX rates up to the
+// given date of every currency the orders and instruments use
+func newValuation(
+	instrumentRepo repository.InstrumentRepositorer,
+	fxRateRepo repository.FXRateRepositorer,
+	orders []models.Order,
+	marketData map[uint][]models.MarketData,
+	baseCurrency string,
+	to time.Time,
+) (*valuation, error) {
+	v := &valuation{
+		prices:       newPriceHistory(marketData, orders),
+		instruments:  make(map[uint]*models.Instrument),
+		currencies:   make(map[uint]string),
+		baseCurrency: models.NormalizeCurrency(baseCurrency),
+	}
+
+	currencies := make([]string, 0)
+	for _, order := range orders {
+		currencies = append(currencies, models.NormalizeCurrency(order.Currency))
+	}
+	instrumentIDs := make([]uint, 0, len(marketData))
+	for instrumentID := range marketData {
+		instrumentIDs = append(instrumentIDs, instrumentID)
+	}
+	if len(instrumentIDs) > 0 {
+		sort.Slice(instrumentIDs, func(i, j int) bool { return instrumentIDs[i] < instrumentIDs[j] })
+		instruments, err := instrumentRepo.GetByIDs(instrumentIDs)
+		if err != nil {
+			return nil, err
+		}
+		for _, instrumentID := range instrumentIDs {
+			instrument, ok := instruments[instrumentID]
+			if !ok {
+				return nil, fmt.Errorf("instrument with ID %d not found", instrumentID)
+			}
+			v.instruments[instrumentID] = instrument
+			v.currencies[instrumentID] = models.NormalizeCurrency(instrument.Currency)
+			currencies = append(currencies, v.currencies[instrumentID])
+		}
+	}
+
+	var err error
+	if v.fx, err = loadFXHistory(fxRateRepo, currencies, v.baseCurrency, to); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// value returns what the holdings are worth at the given date
+func (v *valuation) value(h *holdings, date time.Time) float64 {
+	total := 0.0
+	for currency, balance := range h.cash {
+		if balance != 0 {
+			total += balance * v.fx.rateAt(currency, date)
+		}
+	}
+	for instrumentID, quantity := range h.positions {
+		if quantity != 0 {
+			total += quantity * v.price(instrumentID, date)
+		}
+	}
+	return total
+}
+
+// price returns the instrument's price at the given date in the base
+// currency
+func (v *valuation) price(instrumentID uint, at time.Time) float64 {
+	return v.prices.at(instrumentID, at) * v.fx.rateAt(v.currencies[instrumentID], at)
+}
+
+// externalFlow returns the value an order adds to or withdraws from the
+// portfolio from outside it: deposits, withdrawals and transfers, with
+// securities valued at their price at the time. Transfers between the
+// user's own accounts come in pairs that cancel out.
+func (v *valuation) externalFlow(order models.Order) (float64, bool) {
+	at := order.DateTime
+	switch order.Side {
+	case "CASH_IN", "TRANSFER_IN":
+		return order.Size * v.fx.rateAt(models.NormalizeCurrency(order.Currency), at), true
+	case "CASH_OUT", "TRANSFER_OUT":
+		return -order.Size * v.fx.rateAt(models.NormalizeCurrency(order.Currency), at), true
+	case "SECURITIES_IN":
+		return order.Size * v.price(order.InstrumentID, at), true
+	case "SECURITIES_OUT":
+		return -order.Size * v.price(order.InstrumentID, at), true
+	}
+	return 0, false
+}
+
+type cashFlow struct {
+	date   time.Time
+	amount float64
+}
+
+// xirr solves for the annual rate that makes the net present value of the
+// flows zero. It reports false when the flows have no solution.
+func xirr(flows []cashFlow) (float64, bool) {
+	if len(flows) < 2 {
+		return 0, false
+	}
+	hasPositive, hasNegative := false, false
+	for _, flow := range flows {
+		hasPositive = hasPositive || flow.amount > 0
+		hasNegative = hasNegative || flow.amount < 0
+	}
+	if !hasPositive || !hasNegative {
+		return 0, false
+	}
+
+	first := flows[0].date
+	npv := func(rate float64) float64 {
+		total := 0.0
+		for _, flow := range flows {
+			years := flow.date.Sub(first).Hours() / 24 / 365
+			total += flow.amount / math.Pow(1+rate, years)
+		}
+		return total
+	}
+
+	// Bisection over a bracket wide enough for any realistic return
+	low, high := -0.9999, 1.0
+	for npv(high) > 0 && high < 1e6 {
+		high *= 2
+	}
+	if npv(low)*npv(high) > 0 {
+		return 0, false
+	}
+	for i := 0; i < 200; i++ {
+		mid := (low + high) / 2
+		if npv(low)*npv(mid) <= 0 {
+			high = mid
+		} else {
+			low = mid
+		}
+		if high-low < 1e-10 {
+			break
+		}
+	}
+	return (low + high) / 2, true
+}
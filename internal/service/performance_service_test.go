@@ -0,0 +1,242 @@
+package service
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	mocks "github.com/NahuelDT/portfolio-api/internal/mocks/repository"
+	"github.com/NahuelDT/portfolio-api/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetPerformance(t *testing.T) {
+	mockUserRepo := new(mocks.UserRepositorer)
+	mockOrderRepo := new(mocks.OrderRepositorer)
+	mockInstrumentRepo := new(mocks.InstrumentRepositorer)
+	mockMarketDataRepo := new(mocks.MarketDataRepositorer)
+	mockFXRateRepo := new(mocks.FXRateRepositorer)
+	mockCorporateActionRepo := new(mocks.CorporateActionRepositorer)
+	mockCorporateActionRepo.On("GetEffectiveAsOf", mock.Anything).Return([]models.CorporateAction{}, nil)
+
+	performanceService := NewPerformanceService(mockUserRepo, mockOrderRepo, mockInstrumentRepo, mockMarketDataRepo, mockFXRateRepo, mockCorporateActionRepo)
+
+	now := time.Now()
+	t0 := now.AddDate(0, 0, -730)
+	t1 := now.AddDate(0, 0, -365)
+
+	t.Run("Since inception with a deposit in between", func(t *testing.T) {
+		userID := uint(1)
+		mockOrders := []models.Order{
+			{ID: 3, UserID: userID, Side: "CASH_IN", Size: 1000, Status: "FILLED", DateTime: t1},
+			{ID: 1, UserID: userID, Side: "CASH_IN", Size: 1000, Status: "FILLED", DateTime: t0},
+			{ID: 2, InstrumentID: 1, UserID: userID, Side: "BUY", Size: 10, Price: 100, Status: "FILLED", DateTime: t0.Add(time.Hour)},
+		}
+		mockMarketData := []models.MarketData{
+			{InstrumentID: 1, Close: 100, DateTime: t0},
+			{InstrumentID: 1, Close: 120, DateTime: t1},
+			{InstrumentID: 1, Close: 132, DateTime: now.AddDate(0, 0, -1)},
+		}
+
+		mockUserRepo.On("GetByID", userID).Return(&models.User{ID: userID}, nil)
+		mockOrderRepo.On("GetUserFilledOrders", userID).Return(mockOrders, nil)
+		mockMarketDataRepo.On("GetMarketDataRange", uint(1), time.Time{}, mock.AnythingOfType("time.Time")).Return(mockMarketData, nil)
+		mockInstrumentRepo.On("GetByIDs", []uint{1}).Return(map[uint]*models.Instrument{1: {ID: 1, Currency: "ARS"}}, nil)
+
+		performance, err := performanceService.GetPerformance(userID, "all", "ARS")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "ALL", performance.Period)
+		assert.Equal(t, "ARS", performance.BaseCurrency)
+		assert.Equal(t, t0, performance.From)
+		assert.Equal(t, float64(0), performance.StartValue)
+		assert.Equal(t, float64(2320), performance.EndValue) // 1000 (cash) + 10 * 132
+		assert.Equal(t, float64(2000), performance.NetCashFlow)
+		assert.InDelta(t, 26.5454, performance.TimeWeightedReturn, 1e-3) // 1200 / 1000 * 2320 / 2200
+		assert.InDelta(t, 10.3122, performance.MoneyWeightedReturn, 1e-3)
+
+		mockUserRepo.AssertExpectations(t)
+		mockOrderRepo.AssertExpectations(t)
+		mockMarketDataRepo.AssertExpectations(t)
+	})
+
+	t.Run("Transfers with other users are external flows", func(t *testing.T) {
+		userID := uint(3)
+		mockOrders := []models.Order{
+			{ID: 10, UserID: userID, Side: "CASH_IN", Size: 1500, Status: "FILLED", DateTime: t0},
+			{ID: 11, InstrumentID: 1, UserID: userID, Side: "BUY", Size: 10, Price: 100, Status: "FILLED", DateTime: t0.Add(time.Hour)},
+			{ID: 12, InstrumentID: 1, UserID: userID, Side: "SECURITIES_IN", Size: 5, Price: 90, Status: "FILLED", DateTime: t1, TransferID: 1},
+			{ID: 13, UserID: userID, Side: "TRANSFER_OUT", Size: 200, Status: "FILLED", DateTime: t1.Add(time.Hour), TransferID: 2},
+			// Between the user's own accounts
+			{ID: 14, UserID: userID, Side: "TRANSFER_OUT", Size: 100, Status: "FILLED", DateTime: t1.Add(2 * time.Hour), TransferID: 3},
+			{ID: 15, UserID: userID, AccountID: 4, Side: "TRANSFER_IN", Size: 100, Status: "FILLED", DateTime: t1.Add(2 * time.Hour), TransferID: 3},
+		}
+
+		mockUserRepo.On("GetByID", userID).Return(&models.User{ID: userID}, nil)
+		mockOrderRepo.On("GetUserFilledOrders", userID).Return(mockOrders, nil)
+
+		performance, err := performanceService.GetPerformance(userID, "all", "ARS")
+
+		assert.NoError(t, err)
+		assert.Equal(t, float64(2280), performance.EndValue)    // 300 (cash) + 15 * 132
+		assert.Equal(t, float64(1900), performance.NetCashFlow) // 1500 + 5 * 120 - 200
+		assert.InDelta(t, (1700.0/1500*2280/2100-1)*100, performance.TimeWeightedReturn, 1e-9)
+	})
+
+	t.Run("Foreign holdings are converted at each date's FX rate", func(t *testing.T) {
+		userID := uint(4)
+		mockOrders := []models.Order{
+			{ID: 20, UserID: userID, Side: "CASH_IN", Size: 100, Currency: "USD", Status: "FILLED", DateTime: t0},
+			{ID: 21, InstrumentID: 5, UserID: userID, Side: "BUY", Size: 1, Price: 100, Currency: "USD", Status: "FILLED", DateTime: t0.Add(time.Hour)},
+		}
+		mockMarketData := []models.MarketData{
+			{InstrumentID: 5, Close: 100, DateTime: t0},
+			{InstrumentID: 5, Close: 110, DateTime: now.AddDate(0, 0, -1)},
+		}
+		mockRates := []models.FXRate{
+			{BaseCurrency: "USD", QuoteCurrency: "ARS", Rate: 1000, DateTime: t0},
+			{BaseCurrency: "USD", QuoteCurrency: "ARS", Rate: 1200, DateTime: t1},
+		}
+
+		mockUserRepo.On("GetByID", userID).Return(&models.User{ID: userID}, nil)
+		mockOrderRepo.On("GetUserFilledOrders", userID).Return(mockOrders, nil)
+		mockMarketDataRepo.On("GetMarketDataRange", uint(5), time.Time{}, mock.AnythingOfType("time.Time")).Return(mockMarketData, nil)
+		mockInstrumentRepo.On("GetByIDs", []uint{5}).Return(map[uint]*models.Instrument{5: {ID: 5, Currency: "USD"}}, nil)
+		mockFXRateRepo.On("GetRateHistory", "USD", "ARS", mock.AnythingOfType("time.Time")).Return(mockRates, nil)
+
+		performance, err := performanceService.GetPerformance(userID, "all", "ARS")
+
+		assert.NoError(t, err)
+		assert.Equal(t, float64(132000), performance.EndValue)    // 110 USD at 1200
+		assert.Equal(t, float64(100000), performance.NetCashFlow) // 100 USD at 1000
+		assert.InDelta(t, 32, performance.TimeWeightedReturn, 1e-9)
+
+		// In its own currency the holding only gained 10%
+		performance, err = performanceService.GetPerformance(userID, "all", "USD")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "USD", performance.BaseCurrency)
+		assert.Equal(t, float64(110), performance.EndValue)
+		assert.InDelta(t, 10, performance.TimeWeightedReturn, 1e-9)
+		mockFXRateRepo.AssertNumberOfCalls(t, "GetRateHistory", 1)
+	})
+
+	t.Run("Invalid period", func(t *testing.T) {
+		performance, err := performanceService.GetPerformance(1, "5Y", "ARS")
+
+		assert.ErrorIs(t, err, ErrInvalidPeriod)
+		assert.Nil(t, performance)
+	})
+
+	t.Run("Custom period between two dates", func(t *testing.T) {
+		userID := uint(1)
+
+		performance, err := performanceService.GetPerformanceBetween(userID, t1, now.AddDate(1, 0, 0), "ARS")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "CUSTOM", performance.Period)
+		assert.Equal(t, t1, performance.From)
+		assert.False(t, performance.To.After(time.Now()))
+		assert.Equal(t, float64(1200), performance.StartValue) // 10 * 120, the deposit at t1 is a flow
+		assert.Equal(t, float64(2320), performance.EndValue)
+		assert.InDelta(t, (2320.0/2200-1)*100, performance.TimeWeightedReturn, 1e-9)
+
+		_, err = performanceService.GetPerformanceBetween(userID, t1, t0, "ARS")
+		assert.ErrorIs(t, err, ErrInvalidPeriod)
+	})
+
+	t.Run("User not found", func(t *testing.T) {
+		userID := uint(999)
+		mockUserRepo.On("GetByID", userID).Return(nil, errors.New("user not found"))
+
+		performance, err := performanceService.GetPerformance(userID, "YTD", "ARS")
+
+		assert.Error(t, err)
+		assert.Nil(t, performance)
+	})
+}
+
+func TestCompareToBenchmark(t *testing.T) {
+	mockUserRepo := new(mocks.UserRepositorer)
+	mockOrderRepo := new(mocks.OrderRepositorer)
+	mockInstrumentRepo := new(mocks.InstrumentRepositorer)
+	mockMarketDataRepo := new(mocks.MarketDataRepositorer)
+	mockFXRateRepo := new(mocks.FXRateRepositorer)
+	mockCorporateActionRepo := new(mocks.CorporateActionRepositorer)
+	mockCorporateActionRepo.On("GetEffectiveAsOf", mock.Anything).Return([]models.CorporateAction{}, nil)
+
+	performanceService := NewPerformanceService(mockUserRepo, mockOrderRepo, mockInstrumentRepo, mockMarketDataRepo, mockFXRateRepo, mockCorporateActionRepo)
+
+	t.Run("Series rebased to 100", func(t *testing.T) {
+		userID := uint(1)
+		base := dayOf(time.Now()).AddDate(0, 0, -10)
+		day := func(i int) time.Time { return base.AddDate(0, 0, i).Add(20 * time.Hour) }
+
+		mockOrders := []models.Order{
+			{ID: 1, UserID: userID, Side: "CASH_IN", Size: 1000, Status: "FILLED", DateTime: day(0)},
+			{ID: 2, InstrumentID: 1, UserID: userID, Side: "BUY", Size: 10, Price: 100, Status: "FILLED", DateTime: day(1)},
+		}
+		holdingCloses := []float64{100, 110, 99, 108.9, 119.79}
+		benchmarkCloses := []float64{100, 105, 99.75, 104.7375, 109.974375}
+		holdingData := make([]models.MarketData, 0)
+		benchmarkData := make([]models.MarketData, 0)
+		for i := range holdingCloses {
+			holdingData = append(holdingData, models.MarketData{InstrumentID: 1, Close: holdingCloses[i], DateTime: day(i + 1)})
+			benchmarkData = append(benchmarkData, models.MarketData{InstrumentID: 2, Close: benchmarkCloses[i], DateTime: day(i + 1)})
+		}
+
+		mockUserRepo.On("GetByID", userID).Return(&models.User{ID: userID}, nil)
+		mockOrderRepo.On("GetUserFilledOrders", userID).Return(mockOrders, nil)
+		mockInstrumentRepo.On("GetByID", uint(2)).Return(&models.Instrument{ID: 2, Ticker: "SPY"}, nil)
+		mockMarketDataRepo.On("GetMarketDataRange", uint(1), mock.Anything, mock.Anything).Return(holdingData, nil)
+		mockMarketDataRepo.On("GetMarketDataRange", uint(2), mock.Anything, mock.Anything).Return(benchmarkData, nil)
+		mockInstrumentRepo.On("GetByIDs", []uint{1, 2}).Return(map[uint]*models.Instrument{1: {ID: 1}, 2: {ID: 2, Ticker: "SPY"}}, nil)
+
+		comparison, err := performanceService.CompareToBenchmark(userID, 2, "ALL", "ARS")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "SPY", comparison.Benchmark)
+		assert.Len(t, comparison.Series, 5)
+		for i, point := range comparison.Series {
+			assert.Equal(t, dayOf(day(i+1)), point.Date)
+			assert.InDelta(t, holdingCloses[i], point.Portfolio, 1e-9)
+			assert.InDelta(t, benchmarkCloses[i], point.Benchmark, 1e-9)
+		}
+		assert.InDelta(t, 19.79, comparison.PortfolioReturn, 1e-9)
+		assert.InDelta(t, 9.974375, comparison.BenchmarkReturn, 1e-9)
+		assert.InDelta(t, 9.815625, comparison.ExcessReturn, 1e-9)
+		assert.InDelta(t, 0.05*math.Sqrt(tradingDaysPerYear)*100, comparison.TrackingError, 1e-6) // daily differences of +5%, -5%, +5%, +5%
+
+		mockInstrumentRepo.AssertExpectations(t)
+		mockMarketDataRepo.AssertExpectations(t)
+	})
+
+	t.Run("Invalid benchmark", func(t *testing.T) {
+		userID := uint(1)
+		mockInstrumentRepo.On("GetByID", uint(999)).Return(nil, errors.New("record not found"))
+
+		comparison, err := performanceService.CompareToBenchmark(userID, 999, "YTD", "ARS")
+
+		assert.ErrorIs(t, err, ErrInvalidBenchmark)
+		assert.Nil(t, comparison)
+	})
+}
+
+func TestXIRR(t *testing.T) {
+	start := time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	rate, ok := xirr([]cashFlow{
+		{date: start, amount: -1000},
+		{date: start.AddDate(0, 0, 365), amount: 1100},
+	})
+	assert.True(t, ok)
+	assert.InDelta(t, 0.10, rate, 1e-6)
+
+	_, ok = xirr([]cashFlow{
+		{date: start, amount: 1000},
+		{date: start.AddDate(0, 0, 365), amount: 1100},
+	})
+	assert.False(t, ok)
+}
@@ -1,17 +1,36 @@
 package service
 
 import (
+	"errors"
 	"fmt"
+	"sort"
+	"time"
 
 	"github.com/NahuelDT/portfolio-api/internal/models"
 	"github.com/NahuelDT/portfolio-api/internal/repository"
 )
 
+// CashAllocationKey is the bucket that holds available cash in an allocation
+const CashAllocationKey = "CASH"
+
+var ErrInvalidAllocationGroup = errors.New("invalid allocation group")
+
+// allocationGroups maps each supported groupBy value to the attribute of a
+// holding it groups on. New instrument attributes are added here.
+var allocationGroups = map[string]func(asset models.PortfolioAsset) string{
+	"type":     func(asset models.PortfolioAsset) string { return asset.Type },
+	"currency": func(asset models.PortfolioAsset) string { return asset.Currency },
+}
+
 type PortfolioService struct {
-	userRepo       repository.UserRepositorer
-	orderRepo      repository.OrderRepositorer
-	instrumentRepo repository.InstrumentRepositorer
-	marketDataRepo repository.MarketDataRepositorer
+	userRepo            repository.UserRepositorer
+	orderRepo           repository.OrderRepositorer
+	instrumentRepo      repository.InstrumentRepositorer
+	marketDataRepo      repository.MarketDataRepositorer
+	fxRateRepo          repository.FXRateRepositorer
+	corporateActionRepo repository.CorporateActionRepositorer
+	positionRepo        repository.PositionRepositorer
+	accountRepo         repository.AccountRepositorer
 }
 
 func NewPortfolioService(
@@ -19,92 +38,494 @@ func NewPortfolioService(
 	orderRepo repository.OrderRepositorer,
 	instrumentRepo repository.InstrumentRepositorer,
 	marketDataRepo repository.MarketDataRepositorer,
+	fxRateRepo repository.FXRateRepositorer,
+	corporateActionRepo repository.CorporateActionRepositorer,
+	positionRepo repository.PositionRepositorer,
+	accountRepo repository.AccountRepositorer,
 ) *PortfolioService {
 	return &PortfolioService{
-		userRepo:       userRepo,
-		orderRepo:      orderRepo,
-		instrumentRepo: instrumentRepo,
-		marketDataRepo: marketDataRepo,
+		userRepo:            userRepo,
+		orderRepo:           orderRepo,
+		instrumentRepo:      instrumentRepo,
+		marketDataRepo:      marketDataRepo,
+		fxRateRepo:          fxRateRepo,
+		corporateActionRepo: corporateActionRepo,
+		positionRepo:        positionRepo,
+		accountRepo:         accountRepo,
 	}
 }
 
-func (s *PortfolioService) GetPortfolio(userID uint) (*models.Portfolio, error) {
-	// Check if user exists
-	user, err := s.userRepo.GetByID(userID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get user: %w", err)
+// GetPortfolio values the user's portfolio in the given base currency from
+// the materialized positions and cash balances, consolidating all of their
+// accounts. Only the orders of the holdings that are needed for daily
+// change and FX cost basis are read.
+func (s *PortfolioService) GetPortfolio(userID uint, baseCurrency string) (*models.Portfolio, error) {
+	return s.portfolio(userID, nil, baseCurrency)
+}
+
+// GetAccountPortfolio values a single account of the user like GetPortfolio
+func (s *PortfolioService) GetAccountPortfolio(userID, accountID uint, baseCurrency string) (*models.Portfolio, error) {
+	if err := checkAccount(s.accountRepo, userID, accountID); err != nil {
+		return nil, err
 	}
-	if user == nil {
-		return nil, fmt.Errorf("user with ID %d not found", userID)
+	return s.portfolio(userID, &accountID, baseCurrency)
+}
+
+// GetPortfolioAsOf values the portfolio as it was at the given moment, using
+// only the orders filled up to then and the latest prices at or before it.
+func (s *PortfolioService) GetPortfolioAsOf(userID uint, asOf time.Time, baseCurrency string) (*models.Portfolio, error) {
+	return s.portfolioAsOf(userID, nil, asOf, baseCurrency)
+}
+
+// GetAccountPortfolioAsOf values a single account of the user like
+// GetPortfolioAsOf
+func (s *PortfolioService) GetAccountPortfolioAsOf(userID, accountID uint, asOf time.Time, baseCurrency string) (*models.Portfolio, error) {
+	if err := checkAccount(s.accountRepo, userID, accountID); err != nil {
+		return nil, err
 	}
+	return s.portfolioAsOf(userID, &accountID, asOf, baseCurrency)
+}
 
-	// Get user's cash balance
-	cash, err := s.orderRepo.GetUserCashBalance(userID)
+// portfolio values the user's accounts, or only accountID when it is set
+func (s *PortfolioService) portfolio(userID uint, accountID *uint, baseCurrency string) (*models.Portfolio, error) {
+	if err := s.checkUser(userID); err != nil {
+		return nil, err
+	}
+
+	// Get user's cash balance in each currency
+	balances, err := s.positionRepo.GetUserCashBalances(userID)
 	if err != nil {
 		return nil, err
 	}
 
-	// Get user's filled orders
-	orders, err := s.orderRepo.GetUserFilledOrders(userID)
+	// Get user's positions
+	accountPositions, err := s.positionRepo.GetUserPositions(userID)
 	if err != nil {
 		return nil, err
 	}
+	cash, positions := consolidateBalances(balances, accountPositions, accountID)
 
-	portfolio := &models.Portfolio{
-		AvailableCash: cash,
-		Assets:        make([]models.PortfolioAsset, 0),
+	// Orders are restated like the positions, so the cost basis of split
+	// and merged holdings matches their bought quantity
+	actions, err := loadCorporateActions(s.corporateActionRepo, time.Now())
+	if err != nil {
+		return nil, err
 	}
 
-	// Calculate net positions
-	positions := make(map[uint]float64)
-	for _, order := range orders {
-		if order.Side == "BUY" {
-			positions[order.InstrumentID] += order.Size
-		} else if order.Side == "SELL" {
-			positions[order.InstrumentID] -= order.Size
+	fx := newFXConverter(s.fxRateRepo, nil)
+	portfolio, err := s.valuePortfolio(cash, positions, func(instrumentIDs []uint, since time.Time) ([]models.Order, error) {
+		orders, err := s.orderRepo.GetUserInstrumentOrders(userID, actions.withMergedSources(instrumentIDs), since)
+		if err != nil {
+			return nil, err
 		}
+		return filterOrders(accountOrders(actions.adjustOrders(orders), accountID), instrumentIDs, since), nil
+	}, s.marketDataRepo.GetLatestMarketDataForInstruments, fx, models.NormalizeCurrency(baseCurrency))
+	if err != nil {
+		return nil, err
 	}
+	portfolio.AccountID = accountID
 
-	for instrumentID, netQuantity := range positions {
-		if netQuantity > 0 {
-			instrument, err := s.instrumentRepo.GetByID(instrumentID)
-			if err != nil {
-				return nil, err
-			}
+	unsettled, err := s.orderRepo.GetUnsettledCash(userID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	splitSettledCash(portfolio, unsettled, accountID)
 
-			marketData, err := s.marketDataRepo.GetLatestMarketData(instrumentID)
+	return portfolio, nil
+}
+
+// portfolioAsOf values the user's accounts at the given moment, or only
+// accountID when it is set
+func (s *PortfolioService) portfolioAsOf(userID uint, accountID *uint, asOf time.Time, baseCurrency string) (*models.Portfolio, error) {
+	if err := s.checkUser(userID); err != nil {
+		return nil, err
+	}
+
+	orders, err := s.orderRepo.GetUserFilledOrdersAsOf(userID, asOf)
+	if err != nil {
+		return nil, err
+	}
+	actions, err := loadCorporateActions(s.corporateActionRepo, asOf)
+	if err != nil {
+		return nil, err
+	}
+	orders = accountOrders(actions.adjustOrders(orders), accountID)
+	accountPositions, balances := replayBalances(userID, orders)
+	cash, positions := consolidateBalances(balances, accountPositions, nil)
+
+	fx := newFXConverter(s.fxRateRepo, &asOf)
+	portfolio, err := s.valuePortfolio(cash, positions, func(instrumentIDs []uint, since time.Time) ([]models.Order, error) {
+		return filterOrders(orders, instrumentIDs, since), nil
+	}, func(instrumentIDs []uint) (map[uint]*models.MarketData, error) {
+		marketData := make(map[uint]*models.MarketData, len(instrumentIDs))
+		for _, instrumentID := range instrumentIDs {
+			data, err := s.marketDataRepo.GetLatestMarketDataAsOf(instrumentID, asOf)
 			if err != nil {
 				return nil, err
 			}
+			marketData[instrumentID] = data
+		}
+		return marketData, nil
+	}, fx, models.NormalizeCurrency(baseCurrency))
+	if err != nil {
+		return nil, err
+	}
+	portfolio.AccountID = accountID
+	portfolio.AsOf = &asOf
+	splitSettledCash(portfolio, unsettledProceeds(orders, asOf), nil)
 
-			// Calculate average purchase price
-			totalCost := 0.0
-			totalQuantity := 0.0
-			for _, order := range orders {
-				if order.InstrumentID == instrumentID && order.Side == "BUY" {
-					totalCost += order.Price * order.Size
-					totalQuantity += order.Size
-				}
-			}
-			avgPrice := totalCost / totalQuantity
+	return portfolio, nil
+}
+
+// consolidateBalances adds up the cash by currency and the positions by
+// instrument across the user's accounts, or keeps only those of accountID
+// when it is set
+func consolidateBalances(balances []models.CashBalance, accountPositions []models.Position, accountID *uint) (map[string]float64, []models.Position) {
+	cash := make(map[string]float64)
+	for _, balance := range balances {
+		if accountID == nil || balance.AccountID == *accountID {
+			cash[balance.Currency] += balance.Balance
+		}
+	}
+
+	byInstrument := make(map[uint]*models.Position)
+	positions := make([]models.Position, 0, len(accountPositions))
+	for _, position := range accountPositions {
+		if accountID != nil && position.AccountID != *accountID {
+			continue
+		}
+		consolidated, ok := byInstrument[position.InstrumentID]
+		if !ok {
+			positions = append(positions, models.Position{UserID: position.UserID, InstrumentID: position.InstrumentID})
+			consolidated = &positions[len(positions)-1]
+			byInstrument[position.InstrumentID] = consolidated
+		}
+		consolidated.Quantity += position.Quantity
+		consolidated.BoughtQuantity += position.BoughtQuantity
+		consolidated.BoughtCost += position.BoughtCost
+	}
+	sort.Slice(positions, func(i, j int) bool {
+		return positions[i].InstrumentID < positions[j].InstrumentID
+	})
+	return cash, positions
+}
+
+// splitSettledCash splits the portfolio's cash balances into the unsettled
+// sale proceeds of the user's accounts, or only those of accountID when it
+// is set, and the settled rest
+func splitSettledCash(portfolio *models.Portfolio, unsettled []models.CashBalance, accountID *uint) {
+	portfolio.UnsettledCash, _ = consolidateBalances(unsettled, nil, accountID)
+	portfolio.SettledCash = make(map[string]float64, len(portfolio.CashBalances))
+	for currency, balance := range portfolio.CashBalances {
+		portfolio.SettledCash[currency] = balance - portfolio.UnsettledCash[currency]
+	}
+}
+
+// unsettledProceeds mirrors OrderRepository.GetUnsettledCash over filled
+// orders: the proceeds of the sales that settle after the given time
+func unsettledProceeds(orders []models.Order, at time.Time) []models.CashBalance {
+	unsettled := make([]models.CashBalance, 0)
+	for _, order := range orders {
+		if order.Side == "SELL" && !order.DateTime.After(at) && order.SettlementDate.After(at) {
+			unsettled = append(unsettled, models.CashBalance{
+				UserID:    order.UserID,
+				AccountID: order.AccountID,
+				Currency:  models.NormalizeCurrency(order.Currency),
+				Balance:   order.Size * order.Price,
+			})
+		}
+	}
+	return unsettled
+}
+
+// accountOrders keeps the orders of accountID, or all of them when it is not
+// set
+func accountOrders(orders []models.Order, accountID *uint) []models.Order {
+	if accountID == nil {
+		return orders
+	}
+	filtered := make([]models.Order, 0, len(orders))
+	for _, order := range orders {
+		if order.AccountID == *accountID {
+			filtered = append(filtered, order)
+		}
+	}
+	return filtered
+}
+
+// filterOrders keeps the orders of the given instruments placed from the
+// given time on
+func filterOrders(orders []models.Order, instrumentIDs []uint, since time.Time) []models.Order {
+	wanted := make(map[uint]bool, len(instrumentIDs))
+	for _, instrumentID := range instrumentIDs {
+		wanted[instrumentID] = true
+	}
+	filtered := make([]models.Order, 0)
+	for _, order := range orders {
+		if wanted[order.InstrumentID] && !order.DateTime.Before(since) {
+			filtered = append(filtered, order)
+		}
+	}
+	return filtered
+}
+
+func (s *PortfolioService) checkUser(userID uint) error {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return fmt.Errorf("user with ID %d not found", userID)
+	}
+	return nil
+}
 
-			totalValue := netQuantity * marketData.Close
-			returnPercentage := (marketData.Close - avgPrice) / avgPrice * 100
+// valuePortfolio builds the portfolio from the cash balances and positions,
+// pricing the holdings with latestMarketData and converting every amount to
+// baseCurrency. instrumentOrders returns the filled orders of the given
+// holdings from a given time on, restated for splits and mergers; they give
+// today's trades for the daily change and, for holdings in another
+// currency, the purchases whose cost basis is converted at the rate of each
+// purchase, so returns include the FX move. Instruments, prices and orders are each fetched for all holdings
+// at once, so the number of queries does not grow with the holdings.
+func (s *PortfolioService) valuePortfolio(
+	cash map[string]float64,
+	positions []models.Position,
+	instrumentOrders func(instrumentIDs []uint, since time.Time) ([]models.Order, error),
+	latestMarketData func(instrumentIDs []uint) (map[uint]*models.MarketData, error),
+	fx *fxConverter,
+	baseCurrency string,
+) (*models.Portfolio, error) {
+	portfolio := &models.Portfolio{
+		BaseCurrency: baseCurrency,
+		CashBalances: cash,
+		Assets:       make([]models.PortfolioAsset, 0),
+	}
+
+	for currency, balance := range cash {
+		rate, err := fx.rate(currency, baseCurrency)
+		if err != nil {
+			return nil, err
+		}
+		portfolio.AvailableCash += balance * rate
+	}
 
-			asset := models.PortfolioAsset{
-				Ticker:     instrument.Ticker,
-				Name:       instrument.Name,
-				Quantity:   netQuantity,
-				TotalValue: totalValue,
-				Return:     returnPercentage,
+	held := make([]models.Position, 0, len(positions))
+	instrumentIDs := make([]uint, 0, len(positions))
+	for _, position := range positions {
+		if position.Quantity > 0 {
+			held = append(held, position)
+			instrumentIDs = append(instrumentIDs, position.InstrumentID)
+		}
+	}
+	if len(held) == 0 {
+		portfolio.TotalValue = portfolio.AvailableCash
+		return portfolio, nil
+	}
+
+	instruments, err := s.instrumentRepo.GetByIDs(instrumentIDs)
+	if err != nil {
+		return nil, err
+	}
+	marketData, err := latestMarketData(instrumentIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	// Orders traded since the start of the earliest price's trading day,
+	// and every purchase of the holdings in another currency
+	var since time.Time
+	foreign := make([]uint, 0)
+	for _, instrumentID := range instrumentIDs {
+		instrument, ok := instruments[instrumentID]
+		if !ok {
+			return nil, fmt.Errorf("instrument with ID %d not found", instrumentID)
+		}
+		data, ok := marketData[instrumentID]
+		if !ok {
+			return nil, fmt.Errorf("no market data for instrument %s", instrument.Ticker)
+		}
+		if dayStart := startOfDay(data.DateTime); since.IsZero() || dayStart.Before(since) {
+			since = dayStart
+		}
+		if models.NormalizeCurrency(instrument.Currency) != baseCurrency {
+			foreign = append(foreign, instrumentID)
+		}
+	}
+	recentOrders, err := instrumentOrders(instrumentIDs, since)
+	if err != nil {
+		return nil, err
+	}
+	purchases := make([]models.Order, 0)
+	if len(foreign) > 0 {
+		if purchases, err = instrumentOrders(foreign, time.Time{}); err != nil {
+			return nil, err
+		}
+	}
+
+	totalDailyBase := 0.0
+	for _, position := range held {
+		instrumentID := position.InstrumentID
+		netQuantity := position.Quantity
+		instrument := instruments[instrumentID]
+		currency := models.NormalizeCurrency(instrument.Currency)
+
+		rate, err := fx.rate(currency, baseCurrency)
+		if err != nil {
+			return nil, err
+		}
+
+		// Calculate average purchase price in the base currency
+		totalCost := position.BoughtCost
+		if currency != baseCurrency {
+			totalCost = 0
+			for _, order := range purchases {
+				if order.InstrumentID != instrumentID || (order.Side != "BUY" && order.Side != "SECURITIES_IN") {
+					continue
+				}
+				purchaseRate, err := fx.rateAt(models.NormalizeCurrency(order.Currency), baseCurrency, order.DateTime)
+				if err != nil {
+					return nil, err
+				}
+				totalCost += order.Price * order.Size * purchaseRate
 			}
+		}
+		avgPrice := totalCost / position.BoughtQuantity
+
+		lastPrice := marketData[instrumentID].Close * rate
+		totalValue := netQuantity * lastPrice
+		returnPercentage := (lastPrice - avgPrice) / avgPrice * 100
+		// The daily change is measured in the instrument's currency and
+		// converted at the current rate
+		dailyChange, dailyBase := calculateDailyChange(recentOrders, instrumentID, netQuantity, marketData[instrumentID])
+		dailyChange *= rate
+		dailyBase *= rate
 
-			portfolio.Assets = append(portfolio.Assets, asset)
-			portfolio.TotalValue += totalValue
+		asset := models.PortfolioAsset{
+			Ticker:      instrument.Ticker,
+			Name:        instrument.Name,
+			Type:        instrument.Type,
+			Currency:    currency,
+			Quantity:    netQuantity,
+			TotalValue:  totalValue,
+			Return:      returnPercentage,
+			LastPrice:   marketData[instrumentID].Close,
+			PriceTime:   marketData[instrumentID].DateTime,
+			DailyChange: dailyChange,
 		}
+		if dailyBase > 0 {
+			asset.DailyChangePercent = dailyChange / dailyBase * 100
+		}
+
+		portfolio.Assets = append(portfolio.Assets, asset)
+		portfolio.TotalValue += totalValue
+		portfolio.DailyChange += dailyChange
+		totalDailyBase += dailyBase
 	}
 
-	portfolio.TotalValue += cash
+	if totalDailyBase > 0 {
+		portfolio.DailyChangePercent = portfolio.DailyChange / totalDailyBase * 100
+	}
+	portfolio.TotalValue += portfolio.AvailableCash
 
 	return portfolio, nil
 }
+
+// GetAllocation groups the user's holdings by the given attribute and
+// returns the value and weight of each group, with cash as its own bucket.
+func (s *PortfolioService) GetAllocation(userID uint, groupBy string, baseCurrency string) (*models.PortfolioAllocation, error) {
+	keyOf, ok := allocationGroups[groupBy]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidAllocationGroup, groupBy)
+	}
+
+	portfolio, err := s.GetPortfolio(userID, baseCurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	buckets := make(map[string]*models.AllocationBucket)
+	addToBucket := func(key string, value float64, ticker string) {
+		bucket, ok := buckets[key]
+		if !ok {
+			bucket = &models.AllocationBucket{Key: key, Assets: make([]string, 0)}
+			buckets[key] = bucket
+		}
+		bucket.Value += value
+		if ticker != "" {
+			bucket.Assets = append(bucket.Assets, ticker)
+		}
+	}
+
+	for _, asset := range portfolio.Assets {
+		addToBucket(keyOf(asset), asset.TotalValue, asset.Ticker)
+	}
+	if portfolio.AvailableCash != 0 {
+		addToBucket(CashAllocationKey, portfolio.AvailableCash, "")
+	}
+
+	allocation := &models.PortfolioAllocation{
+		GroupBy:    groupBy,
+		TotalValue: portfolio.TotalValue,
+		Buckets:    make([]models.AllocationBucket, 0, len(buckets)),
+	}
+	for _, bucket := range buckets {
+		if portfolio.TotalValue != 0 {
+			bucket.Weight = bucket.Value / portfolio.TotalValue * 100
+		}
+		sort.Strings(bucket.Assets)
+		allocation.Buckets = append(allocation.Buckets, *bucket)
+	}
+	sort.Slice(allocation.Buckets, func(i, j int) bool {
+		if allocation.Buckets[i].Value != allocation.Buckets[j].Value {
+			return allocation.Buckets[i].Value > allocation.Buckets[j].Value
+		}
+		return allocation.Buckets[i].Key < allocation.Buckets[j].Key
+	})
+
+	return allocation, nil
+}
+
+// calculateDailyChange returns the change in value of a holding since the
+// previous close, together with the capital it is measured against.
+// Quantity traded during the price's trading day is valued from its fill
+// price instead of PreviousClose, so positions opened today only reflect
+// the move since they were bought. Quantity moved in kind during the day is
+// valued at the current price, so the transfer itself is neither a gain nor
+// a loss.
+func calculateDailyChange(orders []models.Order, instrumentID uint, netQuantity float64, marketData *models.MarketData) (float64, float64) {
+	dayStart := startOfDay(marketData.DateTime)
+
+	openingQuantity := netQuantity
+	boughtToday := 0.0
+	soldToday := 0.0
+	for _, order := range orders {
+		if order.InstrumentID != instrumentID || order.DateTime.Before(dayStart) {
+			continue
+		}
+		switch order.Side {
+		case "BUY":
+			openingQuantity -= order.Size
+			boughtToday += order.Size * order.Price
+		case "SELL":
+			openingQuantity += order.Size
+			soldToday += order.Size * order.Price
+		case "SECURITIES_IN":
+			openingQuantity -= order.Size
+			boughtToday += order.Size * marketData.Close
+		case "SECURITIES_OUT":
+			openingQuantity += order.Size
+			soldToday += order.Size * marketData.Close
+		}
+	}
+
+	openingValue := openingQuantity * marketData.PreviousClose
+	change := netQuantity*marketData.Close - openingValue - boughtToday + soldToday
+	return change, openingValue + boughtToday
+}
+
+// startOfDay truncates a timestamp to midnight in its own location
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
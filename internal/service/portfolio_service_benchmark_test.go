@@ -0,0 +1,202 @@
+package service
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/NahuelDT/portfolio-api/internal/models"
+	"github.com/NahuelDT/portfolio-api/internal/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	benchmarkHoldings = 500
+	// benchmarkLatency is the simulated round trip of every query
+	benchmarkLatency = 100 * time.Microsecond
+)
+
+// benchmarkStore is an in-memory stand-in for the database that counts the
+// queries made against it and waits latency on each of them, so a benchmark
+// shows what every extra round trip costs.
+type benchmarkStore struct {
+	queries     int
+	latency     time.Duration
+	perID       bool
+	instruments map[uint]*models.Instrument
+	marketData  map[uint]*models.MarketData
+	positions   []models.Position
+}
+
+func newBenchmarkStore(holdings int) *benchmarkStore {
+	store := &benchmarkStore{
+		instruments: make(map[uint]*models.Instrument, holdings),
+		marketData:  make(map[uint]*models.MarketData, holdings),
+		positions:   make([]models.Position, 0, holdings),
+	}
+	now := time.Now()
+	for i := 1; i <= holdings; i++ {
+		id := uint(i)
+		store.instruments[id] = &models.Instrument{ID: id, Ticker: fmt.Sprintf("T%d", i), Type: "ACCIONES", Currency: "ARS"}
+		store.marketData[id] = &models.MarketData{InstrumentID: id, Close: 110, PreviousClose: 105, DateTime: now}
+		store.positions = append(store.positions, models.Position{UserID: 1, InstrumentID: id, Quantity: 10, BoughtQuantity: 10, BoughtCost: 1000})
+	}
+	return store
+}
+
+func (s *benchmarkStore) query() {
+	s.queries++
+	if s.latency > 0 {
+		time.Sleep(s.latency)
+	}
+}
+
+type benchmarkUserRepo struct {
+	repository.UserRepositorer
+	store *benchmarkStore
+}
+
+func (r benchmarkUserRepo) GetByID(id uint) (*models.User, error) {
+	r.store.query()
+	return &models.User{ID: id}, nil
+}
+
+type benchmarkOrderRepo struct {
+	repository.OrderRepositorer
+	store *benchmarkStore
+}
+
+func (r benchmarkOrderRepo) GetUserInstrumentOrders(userID uint, instrumentIDs []uint, since time.Time) ([]models.Order, error) {
+	r.store.query()
+	return []models.Order{}, nil
+}
+
+func (r benchmarkOrderRepo) GetUnsettledCash(userID uint, asOf time.Time) ([]models.CashBalance, error) {
+	r.store.query()
+	return []models.CashBalance{}, nil
+}
+
+type benchmarkInstrumentRepo struct {
+	repository.InstrumentRepositorer
+	store *benchmarkStore
+}
+
+// GetByIDs takes a single query, or one query per instrument when the store
+// runs the per-ID baseline
+func (r benchmarkInstrumentRepo) GetByIDs(ids []uint) (map[uint]*models.Instrument, error) {
+	if !r.store.perID {
+		r.store.query()
+	}
+	instruments := make(map[uint]*models.Instrument, len(ids))
+	for _, id := range ids {
+		if r.store.perID {
+			r.store.query()
+		}
+		instruments[id] = r.store.instruments[id]
+	}
+	return instruments, nil
+}
+
+type benchmarkMarketDataRepo struct {
+	repository.MarketDataRepositorer
+	store *benchmarkStore
+}
+
+// GetLatestMarketDataForInstruments takes a single query, or one query per
+// instrument when the store runs the per-ID baseline
+func (r benchmarkMarketDataRepo) GetLatestMarketDataForInstruments(instrumentIDs []uint) (map[uint]*models.MarketData, error) {
+	if !r.store.perID {
+		r.store.query()
+	}
+	marketData := make(map[uint]*models.MarketData, len(instrumentIDs))
+	for _, id := range instrumentIDs {
+		if r.store.perID {
+			r.store.query()
+		}
+		marketData[id] = r.store.marketData[id]
+	}
+	return marketData, nil
+}
+
+type benchmarkPositionRepo struct {
+	repository.PositionRepositorer
+	store *benchmarkStore
+}
+
+func (r benchmarkPositionRepo) GetUserPositions(userID uint) ([]models.Position, error) {
+	r.store.query()
+	return r.store.positions, nil
+}
+
+func (r benchmarkPositionRepo) GetUserCashBalances(userID uint) ([]models.CashBalance, error) {
+	r.store.query()
+	return []models.CashBalance{{UserID: userID, Currency: "ARS", Balance: 1000}}, nil
+}
+
+type benchmarkCorporateActionRepo struct {
+	repository.CorporateActionRepositorer
+	store *benchmarkStore
+}
+
+func (r benchmarkCorporateActionRepo) GetEffectiveAsOf(asOf time.Time) ([]models.CorporateAction, error) {
+	r.store.query()
+	return []models.CorporateAction{}, nil
+}
+
+func newBenchmarkPortfolioService(store *benchmarkStore) *PortfolioService {
+	return NewPortfolioService(
+		benchmarkUserRepo{store: store},
+		benchmarkOrderRepo{store: store},
+		benchmarkInstrumentRepo{store: store},
+		benchmarkMarketDataRepo{store: store},
+		nil,
+		benchmarkCorporateActionRepo{store: store},
+		benchmarkPositionRepo{store: store},
+		nil,
+	)
+}
+
+// TestGetPortfolioQueries checks that valuing a portfolio takes the same
+// number of queries whatever the number of holdings
+func TestGetPortfolioQueries(t *testing.T) {
+	queries := func(holdings int) int {
+		store := newBenchmarkStore(holdings)
+		portfolio, err := newBenchmarkPortfolioService(store).GetPortfolio(1, "ARS")
+		assert.NoError(t, err)
+		assert.Len(t, portfolio.Assets, holdings)
+		return store.queries
+	}
+
+	assert.Equal(t, queries(1), queries(benchmarkHoldings))
+}
+
+// BenchmarkGetPortfolio values a portfolio of 500 holdings with the
+// instruments and prices loaded in batches, as the service does
+func BenchmarkGetPortfolio(b *testing.B) {
+	benchmarkGetPortfolio(b, false)
+}
+
+// BenchmarkGetPortfolioPerID is the baseline: the same valuation with the
+// instruments and prices looked up one by one
+func BenchmarkGetPortfolioPerID(b *testing.B) {
+	benchmarkGetPortfolio(b, true)
+}
+
+func benchmarkGetPortfolio(b *testing.B, perID bool) {
+	store := newBenchmarkStore(benchmarkHoldings)
+	store.latency = benchmarkLatency
+	store.perID = perID
+	portfolioService := newBenchmarkPortfolioService(store)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		portfolio, err := portfolioService.GetPortfolio(1, "ARS")
+		if err != nil {
+			b.Fatal(err)
+		}
+		if len(portfolio.Assets) != benchmarkHoldings {
+			b.Fatalf("expected %d assets, got %d", benchmarkHoldings, len(portfolio.Assets))
+		}
+	}
+	b.ReportMetric(float64(store.queries)/float64(b.N), "queries/op")
+}
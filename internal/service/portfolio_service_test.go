@@ -8,6 +8,7 @@ import (
 	mocks "github.com/NahuelDT/portfolio-api/internal/mocks/repository"
 	"github.com/NahuelDT/portfolio-api/internal/models"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 func TestGetPortfolio(t *testing.T) {
@@ -15,8 +16,13 @@ func TestGetPortfolio(t *testing.T) {
 	mockOrderRepo := new(mocks.OrderRepositorer)
 	mockInstrumentRepo := new(mocks.InstrumentRepositorer)
 	mockMarketDataRepo := new(mocks.MarketDataRepositorer)
+	mockFXRateRepo := new(mocks.FXRateRepositorer)
+	mockCorporateActionRepo := new(mocks.CorporateActionRepositorer)
+	mockCorporateActionRepo.On("GetEffectiveAsOf", mock.Anything).Return([]models.CorporateAction{}, nil)
+	mockPositionRepo := new(mocks.PositionRepositorer)
+	mockAccountRepo := new(mocks.AccountRepositorer)
 
-	portfolioService := NewPortfolioService(mockUserRepo, mockOrderRepo, mockInstrumentRepo, mockMarketDataRepo)
+	portfolioService := NewPortfolioService(mockUserRepo, mockOrderRepo, mockInstrumentRepo, mockMarketDataRepo, mockFXRateRepo, mockCorporateActionRepo, mockPositionRepo, mockAccountRepo)
 
 	t.Run("Successful portfolio retrieval", func(t *testing.T) {
 		userID := uint(1)
@@ -35,15 +41,18 @@ func TestGetPortfolio(t *testing.T) {
 		}
 
 		mockUserRepo.On("GetByID", userID).Return(mockUser, nil)
-		mockOrderRepo.On("GetUserCashBalance", userID).Return(float64(1000), nil)
-		mockOrderRepo.On("GetUserFilledOrders", userID).Return(mockOrders, nil)
+		mockPositionRepo.On("GetUserCashBalances", userID).Return([]models.CashBalance{{UserID: userID, Currency: "ARS", Balance: 1000}}, nil)
+		mockOrderRepo.On("GetUnsettledCash", userID, mock.AnythingOfType("time.Time")).Return([]models.CashBalance{}, nil)
+		mockPositionRepo.On("GetUserPositions", userID).Return([]models.Position{
+			{UserID: userID, InstrumentID: 1, Quantity: 10, BoughtQuantity: 10, BoughtCost: 1000},
+			{UserID: userID, InstrumentID: 2, Quantity: 5, BoughtQuantity: 5, BoughtCost: 1000},
+		}, nil)
 
-		for _, order := range mockOrders {
-			mockInstrumentRepo.On("GetByID", order.InstrumentID).Return(mockInstruments[order.InstrumentID], nil)
-			mockMarketDataRepo.On("GetLatestMarketData", order.InstrumentID).Return(mockMarketData[order.InstrumentID], nil)
-		}
+		mockInstrumentRepo.On("GetByIDs", []uint{1, 2}).Return(mockInstruments, nil).Once()
+		mockMarketDataRepo.On("GetLatestMarketDataForInstruments", []uint{1, 2}).Return(mockMarketData, nil).Once()
+		mockOrderRepo.On("GetUserInstrumentOrders", userID, []uint{1, 2}, mock.AnythingOfType("time.Time")).Return(mockOrders, nil).Once()
 
-		portfolio, err := portfolioService.GetPortfolio(userID)
+		portfolio, err := portfolioService.GetPortfolio(userID, "ARS")
 
 		assert.NoError(t, err)
 		assert.NotNil(t, portfolio)
@@ -66,6 +75,7 @@ func TestGetPortfolio(t *testing.T) {
 
 		mockUserRepo.AssertExpectations(t)
 		mockOrderRepo.AssertExpectations(t)
+		mockPositionRepo.AssertExpectations(t)
 		mockInstrumentRepo.AssertExpectations(t)
 		mockMarketDataRepo.AssertExpectations(t)
 	})
@@ -74,7 +84,7 @@ func TestGetPortfolio(t *testing.T) {
 		userID := uint(999)
 		mockUserRepo.On("GetByID", userID).Return(nil, errors.New("user not found"))
 
-		portfolio, err := portfolioService.GetPortfolio(userID)
+		portfolio, err := portfolioService.GetPortfolio(userID, "ARS")
 
 		assert.Error(t, err)
 		assert.Nil(t, portfolio)
@@ -88,10 +98,13 @@ func TestGetPortfolio(t *testing.T) {
 		mockUser := &models.User{ID: userID, Email: "test2@example.com"}
 
 		mockUserRepo.On("GetByID", userID).Return(mockUser, nil)
-		mockOrderRepo.On("GetUserCashBalance", userID).Return(float64(500), nil)
-		mockOrderRepo.On("GetUserFilledOrders", userID).Return([]models.Order{}, nil)
+		mockPositionRepo.On("GetUserCashBalances", userID).Return([]models.CashBalance{{UserID: userID, Currency: "ARS", Balance: 500}}, nil)
+		mockOrderRepo.On("GetUnsettledCash", userID, mock.AnythingOfType("time.Time")).Return([]models.CashBalance{}, nil)
+		mockPositionRepo.On("GetUserPositions", userID).Return([]models.Position{
+			{UserID: userID, InstrumentID: 1, Quantity: 0, BoughtQuantity: 10, BoughtCost: 1000},
+		}, nil)
 
-		portfolio, err := portfolioService.GetPortfolio(userID)
+		portfolio, err := portfolioService.GetPortfolio(userID, "ARS")
 
 		assert.NoError(t, err)
 		assert.NotNil(t, portfolio)
@@ -100,7 +113,313 @@ func TestGetPortfolio(t *testing.T) {
 		assert.Len(t, portfolio.Assets, 0)
 
 		mockUserRepo.AssertExpectations(t)
+		mockPositionRepo.AssertExpectations(t)
+	})
+
+	t.Run("Daily change with position opened today", func(t *testing.T) {
+		userID := uint(3)
+		now := time.Now()
+		yesterday := now.AddDate(0, 0, -1)
+		mockUser := &models.User{ID: userID, Email: "test3@example.com"}
+		mockOrders := []models.Order{
+			{ID: 3, InstrumentID: 3, UserID: userID, Side: "BUY", Size: 10, Price: 50, Type: "MARKET", Status: "FILLED", DateTime: yesterday},
+			{ID: 4, InstrumentID: 3, UserID: userID, Side: "BUY", Size: 10, Price: 54, Type: "MARKET", Status: "FILLED", DateTime: now},
+			{ID: 5, InstrumentID: 4, UserID: userID, Side: "BUY", Size: 4, Price: 20, Type: "MARKET", Status: "FILLED", DateTime: now},
+		}
+
+		mockUserRepo.On("GetByID", userID).Return(mockUser, nil)
+		mockPositionRepo.On("GetUserCashBalances", userID).Return([]models.CashBalance{}, nil)
+		mockOrderRepo.On("GetUnsettledCash", userID, mock.AnythingOfType("time.Time")).Return([]models.CashBalance{}, nil)
+		mockPositionRepo.On("GetUserPositions", userID).Return([]models.Position{
+			{UserID: userID, InstrumentID: 3, Quantity: 20, BoughtQuantity: 20, BoughtCost: 1040},
+			{UserID: userID, InstrumentID: 4, Quantity: 4, BoughtQuantity: 4, BoughtCost: 80},
+		}, nil)
+		mockOrderRepo.On("GetUserInstrumentOrders", userID, []uint{3, 4}, mock.AnythingOfType("time.Time")).Return(mockOrders, nil)
+		mockInstrumentRepo.On("GetByIDs", []uint{3, 4}).Return(map[uint]*models.Instrument{
+			3: {ID: 3, Ticker: "MSFT", Name: "Microsoft Corp."},
+			4: {ID: 4, Ticker: "KO", Name: "Coca-Cola Co."},
+		}, nil)
+		mockMarketDataRepo.On("GetLatestMarketDataForInstruments", []uint{3, 4}).Return(map[uint]*models.MarketData{
+			3: {InstrumentID: 3, Close: 55, PreviousClose: 52, DateTime: now},
+			4: {InstrumentID: 4, Close: 21, PreviousClose: 25, DateTime: now},
+		}, nil)
+
+		portfolio, err := portfolioService.GetPortfolio(userID, "ARS")
+
+		assert.NoError(t, err)
+		assert.Len(t, portfolio.Assets, 2)
+
+		for _, asset := range portfolio.Assets {
+			if asset.Ticker == "MSFT" {
+				assert.Equal(t, float64(55), asset.LastPrice)
+				assert.Equal(t, now, asset.PriceTime)
+				assert.InDelta(t, 40, asset.DailyChange, 1e-9)                   // 10 * (55 - 52) + 10 * (55 - 54)
+				assert.InDelta(t, 40.0/1060*100, asset.DailyChangePercent, 1e-9) // base: 10 * 52 + 10 * 54
+			} else if asset.Ticker == "KO" {
+				assert.InDelta(t, 4, asset.DailyChange, 1e-9) // 4 * (21 - 20), PreviousClose ignored
+				assert.InDelta(t, 5, asset.DailyChangePercent, 1e-9)
+			}
+		}
+		assert.InDelta(t, 44, portfolio.DailyChange, 1e-9)
+		assert.InDelta(t, 44.0/1140*100, portfolio.DailyChangePercent, 1e-9)
+	})
+
+	t.Run("Daily change after in-kind transfers", func(t *testing.T) {
+		userID := uint(10)
+		now := time.Now()
+		mockOrders := []models.Order{
+			// Bought today and moved to another user the same day
+			{ID: 30, InstrumentID: 12, UserID: userID, Side: "BUY", Size: 10, Price: 100, Status: "FILLED", DateTime: now},
+			{ID: 31, InstrumentID: 12, UserID: userID, Side: "SECURITIES_OUT", Size: 10, Price: 95, Status: "FILLED", DateTime: now},
+			// Received today from another user at their cost
+			{ID: 32, InstrumentID: 13, UserID: userID, Side: "SECURITIES_IN", Size: 10, Price: 20, Status: "FILLED", DateTime: now},
+		}
+
+		mockUserRepo.On("GetByID", userID).Return(&models.User{ID: userID}, nil)
+		mockPositionRepo.On("GetUserCashBalances", userID).Return([]models.CashBalance{}, nil)
+		mockOrderRepo.On("GetUnsettledCash", userID, mock.AnythingOfType("time.Time")).Return([]models.CashBalance{}, nil)
+		mockPositionRepo.On("GetUserPositions", userID).Return([]models.Position{
+			{UserID: userID, InstrumentID: 12, Quantity: 5, BoughtQuantity: 15, BoughtCost: 1400},
+			{UserID: userID, InstrumentID: 13, Quantity: 15, BoughtQuantity: 15, BoughtCost: 350},
+		}, nil)
+		mockOrderRepo.On("GetUserInstrumentOrders", userID, []uint{12, 13}, mock.AnythingOfType("time.Time")).Return(mockOrders, nil)
+		mockInstrumentRepo.On("GetByIDs", []uint{12, 13}).Return(map[uint]*models.Instrument{
+			12: {ID: 12, Ticker: "GGAL"},
+			13: {ID: 13, Ticker: "PAMP"},
+		}, nil)
+		mockMarketDataRepo.On("GetLatestMarketDataForInstruments", []uint{12, 13}).Return(map[uint]*models.MarketData{
+			12: {InstrumentID: 12, Close: 105, PreviousClose: 90, DateTime: now},
+			13: {InstrumentID: 13, Close: 33, PreviousClose: 30, DateTime: now},
+		}, nil)
+
+		portfolio, err := portfolioService.GetPortfolio(userID, "ARS")
+
+		assert.NoError(t, err)
+		for _, asset := range portfolio.Assets {
+			if asset.Ticker == "GGAL" {
+				assert.InDelta(t, 125, asset.DailyChange, 1e-9) // 5 * (105 - 90) + 10 * (105 - 100)
+				assert.InDelta(t, 125.0/1450*100, asset.DailyChangePercent, 1e-9)
+			} else if asset.Ticker == "PAMP" {
+				assert.InDelta(t, 15, asset.DailyChange, 1e-9) // 5 * (33 - 30), the received shares do not count
+				assert.InDelta(t, 15.0/480*100, asset.DailyChangePercent, 1e-9)
+			}
+		}
+	})
+
+	t.Run("Portfolio as of a past date", func(t *testing.T) {
+		userID := uint(4)
+		asOf := time.Date(2024, time.January, 31, 23, 59, 59, 0, time.UTC)
+		mockOrders := []models.Order{
+			{ID: 5, UserID: userID, Side: "CASH_IN", Size: 1200, Status: "FILLED", Currency: "ARS", DateTime: asOf.AddDate(0, 0, -20)},
+			{ID: 6, InstrumentID: 1, UserID: userID, Side: "BUY", Size: 10, Price: 100, Status: "FILLED", Currency: "ARS", DateTime: asOf.AddDate(0, 0, -10)},
+			{ID: 7, InstrumentID: 1, UserID: userID, Side: "SELL", Size: 2, Price: 100, Status: "FILLED", Currency: "ARS", DateTime: asOf.Add(-time.Hour), SettlementDate: asOf.AddDate(0, 0, 1)},
+		}
+
+		mockUserRepo.On("GetByID", userID).Return(&models.User{ID: userID}, nil)
+		mockOrderRepo.On("GetUserFilledOrdersAsOf", userID, asOf).Return(mockOrders, nil)
+		mockInstrumentRepo.On("GetByIDs", []uint{1}).Return(map[uint]*models.Instrument{1: {ID: 1, Ticker: "AAPL", Name: "Apple Inc."}}, nil)
+		mockMarketDataRepo.On("GetLatestMarketDataAsOf", uint(1), asOf).Return(&models.MarketData{InstrumentID: 1, Close: 95, DateTime: asOf.Add(-time.Hour)}, nil)
+
+		portfolio, err := portfolioService.GetPortfolioAsOf(userID, asOf, "ARS")
+
+		assert.NoError(t, err)
+		assert.Equal(t, &asOf, portfolio.AsOf)
+		assert.Equal(t, float64(400), portfolio.AvailableCash)
+		assert.Equal(t, float64(1160), portfolio.TotalValue) // 400 (cash) + 8 * 95
+		// The sale settles after asOf
+		assert.Equal(t, map[string]float64{"ARS": 200}, portfolio.SettledCash)
+		assert.Equal(t, map[string]float64{"ARS": 200}, portfolio.UnsettledCash)
+		assert.Len(t, portfolio.Assets, 1)
+		assert.Equal(t, float64(-5), portfolio.Assets[0].Return)
+
 		mockOrderRepo.AssertExpectations(t)
+		mockMarketDataRepo.AssertExpectations(t)
 	})
 
+	t.Run("Portfolio in a base currency with FX conversion", func(t *testing.T) {
+		userID := uint(5)
+		boughtAt := time.Now().AddDate(0, -1, 0)
+		mockOrders := []models.Order{
+			{ID: 7, InstrumentID: 5, UserID: userID, Side: "BUY", Size: 10, Price: 100, Status: "FILLED", Currency: "USD", DateTime: boughtAt},
+		}
+
+		mockUserRepo.On("GetByID", userID).Return(&models.User{ID: userID}, nil)
+		mockPositionRepo.On("GetUserCashBalances", userID).Return([]models.CashBalance{
+			{UserID: userID, Currency: "ARS", Balance: 100000},
+			{UserID: userID, Currency: "USD", Balance: 50},
+		}, nil)
+		mockOrderRepo.On("GetUnsettledCash", userID, mock.AnythingOfType("time.Time")).Return([]models.CashBalance{}, nil)
+		mockPositionRepo.On("GetUserPositions", userID).Return([]models.Position{
+			{UserID: userID, InstrumentID: 5, Quantity: 10, BoughtQuantity: 10, BoughtCost: 1000},
+		}, nil)
+		mockOrderRepo.On("GetUserInstrumentOrders", userID, []uint{5}, mock.AnythingOfType("time.Time")).Return(mockOrders, nil)
+		mockInstrumentRepo.On("GetByIDs", []uint{5}).Return(map[uint]*models.Instrument{
+			5: {ID: 5, Ticker: "SPY", Name: "SPDR S&P 500", Currency: "USD"},
+		}, nil)
+		mockMarketDataRepo.On("GetLatestMarketDataForInstruments", []uint{5}).Return(map[uint]*models.MarketData{
+			5: {InstrumentID: 5, Close: 110, DateTime: time.Now()},
+		}, nil)
+		mockFXRateRepo.On("GetLatestRate", "USD", "ARS").Return(&models.FXRate{BaseCurrency: "USD", QuoteCurrency: "ARS", Rate: 1000}, nil)
+		mockFXRateRepo.On("GetLatestRate", "ARS", "USD").Return(nil, errors.New("record not found"))
+		mockFXRateRepo.On("GetRateAsOf", "USD", "ARS", boughtAt).Return(&models.FXRate{BaseCurrency: "USD", QuoteCurrency: "ARS", Rate: 800}, nil)
+
+		portfolio, err := portfolioService.GetPortfolio(userID, "usd")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "USD", portfolio.BaseCurrency)
+		assert.InDelta(t, 150, portfolio.AvailableCash, 1e-9) // 100000 ARS / 1000 + 50 USD
+		assert.InDelta(t, 1250, portfolio.TotalValue, 1e-9)   // 150 (cash) + 10 * 110
+		assert.InDelta(t, 10, portfolio.Assets[0].Return, 1e-9)
+
+		portfolio, err = portfolioService.GetPortfolio(userID, "ARS")
+
+		assert.NoError(t, err)
+		assert.InDelta(t, 150000, portfolio.AvailableCash, 1e-9)
+		assert.InDelta(t, 1100000, portfolio.Assets[0].TotalValue, 1e-9)
+		assert.InDelta(t, 37.5, portfolio.Assets[0].Return, 1e-9) // 110 * 1000 vs 100 * 800
+		assert.Equal(t, "USD", portfolio.Assets[0].Currency)
+		assert.Equal(t, float64(110), portfolio.Assets[0].LastPrice)
+
+		mockFXRateRepo.AssertExpectations(t)
+	})
+
+	t.Run("Portfolio of a single account", func(t *testing.T) {
+		userID := uint(6)
+		accountID := uint(3)
+
+		mockUserRepo.On("GetByID", userID).Return(&models.User{ID: userID}, nil)
+		mockAccountRepo.On("GetByID", accountID).Return(&models.Account{ID: accountID, UserID: userID, Name: "Retiro"}, nil)
+		mockPositionRepo.On("GetUserCashBalances", userID).Return([]models.CashBalance{
+			{UserID: userID, AccountID: models.MainAccountID, Currency: "ARS", Balance: 700},
+			{UserID: userID, AccountID: accountID, Currency: "ARS", Balance: 300},
+		}, nil)
+		mockOrderRepo.On("GetUnsettledCash", userID, mock.AnythingOfType("time.Time")).Return([]models.CashBalance{
+			{UserID: userID, AccountID: models.MainAccountID, Currency: "ARS", Balance: 500},
+			{UserID: userID, AccountID: accountID, Currency: "ARS", Balance: 100},
+		}, nil)
+		mockPositionRepo.On("GetUserPositions", userID).Return([]models.Position{
+			{UserID: userID, AccountID: models.MainAccountID, InstrumentID: 6, Quantity: 4, BoughtQuantity: 4, BoughtCost: 400},
+			{UserID: userID, AccountID: accountID, InstrumentID: 6, Quantity: 6, BoughtQuantity: 6, BoughtCost: 540},
+		}, nil)
+		mockOrderRepo.On("GetUserInstrumentOrders", userID, []uint{6}, mock.AnythingOfType("time.Time")).Return([]models.Order{}, nil)
+		mockInstrumentRepo.On("GetByIDs", []uint{6}).Return(map[uint]*models.Instrument{6: {ID: 6, Ticker: "YPF"}}, nil)
+		mockMarketDataRepo.On("GetLatestMarketDataForInstruments", []uint{6}).Return(map[uint]*models.MarketData{6: {InstrumentID: 6, Close: 100}}, nil)
+
+		portfolio, err := portfolioService.GetAccountPortfolio(userID, accountID, "ARS")
+
+		assert.NoError(t, err)
+		assert.Equal(t, &accountID, portfolio.AccountID)
+		assert.Equal(t, float64(300), portfolio.AvailableCash)
+		assert.Equal(t, float64(900), portfolio.TotalValue) // 300 (cash) + 6 * 100
+		assert.Equal(t, map[string]float64{"ARS": 200}, portfolio.SettledCash)
+		assert.Equal(t, map[string]float64{"ARS": 100}, portfolio.UnsettledCash)
+		assert.Equal(t, float64(6), portfolio.Assets[0].Quantity)
+
+		portfolio, err = portfolioService.GetPortfolio(userID, "ARS")
+
+		assert.NoError(t, err)
+		assert.Nil(t, portfolio.AccountID)
+		assert.Equal(t, float64(1000), portfolio.AvailableCash)
+		assert.Equal(t, float64(10), portfolio.Assets[0].Quantity)
+		assert.Equal(t, float64(2000), portfolio.TotalValue)
+		assert.Equal(t, map[string]float64{"ARS": 400}, portfolio.SettledCash)
+		assert.Equal(t, map[string]float64{"ARS": 600}, portfolio.UnsettledCash)
+
+		mockAccountRepo.On("GetByID", uint(8)).Return(&models.Account{ID: 8, UserID: 99}, nil)
+
+		_, err = portfolioService.GetAccountPortfolio(userID, 8, "ARS")
+
+		assert.ErrorIs(t, err, ErrInvalidAccount)
+	})
+
+	t.Run("Foreign cost basis of a merged holding", func(t *testing.T) {
+		userID := uint(9)
+		targetID := uint(11)
+		boughtAt := time.Now().AddDate(0, -1, 0)
+		mockMergerRepo := new(mocks.CorporateActionRepositorer)
+		mockMergerRepo.On("GetEffectiveAsOf", mock.Anything).Return([]models.CorporateAction{
+			{ID: 1, InstrumentID: 10, Type: models.MergerAction, Ratio: 0.5, TargetInstrumentID: &targetID, EffectiveDate: time.Now().AddDate(0, 0, -7)},
+		}, nil)
+		mergerPortfolioService := NewPortfolioService(mockUserRepo, mockOrderRepo, mockInstrumentRepo, mockMarketDataRepo, mockFXRateRepo, mockMergerRepo, mockPositionRepo, mockAccountRepo)
+
+		mockUserRepo.On("GetByID", userID).Return(&models.User{ID: userID}, nil)
+		mockPositionRepo.On("GetUserCashBalances", userID).Return([]models.CashBalance{}, nil)
+		mockOrderRepo.On("GetUnsettledCash", userID, mock.AnythingOfType("time.Time")).Return([]models.CashBalance{}, nil)
+		mockPositionRepo.On("GetUserPositions", userID).Return([]models.Position{
+			{UserID: userID, InstrumentID: 11, Quantity: 5, BoughtQuantity: 5, BoughtCost: 1000},
+		}, nil)
+		// The purchase was of the instrument merged into the holding
+		mockOrderRepo.On("GetUserInstrumentOrders", userID, []uint{11, 10}, mock.AnythingOfType("time.Time")).Return([]models.Order{
+			{ID: 20, InstrumentID: 10, UserID: userID, Side: "BUY", Size: 10, Price: 100, Status: "FILLED", Currency: "USD", DateTime: boughtAt},
+		}, nil)
+		mockInstrumentRepo.On("GetByIDs", []uint{11}).Return(map[uint]*models.Instrument{
+			11: {ID: 11, Ticker: "NEW", Currency: "USD"},
+		}, nil)
+		mockMarketDataRepo.On("GetLatestMarketDataForInstruments", []uint{11}).Return(map[uint]*models.MarketData{
+			11: {InstrumentID: 11, Close: 220, DateTime: time.Now()},
+		}, nil)
+		mockFXRateRepo.On("GetRateAsOf", "USD", "ARS", boughtAt).Return(&models.FXRate{BaseCurrency: "USD", QuoteCurrency: "ARS", Rate: 800}, nil)
+
+		portfolio, err := mergerPortfolioService.GetPortfolio(userID, "ARS")
+
+		assert.NoError(t, err)
+		assert.InDelta(t, 1100000, portfolio.Assets[0].TotalValue, 1e-9)
+		assert.InDelta(t, 37.5, portfolio.Assets[0].Return, 1e-9) // 220 * 1000 vs 200 * 800
+	})
+}
+
+func TestGetAllocation(t *testing.T) {
+	mockUserRepo := new(mocks.UserRepositorer)
+	mockOrderRepo := new(mocks.OrderRepositorer)
+	mockInstrumentRepo := new(mocks.InstrumentRepositorer)
+	mockMarketDataRepo := new(mocks.MarketDataRepositorer)
+	mockFXRateRepo := new(mocks.FXRateRepositorer)
+	mockCorporateActionRepo := new(mocks.CorporateActionRepositorer)
+	mockCorporateActionRepo.On("GetEffectiveAsOf", mock.Anything).Return([]models.CorporateAction{}, nil)
+	mockPositionRepo := new(mocks.PositionRepositorer)
+	mockAccountRepo := new(mocks.AccountRepositorer)
+
+	portfolioService := NewPortfolioService(mockUserRepo, mockOrderRepo, mockInstrumentRepo, mockMarketDataRepo, mockFXRateRepo, mockCorporateActionRepo, mockPositionRepo, mockAccountRepo)
+
+	t.Run("Group by instrument type", func(t *testing.T) {
+		userID := uint(1)
+
+		mockUserRepo.On("GetByID", userID).Return(&models.User{ID: userID}, nil)
+		mockPositionRepo.On("GetUserCashBalances", userID).Return([]models.CashBalance{{UserID: userID, Currency: "ARS", Balance: 500}}, nil)
+		mockOrderRepo.On("GetUnsettledCash", userID, mock.AnythingOfType("time.Time")).Return([]models.CashBalance{}, nil)
+		mockPositionRepo.On("GetUserPositions", userID).Return([]models.Position{
+			{UserID: userID, InstrumentID: 1, Quantity: 10, BoughtQuantity: 10, BoughtCost: 1000},
+			{UserID: userID, InstrumentID: 2, Quantity: 5, BoughtQuantity: 5, BoughtCost: 500},
+			{UserID: userID, InstrumentID: 3, Quantity: 20, BoughtQuantity: 20, BoughtCost: 1000},
+		}, nil)
+		mockOrderRepo.On("GetUserInstrumentOrders", userID, []uint{1, 2, 3}, mock.AnythingOfType("time.Time")).Return([]models.Order{}, nil)
+		mockInstrumentRepo.On("GetByIDs", []uint{1, 2, 3}).Return(map[uint]*models.Instrument{
+			1: {ID: 1, Ticker: "AAPL", Type: "ACCIONES"},
+			2: {ID: 2, Ticker: "GOOGL", Type: "ACCIONES"},
+			3: {ID: 3, Ticker: "AL30", Type: "BONOS"},
+		}, nil)
+		mockMarketDataRepo.On("GetLatestMarketDataForInstruments", []uint{1, 2, 3}).Return(map[uint]*models.MarketData{
+			1: {Close: 100},
+			2: {Close: 100},
+			3: {Close: 50},
+		}, nil)
+
+		allocation, err := portfolioService.GetAllocation(userID, "type", "ARS")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "type", allocation.GroupBy)
+		assert.Equal(t, float64(3000), allocation.TotalValue)
+		assert.Equal(t, []models.AllocationBucket{
+			{Key: "ACCIONES", Value: 1500, Weight: 50, Assets: []string{"AAPL", "GOOGL"}},
+			{Key: "BONOS", Value: 1000, Weight: float64(1000) / 3000 * 100, Assets: []string{"AL30"}},
+			{Key: CashAllocationKey, Value: 500, Weight: float64(500) / 3000 * 100, Assets: []string{}},
+		}, allocation.Buckets)
+	})
+
+	t.Run("Invalid group", func(t *testing.T) {
+		allocation, err := portfolioService.GetAllocation(1, "sector", "ARS")
+
+		assert.ErrorIs(t, err, ErrInvalidAllocationGroup)
+		assert.Nil(t, allocation)
+	})
 }
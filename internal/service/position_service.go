@@ -0,0 +1,290 @@
+package service
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/NahuelDT/portfolio-api/internal/models"
+	"github.com/NahuelDT/portfolio-api/internal/repository"
+)
+
+// balanceTolerance absorbs floating point drift between incremental updates
+// and a full replay
+const balanceTolerance = 1e-6
+
+type PositionService struct {
+	orderRepo           repository.OrderRepositorer
+	positionRepo        repository.PositionRepositorer
+	corporateActionRepo repository.CorporateActionRepositorer
+}
+
+func NewPositionService(
+	orderRepo repository.OrderRepositorer,
+	positionRepo repository.PositionRepositorer,
+	corporateActionRepo repository.CorporateActionRepositorer,
+) *PositionService {
+	return &PositionService{
+		orderRepo:           orderRepo,
+		positionRepo:        positionRepo,
+		corporateActionRepo: corporateActionRepo,
+	}
+}
+
+// RebuildUser replaces the user's materialized positions and cash balances
+// with the ones replayed from their order history. The history is read and
+// replaced under the user's lock, so fills made meanwhile are not lost.
+func (s *PositionService) RebuildUser(userID uint) error {
+	actions, err := loadCorporateActions(s.corporateActionRepo, time.Now())
+	if err != nil {
+		return err
+	}
+
+	return s.positionRepo.RebuildUserBalances(userID, func(orders []models.Order) ([]models.Position, []models.CashBalance) {
+		positions, cash := replayBalances(userID, actions.adjustOrders(orders))
+		now := time.Now()
+		for i := range positions {
+			positions[i].UpdatedAt = now
+		}
+		for i := range cash {
+			cash[i].UpdatedAt = now
+		}
+		return positions, cash
+	})
+}
+
+// RebuildAll rebuilds every user with orders and returns how many were rebuilt
+func (s *PositionService) RebuildAll() (int, error) {
+	userIDs, err := s.orderRepo.GetUserIDs()
+	if err != nil {
+		return 0, err
+	}
+	for i, userID := range userIDs {
+		if err := s.RebuildUser(userID); err != nil {
+			return i, err
+		}
+	}
+	return len(userIDs), nil
+}
+
+// CheckUser compares the user's materialized positions and ledger cash
+// balances with the ones replayed from their order history
+func (s *PositionService) CheckUser(userID uint) ([]models.BalanceMismatch, error) {
+	expectedPositions, expectedCash, err := s.replayUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	storedPositions, err := s.positionRepo.GetUserPositions(userID)
+	if err != nil {
+		return nil, err
+	}
+	storedCash, err := s.positionRepo.GetUserCashBalances(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	mismatches := make([]models.BalanceMismatch, 0)
+	compare := func(accountID uint, kind, key, field string, stored, expected float64) {
+		if math.Abs(stored-expected) > balanceTolerance*math.Max(1, math.Abs(expected)) {
+			mismatches = append(mismatches, models.BalanceMismatch{
+				UserID:    userID,
+				AccountID: accountID,
+				Kind:      kind,
+				Key:       key,
+				Field:     field,
+				Stored:    stored,
+				Expected:  expected,
+			})
+		}
+	}
+
+	type positionKey struct{ accountID, instrumentID uint }
+	stored := make(map[positionKey]models.Position, len(storedPositions))
+	positionKeys := make([]positionKey, 0, len(storedPositions)+len(expectedPositions))
+	for _, position := range storedPositions {
+		key := positionKey{position.AccountID, position.InstrumentID}
+		stored[key] = position
+		positionKeys = append(positionKeys, key)
+	}
+	expected := make(map[positionKey]models.Position, len(expectedPositions))
+	for _, position := range expectedPositions {
+		key := positionKey{position.AccountID, position.InstrumentID}
+		expected[key] = position
+		if _, ok := stored[key]; !ok {
+			positionKeys = append(positionKeys, key)
+		}
+	}
+	sort.Slice(positionKeys, func(i, j int) bool {
+		if positionKeys[i].accountID != positionKeys[j].accountID {
+			return positionKeys[i].accountID < positionKeys[j].accountID
+		}
+		return positionKeys[i].instrumentID < positionKeys[j].instrumentID
+	})
+	for _, key := range positionKeys {
+		instrument := strconv.FormatUint(uint64(key.instrumentID), 10)
+		compare(key.accountID, models.PositionMismatch, instrument, "quantity", stored[key].Quantity, expected[key].Quantity)
+		compare(key.accountID, models.PositionMismatch, instrument, "boughtQuantity", stored[key].BoughtQuantity, expected[key].BoughtQuantity)
+		compare(key.accountID, models.PositionMismatch, instrument, "boughtCost", stored[key].BoughtCost, expected[key].BoughtCost)
+	}
+
+	type cashKey struct {
+		accountID uint
+		currency  string
+	}
+	storedBalances := make(map[cashKey]float64, len(storedCash))
+	cashKeys := make([]cashKey, 0, len(storedCash)+len(expectedCash))
+	for _, balance := range storedCash {
+		key := cashKey{balance.AccountID, balance.Currency}
+		storedBalances[key] = balance.Balance
+		cashKeys = append(cashKeys, key)
+	}
+	expectedBalances := make(map[cashKey]float64, len(expectedCash))
+	for _, balance := range expectedCash {
+		key := cashKey{balance.AccountID, balance.Currency}
+		expectedBalances[key] = balance.Balance
+		if _, ok := storedBalances[key]; !ok {
+			cashKeys = append(cashKeys, key)
+		}
+	}
+	sort.Slice(cashKeys, func(i, j int) bool {
+		if cashKeys[i].accountID != cashKeys[j].accountID {
+			return cashKeys[i].accountID < cashKeys[j].accountID
+		}
+		return cashKeys[i].currency < cashKeys[j].currency
+	})
+	for _, key := range cashKeys {
+		compare(key.accountID, models.CashMismatch, key.currency, "balance", storedBalances[key], expectedBalances[key])
+	}
+
+	return mismatches, nil
+}
+
+// CheckAll runs CheckUser for every user with orders
+func (s *PositionService) CheckAll() ([]models.BalanceMismatch, error) {
+	userIDs, err := s.orderRepo.GetUserIDs()
+	if err != nil {
+		return nil, err
+	}
+	mismatches := make([]models.BalanceMismatch, 0)
+	for _, userID := range userIDs {
+		userMismatches, err := s.CheckUser(userID)
+		if err != nil {
+			return nil, err
+		}
+		mismatches = append(mismatches, userMismatches...)
+	}
+	return mismatches, nil
+}
+
+// ApplyCorporateActions rebuilds the positions of the holders of every split
+// or merger that has become effective since the last run
+func (s *PositionService) ApplyCorporateActions(now time.Time) error {
+	actions, err := s.corporateActionRepo.GetUnapplied(now)
+	if err != nil {
+		return err
+	}
+
+	for i := range actions {
+		holderIDs, err := s.positionRepo.GetHolderIDs(actions[i].InstrumentID)
+		if err != nil {
+			return err
+		}
+		for _, userID := range holderIDs {
+			if err := s.RebuildUser(userID); err != nil {
+				return err
+			}
+		}
+		if err := s.corporateActionRepo.MarkApplied(&actions[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// replayUser replays the user's filled orders, restated by the effective
+// corporate actions
+func (s *PositionService) replayUser(userID uint) ([]models.Position, []models.CashBalance, error) {
+	orders, err := s.orderRepo.GetUserFilledOrders(userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	actions, err := loadCorporateActions(s.corporateActionRepo, time.Now())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	positions, cash := replayBalances(userID, actions.adjustOrders(orders))
+	return positions, cash, nil
+}
+
+// replayBalances rebuilds positions and cash balances of each account from
+// filled orders the same way fills update the materialized tables, sorted
+// by account and then by instrument or currency
+func replayBalances(userID uint, orders []models.Order) ([]models.Position, []models.CashBalance) {
+	type positionKey struct{ accountID, instrumentID uint }
+	type cashKey struct {
+		accountID uint
+		currency  string
+	}
+	positions := make(map[positionKey]*models.Position)
+	cash := make(map[cashKey]float64)
+	for _, order := range orders {
+		balance := cashKey{order.AccountID, models.NormalizeCurrency(order.Currency)}
+		switch order.Side {
+		case "CASH_IN", "TRANSFER_IN":
+			cash[balance] += order.Size
+		case "CASH_OUT", "TRANSFER_OUT":
+			cash[balance] -= order.Size
+		case "DIVIDEND":
+			cash[balance] += order.Size * order.Price
+		case "BUY", "SELL", "SECURITIES_IN", "SECURITIES_OUT":
+			key := positionKey{order.AccountID, order.InstrumentID}
+			position, ok := positions[key]
+			if !ok {
+				position = &models.Position{UserID: userID, AccountID: order.AccountID, InstrumentID: order.InstrumentID}
+				positions[key] = position
+			}
+			switch order.Side {
+			case "BUY":
+				cash[balance] -= order.Size * order.Price
+				position.Quantity += order.Size
+				position.BoughtQuantity += order.Size
+				position.BoughtCost += order.Size * order.Price
+			case "SELL":
+				cash[balance] += order.Size * order.Price
+				position.Quantity -= order.Size
+			case "SECURITIES_IN":
+				position.Quantity += order.Size
+				position.BoughtQuantity += order.Size
+				position.BoughtCost += order.Size * order.Price
+			case "SECURITIES_OUT":
+				position.Quantity -= order.Size
+			}
+		}
+	}
+
+	positionList := make([]models.Position, 0, len(positions))
+	for _, position := range positions {
+		positionList = append(positionList, *position)
+	}
+	sort.Slice(positionList, func(i, j int) bool {
+		if positionList[i].AccountID != positionList[j].AccountID {
+			return positionList[i].AccountID < positionList[j].AccountID
+		}
+		return positionList[i].InstrumentID < positionList[j].InstrumentID
+	})
+
+	cashList := make([]models.CashBalance, 0, len(cash))
+	for key, balance := range cash {
+		cashList = append(cashList, models.CashBalance{UserID: userID, AccountID: key.accountID, Currency: key.currency, Balance: balance})
+	}
+	sort.Slice(cashList, func(i, j int) bool {
+		if cashList[i].AccountID != cashList[j].AccountID {
+			return cashList[i].AccountID < cashList[j].AccountID
+		}
+		return cashList[i].Currency < cashList[j].Currency
+	})
+
+	return positionList, cashList
+}
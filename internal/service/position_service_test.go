@@ -0,0 +1,163 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	mocks "github.com/NahuelDT/portfolio-api/internal/mocks/repository"
+	"github.com/NahuelDT/portfolio-api/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRebuildUser(t *testing.T) {
+	setUp := func() (*mocks.OrderRepositorer, *mocks.PositionRepositorer, *mocks.CorporateActionRepositorer, *PositionService) {
+		mockOrderRepo := new(mocks.OrderRepositorer)
+		mockPositionRepo := new(mocks.PositionRepositorer)
+		mockCorporateActionRepo := new(mocks.CorporateActionRepositorer)
+		positionService := NewPositionService(mockOrderRepo, mockPositionRepo, mockCorporateActionRepo)
+		return mockOrderRepo, mockPositionRepo, mockCorporateActionRepo, positionService
+	}
+	date := func(month time.Month, day int) time.Time {
+		return time.Date(2024, month, day, 15, 0, 0, 0, time.UTC)
+	}
+
+	t.Run("Replay orders after splits and a merger", func(t *testing.T) {
+		_, mockPositionRepo, mockCorporateActionRepo, positionService := setUp()
+
+		userID := uint(1)
+		orders := []models.Order{
+			{InstrumentID: 0, Side: "CASH_IN", Size: 5000, Status: "FILLED", DateTime: date(time.January, 2)},
+			{InstrumentID: 0, Side: "CASH_IN", Size: 100, Status: "FILLED", Currency: "usd", DateTime: date(time.January, 2)},
+			{InstrumentID: 1, Side: "BUY", Size: 10, Price: 100, Status: "FILLED", DateTime: date(time.January, 10)},
+			{InstrumentID: 1, Side: "SELL", Size: 4, Price: 60, Status: "FILLED", DateTime: date(time.March, 10)},
+			{InstrumentID: 2, Side: "BUY", Size: 30, Price: 5, Status: "FILLED", DateTime: date(time.January, 10)},
+			{InstrumentID: 1, Side: "DIVIDEND", Size: 16, Price: 0.5, Status: "FILLED", Currency: "USD", DateTime: date(time.March, 20)},
+		}
+		target := uint(3)
+		mockCorporateActionRepo.On("GetEffectiveAsOf", mock.AnythingOfType("time.Time")).Return([]models.CorporateAction{
+			{InstrumentID: 1, Type: models.SplitAction, Ratio: 2, EffectiveDate: date(time.February, 1)},
+			{InstrumentID: 2, Type: models.MergerAction, Ratio: 0.5, TargetInstrumentID: &target, EffectiveDate: date(time.February, 1)},
+			{InstrumentID: 3, Type: models.ReverseSplitAction, Ratio: 0.1, EffectiveDate: date(time.April, 1)},
+		}, nil)
+
+		var positions []models.Position
+		var cash []models.CashBalance
+		mockPositionRepo.On("RebuildUserBalances", userID, mock.Anything).Run(func(args mock.Arguments) {
+			replay := args.Get(1).(func([]models.Order) ([]models.Position, []models.CashBalance))
+			positions, cash = replay(orders)
+		}).Return(nil)
+
+		err := positionService.RebuildUser(userID)
+
+		assert.NoError(t, err)
+		assert.Len(t, positions, 2)
+		assert.Equal(t, uint(1), positions[0].InstrumentID)
+		assert.Equal(t, float64(16), positions[0].Quantity) // 10 * 2 - 4
+		assert.Equal(t, float64(20), positions[0].BoughtQuantity)
+		assert.Equal(t, float64(1000), positions[0].BoughtCost)
+		assert.Equal(t, uint(3), positions[1].InstrumentID)
+		assert.InDelta(t, 1.5, positions[1].Quantity, 1e-9) // 30 * 0.5 * 0.1
+		assert.InDelta(t, 150, positions[1].BoughtCost, 1e-9)
+		assert.False(t, positions[1].UpdatedAt.IsZero())
+		assert.Len(t, cash, 2)
+		assert.Equal(t, "ARS", cash[0].Currency)
+		assert.InDelta(t, 5000-1000+240-150, cash[0].Balance, 1e-9)
+		assert.Equal(t, "USD", cash[1].Currency)
+		assert.InDelta(t, 108, cash[1].Balance, 1e-9)
+		mockPositionRepo.AssertExpectations(t)
+	})
+
+	t.Run("Corporate actions error", func(t *testing.T) {
+		_, mockPositionRepo, mockCorporateActionRepo, positionService := setUp()
+
+		mockCorporateActionRepo.On("GetEffectiveAsOf", mock.AnythingOfType("time.Time")).Return(nil, errors.New("database error"))
+
+		err := positionService.RebuildUser(1)
+
+		assert.EqualError(t, err, "database error")
+		mockPositionRepo.AssertNotCalled(t, "RebuildUserBalances", mock.Anything, mock.Anything)
+	})
+}
+
+func TestCheckUser(t *testing.T) {
+	mockOrderRepo := new(mocks.OrderRepositorer)
+	mockPositionRepo := new(mocks.PositionRepositorer)
+	mockCorporateActionRepo := new(mocks.CorporateActionRepositorer)
+	mockCorporateActionRepo.On("GetEffectiveAsOf", mock.Anything).Return([]models.CorporateAction{}, nil)
+
+	positionService := NewPositionService(mockOrderRepo, mockPositionRepo, mockCorporateActionRepo)
+
+	userID := uint(1)
+	mockOrderRepo.On("GetUserFilledOrders", userID).Return([]models.Order{
+		{InstrumentID: 0, Side: "CASH_IN", Size: 2000, Status: "FILLED"},
+		{InstrumentID: 1, Side: "BUY", Size: 10, Price: 100, Status: "FILLED"},
+	}, nil)
+
+	t.Run("Consistent balances", func(t *testing.T) {
+		mockPositionRepo.On("GetUserPositions", userID).Return([]models.Position{
+			{UserID: userID, InstrumentID: 1, Quantity: 10, BoughtQuantity: 10, BoughtCost: 1000.0000000001},
+		}, nil).Once()
+		mockPositionRepo.On("GetUserCashBalances", userID).Return([]models.CashBalance{{UserID: userID, Currency: "ARS", Balance: 1000}}, nil).Once()
+
+		mismatches, err := positionService.CheckUser(userID)
+
+		assert.NoError(t, err)
+		assert.Empty(t, mismatches)
+	})
+
+	t.Run("Drifted and stray balances", func(t *testing.T) {
+		mockPositionRepo.On("GetUserPositions", userID).Return([]models.Position{
+			{UserID: userID, InstrumentID: 1, Quantity: 12, BoughtQuantity: 10, BoughtCost: 1000},
+			{UserID: userID, InstrumentID: 2, Quantity: 5, BoughtQuantity: 5, BoughtCost: 50},
+		}, nil).Once()
+		mockPositionRepo.On("GetUserCashBalances", userID).Return([]models.CashBalance{{UserID: userID, Currency: "USD", Balance: 3}}, nil).Once()
+
+		mismatches, err := positionService.CheckUser(userID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []models.BalanceMismatch{
+			{UserID: userID, Kind: models.PositionMismatch, Key: "1", Field: "quantity", Stored: 12, Expected: 10},
+			{UserID: userID, Kind: models.PositionMismatch, Key: "2", Field: "quantity", Stored: 5, Expected: 0},
+			{UserID: userID, Kind: models.PositionMismatch, Key: "2", Field: "boughtQuantity", Stored: 5, Expected: 0},
+			{UserID: userID, Kind: models.PositionMismatch, Key: "2", Field: "boughtCost", Stored: 50, Expected: 0},
+			{UserID: userID, Kind: models.CashMismatch, Key: "ARS", Field: "balance", Stored: 0, Expected: 1000},
+			{UserID: userID, Kind: models.CashMismatch, Key: "USD", Field: "balance", Stored: 3, Expected: 0},
+		}, mismatches)
+	})
+}
+
+func TestApplyCorporateActions(t *testing.T) {
+	mockOrderRepo := new(mocks.OrderRepositorer)
+	mockPositionRepo := new(mocks.PositionRepositorer)
+	mockCorporateActionRepo := new(mocks.CorporateActionRepositorer)
+
+	positionService := NewPositionService(mockOrderRepo, mockPositionRepo, mockCorporateActionRepo)
+
+	now := time.Now()
+	split := models.CorporateAction{ID: 1, InstrumentID: 1, Type: models.SplitAction, Ratio: 2, EffectiveDate: now.AddDate(0, 0, -1)}
+	mockCorporateActionRepo.On("GetUnapplied", now).Return([]models.CorporateAction{split}, nil)
+	mockCorporateActionRepo.On("GetEffectiveAsOf", mock.AnythingOfType("time.Time")).Return([]models.CorporateAction{split}, nil)
+	mockPositionRepo.On("GetHolderIDs", uint(1)).Return([]uint{7}, nil)
+	var positions []models.Position
+	mockPositionRepo.On("RebuildUserBalances", uint(7), mock.Anything).Run(func(args mock.Arguments) {
+		replay := args.Get(1).(func([]models.Order) ([]models.Position, []models.CashBalance))
+		positions, _ = replay([]models.Order{
+			{InstrumentID: 1, UserID: 7, Side: "BUY", Size: 10, Price: 100, Status: "FILLED", DateTime: now.AddDate(0, 0, -10)},
+		})
+	}).Return(nil)
+	mockCorporateActionRepo.On("MarkApplied", mock.MatchedBy(func(action *models.CorporateAction) bool {
+		return action.ID == split.ID
+	})).Return(nil)
+
+	err := positionService.ApplyCorporateActions(now)
+
+	assert.NoError(t, err)
+	if assert.Len(t, positions, 1) {
+		assert.Equal(t, float64(20), positions[0].Quantity)
+		assert.Equal(t, float64(1000), positions[0].BoughtCost)
+	}
+	mockPositionRepo.AssertExpectations(t)
+	mockCorporateActionRepo.AssertExpectations(t)
+}
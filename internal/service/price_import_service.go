@@ -0,0 +1,259 @@
+package service
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/NahuelDT/portfolio-api/internal/models"
+	"github.com/NahuelDT/portfolio-api/internal/repository"
+)
+
+// importBatchSize is how many rows are resolved and upserted at a time
+const importBatchSize = 1000
+
+var ErrInvalidImport = errors.New("invalid import file")
+
+// Columns an import file must have. The header names them in any order,
+// ignoring case, spaces, dashes and underscores, and may add previousclose.
+var importColumns = []string{"ticker", "date", "open", "high", "low", "close"}
+
+// PriceImportService loads market data files in bulk: it resolves their
+// tickers, validates each row and upserts the valid ones in batches
+type PriceImportService struct {
+	instrumentRepo repository.InstrumentRepositorer
+	marketDataRepo repository.MarketDataRepositorer
+}
+
+func NewPriceImportService(instrumentRepo repository.InstrumentRepositorer, marketDataRepo repository.MarketDataRepositorer) *PriceImportService {
+	return &PriceImportService{
+		instrumentRepo: instrumentRepo,
+		marketDataRepo: marketDataRepo,
+	}
+}
+
+// importRow is a parsed line waiting for its ticker to be resolved
+type importRow struct {
+	line   int
+	ticker string
+	data   models.MarketData
+}
+
+// priceImport is the state of a file being imported
+type priceImport struct {
+	report      *models.PriceImportReport
+	instruments map[string]uint
+	seen        map[importKey]int
+	pending     []importRow
+}
+
+type importKey struct {
+	instrumentID uint
+	date         time.Time
+}
+
+// Import reads a CSV file with a header line and stores its rows, replacing
+// the prices an instrument already has on the same date. Lines that cannot
+// be stored are reported with the reason and do not stop the import; a row
+// repeating the instrument and date of an earlier one is rejected. On a dry
+// run nothing is stored. Batches stored before an error are kept, and
+// importing the file again is safe.
+func (s *PriceImportService) Import(file io.Reader, dryRun bool) (*models.PriceImportReport, error) {
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("%w: the file is empty", ErrInvalidImport)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImport, err)
+	}
+	columns, err := importHeader(header)
+	if err != nil {
+		return nil, err
+	}
+
+	state := &priceImport{
+		report:      &models.PriceImportReport{Rejected: make([]models.RejectedLine, 0), DryRun: dryRun},
+		instruments: make(map[string]uint),
+		seen:        make(map[importKey]int),
+	}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			state.report.Lines++
+			state.reject(parseErr.StartLine, parseErr.Err.Error())
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		state.report.Lines++
+		line, _ := reader.FieldPos(0)
+		row, reason := parseImportRow(record, columns)
+		if reason != "" {
+			state.reject(line, reason)
+			continue
+		}
+		row.line = line
+		state.pending = append(state.pending, row)
+		if len(state.pending) == importBatchSize {
+			if err := s.flush(state); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := s.flush(state); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(state.report.Rejected, func(i, j int) bool {
+		return state.report.Rejected[i].Line < state.report.Rejected[j].Line
+	})
+	return state.report, nil
+}
+
+// flush resolves the tickers of the pending rows not seen before in a single
+// query and upserts the rows of known instruments
+func (s *PriceImportService) flush(state *priceImport) error {
+	if len(state.pending) == 0 {
+		return nil
+	}
+
+	unresolved := make([]string, 0)
+	for _, row := range state.pending {
+		if _, ok := state.instruments[row.ticker]; !ok {
+			state.instruments[row.ticker] = 0
+			unresolved = append(unresolved, row.ticker)
+		}
+	}
+	if len(unresolved) > 0 {
+		instruments, err := s.instrumentRepo.GetByTickers(unresolved)
+		if err != nil {
+			return err
+		}
+		for ticker, instrument := range instruments {
+			state.instruments[ticker] = instrument.ID
+		}
+	}
+
+	batch := make([]models.MarketData, 0, len(state.pending))
+	for _, row := range state.pending {
+		instrumentID := state.instruments[row.ticker]
+		if instrumentID == 0 {
+			state.reject(row.line, fmt.Sprintf("unknown ticker %q", row.ticker))
+			continue
+		}
+		key := importKey{instrumentID, row.data.DateTime}
+		if line, ok := state.seen[key]; ok {
+			state.reject(row.line, fmt.Sprintf("duplicate of line %d", line))
+			continue
+		}
+		state.seen[key] = row.line
+		row.data.InstrumentID = instrumentID
+		batch = append(batch, row.data)
+	}
+	state.pending = state.pending[:0]
+
+	if !state.report.DryRun {
+		if err := s.marketDataRepo.Upsert(batch); err != nil {
+			return err
+		}
+	}
+	state.report.Imported += len(batch)
+	return nil
+}
+
+func (state *priceImport) reject(line int, reason string) {
+	state.report.Rejected = append(state.report.Rejected, models.RejectedLine{Line: line, Reason: reason})
+}
+
+// importHeader maps each column name to its position in the header
+func importHeader(header []string) (map[string]int, error) {
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimPrefix(name, "\ufeff"))
+		name = strings.NewReplacer(" ", "", "_", "", "-", "").Replace(name)
+		columns[name] = i
+	}
+	for _, name := range importColumns {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("%w: missing column %q", ErrInvalidImport, name)
+		}
+	}
+	return columns, nil
+}
+
+// parseImportRow validates a line and returns its row, or the reason it is
+// rejected
+func parseImportRow(record []string, columns map[string]int) (importRow, string) {
+	field := func(name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	row := importRow{ticker: strings.ToUpper(field("ticker"))}
+	if row.ticker == "" {
+		return row, "missing ticker"
+	}
+
+	value := field("date")
+	date, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		if date, err = time.Parse(time.RFC3339, value); err != nil {
+			return row, fmt.Sprintf("invalid date %q", value)
+		}
+	}
+	row.data.DateTime = date.UTC()
+
+	prices := make(map[string]float64, 5)
+	for _, name := range []string{"open", "high", "low", "close", "previousclose"} {
+		value := field(name)
+		if value == "" {
+			if name == "previousclose" {
+				continue
+			}
+			return row, fmt.Sprintf("missing %s", name)
+		}
+		price, err := strconv.ParseFloat(value, 64)
+		if err != nil || math.IsNaN(price) || math.IsInf(price, 0) {
+			return row, fmt.Sprintf("invalid %s %q", name, value)
+		}
+		if price < 0 || (price == 0 && name != "previousclose") {
+			return row, fmt.Sprintf("%s must be positive", name)
+		}
+		prices[name] = price
+	}
+	row.data.Open = prices["open"]
+	row.data.High = prices["high"]
+	row.data.Low = prices["low"]
+	row.data.Close = prices["close"]
+	row.data.PreviousClose = prices["previousclose"]
+
+	if row.data.High < row.data.Low {
+		return row, "high is below low"
+	}
+	if row.data.Open < row.data.Low || row.data.Open > row.data.High {
+		return row, "open is outside the low-high range"
+	}
+	if row.data.Close < row.data.Low || row.data.Close > row.data.High {
+		return row, "close is outside the low-high range"
+	}
+	return row, ""
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	mocks "github.com/NahuelDT/portfolio-api/internal/mocks/repository"
+	"github.com/NahuelDT/portfolio-api/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPriceImport(t *testing.T) {
+	setUp := func() (*mocks.InstrumentRepositorer, *mocks.MarketDataRepositorer, *PriceImportService) {
+		mockInstrumentRepo := new(mocks.InstrumentRepositorer)
+		mockMarketDataRepo := new(mocks.MarketDataRepositorer)
+		importService := NewPriceImportService(mockInstrumentRepo, mockMarketDataRepo)
+		return mockInstrumentRepo, mockMarketDataRepo, importService
+	}
+	date := func(month time.Month, day int) time.Time {
+		return time.Date(2024, month, day, 0, 0, 0, 0, time.UTC)
+	}
+
+	t.Run("Import valid rows and report the rejected lines", func(t *testing.T) {
+		mockInstrumentRepo, mockMarketDataRepo, importService := setUp()
+
+		file := strings.Join([]string{
+			"Ticker,Date,Open,High,Low,Close,Previous_Close",
+			"ggal,2024-01-02,100,110,95,105,99",
+			"YPFD,2024-01-02,50,55,48,52,",
+			"PAMP,2024-01-02,10,11,9,10,10",
+			"GGAL,2024-01-02,101,111,96,106,99",
+			"GGAL,2024-01-03,105,99,100,102,105",
+			"GGAL,2024-01-04,abc,110,95,105,105",
+			"GGAL,04/01/2024,100,110,95,105,105",
+			"GGAL,2024-01-05,100,110,95,120,105",
+			"YPFD,2024-01-03,52,53",
+			"",
+			"YPFD,2024-01-04T00:00:00Z,52,56,51,55,52",
+		}, "\n")
+
+		mockInstrumentRepo.On("GetByTickers", []string{"GGAL", "YPFD", "PAMP"}).Return(map[string]*models.Instrument{
+			"GGAL": {ID: 1, Ticker: "GGAL"},
+			"YPFD": {ID: 2, Ticker: "YPFD"},
+		}, nil)
+		var stored []models.MarketData
+		mockMarketDataRepo.On("Upsert", mock.Anything).Run(func(args mock.Arguments) {
+			stored = append(stored, args.Get(0).([]models.MarketData)...)
+		}).Return(nil)
+
+		report, err := importService.Import(strings.NewReader(file), false)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 10, report.Lines)
+		assert.Equal(t, 3, report.Imported)
+		assert.False(t, report.DryRun)
+		assert.Equal(t, []models.RejectedLine{
+			{Line: 4, Reason: `unknown ticker "PAMP"`},
+			{Line: 5, Reason: "duplicate of line 2"},
+			{Line: 6, Reason: "high is below low"},
+			{Line: 7, Reason: `invalid open "abc"`},
+			{Line: 8, Reason: `invalid date "04/01/2024"`},
+			{Line: 9, Reason: "close is outside the low-high range"},
+			{Line: 10, Reason: "missing low"},
+		}, report.Rejected)
+		assert.Equal(t, []models.MarketData{
+			{InstrumentID: 1, Open: 100, High: 110, Low: 95, Close: 105, PreviousClose: 99, DateTime: date(time.January, 2)},
+			{InstrumentID: 2, Open: 50, High: 55, Low: 48, Close: 52, DateTime: date(time.January, 2)},
+			{InstrumentID: 2, Open: 52, High: 56, Low: 51, Close: 55, PreviousClose: 52, DateTime: date(time.January, 4)},
+		}, stored)
+		mockInstrumentRepo.AssertExpectations(t)
+	})
+
+	t.Run("Large files are resolved and upserted in batches", func(t *testing.T) {
+		mockInstrumentRepo, mockMarketDataRepo, importService := setUp()
+
+		var file strings.Builder
+		file.WriteString("ticker,date,open,high,low,close\n")
+		start := date(time.January, 1)
+		for i := 0; i < importBatchSize+10; i++ {
+			fmt.Fprintf(&file, "GGAL,%s,100,100,100,100\n", start.AddDate(0, 0, i).Format("2006-01-02"))
+		}
+
+		mockInstrumentRepo.On("GetByTickers", []string{"GGAL"}).Return(map[string]*models.Instrument{"GGAL": {ID: 1}}, nil).Once()
+		var batches []int
+		mockMarketDataRepo.On("Upsert", mock.Anything).Run(func(args mock.Arguments) {
+			batches = append(batches, len(args.Get(0).([]models.MarketData)))
+		}).Return(nil)
+
+		report, err := importService.Import(strings.NewReader(file.String()), false)
+
+		assert.NoError(t, err)
+		assert.Equal(t, importBatchSize+10, report.Imported)
+		assert.Equal(t, []int{importBatchSize, 10}, batches)
+		mockInstrumentRepo.AssertExpectations(t)
+	})
+
+	t.Run("Dry runs validate without storing", func(t *testing.T) {
+		mockInstrumentRepo, mockMarketDataRepo, importService := setUp()
+
+		mockInstrumentRepo.On("GetByTickers", []string{"GGAL"}).Return(map[string]*models.Instrument{"GGAL": {ID: 1}}, nil)
+
+		report, err := importService.Import(strings.NewReader("date,ticker,close,open,low,high\n2024-01-02,GGAL,105,100,95,110\n"), true)
+
+		assert.NoError(t, err)
+		assert.True(t, report.DryRun)
+		assert.Equal(t, 1, report.Imported)
+		assert.Empty(t, report.Rejected)
+		mockMarketDataRepo.AssertNotCalled(t, "Upsert", mock.Anything)
+	})
+
+	t.Run("Files without the required columns are invalid", func(t *testing.T) {
+		_, _, importService := setUp()
+
+		_, err := importService.Import(strings.NewReader("ticker,date,close\nGGAL,2024-01-02,105\n"), false)
+		assert.ErrorIs(t, err, ErrInvalidImport)
+		_, err = importService.Import(strings.NewReader(""), false)
+		assert.ErrorIs(t, err, ErrInvalidImport)
+	})
+}
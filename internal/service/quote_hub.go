@@ -0,0 +1,203 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/NahuelDT/portfolio-api/internal/models"
+	"github.com/NahuelDT/portfolio-api/internal/repository"
+)
+
+// Quotes a subscriber can have queued before it is dropped as too slow, and
+// the most tickers it can follow
+const (
+	quoteBufferSize       = 64
+	maxQuoteSubscriptions = 200
+)
+
+var ErrInvalidSubscription = errors.New("invalid quote subscription")
+
+// QuoteUpdate is a market data row sent to the subscribers of its instrument
+type QuoteUpdate struct {
+	Ticker string             `json:"ticker"`
+	Data   *models.MarketData `json:"data"`
+}
+
+// QuoteHub is an in-process pub/sub of market data. As a MarketDataListener
+// it publishes every row stored through the MarketDataService to the
+// subscribers of the row's instrument. Publishing never blocks: a
+// subscriber whose queue is full is closed, and its client can reconnect.
+type QuoteHub struct {
+	instrumentRepo repository.InstrumentRepositorer
+
+	mu          sync.RWMutex
+	subscribers map[uint]map[*QuoteSubscriber]struct{}
+	tickers     map[uint]string
+}
+
+func NewQuoteHub(instrumentRepo repository.InstrumentRepositorer) *QuoteHub {
+	return &QuoteHub{
+		instrumentRepo: instrumentRepo,
+		subscribers:    make(map[uint]map[*QuoteSubscriber]struct{}),
+		tickers:        make(map[uint]string),
+	}
+}
+
+// QuoteSubscriber receives the quotes of the tickers it subscribed to until
+// it is closed
+type QuoteSubscriber struct {
+	quotes      chan QuoteUpdate
+	instruments map[string]uint
+	closed      bool
+	slow        bool
+}
+
+// Quotes delivers the subscriber's quotes in the order they were stored. It
+// is closed when the subscriber is.
+func (s *QuoteSubscriber) Quotes() <-chan QuoteUpdate {
+	return s.quotes
+}
+
+// Connect registers a subscriber without subscriptions
+func (h *QuoteHub) Connect() *QuoteSubscriber {
+	return &QuoteSubscriber{
+		quotes:      make(chan QuoteUpdate, quoteBufferSize),
+		instruments: make(map[string]uint),
+	}
+}
+
+// Disconnect removes all the subscriber's subscriptions and closes it. It
+// can be called more than once.
+func (h *QuoteHub) Disconnect(subscriber *QuoteSubscriber) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.close(subscriber)
+}
+
+// Slow tells whether the hub closed the subscriber because its queue was full
+func (h *QuoteHub) Slow(subscriber *QuoteSubscriber) bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return subscriber.slow
+}
+
+// Subscribe adds the tickers to the subscriber's subscriptions and returns
+// the ones that were subscribed, upper-cased, and the ones that match no
+// instrument
+func (h *QuoteHub) Subscribe(subscriber *QuoteSubscriber, tickers []string) (subscribed, unknown []string, err error) {
+	tickers = normalizeTickers(tickers)
+	if len(tickers) == 0 {
+		return nil, nil, fmt.Errorf("%w: no tickers", ErrInvalidSubscription)
+	}
+	instruments, err := h.instrumentRepo.GetByTickers(tickers)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if subscriber.closed {
+		return nil, nil, fmt.Errorf("%w: the subscriber is closed", ErrInvalidSubscription)
+	}
+	subscribed = make([]string, 0, len(tickers))
+	unknown = make([]string, 0)
+	for _, ticker := range tickers {
+		instrument, ok := instruments[ticker]
+		if !ok {
+			unknown = append(unknown, ticker)
+			continue
+		}
+		if _, ok := subscriber.instruments[ticker]; !ok && len(subscriber.instruments) >= maxQuoteSubscriptions {
+			return subscribed, unknown, fmt.Errorf("%w: cannot follow more than %d tickers", ErrInvalidSubscription, maxQuoteSubscriptions)
+		}
+		subscriber.instruments[ticker] = instrument.ID
+		if h.subscribers[instrument.ID] == nil {
+			h.subscribers[instrument.ID] = make(map[*QuoteSubscriber]struct{})
+		}
+		h.subscribers[instrument.ID][subscriber] = struct{}{}
+		h.tickers[instrument.ID] = ticker
+		subscribed = append(subscribed, ticker)
+	}
+	return subscribed, unknown, nil
+}
+
+// Unsubscribe removes the tickers from the subscriber's subscriptions and
+// returns the upper-cased tickers it no longer follows
+func (h *QuoteHub) Unsubscribe(subscriber *QuoteSubscriber, tickers []string) []string {
+	tickers = normalizeTickers(tickers)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, ticker := range tickers {
+		if instrumentID, ok := subscriber.instruments[ticker]; ok {
+			delete(subscriber.instruments, ticker)
+			h.remove(instrumentID, subscriber)
+		}
+	}
+	return tickers
+}
+
+// OnMarketData publishes the row to the subscribers of its instrument
+func (h *QuoteHub) OnMarketData(previous, data *models.MarketData) {
+	h.mu.RLock()
+	slow := make([]*QuoteSubscriber, 0)
+	update := QuoteUpdate{Ticker: h.tickers[data.InstrumentID], Data: data}
+	for subscriber := range h.subscribers[data.InstrumentID] {
+		select {
+		case subscriber.quotes <- update:
+		default:
+			slow = append(slow, subscriber)
+		}
+	}
+	h.mu.RUnlock()
+
+	if len(slow) == 0 {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, subscriber := range slow {
+		if !subscriber.closed {
+			subscriber.slow = true
+			h.close(subscriber)
+		}
+	}
+}
+
+// close removes the subscriber from every instrument and closes its queue.
+// The caller holds the write lock.
+func (h *QuoteHub) close(subscriber *QuoteSubscriber) {
+	if subscriber.closed {
+		return
+	}
+	for _, instrumentID := range subscriber.instruments {
+		h.remove(instrumentID, subscriber)
+	}
+	subscriber.closed = true
+	close(subscriber.quotes)
+}
+
+func (h *QuoteHub) remove(instrumentID uint, subscriber *QuoteSubscriber) {
+	delete(h.subscribers[instrumentID], subscriber)
+	if len(h.subscribers[instrumentID]) == 0 {
+		delete(h.subscribers, instrumentID)
+		delete(h.tickers, instrumentID)
+	}
+}
+
+// normalizeTickers upper-cases the tickers, dropping blanks and repeats
+func normalizeTickers(tickers []string) []string {
+	seen := make(map[string]bool, len(tickers))
+	normalized := make([]string, 0, len(tickers))
+	for _, ticker := range tickers {
+		ticker = strings.ToUpper(strings.TrimSpace(ticker))
+		if ticker == "" || seen[ticker] {
+			continue
+		}
+		seen[ticker] = true
+		normalized = append(normalized, ticker)
+	}
+	return normalized
+}
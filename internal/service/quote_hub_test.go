@@ -0,0 +1,111 @@
+package service
+
+import (
+	"testing"
+
+	mocks "github.com/NahuelDT/portfolio-api/internal/mocks/repository"
+	"github.com/NahuelDT/portfolio-api/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestQuoteHub(t *testing.T) {
+	setUp := func() *QuoteHub {
+		mockInstrumentRepo := new(mocks.InstrumentRepositorer)
+		mockInstrumentRepo.On("GetByTickers", mock.Anything).Return(map[string]*models.Instrument{
+			"GGAL": {ID: 1, Ticker: "GGAL"},
+			"YPFD": {ID: 2, Ticker: "YPFD"},
+		}, nil)
+		return NewQuoteHub(mockInstrumentRepo)
+	}
+
+	t.Run("Subscribers get the quotes of their tickers", func(t *testing.T) {
+		hub := setUp()
+		subscriber := hub.Connect()
+		other := hub.Connect()
+
+		subscribed, unknown, err := hub.Subscribe(subscriber, []string{"ggal", " GGAL", "PAMP"})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"GGAL"}, subscribed)
+		assert.Equal(t, []string{"PAMP"}, unknown)
+		_, _, err = hub.Subscribe(other, []string{"YPFD"})
+		assert.NoError(t, err)
+
+		data := &models.MarketData{InstrumentID: 1, Close: 100}
+		hub.OnMarketData(nil, data)
+		hub.OnMarketData(nil, &models.MarketData{InstrumentID: 3, Close: 5})
+
+		assert.Equal(t, QuoteUpdate{Ticker: "GGAL", Data: data}, <-subscriber.Quotes())
+		assert.Len(t, subscriber.Quotes(), 0)
+		assert.Len(t, other.Quotes(), 0)
+	})
+
+	t.Run("Unsubscribed tickers stop being delivered", func(t *testing.T) {
+		hub := setUp()
+		subscriber := hub.Connect()
+
+		_, _, err := hub.Subscribe(subscriber, []string{"GGAL", "YPFD"})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"GGAL"}, hub.Unsubscribe(subscriber, []string{"ggal"}))
+
+		hub.OnMarketData(nil, &models.MarketData{InstrumentID: 1, Close: 100})
+		hub.OnMarketData(nil, &models.MarketData{InstrumentID: 2, Close: 50})
+
+		update := <-subscriber.Quotes()
+		assert.Equal(t, "YPFD", update.Ticker)
+		assert.Len(t, subscriber.Quotes(), 0)
+	})
+
+	t.Run("Slow subscribers are closed without blocking the others", func(t *testing.T) {
+		hub := setUp()
+		slow := hub.Connect()
+		fast := hub.Connect()
+		_, _, err := hub.Subscribe(slow, []string{"GGAL"})
+		assert.NoError(t, err)
+		_, _, err = hub.Subscribe(fast, []string{"GGAL"})
+		assert.NoError(t, err)
+
+		received := 0
+		for i := 0; i <= quoteBufferSize; i++ {
+			hub.OnMarketData(nil, &models.MarketData{InstrumentID: 1, Close: float64(i)})
+			<-fast.Quotes()
+			received++
+		}
+
+		assert.Equal(t, quoteBufferSize+1, received)
+		assert.True(t, hub.Slow(slow))
+		assert.False(t, hub.Slow(fast))
+		queued := 0
+		for range slow.Quotes() {
+			queued++
+		}
+		assert.Equal(t, quoteBufferSize, queued)
+
+		// Closed subscribers cannot subscribe again, and disconnecting them is safe
+		_, _, err = hub.Subscribe(slow, []string{"GGAL"})
+		assert.ErrorIs(t, err, ErrInvalidSubscription)
+		hub.Disconnect(slow)
+	})
+
+	t.Run("Disconnect closes the subscriber", func(t *testing.T) {
+		hub := setUp()
+		subscriber := hub.Connect()
+		_, _, err := hub.Subscribe(subscriber, []string{"GGAL"})
+		assert.NoError(t, err)
+
+		hub.Disconnect(subscriber)
+		hub.OnMarketData(nil, &models.MarketData{InstrumentID: 1, Close: 100})
+
+		_, open := <-subscriber.Quotes()
+		assert.False(t, open)
+		assert.False(t, hub.Slow(subscriber))
+		assert.Empty(t, hub.subscribers)
+	})
+
+	t.Run("Subscriptions need tickers", func(t *testing.T) {
+		hub := setUp()
+
+		_, _, err := hub.Subscribe(hub.Connect(), []string{" "})
+		assert.ErrorIs(t, err, ErrInvalidSubscription)
+	})
+}
@@ -0,0 +1,301 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/NahuelDT/portfolio-api/internal/models"
+	"github.com/NahuelDT/portfolio-api/internal/repository"
+)
+
+// tradingDaysPerYear annualizes daily volatility and ratios
+const tradingDaysPerYear = 252
+
+var ErrInvalidBenchmark = errors.New("invalid benchmark")
+
+type RiskService struct {
+	userRepo            repository.UserRepositorer
+	orderRepo           repository.OrderRepositorer
+	instrumentRepo      repository.InstrumentRepositorer
+	marketDataRepo      repository.MarketDataRepositorer
+	fxRateRepo          repository.FXRateRepositorer
+	corporateActionRepo repository.CorporateActionRepositorer
+}
+
+func NewRiskService(
+	userRepo repository.UserRepositorer,
+	orderRepo repository.OrderRepositorer,
+	instrumentRepo repository.InstrumentRepositorer,
+	marketDataRepo repository.MarketDataRepositorer,
+	fxRateRepo repository.FXRateRepositorer,
+	corporateActionRepo repository.CorporateActionRepositorer,
+) *RiskService {
+	return &RiskService{
+		userRepo:            userRepo,
+		orderRepo:           orderRepo,
+		instrumentRepo:      instrumentRepo,
+		marketDataRepo:      marketDataRepo,
+		fxRateRepo:          fxRateRepo,
+		corporateActionRepo: corporateActionRepo,
+	}
+}
+
+// GetRisk calculates risk metrics from daily closes over the period for each
+// current holding and for the whole portfolio. Portfolio returns neutralize
+// CASH_IN and CASH_OUT flows and are valued in the given base currency, so
+// they include FX moves. Beta is only reported when benchmarkID is set.
+// riskFreeRate is an annual percentage.
+func (s *RiskService) GetRisk(userID uint, period string, baseCurrency string, benchmarkID uint, riskFreeRate float64) (*models.PortfolioRisk, error) {
+	end := time.Now()
+	start, err := periodStart(period, end)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.userRepo.GetByID(userID); err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	risk := &models.PortfolioRisk{
+		Period:       strings.ToUpper(period),
+		From:         start,
+		To:           end,
+		BaseCurrency: models.NormalizeCurrency(baseCurrency),
+		Assets:       make([]models.AssetRisk, 0),
+	}
+
+	actions, err := loadCorporateActions(s.corporateActionRepo, end)
+	if err != nil {
+		return nil, err
+	}
+
+	var benchmarkReturns map[time.Time]float64
+	if benchmarkID != 0 {
+		benchmark, err := s.instrumentRepo.GetByID(benchmarkID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidBenchmark, err)
+		}
+		benchmarkData, err := s.marketDataRepo.GetMarketDataRange(benchmarkID, start, end)
+		if err != nil {
+			return nil, err
+		}
+		benchmarkData = actions.adjustMarketData(benchmarkID, benchmarkData)
+		risk.Benchmark = benchmark.Ticker
+		benchmarkReturns = make(map[time.Time]float64)
+		for _, r := range closeReturns(dailyCloses(benchmarkData, start)) {
+			benchmarkReturns[r.day] = r.value
+		}
+	}
+
+	orders, err := s.orderRepo.GetUserFilledOrders(userID)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(orders, func(i, j int) bool {
+		return orders[i].DateTime.Before(orders[j].DateTime)
+	})
+	orders = actions.adjustOrders(orders)
+
+	marketData, err := loadMarketData(s.marketDataRepo, orders, end, actions)
+	if err != nil {
+		return nil, err
+	}
+	valuation, err := newValuation(s.instrumentRepo, s.fxRateRepo, orders, marketData, baseCurrency, end)
+	if err != nil {
+		return nil, err
+	}
+
+	dailyRiskFree := riskFreeRate / 100 / tradingDaysPerYear
+	current := newHoldings()
+	for _, order := range orders {
+		current.apply(order)
+	}
+	for instrumentID, quantity := range current.positions {
+		if quantity <= 0 {
+			continue
+		}
+		instrument := valuation.instruments[instrumentID]
+		returns := closeReturns(dailyCloses(marketData[instrumentID], start))
+		risk.Assets = append(risk.Assets, models.AssetRisk{
+			Ticker:      instrument.Ticker,
+			Name:        instrument.Name,
+			RiskMetrics: calculateRiskMetrics(returns, benchmarkReturns, dailyRiskFree),
+		})
+	}
+	sort.Slice(risk.Assets, func(i, j int) bool {
+		return risk.Assets[i].Ticker < risk.Assets[j].Ticker
+	})
+
+	returns := portfolioReturns(orders, marketData, valuation, start)
+	risk.Portfolio = calculateRiskMetrics(returns, benchmarkReturns, dailyRiskFree)
+
+	return risk, nil
+}
+
+type dailyValue struct {
+	day   time.Time
+	value float64
+}
+
+// dayOf truncates a timestamp to its UTC calendar day
+func dayOf(t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// dailyCloses keeps the last close of each day from the given date on
+func dailyCloses(data []models.MarketData, from time.Time) []dailyValue {
+	closes := make([]dailyValue, 0, len(data))
+	for _, row := range data {
+		if row.DateTime.Before(from) {
+			continue
+		}
+		day := dayOf(row.DateTime)
+		if n := len(closes); n > 0 && closes[n-1].day.Equal(day) {
+			closes[n-1].value = row.Close
+		} else {
+			closes = append(closes, dailyValue{day: day, value: row.Close})
+		}
+	}
+	return closes
+}
+
+// closeReturns converts consecutive closes into simple returns
+func closeReturns(closes []dailyValue) []dailyValue {
+	returns := make([]dailyValue, 0, len(closes))
+	for i := 1; i < len(closes); i++ {
+		if closes[i-1].value > 0 {
+			returns = append(returns, dailyValue{day: closes[i].day, value: closes[i].value/closes[i-1].value - 1})
+		}
+	}
+	return returns
+}
+
+// portfolioReturns values the holdings at the end of every trading day of
+// the traded instruments and returns the daily returns net of external
+// flows.
+func portfolioReturns(orders []models.Order, marketData map[uint][]models.MarketData, valuation *valuation, from time.Time) []dailyValue {
+	days := tradingDays(marketData, from)
+	holdings := newHoldings()
+	returns := make([]dailyValue, 0, len(days))
+	previousValue := 0.0
+	next := 0
+	for _, day := range days {
+		dayEnd := day.Add(24 * time.Hour)
+		flow := 0.0
+		for ; next < len(orders) && orders[next].DateTime.Before(dayEnd); next++ {
+			amount, _ := valuation.externalFlow(orders[next])
+			flow += amount
+			holdings.apply(orders[next])
+		}
+
+		value := valuation.value(holdings, dayEnd.Add(-time.Nanosecond))
+		if previousValue > 0 {
+			returns = append(returns, dailyValue{day: day, value: (value-flow)/previousValue - 1})
+		}
+		previousValue = value
+	}
+	return returns
+}
+
+// tradingDays lists the days with market data for any of the instruments
+// from the given date on, oldest first
+func tradingDays(marketData map[uint][]models.MarketData, from time.Time) []time.Time {
+	daySet := make(map[time.Time]bool)
+	for _, data := range marketData {
+		for _, c := range dailyCloses(data, from) {
+			daySet[c.day] = true
+		}
+	}
+	days := make([]time.Time, 0, len(daySet))
+	for day := range daySet {
+		days = append(days, day)
+	}
+	sort.Slice(days, func(i, j int) bool {
+		return days[i].Before(days[j])
+	})
+	return days
+}
+
+// calculateRiskMetrics derives annualized volatility, Sharpe and Sortino
+// ratios, maximum drawdown and, when benchmark returns are given, beta
+// from a series of daily returns.
+func calculateRiskMetrics(returns []dailyValue, benchmarkReturns map[time.Time]float64, dailyRiskFree float64) models.RiskMetrics {
+	metrics := models.RiskMetrics{}
+	if len(returns) < 2 {
+		return metrics
+	}
+
+	mean := 0.0
+	for _, r := range returns {
+		mean += r.value
+	}
+	mean /= float64(len(returns))
+
+	variance := 0.0
+	downside := 0.0
+	growth, peak := 1.0, 1.0
+	for _, r := range returns {
+		variance += (r.value - mean) * (r.value - mean)
+		if excess := r.value - dailyRiskFree; excess < 0 {
+			downside += excess * excess
+		}
+
+		growth *= 1 + r.value
+		peak = math.Max(peak, growth)
+		metrics.MaxDrawdown = math.Min(metrics.MaxDrawdown, (growth/peak-1)*100)
+	}
+	stdDev := math.Sqrt(variance / float64(len(returns)-1))
+	downsideDev := math.Sqrt(downside / float64(len(returns)))
+	annualization := math.Sqrt(tradingDaysPerYear)
+
+	metrics.Volatility = stdDev * annualization * 100
+	if stdDev > 0 {
+		metrics.SharpeRatio = (mean - dailyRiskFree) / stdDev * annualization
+	}
+	if downsideDev > 0 {
+		metrics.SortinoRatio = (mean - dailyRiskFree) / downsideDev * annualization
+	}
+
+	if benchmarkReturns != nil {
+		beta := calculateBeta(returns, benchmarkReturns)
+		metrics.Beta = &beta
+	}
+
+	return metrics
+}
+
+// calculateBeta regresses the returns on the benchmark returns of the same days
+func calculateBeta(returns []dailyValue, benchmarkReturns map[time.Time]float64) float64 {
+	pairs := make([][2]float64, 0, len(returns))
+	for _, r := range returns {
+		if b, ok := benchmarkReturns[r.day]; ok {
+			pairs = append(pairs, [2]float64{r.value, b})
+		}
+	}
+	if len(pairs) < 2 {
+		return 0
+	}
+
+	meanReturn, meanBenchmark := 0.0, 0.0
+	for _, pair := range pairs {
+		meanReturn += pair[0]
+		meanBenchmark += pair[1]
+	}
+	meanReturn /= float64(len(pairs))
+	meanBenchmark /= float64(len(pairs))
+
+	covariance, variance := 0.0, 0.0
+	for _, pair := range pairs {
+		covariance += (pair[0] - meanReturn) * (pair[1] - meanBenchmark)
+		variance += (pair[1] - meanBenchmark) * (pair[1] - meanBenchmark)
+	}
+	if variance == 0 {
+		return 0
+	}
+	return covariance / variance
+}
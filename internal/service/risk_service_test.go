@@ -0,0 +1,82 @@
+package service
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	mocks "github.com/NahuelDT/portfolio-api/internal/mocks/repository"
+	"github.com/NahuelDT/portfolio-api/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetRisk(t *testing.T) {
+	mockUserRepo := new(mocks.UserRepositorer)
+	mockOrderRepo := new(mocks.OrderRepositorer)
+	mockInstrumentRepo := new(mocks.InstrumentRepositorer)
+	mockMarketDataRepo := new(mocks.MarketDataRepositorer)
+	mockFXRateRepo := new(mocks.FXRateRepositorer)
+	mockCorporateActionRepo := new(mocks.CorporateActionRepositorer)
+	mockCorporateActionRepo.On("GetEffectiveAsOf", mock.Anything).Return([]models.CorporateAction{}, nil)
+
+	riskService := NewRiskService(mockUserRepo, mockOrderRepo, mockInstrumentRepo, mockMarketDataRepo, mockFXRateRepo, mockCorporateActionRepo)
+
+	t.Run("Holding and portfolio metrics against a benchmark", func(t *testing.T) {
+		userID := uint(1)
+		base := dayOf(time.Now()).AddDate(0, 0, -10)
+		day := func(i int) time.Time { return base.AddDate(0, 0, i).Add(20 * time.Hour) }
+
+		mockOrders := []models.Order{
+			{ID: 1, UserID: userID, Side: "CASH_IN", Size: 1000, Status: "FILLED", DateTime: day(0)},
+			{ID: 2, InstrumentID: 1, UserID: userID, Side: "BUY", Size: 10, Price: 100, Status: "FILLED", DateTime: day(1)},
+		}
+		// Returns of +10%, -10%, +10%, +10% for the holding and half of that for the benchmark
+		holdingCloses := []float64{100, 110, 99, 108.9, 119.79}
+		benchmarkCloses := []float64{100, 105, 99.75, 104.7375, 109.974375}
+		holdingData := make([]models.MarketData, 0)
+		benchmarkData := make([]models.MarketData, 0)
+		for i := range holdingCloses {
+			holdingData = append(holdingData, models.MarketData{InstrumentID: 1, Close: holdingCloses[i], DateTime: day(i + 1)})
+			benchmarkData = append(benchmarkData, models.MarketData{InstrumentID: 2, Close: benchmarkCloses[i], DateTime: day(i + 1)})
+		}
+
+		mockUserRepo.On("GetByID", userID).Return(&models.User{ID: userID}, nil)
+		mockOrderRepo.On("GetUserFilledOrders", userID).Return(mockOrders, nil)
+		mockInstrumentRepo.On("GetByID", uint(2)).Return(&models.Instrument{ID: 2, Ticker: "SPY", Name: "SPDR S&P 500"}, nil)
+		mockMarketDataRepo.On("GetMarketDataRange", uint(1), mock.Anything, mock.Anything).Return(holdingData, nil)
+		mockMarketDataRepo.On("GetMarketDataRange", uint(2), mock.Anything, mock.Anything).Return(benchmarkData, nil)
+		mockInstrumentRepo.On("GetByIDs", []uint{1}).Return(map[uint]*models.Instrument{1: {ID: 1, Ticker: "AAPL", Name: "Apple Inc."}}, nil).Once()
+
+		risk, err := riskService.GetRisk(userID, "1Y", "ARS", 2, 0)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "ARS", risk.BaseCurrency)
+		assert.Equal(t, "SPY", risk.Benchmark)
+		assert.Len(t, risk.Assets, 1)
+		assert.Equal(t, "Apple Inc.", risk.Assets[0].Name)
+
+		annualization := math.Sqrt(tradingDaysPerYear)
+		for _, metrics := range []models.RiskMetrics{risk.Assets[0].RiskMetrics, risk.Portfolio} {
+			assert.InDelta(t, 0.1*annualization*100, metrics.Volatility, 1e-6) // std dev of 0.1
+			assert.InDelta(t, 0.5*annualization, metrics.SharpeRatio, 1e-6)    // mean 0.05 / 0.1
+			assert.InDelta(t, 1*annualization, metrics.SortinoRatio, 1e-6)     // mean 0.05 / downside 0.05
+			assert.InDelta(t, -10, metrics.MaxDrawdown, 1e-6)
+			if assert.NotNil(t, metrics.Beta) {
+				assert.InDelta(t, 2, *metrics.Beta, 1e-6)
+			}
+		}
+
+		mockUserRepo.AssertExpectations(t)
+		mockOrderRepo.AssertExpectations(t)
+		mockInstrumentRepo.AssertExpectations(t)
+		mockMarketDataRepo.AssertExpectations(t)
+	})
+
+	t.Run("Invalid period", func(t *testing.T) {
+		risk, err := riskService.GetRisk(1, "2W", "ARS", 0, 0)
+
+		assert.ErrorIs(t, err, ErrInvalidPeriod)
+		assert.Nil(t, risk)
+	})
+}
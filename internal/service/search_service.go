@@ -3,6 +3,7 @@ package service
 import (
 	"strings"
 
+	"github.com/NahuelDT/portfolio-api/internal/models"
 	"github.com/NahuelDT/portfolio-api/internal/repository"
 )
 
@@ -17,10 +18,11 @@ func NewSearchService(instrumentRepo repository.InstrumentRepositorer) *SearchSe
 }
 
 type SearchResult struct {
-	ID     uint   `json:"id"`
-	Ticker string `json:"ticker"`
-	Name   string `json:"name"`
-	Type   string `json:"type"`
+	ID       uint   `json:"id"`
+	Ticker   string `json:"ticker"`
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Currency string `json:"currency"`
 }
 
 func (s *SearchService) SearchAssets(query string) ([]SearchResult, error) {
@@ -37,10 +39,11 @@ func (s *SearchService) SearchAssets(query string) ([]SearchResult, error) {
 	results := make([]SearchResult, len(instruments))
 	for i, instrument := range instruments {
 		results[i] = SearchResult{
-			ID:     instrument.ID,
-			Ticker: instrument.Ticker,
-			Name:   instrument.Name,
-			Type:   instrument.Type,
+			ID:       instrument.ID,
+			Ticker:   instrument.Ticker,
+			Name:     instrument.Name,
+			Type:     instrument.Type,
+			Currency: models.NormalizeCurrency(instrument.Currency),
 		}
 	}
 
@@ -0,0 +1,275 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/NahuelDT/portfolio-api/internal/models"
+	"github.com/NahuelDT/portfolio-api/internal/repository"
+)
+
+var ErrInvalidStatementPeriod = errors.New("invalid statement period")
+
+type StatementService struct {
+	userRepo           repository.UserRepositorer
+	orderRepo          repository.OrderRepositorer
+	instrumentRepo     repository.InstrumentRepositorer
+	statementRepo      repository.StatementRepositorer
+	portfolioService   PortfolioServicer
+	performanceService PerformanceServicer
+	ledgerRepo         repository.LedgerRepositorer
+}
+
+func NewStatementService(
+	userRepo repository.UserRepositorer,
+	orderRepo repository.OrderRepositorer,
+	instrumentRepo repository.InstrumentRepositorer,
+	statementRepo repository.StatementRepositorer,
+	portfolioService PortfolioServicer,
+	performanceService PerformanceServicer,
+	ledgerRepo repository.LedgerRepositorer,
+) *StatementService {
+	return &StatementService{
+		userRepo:           userRepo,
+		orderRepo:          orderRepo,
+		instrumentRepo:     instrumentRepo,
+		statementRepo:      statementRepo,
+		portfolioService:   portfolioService,
+		performanceService: performanceService,
+		ledgerRepo:         ledgerRepo,
+	}
+}
+
+// GenerateStatement builds the user's statement for a finished month (1-12)
+// or quarter (1-4) of the year and stores it in the statement history.
+func (s *StatementService) GenerateStatement(userID uint, periodType string, year, number int, baseCurrency string) (*models.AccountStatement, error) {
+	periodType = strings.ToUpper(periodType)
+	start, end, err := statementPeriod(periodType, year, number)
+	if err != nil {
+		return nil, err
+	}
+	if end.After(time.Now()) {
+		return nil, fmt.Errorf("%w: the period has not ended", ErrInvalidStatementPeriod)
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	statement := &models.AccountStatement{
+		UserID:        userID,
+		AccountNumber: user.AccountNumber,
+		PeriodType:    periodType,
+		PeriodStart:   start,
+		PeriodEnd:     end,
+		GeneratedAt:   time.Now(),
+		Deposits:      make(map[string]float64),
+		Withdrawals:   make(map[string]float64),
+		TransfersIn:   make(map[string]float64),
+		TransfersOut:  make(map[string]float64),
+		Income:        make(map[string]float64),
+		// No fees are charged on orders yet
+		Fees:   make(map[string]float64),
+		Trades: make([]models.StatementTrade, 0),
+	}
+
+	statement.OpeningCash, err = s.ledgerRepo.GetCashBalancesAsOf(userID, start.Add(-time.Nanosecond))
+	if err != nil {
+		return nil, err
+	}
+
+	orders, err := s.orderRepo.GetUserFilledOrdersAsOf(userID, end)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(orders, func(i, j int) bool {
+		return orders[i].DateTime.Before(orders[j].DateTime)
+	})
+
+	instruments := make(map[uint]*models.Instrument)
+	// Transfers between the user's own accounts do not change the statement
+	internal := internalTransfers(orders)
+	for _, order := range orders {
+		if order.DateTime.Before(start) || internal[order.TransferID] {
+			continue
+		}
+		currency := models.NormalizeCurrency(order.Currency)
+		switch order.Side {
+		case "CASH_IN":
+			statement.Deposits[currency] += order.Size
+		case "CASH_OUT":
+			statement.Withdrawals[currency] += order.Size
+		case "TRANSFER_IN":
+			statement.TransfersIn[currency] += order.Size
+		case "TRANSFER_OUT":
+			statement.TransfersOut[currency] += order.Size
+		case "DIVIDEND":
+			statement.Income[currency] += order.Size * order.Price
+		case "BUY", "SELL", "SECURITIES_IN", "SECURITIES_OUT":
+			instrument, ok := instruments[order.InstrumentID]
+			if !ok {
+				instrument, err = s.instrumentRepo.GetByID(order.InstrumentID)
+				if err != nil {
+					return nil, err
+				}
+				instruments[order.InstrumentID] = instrument
+			}
+			// Securities transferred in kind move no cash
+			amount := 0.0
+			switch order.Side {
+			case "BUY":
+				amount = -order.Size * order.Price
+			case "SELL":
+				amount = order.Size * order.Price
+			}
+			statement.Trades = append(statement.Trades, models.StatementTrade{
+				Date:     order.DateTime,
+				Ticker:   instrument.Ticker,
+				Side:     order.Side,
+				Quantity: order.Size,
+				Price:    order.Price,
+				Amount:   amount,
+				Currency: currency,
+			})
+		}
+	}
+
+	portfolio, err := s.portfolioService.GetPortfolioAsOf(userID, end, baseCurrency)
+	if err != nil {
+		return nil, err
+	}
+	statement.BaseCurrency = portfolio.BaseCurrency
+	statement.ClosingCash = portfolio.CashBalances
+	statement.Holdings = portfolio.Assets
+	statement.TotalValue = portfolio.TotalValue
+
+	statement.Performance, err = s.performanceService.GetPerformanceBetween(userID, start, end, statement.BaseCurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := json.Marshal(statement)
+	if err != nil {
+		return nil, err
+	}
+	stored := &models.Statement{
+		UserID:      userID,
+		PeriodType:  periodType,
+		PeriodStart: start,
+		PeriodEnd:   end,
+		GeneratedAt: statement.GeneratedAt,
+		Content:     string(content),
+	}
+	if err := s.statementRepo.Create(stored); err != nil {
+		return nil, err
+	}
+	statement.ID = stored.ID
+
+	return statement, nil
+}
+
+// GetStatements lists the statements generated for the user
+func (s *StatementService) GetStatements(userID uint) ([]models.Statement, error) {
+	return s.statementRepo.GetByUser(userID)
+}
+
+// GetStatement loads a stored statement
+func (s *StatementService) GetStatement(statementID uint) (*models.AccountStatement, error) {
+	stored, err := s.statementRepo.GetByID(statementID)
+	if err != nil {
+		return nil, err
+	}
+
+	var statement models.AccountStatement
+	if err := json.Unmarshal([]byte(stored.Content), &statement); err != nil {
+		return nil, fmt.Errorf("failed to read statement %d: %w", statementID, err)
+	}
+	statement.ID = stored.ID
+
+	return &statement, nil
+}
+
+// statementPeriod resolves a month or quarter of the year to its first and
+// last moment in UTC
+func statementPeriod(periodType string, year, number int) (time.Time, time.Time, error) {
+	var start time.Time
+	var months int
+	switch periodType {
+	case models.MonthlyStatement:
+		if number < 1 || number > 12 {
+			return time.Time{}, time.Time{}, fmt.Errorf("%w: month %d", ErrInvalidStatementPeriod, number)
+		}
+		start = time.Date(year, time.Month(number), 1, 0, 0, 0, 0, time.UTC)
+		months = 1
+	case models.QuarterlyStatement:
+		if number < 1 || number > 4 {
+			return time.Time{}, time.Time{}, fmt.Errorf("%w: quarter %d", ErrInvalidStatementPeriod, number)
+		}
+		start = time.Date(year, time.Month((number-1)*3+1), 1, 0, 0, 0, 0, time.UTC)
+		months = 3
+	default:
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: %s", ErrInvalidStatementPeriod, periodType)
+	}
+	return start, start.AddDate(0, months, 0).Add(-time.Nanosecond), nil
+}
+
+var statementTemplate = template.Must(template.New("statement").Funcs(template.FuncMap{
+	"amount": func(value float64) string { return fmt.Sprintf("%.2f", value) },
+	"date":   func(value time.Time) string { return value.Format("2006-01-02") },
+}).Parse(`<!DOCTYPE html>
+<html lang="es">
+<head>
+<meta charset="utf-8">
+<title>Resumen de cuenta {{.AccountNumber}} {{date .PeriodStart}} - {{date .PeriodEnd}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; width: 100%; margin-bottom: 1.5em; }
+th, td { border-bottom: 1px solid #ccc; padding: 4px 8px; text-align: left; }
+td.num, th.num { text-align: right; }
+@media print { body { margin: 0; } }
+</style>
+</head>
+<body>
+<h1>Resumen de cuenta</h1>
+<p>Cuenta {{.AccountNumber}} &middot; {{date .PeriodStart}} a {{date .PeriodEnd}} &middot; Generado {{date .GeneratedAt}}</p>
+
+<h2>Efectivo</h2>
+<table>
+<tr><th>Moneda</th><th class="num">Saldo inicial</th><th class="num">Depósitos</th><th class="num">Retiros</th><th class="num">Transferencias recibidas</th><th class="num">Transferencias enviadas</th><th class="num">Dividendos</th><th class="num">Comisiones</th><th class="num">Saldo final</th></tr>
+{{range $currency, $closing := .ClosingCash}}<tr><td>{{$currency}}</td><td class="num">{{amount (index $.OpeningCash $currency)}}</td><td class="num">{{amount (index $.Deposits $currency)}}</td><td class="num">{{amount (index $.Withdrawals $currency)}}</td><td class="num">{{amount (index $.TransfersIn $currency)}}</td><td class="num">{{amount (index $.TransfersOut $currency)}}</td><td class="num">{{amount (index $.Income $currency)}}</td><td class="num">{{amount (index $.Fees $currency)}}</td><td class="num">{{amount $closing}}</td></tr>
+{{end}}</table>
+
+<h2>Operaciones</h2>
+<table>
+<tr><th>Fecha</th><th>Instrumento</th><th>Operación</th><th class="num">Cantidad</th><th class="num">Precio</th><th class="num">Importe</th><th>Moneda</th></tr>
+{{range .Trades}}<tr><td>{{date .Date}}</td><td>{{.Ticker}}</td><td>{{.Side}}</td><td class="num">{{.Quantity}}</td><td class="num">{{amount .Price}}</td><td class="num">{{amount .Amount}}</td><td>{{.Currency}}</td></tr>
+{{else}}<tr><td colspan="7">Sin operaciones en el período</td></tr>
+{{end}}</table>
+
+<h2>Tenencias al cierre ({{.BaseCurrency}})</h2>
+<table>
+<tr><th>Instrumento</th><th class="num">Cantidad</th><th class="num">Último precio</th><th class="num">Valor</th><th class="num">Rendimiento %</th></tr>
+{{range .Holdings}}<tr><td>{{.Ticker}} - {{.Name}}</td><td class="num">{{.Quantity}}</td><td class="num">{{amount .LastPrice}} {{.Currency}}</td><td class="num">{{amount .TotalValue}}</td><td class="num">{{amount .Return}}</td></tr>
+{{end}}<tr><th colspan="3">Valor total</th><th class="num">{{amount .TotalValue}}</th><th></th></tr>
+</table>
+
+{{with .Performance}}<h2>Rendimiento del período</h2>
+<table>
+<tr><td>Ponderado por tiempo</td><td class="num">{{amount .TimeWeightedReturn}} %</td></tr>
+<tr><td>Ponderado por dinero (anual)</td><td class="num">{{amount .MoneyWeightedReturn}} %</td></tr>
+</table>
+{{end}}</body>
+</html>
+`))
+
+// RenderStatementHTML writes the statement as a printable HTML document
+func RenderStatementHTML(w io.Writer, statement *models.AccountStatement) error {
+	return statementTemplate.Execute(w, statement)
+}
@@ -0,0 +1,102 @@
+package service_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	mocks "github.com/NahuelDT/portfolio-api/internal/mocks/repository"
+	serviceMocks "github.com/NahuelDT/portfolio-api/internal/mocks/service"
+	"github.com/NahuelDT/portfolio-api/internal/models"
+	"github.com/NahuelDT/portfolio-api/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGenerateStatement(t *testing.T) {
+	mockUserRepo := new(mocks.UserRepositorer)
+	mockOrderRepo := new(mocks.OrderRepositorer)
+	mockInstrumentRepo := new(mocks.InstrumentRepositorer)
+	mockStatementRepo := new(mocks.StatementRepositorer)
+	mockPortfolioService := new(serviceMocks.PortfolioServicer)
+	mockPerformanceService := new(serviceMocks.PerformanceServicer)
+	mockLedgerRepo := new(mocks.LedgerRepositorer)
+
+	statementService := service.NewStatementService(mockUserRepo, mockOrderRepo, mockInstrumentRepo, mockStatementRepo, mockPortfolioService, mockPerformanceService, mockLedgerRepo)
+
+	t.Run("Monthly statement", func(t *testing.T) {
+		userID := uint(1)
+		start := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+		end := time.Date(2024, time.April, 1, 0, 0, 0, 0, time.UTC).Add(-time.Nanosecond)
+		mockOrders := []models.Order{
+			{ID: 1, UserID: userID, Side: "CASH_IN", Size: 5000, Status: "FILLED", DateTime: start.AddDate(0, -1, 0)},
+			{ID: 2, UserID: userID, Side: "CASH_IN", Size: 1000, Status: "FILLED", DateTime: start.AddDate(0, 0, 2)},
+			{ID: 3, InstrumentID: 1, UserID: userID, Side: "BUY", Size: 10, Price: 100, Status: "FILLED", Currency: "ARS", DateTime: start.AddDate(0, 0, 5)},
+			{ID: 4, UserID: userID, Side: "CASH_OUT", Size: 500, Status: "FILLED", DateTime: start.AddDate(0, 0, 20)},
+		}
+		mockPortfolio := &models.Portfolio{
+			BaseCurrency: "ARS",
+			TotalValue:   5600,
+			CashBalances: map[string]float64{"ARS": 4500},
+			Assets:       []models.PortfolioAsset{{Ticker: "AAPL", Quantity: 10, TotalValue: 1100}},
+		}
+		mockPerformance := &models.Performance{Period: "CUSTOM", TimeWeightedReturn: 1.5}
+
+		mockUserRepo.On("GetByID", userID).Return(&models.User{ID: userID, AccountNumber: "ACC-1"}, nil)
+		mockLedgerRepo.On("GetCashBalancesAsOf", userID, start.Add(-time.Nanosecond)).Return(map[string]float64{"ARS": 5000}, nil)
+		mockOrderRepo.On("GetUserFilledOrdersAsOf", userID, end).Return(mockOrders, nil)
+		mockInstrumentRepo.On("GetByID", uint(1)).Return(&models.Instrument{ID: 1, Ticker: "AAPL"}, nil)
+		mockPortfolioService.On("GetPortfolioAsOf", userID, end, "ARS").Return(mockPortfolio, nil)
+		mockPerformanceService.On("GetPerformanceBetween", userID, start, end, "ARS").Return(mockPerformance, nil)
+		mockStatementRepo.On("Create", mock.AnythingOfType("*models.Statement")).Run(func(args mock.Arguments) {
+			args.Get(0).(*models.Statement).ID = 7
+		}).Return(nil)
+
+		statement, err := statementService.GenerateStatement(userID, "monthly", 2024, 3, "ARS")
+
+		assert.NoError(t, err)
+		assert.Equal(t, uint(7), statement.ID)
+		assert.Equal(t, "ACC-1", statement.AccountNumber)
+		assert.Equal(t, models.MonthlyStatement, statement.PeriodType)
+		assert.Equal(t, start, statement.PeriodStart)
+		assert.Equal(t, end, statement.PeriodEnd)
+		assert.Equal(t, map[string]float64{"ARS": 5000}, statement.OpeningCash)
+		assert.Equal(t, map[string]float64{"ARS": 4500}, statement.ClosingCash)
+		assert.Equal(t, map[string]float64{"ARS": 1000}, statement.Deposits)
+		assert.Equal(t, map[string]float64{"ARS": 500}, statement.Withdrawals)
+		assert.Equal(t, []models.StatementTrade{
+			{Date: start.AddDate(0, 0, 5), Ticker: "AAPL", Side: "BUY", Quantity: 10, Price: 100, Amount: -1000, Currency: "ARS"},
+		}, statement.Trades)
+		assert.Equal(t, mockPortfolio.Assets, statement.Holdings)
+		assert.Equal(t, float64(5600), statement.TotalValue)
+		assert.Equal(t, mockPerformance, statement.Performance)
+
+		stored := mockStatementRepo.Calls[0].Arguments.Get(0).(*models.Statement)
+		var content models.AccountStatement
+		assert.NoError(t, json.Unmarshal([]byte(stored.Content), &content))
+		assert.Equal(t, statement.Deposits, content.Deposits)
+
+		var buf bytes.Buffer
+		assert.NoError(t, service.RenderStatementHTML(&buf, statement))
+		assert.Contains(t, buf.String(), "ACC-1")
+		assert.Contains(t, buf.String(), "<td>AAPL</td>")
+		assert.Contains(t, buf.String(), "5600.00")
+	})
+
+	t.Run("Period not ended", func(t *testing.T) {
+		now := time.Now()
+
+		statement, err := statementService.GenerateStatement(1, "quarterly", now.Year(), 4, "ARS")
+
+		assert.ErrorIs(t, err, service.ErrInvalidStatementPeriod)
+		assert.Nil(t, statement)
+	})
+
+	t.Run("Invalid month", func(t *testing.T) {
+		statement, err := statementService.GenerateStatement(1, "monthly", 2024, 13, "ARS")
+
+		assert.ErrorIs(t, err, service.ErrInvalidStatementPeriod)
+		assert.Nil(t, statement)
+	})
+}
@@ -0,0 +1,255 @@
+package service
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/NahuelDT/portfolio-api/internal/models"
+	"github.com/NahuelDT/portfolio-api/internal/repository"
+)
+
+type TaxService struct {
+	userRepo            repository.UserRepositorer
+	orderRepo           repository.OrderRepositorer
+	instrumentRepo      repository.InstrumentRepositorer
+	corporateActionRepo repository.CorporateActionRepositorer
+}
+
+func NewTaxService(
+	userRepo repository.UserRepositorer,
+	orderRepo repository.OrderRepositorer,
+	instrumentRepo repository.InstrumentRepositorer,
+	corporateActionRepo repository.CorporateActionRepositorer,
+) *TaxService {
+	return &TaxService{
+		userRepo:            userRepo,
+		orderRepo:           orderRepo,
+		instrumentRepo:      instrumentRepo,
+		corporateActionRepo: corporateActionRepo,
+	}
+}
+
+// taxLot is the unsold part of a filled BUY order
+type taxLot struct {
+	date     time.Time
+	quantity float64
+	price    float64
+}
+
+// lotKey identifies the lots of an instrument held in one of the user's
+// accounts
+type lotKey struct {
+	accountID    uint
+	instrumentID uint
+}
+
+// takeLots takes quantity out of the open lots first-in first-out and
+// returns the lots left and the ones taken
+func takeLots(open []taxLot, quantity float64) ([]taxLot, []taxLot) {
+	taken := make([]taxLot, 0)
+	for quantity > 0 && len(open) > 0 {
+		if open[0].quantity > quantity {
+			part := open[0]
+			part.quantity = quantity
+			taken = append(taken, part)
+			open[0].quantity -= quantity
+			return open, taken
+		}
+		quantity -= open[0].quantity
+		taken = append(taken, open[0])
+		open = open[1:]
+	}
+	return open, taken
+}
+
+// addLots adds lots moved from another account, keeping the open lots in
+// acquisition order
+func addLots(open, moved []taxLot) []taxLot {
+	open = append(append(make([]taxLot, 0, len(open)+len(moved)), open...), moved...)
+	sort.SliceStable(open, func(i, j int) bool {
+		return open[i].date.Before(open[j].date)
+	})
+	return open
+}
+
+// GetCapitalGains matches the user's filled SELL orders against the BUY lots
+// of the same account first-in first-out and reports the gains realized
+// during the year. Lots held for more than a year are long term. Securities
+// moved between the user's own accounts take their lots, with their cost and
+// acquisition date, to the receiving account. Securities received from
+// another user open a lot at the carried cost on the transfer date, and
+// securities sent to another user close lots without realizing a gain.
+func (s *TaxService) GetCapitalGains(userID uint, year int) (*models.CapitalGainsReport, error) {
+	if _, err := s.userRepo.GetByID(userID); err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	orders, err := s.orderRepo.GetUserFilledOrders(userID)
+	if err != nil {
+		return nil, err
+	}
+	// Lots are matched in post-split shares; the cost of each lot is kept
+	actions, err := loadCorporateActions(s.corporateActionRepo, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	orders = actions.adjustOrders(orders)
+	sort.SliceStable(orders, func(i, j int) bool {
+		return orders[i].DateTime.Before(orders[j].DateTime)
+	})
+
+	report := &models.CapitalGainsReport{
+		UserID: userID,
+		Year:   year,
+		Gains:  make([]models.RealizedGain, 0),
+		Totals: make([]models.CapitalGainsTotal, 0),
+	}
+
+	lots := make(map[lotKey][]taxLot)
+	instruments := make(map[uint]*models.Instrument)
+	internal := internalTransfers(orders)
+	// Lots of internal transfers whose credit has not been seen yet, and
+	// credits seen before their debit, by transfer
+	moving := make(map[uint][]taxLot)
+	receiving := make(map[uint]lotKey)
+	for _, order := range orders {
+		key := lotKey{accountID: order.AccountID, instrumentID: order.InstrumentID}
+
+		switch order.Side {
+		case "BUY":
+			lots[key] = append(lots[key], taxLot{date: order.DateTime, quantity: order.Size, price: order.Price})
+
+		case "SECURITIES_IN":
+			if !internal[order.TransferID] {
+				lots[key] = append(lots[key], taxLot{date: order.DateTime, quantity: order.Size, price: order.Price})
+			} else if moved, ok := moving[order.TransferID]; ok {
+				lots[key] = addLots(lots[key], moved)
+				delete(moving, order.TransferID)
+			} else {
+				receiving[order.TransferID] = key
+			}
+
+		case "SECURITIES_OUT":
+			var moved []taxLot
+			lots[key], moved = takeLots(lots[key], order.Size)
+			if !internal[order.TransferID] {
+				continue
+			}
+			if to, ok := receiving[order.TransferID]; ok {
+				lots[to] = addLots(lots[to], moved)
+				delete(receiving, order.TransferID)
+			} else {
+				moving[order.TransferID] = moved
+			}
+
+		case "SELL":
+			remaining := order.Size
+			open := lots[key]
+			for remaining > 0 && len(open) > 0 {
+				lot := &open[0]
+				quantity := remaining
+				if lot.quantity < quantity {
+					quantity = lot.quantity
+				}
+
+				if order.DateTime.Year() == year {
+					instrument, ok := instruments[order.InstrumentID]
+					if !ok {
+						instrument, err = s.instrumentRepo.GetByID(order.InstrumentID)
+						if err != nil {
+							return nil, err
+						}
+						instruments[order.InstrumentID] = instrument
+					}
+
+					gain := models.RealizedGain{
+						Ticker:          instrument.Ticker,
+						Name:            instrument.Name,
+						Currency:        models.NormalizeCurrency(instrument.Currency),
+						Quantity:        quantity,
+						AcquisitionDate: lot.date,
+						DisposalDate:    order.DateTime,
+						Proceeds:        quantity * order.Price,
+						CostBasis:       quantity * lot.price,
+						Term:            models.ShortTermGain,
+					}
+					gain.Gain = gain.Proceeds - gain.CostBasis
+					if order.DateTime.After(lot.date.AddDate(1, 0, 0)) {
+						gain.Term = models.LongTermGain
+					}
+					report.Gains = append(report.Gains, gain)
+				}
+
+				lot.quantity -= quantity
+				remaining -= quantity
+				if lot.quantity <= 0 {
+					open = open[1:]
+				}
+			}
+			lots[key] = open
+		}
+	}
+
+	totals := make(map[string]*models.CapitalGainsTotal)
+	for _, gain := range report.Gains {
+		total, ok := totals[gain.Currency]
+		if !ok {
+			total = &models.CapitalGainsTotal{Currency: gain.Currency}
+			totals[gain.Currency] = total
+		}
+		total.Proceeds += gain.Proceeds
+		total.CostBasis += gain.CostBasis
+		total.TotalGain += gain.Gain
+		if gain.Term == models.LongTermGain {
+			total.LongTermGain += gain.Gain
+		} else {
+			total.ShortTermGain += gain.Gain
+		}
+	}
+	for _, total := range totals {
+		report.Totals = append(report.Totals, *total)
+	}
+	sort.Slice(report.Totals, func(i, j int) bool {
+		return report.Totals[i].Currency < report.Totals[j].Currency
+	})
+
+	return report, nil
+}
+
+// WriteCapitalGainsCSV writes one row per realized gain of the report
+func WriteCapitalGainsCSV(w io.Writer, report *models.CapitalGainsReport) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{
+		"ticker", "name", "currency", "quantity", "acquisition_date", "disposal_date",
+		"proceeds", "cost_basis", "gain", "term",
+	}); err != nil {
+		return err
+	}
+
+	formatAmount := func(value float64) string {
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	}
+	for _, gain := range report.Gains {
+		if err := writer.Write([]string{
+			gain.Ticker,
+			gain.Name,
+			gain.Currency,
+			formatAmount(gain.Quantity),
+			gain.AcquisitionDate.Format("2006-01-02"),
+			gain.DisposalDate.Format("2006-01-02"),
+			formatAmount(gain.Proceeds),
+			formatAmount(gain.CostBasis),
+			formatAmount(gain.Gain),
+			gain.Term,
+		}); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
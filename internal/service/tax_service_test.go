@@ -0,0 +1,132 @@
+package service
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	mocks "github.com/NahuelDT/portfolio-api/internal/mocks/repository"
+	"github.com/NahuelDT/portfolio-api/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetCapitalGains(t *testing.T) {
+	mockUserRepo := new(mocks.UserRepositorer)
+	mockOrderRepo := new(mocks.OrderRepositorer)
+	mockInstrumentRepo := new(mocks.InstrumentRepositorer)
+	mockCorporateActionRepo := new(mocks.CorporateActionRepositorer)
+	mockCorporateActionRepo.On("GetEffectiveAsOf", mock.Anything).Return([]models.CorporateAction{}, nil)
+
+	taxService := NewTaxService(mockUserRepo, mockOrderRepo, mockInstrumentRepo, mockCorporateActionRepo)
+
+	date := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 15, 0, 0, 0, time.UTC)
+	}
+
+	userID := uint(1)
+	mockOrders := []models.Order{
+		{ID: 4, InstrumentID: 1, UserID: userID, Side: "SELL", Size: 10, Price: 150, Status: "FILLED", DateTime: date(2024, time.February, 1)},
+		{ID: 1, InstrumentID: 1, UserID: userID, Side: "BUY", Size: 10, Price: 100, Status: "FILLED", DateTime: date(2022, time.March, 1)},
+		{ID: 3, InstrumentID: 1, UserID: userID, Side: "BUY", Size: 10, Price: 120, Status: "FILLED", DateTime: date(2023, time.June, 1)},
+		{ID: 2, InstrumentID: 1, UserID: userID, Side: "SELL", Size: 5, Price: 130, Status: "FILLED", DateTime: date(2023, time.January, 10)},
+	}
+
+	mockUserRepo.On("GetByID", userID).Return(&models.User{ID: userID}, nil)
+	mockOrderRepo.On("GetUserFilledOrders", userID).Return(mockOrders, nil)
+	mockInstrumentRepo.On("GetByID", uint(1)).Return(&models.Instrument{ID: 1, Ticker: "AAPL", Name: "Apple Inc."}, nil)
+
+	t.Run("FIFO lots for the year", func(t *testing.T) {
+		report, err := taxService.GetCapitalGains(userID, 2024)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []models.RealizedGain{
+			{
+				Ticker: "AAPL", Name: "Apple Inc.", Currency: "ARS", Quantity: 5,
+				AcquisitionDate: date(2022, time.March, 1), DisposalDate: date(2024, time.February, 1),
+				Proceeds: 750, CostBasis: 500, Gain: 250, Term: models.LongTermGain,
+			},
+			{
+				Ticker: "AAPL", Name: "Apple Inc.", Currency: "ARS", Quantity: 5,
+				AcquisitionDate: date(2023, time.June, 1), DisposalDate: date(2024, time.February, 1),
+				Proceeds: 750, CostBasis: 600, Gain: 150, Term: models.ShortTermGain,
+			},
+		}, report.Gains)
+		assert.Equal(t, []models.CapitalGainsTotal{
+			{Currency: "ARS", Proceeds: 1500, CostBasis: 1100, ShortTermGain: 150, LongTermGain: 250, TotalGain: 400},
+		}, report.Totals)
+	})
+
+	t.Run("CSV export", func(t *testing.T) {
+		report, err := taxService.GetCapitalGains(userID, 2023)
+		assert.NoError(t, err)
+
+		var buf bytes.Buffer
+		err = WriteCapitalGainsCSV(&buf, report)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "ticker,name,currency,quantity,acquisition_date,disposal_date,proceeds,cost_basis,gain,term\n"+
+			"AAPL,Apple Inc.,ARS,5,2022-03-01,2023-01-10,650,500,150,SHORT\n", buf.String())
+	})
+	t.Run("Transfers in kind", func(t *testing.T) {
+		transferUserID := uint(2)
+		mockUserRepo.On("GetByID", transferUserID).Return(&models.User{ID: transferUserID}, nil)
+		mockOrderRepo.On("GetUserFilledOrders", transferUserID).Return([]models.Order{
+			{ID: 5, InstrumentID: 1, UserID: transferUserID, Side: "BUY", Size: 10, Price: 100, Status: "FILLED", DateTime: date(2022, time.March, 1)},
+			// Moved to another of the user's accounts, the lot keeps its date
+			{ID: 6, InstrumentID: 1, UserID: transferUserID, Side: "SECURITIES_OUT", Size: 10, Price: 100, Status: "FILLED", DateTime: date(2024, time.January, 5), TransferID: 1},
+			{ID: 7, InstrumentID: 1, UserID: transferUserID, AccountID: 3, Side: "SECURITIES_IN", Size: 10, Price: 100, Status: "FILLED", DateTime: date(2024, time.January, 5), TransferID: 1},
+			// Given to another user, without a gain
+			{ID: 8, InstrumentID: 1, UserID: transferUserID, AccountID: 3, Side: "SECURITIES_OUT", Size: 4, Price: 100, Status: "FILLED", DateTime: date(2024, time.January, 6), TransferID: 2},
+			// Received from another user at the carried cost
+			{ID: 9, InstrumentID: 1, UserID: transferUserID, AccountID: 3, Side: "SECURITIES_IN", Size: 2, Price: 90, Status: "FILLED", DateTime: date(2024, time.January, 7), TransferID: 3},
+			{ID: 10, InstrumentID: 1, UserID: transferUserID, AccountID: 3, Side: "SELL", Size: 8, Price: 150, Status: "FILLED", DateTime: date(2024, time.February, 1)},
+		}, nil)
+
+		report, err := taxService.GetCapitalGains(transferUserID, 2024)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []models.RealizedGain{
+			{
+				Ticker: "AAPL", Name: "Apple Inc.", Currency: "ARS", Quantity: 6,
+				AcquisitionDate: date(2022, time.March, 1), DisposalDate: date(2024, time.February, 1),
+				Proceeds: 900, CostBasis: 600, Gain: 300, Term: models.LongTermGain,
+			},
+			{
+				Ticker: "AAPL", Name: "Apple Inc.", Currency: "ARS", Quantity: 2,
+				AcquisitionDate: date(2024, time.January, 7), DisposalDate: date(2024, time.February, 1),
+				Proceeds: 300, CostBasis: 180, Gain: 120, Term: models.ShortTermGain,
+			},
+		}, report.Gains)
+	})
+
+	t.Run("Lots are matched within each account", func(t *testing.T) {
+		accountsUserID := uint(3)
+		mockUserRepo.On("GetByID", accountsUserID).Return(&models.User{ID: accountsUserID}, nil)
+		mockOrderRepo.On("GetUserFilledOrders", accountsUserID).Return([]models.Order{
+			{ID: 11, InstrumentID: 1, UserID: accountsUserID, Side: "BUY", Size: 10, Price: 100, Status: "FILLED", DateTime: date(2022, time.March, 1)},
+			{ID: 12, InstrumentID: 1, UserID: accountsUserID, AccountID: 4, Side: "BUY", Size: 10, Price: 200, Status: "FILLED", DateTime: date(2023, time.June, 1)},
+			// Part of the oldest lot moves to account 4, credit listed first
+			{ID: 14, InstrumentID: 1, UserID: accountsUserID, AccountID: 4, Side: "SECURITIES_IN", Size: 4, Price: 100, Status: "FILLED", DateTime: date(2023, time.September, 1), TransferID: 5},
+			{ID: 13, InstrumentID: 1, UserID: accountsUserID, Side: "SECURITIES_OUT", Size: 4, Price: 100, Status: "FILLED", DateTime: date(2023, time.September, 1), TransferID: 5},
+			// Across accounts, FIFO would sell the 10 of the main account first
+			{ID: 15, InstrumentID: 1, UserID: accountsUserID, AccountID: 4, Side: "SELL", Size: 12, Price: 250, Status: "FILLED", DateTime: date(2024, time.February, 1)},
+		}, nil)
+
+		report, err := taxService.GetCapitalGains(accountsUserID, 2024)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []models.RealizedGain{
+			{
+				Ticker: "AAPL", Name: "Apple Inc.", Currency: "ARS", Quantity: 4,
+				AcquisitionDate: date(2022, time.March, 1), DisposalDate: date(2024, time.February, 1),
+				Proceeds: 1000, CostBasis: 400, Gain: 600, Term: models.LongTermGain,
+			},
+			{
+				Ticker: "AAPL", Name: "Apple Inc.", Currency: "ARS", Quantity: 8,
+				AcquisitionDate: date(2023, time.June, 1), DisposalDate: date(2024, time.February, 1),
+				Proceeds: 2000, CostBasis: 1600, Gain: 400, Term: models.ShortTermGain,
+			},
+		}, report.Gains)
+	})
+}
@@ -0,0 +1,137 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/NahuelDT/portfolio-api/internal/models"
+	"github.com/NahuelDT/portfolio-api/internal/repository"
+)
+
+var ErrInvalidTransfer = errors.New("invalid transfer")
+
+type TransferService struct {
+	transferRepo   repository.TransferRepositorer
+	userRepo       repository.UserRepositorer
+	accountRepo    repository.AccountRepositorer
+	instrumentRepo repository.InstrumentRepositorer
+}
+
+func NewTransferService(
+	transferRepo repository.TransferRepositorer,
+	userRepo repository.UserRepositorer,
+	accountRepo repository.AccountRepositorer,
+	instrumentRepo repository.InstrumentRepositorer,
+) *TransferService {
+	return &TransferService{
+		transferRepo:   transferRepo,
+		userRepo:       userRepo,
+		accountRepo:    accountRepo,
+		instrumentRepo: instrumentRepo,
+	}
+}
+
+// Transfer validates and applies a transfer of cash, or of securities in
+// kind when it has an instrument, between two accounts of the same or
+// different users
+func (s *TransferService) Transfer(transfer *models.Transfer) error {
+	transfer.Note = strings.TrimSpace(transfer.Note)
+	if transfer.FromUserID == transfer.ToUserID && transfer.FromAccountID == transfer.ToAccountID {
+		return fmt.Errorf("%w: source and destination are the same account", ErrInvalidTransfer)
+	}
+
+	size := transfer.Amount
+	if transfer.InstrumentID != 0 {
+		transfer.Kind = models.SecuritiesTransfer
+		if transfer.Quantity <= 0 {
+			return fmt.Errorf("%w: quantity must be positive", ErrInvalidTransfer)
+		}
+		instrument, err := s.instrumentRepo.GetByID(transfer.InstrumentID)
+		if err != nil {
+			return fmt.Errorf("%w: instrument %d does not exist", ErrInvalidTransfer, transfer.InstrumentID)
+		}
+		transfer.Currency = models.NormalizeCurrency(instrument.Currency)
+		transfer.Amount = 0
+		size = transfer.Quantity
+	} else {
+		transfer.Kind = models.CashTransfer
+		if transfer.Amount <= 0 {
+			return fmt.Errorf("%w: amount must be positive", ErrInvalidTransfer)
+		}
+		transfer.Currency = models.NormalizeCurrency(transfer.Currency)
+		transfer.Quantity = 0
+	}
+
+	if _, err := s.userRepo.GetByID(transfer.FromUserID); err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if _, err := s.userRepo.GetByID(transfer.ToUserID); err != nil {
+		return fmt.Errorf("%w: user %d does not exist", ErrInvalidTransfer, transfer.ToUserID)
+	}
+	if err := checkAccount(s.accountRepo, transfer.FromUserID, transfer.FromAccountID); err != nil {
+		return err
+	}
+	if err := checkAccount(s.accountRepo, transfer.ToUserID, transfer.ToAccountID); err != nil {
+		return err
+	}
+
+	transfer.ID = 0
+	transfer.CreatedAt = time.Now()
+	debit := models.Order{
+		InstrumentID:   transfer.InstrumentID,
+		UserID:         transfer.FromUserID,
+		AccountID:      transfer.FromAccountID,
+		Side:           "TRANSFER_OUT",
+		Size:           size,
+		Type:           "MARKET",
+		Status:         "FILLED",
+		Currency:       transfer.Currency,
+		DateTime:       transfer.CreatedAt,
+		SettlementDate: transfer.CreatedAt,
+	}
+	credit := debit
+	credit.UserID = transfer.ToUserID
+	credit.AccountID = transfer.ToAccountID
+	credit.Side = "TRANSFER_IN"
+	if transfer.Kind == models.SecuritiesTransfer {
+		debit.Side = "SECURITIES_OUT"
+		credit.Side = "SECURITIES_IN"
+	}
+
+	if err := s.transferRepo.Create(transfer, &debit, &credit); err != nil {
+		if errors.Is(err, repository.ErrInsufficientFunds) || errors.Is(err, repository.ErrInsufficientPosition) {
+			return fmt.Errorf("%w: %v", ErrInvalidTransfer, err)
+		}
+		return err
+	}
+	return nil
+}
+
+// GetTransfers lists the transfers the user sent or received
+func (s *TransferService) GetTransfers(userID uint) ([]models.Transfer, error) {
+	if _, err := s.userRepo.GetByID(userID); err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	return s.transferRepo.GetByUser(userID)
+}
+
+// internalTransfers returns the transfers with both their orders among the
+// given ones, which move holdings between accounts of the same user and
+// leave the user's consolidated holdings unchanged
+func internalTransfers(orders []models.Order) map[uint]bool {
+	legs := make(map[uint]int)
+	for _, order := range orders {
+		if order.TransferID != 0 {
+			legs[order.TransferID]++
+		}
+	}
+	internal := make(map[uint]bool)
+	for transferID, count := range legs {
+		if count == 2 {
+			internal[transferID] = true
+		}
+	}
+	return internal
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	mocks "github.com/NahuelDT/portfolio-api/internal/mocks/repository"
+	"github.com/NahuelDT/portfolio-api/internal/models"
+	"github.com/NahuelDT/portfolio-api/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestTransfers(t *testing.T) {
+	setUp := func() (*mocks.TransferRepositorer, *mocks.UserRepositorer, *mocks.AccountRepositorer, *mocks.InstrumentRepositorer, *TransferService) {
+		mockTransferRepo := new(mocks.TransferRepositorer)
+		mockUserRepo := new(mocks.UserRepositorer)
+		mockAccountRepo := new(mocks.AccountRepositorer)
+		mockInstrumentRepo := new(mocks.InstrumentRepositorer)
+		transferService := NewTransferService(mockTransferRepo, mockUserRepo, mockAccountRepo, mockInstrumentRepo)
+		return mockTransferRepo, mockUserRepo, mockAccountRepo, mockInstrumentRepo, transferService
+	}
+
+	t.Run("Transfer cash to another user", func(t *testing.T) {
+		mockTransferRepo, mockUserRepo, mockAccountRepo, _, transferService := setUp()
+
+		mockUserRepo.On("GetByID", uint(1)).Return(&models.User{ID: 1}, nil)
+		mockUserRepo.On("GetByID", uint(2)).Return(&models.User{ID: 2}, nil)
+		mockAccountRepo.On("GetByID", uint(5)).Return(&models.Account{ID: 5, UserID: 2, Name: "Familia"}, nil)
+
+		var debit, credit *models.Order
+		mockTransferRepo.On("Create", mock.AnythingOfType("*models.Transfer"), mock.AnythingOfType("*models.Order"), mock.AnythingOfType("*models.Order")).
+			Run(func(args mock.Arguments) {
+				debit = args.Get(1).(*models.Order)
+				credit = args.Get(2).(*models.Order)
+			}).
+			Return(nil)
+
+		transfer := &models.Transfer{FromUserID: 1, ToUserID: 2, ToAccountID: 5, Currency: "usd", Amount: 150, Note: " Cuota "}
+		err := transferService.Transfer(transfer)
+
+		assert.NoError(t, err)
+		assert.Equal(t, models.CashTransfer, transfer.Kind)
+		assert.Equal(t, "USD", transfer.Currency)
+		assert.Equal(t, "Cuota", transfer.Note)
+		assert.Equal(t, "TRANSFER_OUT", debit.Side)
+		assert.Equal(t, uint(1), debit.UserID)
+		assert.Equal(t, models.MainAccountID, debit.AccountID)
+		assert.Equal(t, "TRANSFER_IN", credit.Side)
+		assert.Equal(t, uint(2), credit.UserID)
+		assert.Equal(t, uint(5), credit.AccountID)
+		for _, order := range []*models.Order{debit, credit} {
+			assert.Equal(t, float64(150), order.Size)
+			assert.Equal(t, "USD", order.Currency)
+			assert.Equal(t, "FILLED", order.Status)
+			assert.Equal(t, transfer.CreatedAt, order.DateTime)
+		}
+	})
+
+	t.Run("Transfer securities in kind", func(t *testing.T) {
+		mockTransferRepo, mockUserRepo, mockAccountRepo, mockInstrumentRepo, transferService := setUp()
+
+		mockUserRepo.On("GetByID", uint(1)).Return(&models.User{ID: 1}, nil)
+		mockAccountRepo.On("GetByID", uint(3)).Return(&models.Account{ID: 3, UserID: 1, Name: "Retiro"}, nil)
+		mockInstrumentRepo.On("GetByID", uint(7)).Return(&models.Instrument{ID: 7, Ticker: "SPY", Currency: "USD"}, nil)
+
+		var debit, credit *models.Order
+		mockTransferRepo.On("Create", mock.AnythingOfType("*models.Transfer"), mock.AnythingOfType("*models.Order"), mock.AnythingOfType("*models.Order")).
+			Run(func(args mock.Arguments) {
+				debit = args.Get(1).(*models.Order)
+				credit = args.Get(2).(*models.Order)
+			}).
+			Return(nil)
+
+		transfer := &models.Transfer{FromUserID: 1, ToUserID: 1, ToAccountID: 3, InstrumentID: 7, Quantity: 4, Amount: 99}
+		err := transferService.Transfer(transfer)
+
+		assert.NoError(t, err)
+		assert.Equal(t, models.SecuritiesTransfer, transfer.Kind)
+		assert.Equal(t, "USD", transfer.Currency)
+		assert.Zero(t, transfer.Amount)
+		assert.Equal(t, "SECURITIES_OUT", debit.Side)
+		assert.Equal(t, "SECURITIES_IN", credit.Side)
+		assert.Equal(t, uint(7), credit.InstrumentID)
+		assert.Equal(t, float64(4), credit.Size)
+	})
+
+	t.Run("Reject invalid transfers", func(t *testing.T) {
+		mockTransferRepo, mockUserRepo, mockAccountRepo, mockInstrumentRepo, transferService := setUp()
+
+		mockUserRepo.On("GetByID", uint(1)).Return(&models.User{ID: 1}, nil)
+		mockUserRepo.On("GetByID", uint(9)).Return(nil, errors.New("record not found"))
+		mockAccountRepo.On("GetByID", uint(2)).Return(&models.Account{ID: 2, UserID: 1}, nil)
+		mockAccountRepo.On("GetByID", uint(3)).Return(&models.Account{ID: 3, UserID: 8}, nil)
+		mockInstrumentRepo.On("GetByID", uint(4)).Return(nil, errors.New("record not found"))
+		mockTransferRepo.On("Create", mock.Anything, mock.Anything, mock.Anything).
+			Return(fmt.Errorf("%w: balance is 10 ARS", repository.ErrInsufficientFunds))
+
+		err := transferService.Transfer(&models.Transfer{FromUserID: 1, ToUserID: 1, ToAccountID: 2, Amount: 0})
+		assert.ErrorIs(t, err, ErrInvalidTransfer)
+
+		err = transferService.Transfer(&models.Transfer{FromUserID: 1, FromAccountID: 2, ToUserID: 1, ToAccountID: 2, Amount: 10})
+		assert.ErrorIs(t, err, ErrInvalidTransfer)
+
+		err = transferService.Transfer(&models.Transfer{FromUserID: 1, ToUserID: 9, Amount: 10})
+		assert.ErrorIs(t, err, ErrInvalidTransfer)
+
+		err = transferService.Transfer(&models.Transfer{FromUserID: 1, ToUserID: 1, ToAccountID: 3, Amount: 10})
+		assert.ErrorIs(t, err, ErrInvalidAccount)
+
+		err = transferService.Transfer(&models.Transfer{FromUserID: 1, ToUserID: 1, ToAccountID: 2, InstrumentID: 4, Quantity: 1})
+		assert.ErrorIs(t, err, ErrInvalidTransfer)
+
+		err = transferService.Transfer(&models.Transfer{FromUserID: 1, ToUserID: 1, ToAccountID: 2, Amount: 100})
+		assert.ErrorIs(t, err, ErrInvalidTransfer)
+	})
+
+	t.Run("Securities transfers move positions between accounts", func(t *testing.T) {
+		now := time.Now()
+		orders := []models.Order{
+			{ID: 1, UserID: 1, InstrumentID: 7, Side: "BUY", Size: 10, Price: 100, DateTime: now.AddDate(-2, 0, 0)},
+			{ID: 2, UserID: 1, InstrumentID: 7, Side: "SECURITIES_OUT", Size: 4, Price: 100, DateTime: now, TransferID: 1},
+			{ID: 3, UserID: 1, AccountID: 3, InstrumentID: 7, Side: "SECURITIES_IN", Size: 4, Price: 100, DateTime: now, TransferID: 1},
+			{ID: 4, UserID: 1, InstrumentID: 7, Side: "SECURITIES_OUT", Size: 2, Price: 100, DateTime: now, TransferID: 2},
+		}
+
+		assert.Equal(t, map[uint]bool{1: true}, internalTransfers(orders))
+
+		// Only the purchase moves cash
+		positions, cash := replayBalances(1, orders)
+		assert.Equal(t, []models.CashBalance{{UserID: 1, Currency: "ARS", Balance: -1000}}, cash)
+		assert.Equal(t, []models.Position{
+			{UserID: 1, InstrumentID: 7, Quantity: 4, BoughtQuantity: 10, BoughtCost: 1000},
+			{UserID: 1, AccountID: 3, InstrumentID: 7, Quantity: 4, BoughtQuantity: 4, BoughtCost: 400},
+		}, positions)
+	})
+}
+
+func TestGetTransfers(t *testing.T) {
+	mockTransferRepo := new(mocks.TransferRepositorer)
+	mockUserRepo := new(mocks.UserRepositorer)
+	transferService := NewTransferService(mockTransferRepo, mockUserRepo, nil, nil)
+
+	mockUserRepo.On("GetByID", uint(1)).Return(&models.User{ID: 1}, nil)
+	mockTransferRepo.On("GetByUser", uint(1)).Return([]models.Transfer{
+		{ID: 2, Kind: models.CashTransfer, FromUserID: 2, ToUserID: 1, Currency: "ARS", Amount: 50},
+		{ID: 1, Kind: models.CashTransfer, FromUserID: 1, ToUserID: 2, Currency: "ARS", Amount: 100},
+	}, nil)
+
+	transfers, err := transferService.GetTransfers(1)
+
+	assert.NoError(t, err)
+	assert.Len(t, transfers, 2)
+	mockTransferRepo.AssertExpectations(t)
+}
@@ -0,0 +1,244 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/NahuelDT/portfolio-api/internal/models"
+	"github.com/NahuelDT/portfolio-api/internal/repository"
+)
+
+var (
+	ErrInvalidWatchlist     = errors.New("invalid watchlist")
+	ErrWatchlistFull        = fmt.Errorf("%w: a watchlist can hold at most %d instruments", ErrInvalidWatchlist, models.MaxWatchlistSize)
+	ErrInvalidWatchlistSort = errors.New("invalid watchlist sort")
+)
+
+// watchlistSorts maps each supported sortBy value to the ordering of the
+// quotes it applies. The default, "position", keeps the user's own order.
+var watchlistSorts = map[string]func(a, b models.WatchlistQuote) bool{
+	"position": nil,
+	"ticker":   func(a, b models.WatchlistQuote) bool { return a.Ticker < b.Ticker },
+	"price":    func(a, b models.WatchlistQuote) bool { return a.Close < b.Close },
+	"change":   func(a, b models.WatchlistQuote) bool { return a.DayChangePercent < b.DayChangePercent },
+}
+
+type WatchlistService struct {
+	watchlistRepo  repository.WatchlistRepositorer
+	userRepo       repository.UserRepositorer
+	instrumentRepo repository.InstrumentRepositorer
+	marketDataRepo repository.MarketDataRepositorer
+}
+
+func NewWatchlistService(
+	watchlistRepo repository.WatchlistRepositorer,
+	userRepo repository.UserRepositorer,
+	instrumentRepo repository.InstrumentRepositorer,
+	marketDataRepo repository.MarketDataRepositorer,
+) *WatchlistService {
+	return &WatchlistService{
+		watchlistRepo:  watchlistRepo,
+		userRepo:       userRepo,
+		instrumentRepo: instrumentRepo,
+		marketDataRepo: marketDataRepo,
+	}
+}
+
+// CreateWatchlist validates and stores a new watchlist for the user
+func (s *WatchlistService) CreateWatchlist(watchlist *models.Watchlist) error {
+	if _, err := s.userRepo.GetByID(watchlist.UserID); err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if err := s.validate(watchlist); err != nil {
+		return err
+	}
+	return s.watchlistRepo.Create(watchlist)
+}
+
+// GetWatchlists lists the user's watchlists
+func (s *WatchlistService) GetWatchlists(userID uint) ([]models.Watchlist, error) {
+	return s.watchlistRepo.GetByUser(userID)
+}
+
+// GetWatchlist loads a watchlist with its instruments
+func (s *WatchlistService) GetWatchlist(watchlistID uint) (*models.Watchlist, error) {
+	return s.watchlistRepo.GetByID(watchlistID)
+}
+
+// UpdateWatchlist renames the watchlist and replaces its instruments
+func (s *WatchlistService) UpdateWatchlist(watchlistID uint, name string, instrumentIDs []uint) (*models.Watchlist, error) {
+	watchlist, err := s.watchlistRepo.GetByID(watchlistID)
+	if err != nil {
+		return nil, err
+	}
+
+	watchlist.Name = name
+	watchlist.InstrumentIDs = instrumentIDs
+	if err := s.validate(watchlist); err != nil {
+		return nil, err
+	}
+	if err := s.watchlistRepo.Update(watchlist); err != nil {
+		return nil, err
+	}
+	return watchlist, nil
+}
+
+// AddInstrument appends an instrument to the end of the watchlist. Adding
+// an instrument that is already in the list leaves it unchanged.
+func (s *WatchlistService) AddInstrument(watchlistID, instrumentID uint) (*models.Watchlist, error) {
+	watchlist, err := s.watchlistRepo.GetByID(watchlistID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, id := range watchlist.InstrumentIDs {
+		if id == instrumentID {
+			return watchlist, nil
+		}
+	}
+	watchlist.InstrumentIDs = append(watchlist.InstrumentIDs, instrumentID)
+	if err := s.validate(watchlist); err != nil {
+		return nil, err
+	}
+	if err := s.watchlistRepo.Update(watchlist); err != nil {
+		return nil, err
+	}
+	return watchlist, nil
+}
+
+// RemoveInstrument takes an instrument out of the watchlist
+func (s *WatchlistService) RemoveInstrument(watchlistID, instrumentID uint) (*models.Watchlist, error) {
+	watchlist, err := s.watchlistRepo.GetByID(watchlistID)
+	if err != nil {
+		return nil, err
+	}
+
+	remaining := make([]uint, 0, len(watchlist.InstrumentIDs))
+	for _, id := range watchlist.InstrumentIDs {
+		if id != instrumentID {
+			remaining = append(remaining, id)
+		}
+	}
+	if len(remaining) == len(watchlist.InstrumentIDs) {
+		return nil, fmt.Errorf("%w: instrument %d is not in the watchlist", ErrInvalidWatchlist, instrumentID)
+	}
+	watchlist.InstrumentIDs = remaining
+	if err := s.watchlistRepo.Update(watchlist); err != nil {
+		return nil, err
+	}
+	return watchlist, nil
+}
+
+// DeleteWatchlist removes the watchlist
+func (s *WatchlistService) DeleteWatchlist(watchlistID uint) error {
+	return s.watchlistRepo.Delete(watchlistID)
+}
+
+// GetQuotes returns the latest close, previous close and day change of every
+// instrument in the watchlist, sorted by sortBy in ascending or descending
+// order. Instruments and prices are each fetched in a single query.
+func (s *WatchlistService) GetQuotes(watchlistID uint, sortBy, order string) (*models.WatchlistSnapshot, error) {
+	sortBy = strings.ToLower(sortBy)
+	less, ok := watchlistSorts[sortBy]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidWatchlistSort, sortBy)
+	}
+	order = strings.ToLower(order)
+	if order != "asc" && order != "desc" {
+		return nil, fmt.Errorf("%w: order %q, expected asc or desc", ErrInvalidWatchlistSort, order)
+	}
+
+	watchlist, err := s.watchlistRepo.GetByID(watchlistID)
+	if err != nil {
+		return nil, err
+	}
+	instruments, err := s.instrumentRepo.GetByIDs(watchlist.InstrumentIDs)
+	if err != nil {
+		return nil, err
+	}
+	marketData, err := s.marketDataRepo.GetLatestMarketDataForInstruments(watchlist.InstrumentIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &models.WatchlistSnapshot{
+		ID:     watchlist.ID,
+		UserID: watchlist.UserID,
+		Name:   watchlist.Name,
+		SortBy: sortBy,
+		Order:  order,
+		Quotes: make([]models.WatchlistQuote, 0, len(watchlist.InstrumentIDs)),
+	}
+	for _, instrumentID := range watchlist.InstrumentIDs {
+		instrument, ok := instruments[instrumentID]
+		if !ok {
+			return nil, fmt.Errorf("instrument with ID %d not found", instrumentID)
+		}
+
+		quote := models.WatchlistQuote{
+			InstrumentID: instrumentID,
+			Ticker:       instrument.Ticker,
+			Name:         instrument.Name,
+			Currency:     models.NormalizeCurrency(instrument.Currency),
+		}
+		if data, ok := marketData[instrumentID]; ok {
+			priceTime := data.DateTime
+			quote.Close = data.Close
+			quote.PreviousClose = data.PreviousClose
+			quote.DayChange = data.Close - data.PreviousClose
+			if data.PreviousClose != 0 {
+				quote.DayChangePercent = quote.DayChange / data.PreviousClose * 100
+			}
+			quote.PriceTime = &priceTime
+		}
+		snapshot.Quotes = append(snapshot.Quotes, quote)
+	}
+
+	if less != nil {
+		sort.SliceStable(snapshot.Quotes, func(i, j int) bool {
+			return less(snapshot.Quotes[i], snapshot.Quotes[j])
+		})
+	}
+	if order == "desc" {
+		for i, j := 0, len(snapshot.Quotes)-1; i < j; i, j = i+1, j-1 {
+			snapshot.Quotes[i], snapshot.Quotes[j] = snapshot.Quotes[j], snapshot.Quotes[i]
+		}
+	}
+
+	return snapshot, nil
+}
+
+// validate checks the name and size of the watchlist and that every
+// instrument exists. Repeated instruments are dropped, keeping the first.
+func (s *WatchlistService) validate(watchlist *models.Watchlist) error {
+	watchlist.Name = strings.TrimSpace(watchlist.Name)
+	if watchlist.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidWatchlist)
+	}
+
+	seen := make(map[uint]bool, len(watchlist.InstrumentIDs))
+	instrumentIDs := make([]uint, 0, len(watchlist.InstrumentIDs))
+	for _, instrumentID := range watchlist.InstrumentIDs {
+		if !seen[instrumentID] {
+			seen[instrumentID] = true
+			instrumentIDs = append(instrumentIDs, instrumentID)
+		}
+	}
+	watchlist.InstrumentIDs = instrumentIDs
+	if len(instrumentIDs) > models.MaxWatchlistSize {
+		return ErrWatchlistFull
+	}
+
+	instruments, err := s.instrumentRepo.GetByIDs(instrumentIDs)
+	if err != nil {
+		return err
+	}
+	for _, instrumentID := range instrumentIDs {
+		if _, ok := instruments[instrumentID]; !ok {
+			return fmt.Errorf("%w: instrument %d does not exist", ErrInvalidWatchlist, instrumentID)
+		}
+	}
+	return nil
+}
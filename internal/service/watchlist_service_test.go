@@ -0,0 +1,150 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	mocks "github.com/NahuelDT/portfolio-api/internal/mocks/repository"
+	"github.com/NahuelDT/portfolio-api/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestWatchlists(t *testing.T) {
+	setUp := func() (*mocks.WatchlistRepositorer, *mocks.UserRepositorer, *mocks.InstrumentRepositorer, *mocks.MarketDataRepositorer, *WatchlistService) {
+		mockWatchlistRepo := new(mocks.WatchlistRepositorer)
+		mockUserRepo := new(mocks.UserRepositorer)
+		mockInstrumentRepo := new(mocks.InstrumentRepositorer)
+		mockMarketDataRepo := new(mocks.MarketDataRepositorer)
+		watchlistService := NewWatchlistService(mockWatchlistRepo, mockUserRepo, mockInstrumentRepo, mockMarketDataRepo)
+		return mockWatchlistRepo, mockUserRepo, mockInstrumentRepo, mockMarketDataRepo, watchlistService
+	}
+
+	instruments := map[uint]*models.Instrument{
+		1: {ID: 1, Ticker: "AAPL", Name: "Apple Inc.", Currency: "USD"},
+		2: {ID: 2, Ticker: "GGAL", Name: "Grupo Galicia"},
+		3: {ID: 3, Ticker: "YPFD", Name: "YPF"},
+	}
+
+	t.Run("Create a watchlist dropping repeated instruments", func(t *testing.T) {
+		mockWatchlistRepo, mockUserRepo, mockInstrumentRepo, _, watchlistService := setUp()
+
+		watchlist := &models.Watchlist{UserID: 1, Name: "  Tech  ", InstrumentIDs: []uint{1, 2, 1}}
+
+		mockUserRepo.On("GetByID", uint(1)).Return(&models.User{ID: 1}, nil)
+		mockInstrumentRepo.On("GetByIDs", []uint{1, 2}).Return(instruments, nil)
+		mockWatchlistRepo.On("Create", watchlist).Return(nil)
+
+		err := watchlistService.CreateWatchlist(watchlist)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Tech", watchlist.Name)
+		assert.Equal(t, []uint{1, 2}, watchlist.InstrumentIDs)
+		mockWatchlistRepo.AssertExpectations(t)
+	})
+
+	t.Run("Reject unknown instruments", func(t *testing.T) {
+		mockWatchlistRepo, mockUserRepo, mockInstrumentRepo, _, watchlistService := setUp()
+
+		mockUserRepo.On("GetByID", uint(1)).Return(&models.User{ID: 1}, nil)
+		mockInstrumentRepo.On("GetByIDs", []uint{9}).Return(map[uint]*models.Instrument{}, nil)
+
+		err := watchlistService.CreateWatchlist(&models.Watchlist{UserID: 1, Name: "Tech", InstrumentIDs: []uint{9}})
+
+		assert.ErrorIs(t, err, ErrInvalidWatchlist)
+		mockWatchlistRepo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+
+	t.Run("Reject a watchlist without name", func(t *testing.T) {
+		_, mockUserRepo, _, _, watchlistService := setUp()
+
+		mockUserRepo.On("GetByID", uint(1)).Return(&models.User{ID: 1}, nil)
+
+		err := watchlistService.CreateWatchlist(&models.Watchlist{UserID: 1, Name: " "})
+
+		assert.ErrorIs(t, err, ErrInvalidWatchlist)
+	})
+
+	t.Run("Adding beyond the size limit fails", func(t *testing.T) {
+		mockWatchlistRepo, _, _, _, watchlistService := setUp()
+
+		full := make([]uint, models.MaxWatchlistSize)
+		for i := range full {
+			full[i] = uint(i + 1)
+		}
+		mockWatchlistRepo.On("GetByID", uint(4)).Return(&models.Watchlist{ID: 4, UserID: 1, Name: "Full", InstrumentIDs: full}, nil)
+
+		watchlist, err := watchlistService.AddInstrument(4, 100)
+
+		assert.ErrorIs(t, err, ErrWatchlistFull)
+		assert.ErrorIs(t, err, ErrInvalidWatchlist)
+		assert.Nil(t, watchlist)
+		mockWatchlistRepo.AssertNotCalled(t, "Update", mock.Anything)
+	})
+
+	t.Run("Add and remove instruments", func(t *testing.T) {
+		mockWatchlistRepo, _, mockInstrumentRepo, _, watchlistService := setUp()
+
+		mockWatchlistRepo.On("GetByID", uint(5)).Return(func(uint) *models.Watchlist {
+			return &models.Watchlist{ID: 5, UserID: 1, Name: "Local", InstrumentIDs: []uint{2}}
+		}, nil)
+		mockInstrumentRepo.On("GetByIDs", []uint{2, 3}).Return(instruments, nil)
+		mockWatchlistRepo.On("Update", mock.AnythingOfType("*models.Watchlist")).Return(nil)
+
+		watchlist, err := watchlistService.AddInstrument(5, 3)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []uint{2, 3}, watchlist.InstrumentIDs)
+
+		watchlist, err = watchlistService.RemoveInstrument(5, 2)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []uint{}, watchlist.InstrumentIDs)
+
+		_, err = watchlistService.RemoveInstrument(5, 3)
+
+		assert.ErrorIs(t, err, ErrInvalidWatchlist)
+		mockWatchlistRepo.AssertNumberOfCalls(t, "Update", 2)
+	})
+
+	t.Run("Quotes sorted by day change", func(t *testing.T) {
+		mockWatchlistRepo, _, mockInstrumentRepo, mockMarketDataRepo, watchlistService := setUp()
+
+		now := time.Now()
+		mockWatchlistRepo.On("GetByID", uint(6)).Return(&models.Watchlist{ID: 6, UserID: 1, Name: "Mixed", InstrumentIDs: []uint{1, 2, 3}}, nil)
+		mockInstrumentRepo.On("GetByIDs", []uint{1, 2, 3}).Return(instruments, nil)
+		mockMarketDataRepo.On("GetLatestMarketDataForInstruments", []uint{1, 2, 3}).Return(map[uint]*models.MarketData{
+			1: {InstrumentID: 1, Close: 190, PreviousClose: 200, DateTime: now},
+			2: {InstrumentID: 2, Close: 4400, PreviousClose: 4000, DateTime: now},
+		}, nil)
+
+		snapshot, err := watchlistService.GetQuotes(6, "change", "desc")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Mixed", snapshot.Name)
+		assert.Len(t, snapshot.Quotes, 3)
+		assert.Equal(t, "GGAL", snapshot.Quotes[0].Ticker)
+		assert.Equal(t, float64(400), snapshot.Quotes[0].DayChange)
+		assert.InDelta(t, 10, snapshot.Quotes[0].DayChangePercent, 1e-9)
+		assert.Equal(t, &now, snapshot.Quotes[0].PriceTime)
+		// Without market data the quote is empty and sorts as unchanged
+		assert.Equal(t, "YPFD", snapshot.Quotes[1].Ticker)
+		assert.Nil(t, snapshot.Quotes[1].PriceTime)
+		assert.Equal(t, "AAPL", snapshot.Quotes[2].Ticker)
+		assert.Equal(t, "USD", snapshot.Quotes[2].Currency)
+		assert.InDelta(t, -5, snapshot.Quotes[2].DayChangePercent, 1e-9)
+	})
+
+	t.Run("Invalid sort", func(t *testing.T) {
+		_, _, _, _, watchlistService := setUp()
+
+		snapshot, err := watchlistService.GetQuotes(6, "volume", "asc")
+
+		assert.ErrorIs(t, err, ErrInvalidWatchlistSort)
+		assert.Nil(t, snapshot)
+
+		_, err = watchlistService.GetQuotes(6, "ticker", "up")
+
+		assert.ErrorIs(t, err, ErrInvalidWatchlistSort)
+	})
+}
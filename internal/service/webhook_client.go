@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// blockedPrefixes are the ranges webhooks may not point to on top of
+// loopback, private, link-local (which covers the 169.254.169.254 cloud
+// metadata endpoint), multicast and unspecified addresses
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+}
+
+// publicAddress reports whether ip is a public unicast address that
+// webhooks may be delivered to
+func publicAddress(ip net.IP) bool {
+	addr, ok := netip.AddrFromSlice(ip)
+	if !ok {
+		return false
+	}
+	addr = addr.Unmap()
+	if addr.IsLoopback() || addr.IsPrivate() || addr.IsLinkLocalUnicast() || addr.IsMulticast() ||
+		addr.IsUnspecified() || !addr.IsGlobalUnicast() {
+		return false
+	}
+	for _, prefix := range blockedPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// checkWebhookURL validates that webhookURL is an http(s) URL whose host
+// resolves only to public addresses
+func checkWebhookURL(ctx context.Context, lookupIP func(ctx context.Context, host string) ([]net.IPAddr, error), webhookURL string) error {
+	parsed, err := url.Parse(webhookURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Hostname() == "" {
+		return fmt.Errorf("%w: %q is not an http(s) URL", ErrInvalidWebhook, webhookURL)
+	}
+
+	host := parsed.Hostname()
+	addrs := []net.IPAddr{{IP: net.ParseIP(host)}}
+	if addrs[0].IP == nil {
+		if addrs, err = lookupIP(ctx, host); err != nil {
+			return fmt.Errorf("%w: cannot resolve %q", ErrInvalidWebhook, host)
+		}
+	}
+	for _, addr := range addrs {
+		if !publicAddress(addr.IP) {
+			return fmt.Errorf("%w: %q resolves to the non-public address %s", ErrInvalidWebhook, host, addr.IP)
+		}
+	}
+	return nil
+}
+
+// newWebhookClient returns a client that refuses to connect to non-public
+// addresses. The check runs on every connection, after name resolution, so
+// it also covers redirects and hosts that resolve differently than when the
+// webhook was set.
+func newWebhookClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 5 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !publicAddress(ip) {
+				return fmt.Errorf("webhook address %s is not public", host)
+			}
+			return nil
+		},
+	}
+	return &http.Client{
+		Timeout: 10 * time.Second,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: 5 * time.Second,
+			MaxIdleConns:        10,
+			IdleConnTimeout:     90 * time.Second,
+		},
+	}
+}
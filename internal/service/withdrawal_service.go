@@ -0,0 +1,116 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/NahuelDT/portfolio-api/internal/models"
+	"github.com/NahuelDT/portfolio-api/internal/repository"
+)
+
+var ErrInvalidWithdrawal = errors.New("invalid withdrawal decision")
+
+// WithdrawalRule flags a CASH_OUT order for an admin's approval by returning
+// the reason, or "" to let it through. availableCash is the settled cash of
+// the account that is not already held.
+type WithdrawalRule func(order *models.Order, availableCash float64) string
+
+// WithdrawalThresholdRule flags withdrawals above the threshold of their
+// currency, or above the one keyed by "" for currencies without their own
+func WithdrawalThresholdRule(thresholds map[string]float64) WithdrawalRule {
+	return func(order *models.Order, availableCash float64) string {
+		threshold, ok := thresholds[order.Currency]
+		if !ok {
+			threshold, ok = thresholds[""]
+		}
+		if ok && order.Size > threshold {
+			return fmt.Sprintf("amount above the %s %.2f approval threshold", order.Currency, threshold)
+		}
+		return ""
+	}
+}
+
+// EmptyAccountWithdrawalRule flags withdrawals that take out all the cash
+// available in the account
+func EmptyAccountWithdrawalRule(order *models.Order, availableCash float64) string {
+	if order.Size >= availableCash {
+		return "withdraws all the available cash of the account"
+	}
+	return ""
+}
+
+type WithdrawalService struct {
+	withdrawalRepo repository.WithdrawalRepositorer
+}
+
+func NewWithdrawalService(withdrawalRepo repository.WithdrawalRepositorer) *WithdrawalService {
+	return &WithdrawalService{withdrawalRepo: withdrawalRepo}
+}
+
+// GetPending lists the withdrawals waiting for approval
+func (s *WithdrawalService) GetPending() ([]models.Order, error) {
+	return s.withdrawalRepo.GetPending()
+}
+
+// Approve fills a withdrawal pending approval, taking the held cash out of
+// the account
+func (s *WithdrawalService) Approve(orderID uint, reviewer, note string) (*models.Order, error) {
+	audit, err := newWithdrawalDecision(models.WithdrawalApproved, reviewer, note)
+	if err != nil {
+		return nil, err
+	}
+	order, err := s.withdrawalRepo.Approve(orderID, audit.CreatedAt, audit)
+	if errors.Is(err, repository.ErrWithdrawalNotPending) || errors.Is(err, repository.ErrInsufficientFunds) {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidWithdrawal, err)
+	}
+	return order, err
+}
+
+// Reject rejects a withdrawal pending approval, releasing the held cash. A
+// note explaining the rejection is required.
+func (s *WithdrawalService) Reject(orderID uint, reviewer, note string) (*models.Order, error) {
+	audit, err := newWithdrawalDecision(models.WithdrawalRejected, reviewer, note)
+	if err != nil {
+		return nil, err
+	}
+	if audit.Note == "" {
+		return nil, fmt.Errorf("%w: a note is required to reject a withdrawal", ErrInvalidWithdrawal)
+	}
+	order, err := s.withdrawalRepo.Reject(orderID, audit)
+	if errors.Is(err, repository.ErrWithdrawalNotPending) {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidWithdrawal, err)
+	}
+	return order, err
+}
+
+// GetAuditTrail lists the request and decisions of a withdrawal
+func (s *WithdrawalService) GetAuditTrail(orderID uint) ([]models.WithdrawalAudit, error) {
+	return s.withdrawalRepo.GetAuditTrail(orderID)
+}
+
+func newWithdrawalDecision(action, reviewer, note string) (*models.WithdrawalAudit, error) {
+	reviewer = strings.TrimSpace(reviewer)
+	if reviewer == "" {
+		return nil, fmt.Errorf("%w: reviewer is required", ErrInvalidWithdrawal)
+	}
+	return &models.WithdrawalAudit{
+		Action:    action,
+		Reviewer:  reviewer,
+		Note:      strings.TrimSpace(note),
+		CreatedAt: time.Now(),
+	}, nil
+}
+
+// flagWithdrawal runs the withdrawal rules on a CASH_OUT order and returns
+// the reasons of the ones that flag it
+func flagWithdrawal(rules []WithdrawalRule, order *models.Order, availableCash float64) []string {
+	reasons := make([]string, 0)
+	for _, rule := range rules {
+		if reason := rule(order, availableCash); reason != "" {
+			reasons = append(reasons, reason)
+		}
+	}
+	return reasons
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	mocks "github.com/NahuelDT/portfolio-api/internal/mocks/repository"
+	"github.com/NahuelDT/portfolio-api/internal/models"
+	"github.com/NahuelDT/portfolio-api/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestWithdrawalApproval(t *testing.T) {
+	setUp := func() (*mocks.WithdrawalRepositorer, *WithdrawalService) {
+		mockWithdrawalRepo := new(mocks.WithdrawalRepositorer)
+		return mockWithdrawalRepo, NewWithdrawalService(mockWithdrawalRepo)
+	}
+
+	t.Run("Approve a pending withdrawal", func(t *testing.T) {
+		mockWithdrawalRepo, withdrawalService := setUp()
+
+		var audit *models.WithdrawalAudit
+		mockWithdrawalRepo.On("Approve", uint(7), mock.AnythingOfType("time.Time"), mock.AnythingOfType("*models.WithdrawalAudit")).Run(func(args mock.Arguments) {
+			audit = args.Get(2).(*models.WithdrawalAudit)
+		}).Return(&models.Order{ID: 7, Side: "CASH_OUT", Status: "FILLED"}, nil)
+
+		order, err := withdrawalService.Approve(7, " compliance ", "")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "FILLED", order.Status)
+		assert.Equal(t, models.WithdrawalApproved, audit.Action)
+		assert.Equal(t, "compliance", audit.Reviewer)
+		assert.WithinDuration(t, time.Now(), audit.CreatedAt, time.Minute)
+		mockWithdrawalRepo.AssertExpectations(t)
+	})
+
+	t.Run("Reject a pending withdrawal with a note", func(t *testing.T) {
+		mockWithdrawalRepo, withdrawalService := setUp()
+
+		var audit *models.WithdrawalAudit
+		mockWithdrawalRepo.On("Reject", uint(7), mock.AnythingOfType("*models.WithdrawalAudit")).Run(func(args mock.Arguments) {
+			audit = args.Get(1).(*models.WithdrawalAudit)
+		}).Return(&models.Order{ID: 7, Side: "CASH_OUT", Status: "REJECTED"}, nil)
+
+		order, err := withdrawalService.Reject(7, "compliance", "Unverified destination account")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "REJECTED", order.Status)
+		assert.Equal(t, models.WithdrawalRejected, audit.Action)
+		assert.Equal(t, "Unverified destination account", audit.Note)
+		mockWithdrawalRepo.AssertExpectations(t)
+	})
+
+	t.Run("Decisions need a reviewer and rejections a note", func(t *testing.T) {
+		mockWithdrawalRepo, withdrawalService := setUp()
+
+		_, err := withdrawalService.Approve(7, "  ", "")
+		assert.ErrorIs(t, err, ErrInvalidWithdrawal)
+		_, err = withdrawalService.Reject(7, "compliance", " ")
+		assert.ErrorIs(t, err, ErrInvalidWithdrawal)
+		mockWithdrawalRepo.AssertNotCalled(t, "Approve", mock.Anything, mock.Anything, mock.Anything)
+		mockWithdrawalRepo.AssertNotCalled(t, "Reject", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Withdrawals that are not pending or no longer covered are invalid", func(t *testing.T) {
+		mockWithdrawalRepo, withdrawalService := setUp()
+
+		mockWithdrawalRepo.On("Approve", uint(7), mock.Anything, mock.Anything).Return(nil, repository.ErrWithdrawalNotPending)
+		mockWithdrawalRepo.On("Approve", uint(8), mock.Anything, mock.Anything).Return(nil, repository.ErrInsufficientFunds)
+		mockWithdrawalRepo.On("Reject", uint(7), mock.Anything).Return(nil, repository.ErrWithdrawalNotPending)
+
+		_, err := withdrawalService.Approve(7, "compliance", "")
+		assert.ErrorIs(t, err, ErrInvalidWithdrawal)
+		_, err = withdrawalService.Approve(8, "compliance", "")
+		assert.ErrorIs(t, err, ErrInvalidWithdrawal)
+		_, err = withdrawalService.Reject(7, "compliance", "Duplicate request")
+		assert.ErrorIs(t, err, ErrInvalidWithdrawal)
+	})
+
+	t.Run("Threshold rule falls back to the default threshold", func(t *testing.T) {
+		rule := WithdrawalThresholdRule(map[string]float64{"": 1000, "USD": 100})
+
+		assert.NotEmpty(t, rule(&models.Order{Currency: "USD", Size: 150}, 5000))
+		assert.Empty(t, rule(&models.Order{Currency: "ARS", Size: 150}, 5000))
+		assert.NotEmpty(t, rule(&models.Order{Currency: "ARS", Size: 1500}, 5000))
+		assert.Empty(t, WithdrawalThresholdRule(map[string]float64{})(&models.Order{Currency: "ARS", Size: 1500}, 5000))
+	})
+}
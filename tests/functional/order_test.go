@@ -1,9 +1,11 @@
 package functional_tests
 
 import (
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/NahuelDT/portfolio-api/internal/migrations"
 	"github.com/NahuelDT/portfolio-api/internal/models"
 	"github.com/NahuelDT/portfolio-api/internal/repository"
 	"github.com/NahuelDT/portfolio-api/internal/service"
@@ -16,12 +18,15 @@ func setupTest(t *testing.T) (*gorm.DB, *service.OrderService, *repository.Order
 	dsn := "host=jelani.db.elephantsql.com user=gwxuuoxr password=RHT87Wu0WhMrwy1e7da0OFDaEzMDGtIk dbname=gwxuuoxr port=5432 sslmode=disable TimeZone=UTC"
 	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
 	assert.NoError(t, err)
+	assert.NoError(t, migrations.Migrate(db))
 
 	orderRepo := repository.NewOrderRepository(db)
 	userRepo := repository.NewUserRepository(db)
 	instrumentRepo := repository.NewInstrumentRepository(db)
 	marketDataRepo := repository.NewMarketDataRepository(db)
-	orderService := service.NewOrderService(orderRepo, userRepo, instrumentRepo, marketDataRepo)
+	positionRepo := repository.NewPositionRepository(db)
+	accountRepo := repository.NewAccountRepository(db)
+	orderService := service.NewOrderService(orderRepo, userRepo, instrumentRepo, marketDataRepo, positionRepo, accountRepo, models.DefaultSettlementCycle, repository.NewWithdrawalRepository(db))
 
 	return db, orderService, orderRepo, userRepo, instrumentRepo, marketDataRepo
 }
@@ -70,7 +75,7 @@ func TestMarketBuyOrder(t *testing.T) {
 	assert.Equal(t, "FILLED", createdOrder.Status)
 	assert.Equal(t, 150.0, createdOrder.Price)
 
-	balance, err := orderRepo.GetUserCashBalance(user.ID)
+	balance, err := repository.NewLedgerRepository(db).GetCashBalance(user.ID, models.DefaultCurrency)
 	assert.NoError(t, err)
 	assert.Equal(t, 1500.0, balance)
 
@@ -135,7 +140,7 @@ func TestMarketSellOrder(t *testing.T) {
 	assert.Equal(t, "FILLED", createdOrder.Status)
 	assert.Equal(t, 150.0, createdOrder.Price)
 
-	balance, err := orderRepo.GetUserCashBalance(user.ID)
+	balance, err := repository.NewLedgerRepository(db).GetCashBalance(user.ID, models.DefaultCurrency)
 	assert.NoError(t, err)
 	assert.Equal(t, 2250.0, balance)
 
@@ -203,7 +208,7 @@ func TestLimitBuyOrder(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, "NEW", limitOrderAfterPriceChange.Status)
 
-	balance, err := orderRepo.GetUserCashBalance(user.ID)
+	balance, err := repository.NewLedgerRepository(db).GetCashBalance(user.ID, models.DefaultCurrency)
 	assert.NoError(t, err)
 	assert.InDelta(t, 3000.0, balance, 0.01)
 
@@ -254,7 +259,7 @@ func TestLimitSellOrder(t *testing.T) {
 	err = orderService.PlaceOrder(buyOrder, 0)
 	assert.NoError(t, err)
 
-	balanceAfterBuy, err := orderRepo.GetUserCashBalance(user.ID)
+	balanceAfterBuy, err := repository.NewLedgerRepository(db).GetCashBalance(user.ID, models.DefaultCurrency)
 	assert.NoError(t, err)
 	assert.InDelta(t, 1500.0, balanceAfterBuy, 0.01)
 
@@ -273,7 +278,7 @@ func TestLimitSellOrder(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, "NEW", createdOrder.Status)
 
-	finalBalance, err := orderRepo.GetUserCashBalance(user.ID)
+	finalBalance, err := repository.NewLedgerRepository(db).GetCashBalance(user.ID, models.DefaultCurrency)
 	assert.NoError(t, err)
 	assert.InDelta(t, 1500.0, finalBalance, 0.01)
 
@@ -328,7 +333,7 @@ func TestInsufficientFundsOrder(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, "REJECTED", createdOrder.Status)
 
-	balance, err := orderRepo.GetUserCashBalance(user.ID)
+	balance, err := repository.NewLedgerRepository(db).GetCashBalance(user.ID, models.DefaultCurrency)
 	assert.NoError(t, err)
 	assert.Equal(t, 1000.0, balance)
 
@@ -339,6 +344,143 @@ func TestInsufficientFundsOrder(t *testing.T) {
 	db.Unscoped().Delete(user)
 }
 
+func TestConcurrentBuyOrders(t *testing.T) {
+	db, orderService, orderRepo, userRepo, _, _ := setupTest(t)
+
+	user := &models.User{Email: "test@example.com", AccountNumber: "TEST123"}
+	err := userRepo.Create(user)
+	assert.NoError(t, err)
+
+	cashInOrder := &models.Order{
+		UserID:       user.ID,
+		InstrumentID: 66,
+		Side:         "CASH_IN",
+		Type:         "MARKET",
+		Size:         1500,
+	}
+	err = orderService.PlaceOrder(cashInOrder, 0)
+	assert.NoError(t, err)
+
+	// Both purchases pass a check made before either is stored, but the
+	// cash only covers one of them
+	buyOrders := make([]*models.Order, 2)
+	var wg sync.WaitGroup
+	for i := range buyOrders {
+		buyOrders[i] = &models.Order{UserID: user.ID, InstrumentID: 66, Side: "BUY", Type: "MARKET", Size: 10, Price: 150, Status: "FILLED", DateTime: time.Now()}
+		wg.Add(1)
+		go func(order *models.Order) {
+			defer wg.Done()
+			assert.NoError(t, orderRepo.Create(order))
+		}(buyOrders[i])
+	}
+	wg.Wait()
+
+	assert.ElementsMatch(t, []string{"FILLED", "REJECTED"}, []string{buyOrders[0].Status, buyOrders[1].Status})
+	balance, err := repository.NewPositionRepository(db).GetAccountCashBalance(user.ID, 0, models.DefaultCurrency)
+	assert.NoError(t, err)
+	assert.Equal(t, 0.0, balance)
+
+	db.Unscoped().Delete(buyOrders[0])
+	db.Unscoped().Delete(buyOrders[1])
+	db.Unscoped().Delete(cashInOrder)
+	db.Unscoped().Delete(user)
+}
+
+func TestConcurrentFlaggedWithdrawals(t *testing.T) {
+	db, orderService, _, userRepo, _, _ := setupTest(t)
+	withdrawalRepo := repository.NewWithdrawalRepository(db)
+
+	user := &models.User{Email: "test@example.com", AccountNumber: "TEST123"}
+	err := userRepo.Create(user)
+	assert.NoError(t, err)
+
+	cashInOrder := &models.Order{
+		UserID:       user.ID,
+		InstrumentID: 66,
+		Side:         "CASH_IN",
+		Type:         "MARKET",
+		Size:         1500,
+	}
+	err = orderService.PlaceOrder(cashInOrder, 0)
+	assert.NoError(t, err)
+
+	// Both withdrawals pass a check made before either is held, but the
+	// cash only covers one of them
+	cashOutOrders := make([]*models.Order, 2)
+	errs := make([]error, 2)
+	var wg sync.WaitGroup
+	for i := range cashOutOrders {
+		cashOutOrders[i] = &models.Order{UserID: user.ID, InstrumentID: 66, Side: "CASH_OUT", Type: "MARKET", Size: 1000, Status: models.PendingApprovalStatus, Currency: models.DefaultCurrency, DateTime: time.Now()}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = withdrawalRepo.Hold(cashOutOrders[i], &models.WithdrawalAudit{UserID: user.ID, Action: models.WithdrawalRequested, Amount: 1000, Currency: models.DefaultCurrency, CreatedAt: time.Now()})
+		}(i)
+	}
+	wg.Wait()
+
+	held := 0
+	for _, err := range errs {
+		if err == nil {
+			held++
+		} else {
+			assert.ErrorIs(t, err, repository.ErrInsufficientFunds)
+		}
+	}
+	assert.Equal(t, 1, held)
+	pending, err := withdrawalRepo.GetHeldCash(user.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, pending, 1) {
+		assert.Equal(t, 1000.0, pending[0].Balance)
+	}
+
+	for _, order := range cashOutOrders {
+		if order.ID != 0 {
+			db.Where("orderid = ?", order.ID).Delete(&models.WithdrawalAudit{})
+			db.Unscoped().Delete(order)
+		}
+	}
+	db.Unscoped().Delete(cashInOrder)
+	db.Unscoped().Delete(user)
+}
+
+func TestBuyOrderCheckedAgainstLedger(t *testing.T) {
+	db, orderService, orderRepo, userRepo, _, _ := setupTest(t)
+
+	user := &models.User{Email: "test@example.com", AccountNumber: "TEST123"}
+	err := userRepo.Create(user)
+	assert.NoError(t, err)
+
+	cashInOrder := &models.Order{
+		UserID:       user.ID,
+		InstrumentID: 66,
+		Side:         "CASH_IN",
+		Type:         "MARKET",
+		Size:         1000,
+	}
+	err = orderService.PlaceOrder(cashInOrder, 0)
+	assert.NoError(t, err)
+
+	// A projected balance that drifted from the ledger does not let the
+	// purchase through
+	err = db.Model(&models.CashBalance{}).
+		Where("userid = ? AND accountid = ? AND currency = ?", user.ID, 0, models.DefaultCurrency).
+		Update("balance", 1000000).Error
+	assert.NoError(t, err)
+
+	buyOrder := &models.Order{UserID: user.ID, InstrumentID: 66, Side: "BUY", Type: "MARKET", Size: 10, Price: 150, Status: "FILLED", DateTime: time.Now()}
+	assert.NoError(t, orderRepo.Create(buyOrder))
+	assert.Equal(t, "REJECTED", buyOrder.Status)
+
+	balance, err := repository.NewPositionRepository(db).GetAccountCashBalance(user.ID, 0, models.DefaultCurrency)
+	assert.NoError(t, err)
+	assert.Equal(t, 1000.0, balance)
+
+	db.Unscoped().Delete(buyOrder)
+	db.Unscoped().Delete(cashInOrder)
+	db.Unscoped().Delete(user)
+}
+
 func TestCancelOrder(t *testing.T) {
 	db, orderService, orderRepo, userRepo, instrumentRepo, marketDataRepo := setupTest(t)
 
@@ -390,7 +532,7 @@ func TestCancelOrder(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, "CANCELLED", cancelledOrder.Status)
 
-	balance, err := orderRepo.GetUserCashBalance(user.ID)
+	balance, err := repository.NewLedgerRepository(db).GetCashBalance(user.ID, models.DefaultCurrency)
 	assert.NoError(t, err)
 	assert.Equal(t, 3000.0, balance)
 